@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+// ModuleAliases maps short names to module paths, so that "grpc" can be
+// passed where google.golang.org/grpc is expected. An alias also expands
+// as the first element of a longer path: "grpc/examples" is
+// google.golang.org/grpc/examples.
+type ModuleAliases map[string]string
+
+// ParseModuleAliases parses a comma-separated list of name=path pairs.
+func ParseModuleAliases(list string) (ModuleAliases, error) {
+	aliases := make(ModuleAliases)
+
+	for _, pair := range strings.Split(list, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		name, target, ok := strings.Cut(pair, "=")
+		name, target = strings.TrimSpace(name), strings.TrimSpace(target)
+
+		if !ok || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid module alias %q: want name=module/path", pair)
+		}
+
+		if err := module.CheckPath(target); err != nil {
+			return nil, fmt.Errorf("invalid module alias %q: %w", pair, err)
+		}
+
+		if prev, ok := aliases[name]; ok && prev != target {
+			return nil, fmt.Errorf("module alias %q defined twice", name)
+		}
+
+		aliases[name] = target
+	}
+
+	return aliases, nil
+}
+
+// Resolve expands an alias at the start of mod and reports whether it
+// did.
+func (a ModuleAliases) Resolve(mod string) (string, bool) {
+	first, rest, hasRest := strings.Cut(mod, "/")
+
+	target, ok := a[first]
+	if !ok {
+		return mod, false
+	}
+
+	if hasRest {
+		return target + "/" + rest, true
+	}
+
+	return target, true
+}
+
+// Middleware expands aliases in the module argument of tool calls before
+// they are validated.
+func (a ModuleAliases) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok && method == "tools/call" && len(a) > 0 {
+				err := rewriteArguments(call, func(args map[string]any) bool {
+					mod, _ := args["module"].(string)
+
+					resolved, ok := a.Resolve(mod)
+					if ok {
+						args["module"] = resolved
+					}
+
+					return ok
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+type listAliasesInput struct{}
+
+func handleListAliases(aliases ModuleAliases) (*mcp.CallToolResult, any, error) {
+	if len(aliases) == 0 {
+		return textResult("No module aliases are configured; start the server with " +
+			"-module-aliases name=module/path,... to define some."), nil, nil
+	}
+
+	var sb strings.Builder
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ALIAS\tMODULE")
+
+	for _, name := range sortedKeys(aliases) {
+		fmt.Fprintf(tw, "%s\t%s\n", name, aliases[name])
+	}
+
+	_ = tw.Flush()
+
+	return textResult(sb.String()), nil, nil
+}
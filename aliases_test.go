@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestParseModuleAliases(t *testing.T) {
+	aliases, err := ParseModuleAliases(" k8s = k8s.io/kubernetes, grpc=google.golang.org/grpc,")
+	mustf(t, err, "parse aliases")
+
+	if len(aliases) != 2 || aliases["k8s"] != "k8s.io/kubernetes" || aliases["grpc"] != "google.golang.org/grpc" {
+		t.Errorf("aliases = %v", aliases)
+	}
+
+	invalid := []string{"grpc", "=google.golang.org/grpc", "a/b=example.com/m", "x=notamodule", "x=a.com/m,x=b.com/m"}
+
+	for _, list := range invalid {
+		if _, err := ParseModuleAliases(list); err == nil {
+			t.Errorf("ParseModuleAliases(%q) should fail", list)
+		}
+	}
+}
+
+func TestModuleAliases_Resolve(t *testing.T) {
+	aliases := ModuleAliases{"grpc": "google.golang.org/grpc"}
+
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"grpc", "google.golang.org/grpc", true},
+		{"grpc/examples", "google.golang.org/grpc/examples", true},
+		{"grpcx", "grpcx", false},
+		{"google.golang.org/grpc", "google.golang.org/grpc", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := aliases.Resolve(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHandleListAliases(t *testing.T) {
+	result, _, _ := handleListAliases(ModuleAliases{"k8s": "k8s.io/kubernetes", "grpc": "google.golang.org/grpc"})
+	text := resultText(t, result)
+
+	want := "ALIAS  MODULE\ngrpc   google.golang.org/grpc\nk8s    k8s.io/kubernetes\n"
+	if text != want {
+		t.Errorf("got:\n%s\nwant:\n%s", text, want)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+const (
+	defaultAlternativesLimit = 8
+	alternativesSearchLimit  = 30
+	maxSynopsisWidth         = 70
+)
+
+// alternative is a module found for an alternatives query.
+type alternative struct {
+	module     string
+	latest     *VersionInfo
+	repo       *repoInfo
+	synopsis   string
+	importedBy int
+	dependents int     // dependent packages on deps.dev, -1 if unknown
+	relevance  float64 // 1 for pkg.go.dev's first result, down to 0 for its last
+}
+
+// Weights of the popularity signals in an alternative's score.
+const (
+	relevanceWeight  = 1.0
+	importedByWeight = 2.0
+	dependentsWeight = 1.0
+	starsWeight      = 1.0
+	recencyWeight    = 1.0
+)
+
+// score combines an alternative's search relevance, importer and
+// dependent counts, stars and the age of its latest release into 0-100.
+// Counts are scored on a log scale that reaches 1 at 100,000; a release
+// scores 1 in its first year and nothing after five.
+func (a *alternative) score(now time.Time) float64 {
+	logScale := func(n int) float64 { return min(math.Log10(1+float64(max(n, 0)))/5, 1) }
+
+	recency := 0.0
+	if !a.latest.Time.IsZero() {
+		years := now.Sub(a.latest.Time).Hours() / (24 * 365)
+		recency = min(max((5-years)/4, 0), 1)
+	}
+
+	total := relevanceWeight*a.relevance +
+		importedByWeight*logScale(a.importedBy) +
+		dependentsWeight*logScale(a.dependents) +
+		starsWeight*logScale(a.stars()) +
+		recencyWeight*recency
+
+	return 100 * total / (relevanceWeight + importedByWeight + dependentsWeight + starsWeight + recencyWeight)
+}
+
+// isModulePath reports whether s looks like a module path rather than a
+// free-text description.
+func isModulePath(s string) bool {
+	first, _, _ := strings.Cut(s, "/")
+
+	return !strings.ContainsAny(s, " \t") && strings.Contains(first, ".") && module.CheckPath(s) == nil
+}
+
+// capabilityQuery turns a module path into a search query describing what
+// it does, using its own pkg.go.dev synopsis. It falls back to the
+// repository name.
+func capabilityQuery(ctx context.Context, pkgsite *PkgsiteClient, mod string) string {
+	results, err := pkgsite.Search(ctx, mod, 5)
+	if err == nil {
+		for _, r := range results {
+			if r.path == mod && r.synopsis != "" {
+				return strings.TrimSuffix(trimPackagePrefix(r.synopsis), ".")
+			}
+		}
+	}
+
+	return repoName(mod)
+}
+
+// trimPackagePrefix drops a leading "Package foo" from a synopsis, keeping
+// the descriptive part.
+func trimPackagePrefix(synopsis string) string {
+	words := strings.Fields(synopsis)
+	if len(words) > 2 && words[0] == "Package" {
+		return strings.Join(words[2:], " ")
+	}
+
+	return synopsis
+}
+
+// findAlternatives searches pkg.go.dev for query and returns up to limit
+// distinct modules other than exclude, with their latest release and
+// deps.dev repository data.
+func findAlternatives(
+	ctx context.Context, proxy *ProxyClient, pkgsite *PkgsiteClient, depsDev *DepsDevClient,
+	query, exclude string, limit int,
+) ([]*alternative, error) {
+	results, err := pkgsite.Search(ctx, query, alternativesSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("search pkg.go.dev: %w", err)
+	}
+
+	found := make([]*alternative, len(results))
+
+	parallelEach(results, func(i int, r searchResult) {
+		mod, info, ok := resolveCandidateModule(ctx, proxy, r.path)
+		if !ok {
+			return
+		}
+
+		found[i] = &alternative{
+			module:     mod,
+			latest:     info,
+			synopsis:   r.synopsis,
+			importedBy: r.importedBy,
+			dependents: -1,
+			relevance:  1 - float64(i)/float64(len(results)),
+		}
+	})
+
+	seen := map[string]bool{exclude: true}
+
+	var alts []*alternative
+
+	for _, a := range found {
+		if a == nil || seen[a.module] || strings.HasPrefix(a.module, exclude+"/") {
+			continue
+		}
+
+		seen[a.module] = true
+
+		alts = append(alts, a)
+		if len(alts) == limit {
+			break
+		}
+	}
+
+	parallelEach(alts, func(_ int, a *alternative) {
+		a.repo = lookupRepo(ctx, depsDev, a.module, a.latest.Version)
+
+		if d, err := depsDev.Dependents(ctx, a.module, a.latest.Version); err == nil {
+			a.dependents = int(d.DependentCount)
+		}
+	})
+
+	return alts, nil
+}
+
+func (a *alternative) stars() int {
+	if a.repo == nil {
+		return 0
+	}
+
+	return a.repo.stars
+}
+
+func formatAlternatives(sb *strings.Builder, alts []*alternative, now time.Time) {
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "#\tMODULE\tLATEST\tRELEASED\tLICENSE\tSTARS\tIMPORTED BY\tDEPENDENTS\tSCORE")
+
+	for i, a := range alts {
+		license := "unknown"
+		if a.repo != nil && len(a.repo.licenses) > 0 {
+			license = strings.Join(a.repo.licenses, ", ")
+		}
+
+		released := "-"
+		if !a.latest.Time.IsZero() {
+			released = a.latest.Time.Format(time.DateOnly)
+		}
+
+		dependents := "-"
+		if a.dependents >= 0 {
+			dependents = fmt.Sprint(a.dependents)
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%.0f\n",
+			i+1, a.module, a.latest.Version, released, license, a.stars(), a.importedBy, dependents, a.score(now))
+	}
+
+	_ = tw.Flush()
+
+	sb.WriteString("\n")
+
+	for i, a := range alts {
+		if a.synopsis == "" {
+			continue
+		}
+
+		synopsis := a.synopsis
+		if len(synopsis) > maxSynopsisWidth {
+			synopsis = synopsis[:maxSynopsisWidth-3] + "..."
+		}
+
+		fmt.Fprintf(sb, "%d. %s\n", i+1, synopsis)
+	}
+}
+
+type alternativesInput struct {
+	Query string `json:"query" jsonschema:"Module path to find alternatives for, or a capability description"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of modules (default 8)"`
+	Sort  string `json:"sort,omitempty" jsonschema:"score (default), relevance (pkg.go.dev order), stars or imported_by"`
+}
+
+func handleAlternatives(
+	ctx context.Context, proxy *ProxyClient, pkgsite *PkgsiteClient,
+	depsDev *DepsDevClient, input alternativesInput,
+) (*mcp.CallToolResult, any, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return errorResult("A module path or capability description is required."), nil, nil
+	}
+
+	switch input.Sort {
+	case "", "score", "relevance", "stars", "imported_by":
+	default:
+		return errorResult(fmt.Sprintf("Unknown sort %q; use score, relevance, stars or imported_by.", input.Sort)), nil, nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultAlternativesLimit
+	}
+
+	exclude := ""
+	searchQuery := query
+
+	if isModulePath(query) {
+		exclude = query
+		searchQuery = capabilityQuery(ctx, pkgsite, query)
+	}
+
+	alts, err := findAlternatives(ctx, proxy, pkgsite, depsDev, searchQuery, exclude, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	switch input.Sort {
+	case "", "score":
+		sort.SliceStable(alts, func(i, j int) bool { return alts[i].score(now) > alts[j].score(now) })
+	case "stars":
+		sort.SliceStable(alts, func(i, j int) bool { return alts[i].stars() > alts[j].stars() })
+	case "imported_by":
+		sort.SliceStable(alts, func(i, j int) bool { return alts[i].importedBy > alts[j].importedBy })
+	}
+
+	var sb strings.Builder
+
+	if exclude != "" {
+		fmt.Fprintf(&sb, "Alternatives to %s (searched for %q):\n\n", exclude, searchQuery)
+	} else {
+		fmt.Fprintf(&sb, "Modules for %q:\n\n", searchQuery)
+	}
+
+	if len(alts) == 0 {
+		sb.WriteString("No matching modules found.\n")
+
+		return textResult(sb.String()), nil, nil
+	}
+
+	formatAlternatives(&sb, alts, now)
+
+	return textResult(sb.String()), nil, nil
+}
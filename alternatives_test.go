@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func alternativesServer() *httptest.Server {
+	snippet := func(path, synopsis, importedBy string) string {
+		return `<div class="SearchSnippet"><a href="/` + path + `" data-test-id="snippet-title">x</a>` +
+			`<p class="SearchSnippet-synopsis">` + synopsis + `</p>` +
+			`<a href="/` + path + `?tab=importedby"><strong>` + importedBy + `</strong></a></div>`
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/search":
+			switch r.URL.Query().Get("q") {
+			case "example.com/yaml":
+				_, _ = w.Write([]byte(snippet("example.com/yaml", "Package yaml parses YAML documents.", "10")))
+			case "parses YAML documents":
+				_, _ = w.Write([]byte(snippet("example.com/yaml", "Package yaml parses YAML.", "10") +
+					snippet("example.com/goyaml/decode", "Package decode decodes YAML.", "50") +
+					snippet("example.com/goyaml", "Package goyaml is a YAML library.", "900") +
+					snippet("example.com/fastyaml", "Package fastyaml is fast.", "5")))
+			}
+		case "/example.com/yaml/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.0.0","Time":"2020-01-01T00:00:00Z"}`))
+		case "/example.com/goyaml/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v3.0.1","Time":"2024-04-02T00:00:00Z"}`))
+		case "/example.com/fastyaml/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v0.4.0","Time":"2025-01-10T00:00:00Z"}`))
+		case "/v3/systems/go/packages/example.com%2Ffastyaml/versions/v0.4.0":
+			_, _ = w.Write([]byte(`{"licenses":["Apache-2.0"],
+"relatedProjects":[{"projectKey":{"id":"github.com/fast/yaml"},"relationType":"SOURCE_REPO"}]}`))
+		case "/v3/projects/github.com%2Ffast%2Fyaml":
+			_, _ = w.Write([]byte(`{"starsCount":5000}`))
+		case "/v3alpha/systems/go/packages/example.com%2Fgoyaml/versions/v3.0.1:dependents":
+			_, _ = w.Write([]byte(`{"dependentCount":"1200","directDependentCount":"300"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestHandleAlternatives(t *testing.T) {
+	ts := alternativesServer()
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+	pkgsite := &PkgsiteClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+	depsDev := &DepsDevClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+
+	result, _, err := handleAlternatives(context.Background(), proxy, pkgsite, depsDev,
+		alternativesInput{Query: "example.com/yaml", Sort: "relevance"})
+	mustf(t, err, "alternatives")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	if !strings.Contains(text, `Alternatives to example.com/yaml (searched for "parses YAML documents")`) {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+
+	if strings.Count(text, "example.com/yaml") != 1 {
+		t.Errorf("queried module listed as its own alternative:\n%s", text)
+	}
+
+	goyaml := strings.Index(text, "example.com/goyaml")
+	fastyaml := strings.Index(text, "example.com/fastyaml")
+
+	if goyaml < 0 || fastyaml < 0 || goyaml > fastyaml {
+		t.Errorf("expected goyaml then fastyaml in relevance order:\n%s", text)
+	}
+
+	if strings.Count(text, "example.com/goyaml ") != 1 {
+		t.Errorf("expected goyaml once after package deduplication:\n%s", text)
+	}
+
+	if !strings.Contains(text, "Apache-2.0") || !strings.Contains(text, "5000") ||
+		!strings.Contains(text, "2024-04-02") || !strings.Contains(text, "1200") {
+		t.Errorf("expected license, stars, release date and dependents:\n%s", text)
+	}
+
+	result, _, err = handleAlternatives(context.Background(), proxy, pkgsite, depsDev,
+		alternativesInput{Query: "parses YAML documents", Sort: "stars"})
+	mustf(t, err, "alternatives by stars")
+
+	text = result.Content[0].(*mcp.TextContent).Text
+
+	if !strings.HasPrefix(text, `Modules for "parses YAML documents"`) ||
+		strings.Index(text, "example.com/fastyaml") > strings.Index(text, "example.com/goyaml") {
+		t.Errorf("expected fastyaml first when sorted by stars:\n%s", text)
+	}
+}
+
+func TestAlternativeScore(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	popular := &alternative{
+		latest:     &VersionInfo{Time: now.AddDate(0, -3, 0)},
+		repo:       &repoInfo{stars: 18000},
+		importedBy: 40000,
+		dependents: 90000,
+		relevance:  0.5,
+	}
+	abandoned := &alternative{
+		latest:     &VersionInfo{Time: now.AddDate(-8, 0, 0)},
+		importedBy: 3,
+		dependents: -1,
+		relevance:  1,
+	}
+
+	if p, a := popular.score(now), abandoned.score(now); p <= a || p > 100 || a < 0 {
+		t.Errorf("popular = %.1f, abandoned = %.1f; want the popular module ahead, within 0-100", p, a)
+	}
+
+	top := &alternative{
+		latest: &VersionInfo{Time: now}, repo: &repoInfo{stars: 1e6},
+		importedBy: 1e6, dependents: 1e6, relevance: 1,
+	}
+	if got := top.score(now); got != 100 {
+		t.Errorf("top score = %.1f, want 100", got)
+	}
+}
+
+func TestHandleAlternatives_BadSort(t *testing.T) {
+	result, _, err := handleAlternatives(context.Background(), nil, nil, nil,
+		alternativesInput{Query: "yaml", Sort: "bogus"})
+	mustf(t, err, "alternatives")
+
+	if !result.IsError {
+		t.Error("expected error result for unknown sort")
+	}
+}
+
+func TestIsModulePath(t *testing.T) {
+	for s, want := range map[string]bool{
+		"github.com/owner/repo": true,
+		"example.com/yaml":      true,
+		"yaml parser":           false,
+		"http router":           false,
+		"yaml":                  false,
+	} {
+		if got := isModulePath(s); got != want {
+			t.Errorf("isModulePath(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// apiDecl is an exported declaration: its signature as written and, for
+// comparing versions, the signature without parameter names.
+type apiDecl struct {
+	sig string
+	key string
+}
+
+// packageAPI maps the names of a package's exported declarations, such as
+// Client, Client.Do or Options.Timeout, to the declarations.
+type packageAPI map[string]apiDecl
+
+// moduleAPI returns the exported API of the importable packages under
+// prefix by package directory, "." being the root. Test files, commands
+// and internal packages are left out. When files for different build
+// constraints declare the same name, the first by path wins.
+func moduleAPI(mf moduleFiles, prefix string) (map[string]packageAPI, error) {
+	fset, files, err := parseGoFiles(mf, prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	api := make(map[string]packageAPI)
+
+	for _, f := range files {
+		dir := path.Dir(f.path)
+		if strings.HasSuffix(f.path, "_test.go") || f.ast.Name == nil || f.ast.Name.Name == "main" || isInternal(dir) {
+			continue
+		}
+
+		pkg, ok := api[dir]
+		if !ok {
+			pkg = make(packageAPI)
+			api[dir] = pkg
+		}
+
+		add := func(name, sig, key string) {
+			if _, ok := pkg[name]; !ok {
+				pkg[name] = apiDecl{sig: sig, key: key}
+			}
+		}
+
+		for _, decl := range f.ast.Decls {
+			addDeclAPI(fset, decl, add)
+		}
+	}
+
+	return api, nil
+}
+
+// isInternal reports whether the package in dir can only be imported from
+// within the module.
+func isInternal(dir string) bool {
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func addDeclAPI(fset *token.FileSet, decl ast.Decl, add func(name, sig, key string)) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if !d.Name.IsExported() {
+			return
+		}
+
+		name := d.Name.Name
+
+		if d.Recv != nil {
+			recv := receiverName(d)
+			if !token.IsExported(recv) {
+				return
+			}
+
+			name = recv + "." + name
+		}
+
+		fn := *d
+		fn.Doc, fn.Body = nil, nil
+		sig := printNode(fset, &fn)
+
+		fn.Recv, fn.Type = unnamedFields(d.Recv), unnamedFuncType(d.Type)
+		add(name, sig, printNode(fset, &fn))
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				addTypeAPI(fset, s, add)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					if !n.IsExported() {
+						continue
+					}
+
+					sig := d.Tok.String() + " " + n.Name
+					if s.Type != nil {
+						sig += " " + printNode(fset, s.Type)
+					}
+
+					add(n.Name, sig, sig)
+				}
+			}
+		}
+	}
+}
+
+// addTypeAPI adds an exported type. The fields of structs and the methods
+// of interfaces are declarations of their own, so that a change names the
+// member rather than the whole type.
+func addTypeAPI(fset *token.FileSet, s *ast.TypeSpec, add func(name, sig, key string)) {
+	if !s.Name.IsExported() {
+		return
+	}
+
+	name := s.Name.Name
+
+	spec := *s
+	spec.Doc, spec.Comment = nil, nil
+
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		spec.Type = ast.NewIdent("struct")
+		sig := "type " + printNode(fset, &spec)
+		add(name, sig, sig)
+
+		for _, field := range t.Fields.List {
+			typ := printNode(fset, field.Type)
+
+			if len(field.Names) == 0 {
+				if embedded := embeddedName(field.Type); token.IsExported(embedded) {
+					add(name+"."+embedded, "embedded "+typ, "embedded "+typ)
+				}
+			}
+
+			for _, n := range field.Names {
+				if n.IsExported() {
+					add(name+"."+n.Name, "field "+n.Name+" "+typ, "field "+n.Name+" "+typ)
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		spec.Type = ast.NewIdent("interface")
+		sig := "type " + printNode(fset, &spec)
+		add(name, sig, sig)
+
+		for _, m := range t.Methods.List {
+			ft, ok := m.Type.(*ast.FuncType)
+			if !ok {
+				typ := printNode(fset, m.Type)
+				add(name+"."+typ, "embedded "+typ, "embedded "+typ)
+
+				continue
+			}
+
+			for _, n := range m.Names {
+				if n.IsExported() {
+					add(name+"."+n.Name,
+						"method "+n.Name+strings.TrimPrefix(printNode(fset, ft), "func"),
+						"method "+n.Name+strings.TrimPrefix(printNode(fset, unnamedFuncType(ft)), "func"))
+				}
+			}
+		}
+	default:
+		sig := "type " + printNode(fset, &spec)
+		add(name, sig, sig)
+	}
+}
+
+// embeddedName returns the field name of an embedded type, such as T for
+// *pkg.T[K].
+func embeddedName(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.SelectorExpr:
+			return t.Sel.Name
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func unnamedFuncType(ft *ast.FuncType) *ast.FuncType {
+	return &ast.FuncType{TypeParams: ft.TypeParams, Params: unnamedFields(ft.Params), Results: unnamedFields(ft.Results)}
+}
+
+// unnamedFields drops the names of parameters, which callers do not see.
+func unnamedFields(fl *ast.FieldList) *ast.FieldList {
+	if fl == nil {
+		return nil
+	}
+
+	out := &ast.FieldList{}
+
+	for _, f := range fl.List {
+		for range max(len(f.Names), 1) {
+			out.List = append(out.List, &ast.Field{Type: f.Type})
+		}
+	}
+
+	return out
+}
+
+// signatureSpacing removes the spaces and trailing commas left in a
+// declaration written over several lines once it is joined into one.
+var signatureSpacing = strings.NewReplacer("( ", "(", ", )", ")", " )", ")", ",)", ")")
+
+// printNode formats a declaration on one line.
+func printNode(fset *token.FileSet, node any) string {
+	var sb strings.Builder
+
+	if err := printer.Fprint(&sb, fset, node); err != nil {
+		return ""
+	}
+
+	return signatureSpacing.Replace(strings.Join(strings.Fields(sb.String()), " "))
+}
+
+// apiChange is a difference between the APIs of two versions: a removed
+// ('-'), changed ('~') or added ('+') declaration, or a whole package when
+// name is empty.
+type apiChange struct {
+	kind     byte
+	pkg      string
+	name     string
+	from, to string // signatures
+}
+
+// diffAPI compares the APIs of two versions, sorted by package and name.
+func diffAPI(from, to map[string]packageAPI) []apiChange {
+	dirs := sortedKeys(from)
+
+	for dir := range to {
+		if _, ok := from[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	sort.Strings(dirs)
+
+	var changes []apiChange
+
+	for _, dir := range dirs {
+		old, inFrom := from[dir]
+		cur, inTo := to[dir]
+
+		switch {
+		case !inTo:
+			changes = append(changes, apiChange{kind: '-', pkg: dir})
+
+			continue
+		case !inFrom:
+			changes = append(changes, apiChange{kind: '+', pkg: dir})
+
+			continue
+		}
+
+		names := sortedKeys(old)
+
+		for name := range cur {
+			if _, ok := old[name]; !ok {
+				names = append(names, name)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			o, inOld := old[name]
+			c, inCur := cur[name]
+
+			switch {
+			case !inCur:
+				changes = append(changes, apiChange{kind: '-', pkg: dir, name: name, from: o.sig})
+			case !inOld:
+				changes = append(changes, apiChange{kind: '+', pkg: dir, name: name, to: c.sig})
+			case o.key != c.key:
+				changes = append(changes, apiChange{kind: '~', pkg: dir, name: name, from: o.sig, to: c.sig})
+			}
+		}
+	}
+
+	return changes
+}
+
+// apiKindOrder lists declarations like go doc: constants, variables,
+// functions and types, and within a type its embedded types, fields and
+// methods.
+var apiKindOrder = map[string]int{
+	"const": 0, "var": 1, "func": 2, "type": 3,
+	"embedded": 0, "field": 1, "method": 2,
+}
+
+// sortAPINames sorts declaration names by kind, then name.
+func sortAPINames(pkg packageAPI, names []string) {
+	slices.SortFunc(names, func(a, b string) int {
+		kindA, _, _ := strings.Cut(pkg[a].sig, " ")
+		kindB, _, _ := strings.Cut(pkg[b].sig, " ")
+
+		return cmp.Or(cmp.Compare(apiKindOrder[kindA], apiKindOrder[kindB]), cmp.Compare(a, b))
+	})
+}
+
+// formatAPI lists the exported declarations of each package, the fields,
+// embedded types and methods of a type indented under it.
+func formatAPI(module, version string, api map[string]packageAPI) string {
+	total := 0
+
+	for _, pkg := range api {
+		total += len(pkg)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Exported API of %s@%s (%d packages, %d declarations):\n", module, version, len(api), total)
+
+	for _, dir := range sortedKeys(api) {
+		pkg := api[dir]
+
+		var top []string
+
+		members := make(map[string][]string)
+
+		for name := range pkg {
+			if typ, _, ok := strings.Cut(name, "."); ok {
+				members[typ] = append(members[typ], name)
+			} else {
+				top = append(top, name)
+			}
+		}
+
+		// Members of a type declared in a file that was left out are
+		// listed on their own.
+		for typ, names := range members {
+			if _, ok := pkg[typ]; !ok {
+				top = append(top, names...)
+			}
+		}
+
+		sortAPINames(pkg, top)
+
+		fmt.Fprintf(&sb, "\n%s (%d):\n", packageImportPath(module, dir), len(pkg))
+
+		for _, name := range top {
+			fmt.Fprintf(&sb, "  %s\n", pkg[name].sig)
+
+			if strings.Contains(name, ".") {
+				continue
+			}
+
+			sortAPINames(pkg, members[name])
+
+			for _, member := range members[name] {
+				fmt.Fprintf(&sb, "    %s\n", pkg[member].sig)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+type apiInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Package string `json:"package,omitempty" jsonschema:"Only this package (import path or directory), default all"`
+}
+
+func handleAPI(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input apiInput,
+) (*mcp.CallToolResult, any, error) {
+	var dir, prefix string
+
+	if input.Package != "" {
+		importPath := packageImportPath(input.Module, input.Package)
+		if !inModule(importPath, input.Module) {
+			return errorResult(fmt.Sprintf("Package %s is not in module %s.", importPath, input.Module)), nil, nil
+		}
+
+		dir = "."
+		if importPath != input.Module {
+			dir = strings.TrimPrefix(importPath, input.Module+"/")
+			prefix = dir + "/"
+		}
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	api, err := moduleAPI(mf, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dir != "" {
+		pkg, ok := api[dir]
+		if !ok {
+			return errorResult(fmt.Sprintf("No exported API for %s in %s@%s: the package is internal, a command "+
+				"or has no Go files.", input.Package, input.Module, version)), nil, nil
+		}
+
+		api = map[string]packageAPI{dir: pkg}
+	}
+
+	return textResult(formatAPI(input.Module, version, api)), nil, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestModuleAPI(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{
+		"client.go": "package m\n\n// Client talks to the server.\ntype Client struct {\n\tTimeout int\n\tretries int\n" +
+			"\t*Base\n}\n\nfunc (c *Client) Do(\n\treq string,\n) (int, error) { return 0, nil }\n\n" +
+			"func (c *Client) reset() {}\n\nfunc New(addr, name string) *Client { return nil }\n",
+		"values.go":           "package m\n\nconst (\n\tMax = 3\n\tmin = 1\n)\n\nvar Default Client\n",
+		"iface.go":            "package m\n\ntype Doer interface {\n\tDo(req string) (int, error)\n\tfmt.Stringer\n}\n",
+		"m_test.go":           "package m\n\nfunc Helper() {}\n",
+		"internal/x/x.go":     "package x\n\nfunc Hidden() {}\n",
+		"cmd/tool/main.go":    "package main\n\nfunc Run() {}\n",
+		"sub/sub.go":          "package sub\n\ntype ID = string\n",
+		"testdata/fixture.go": "package fixture\n\nfunc Fixture() {}\n",
+	})
+
+	api, err := moduleAPI(dirFiles{root: dir}, "")
+	mustf(t, err, "parse API")
+
+	if len(api) != 2 {
+		t.Fatalf("packages = %v, want . and sub", sortedKeys(api))
+	}
+
+	want := map[string]string{
+		"Client":            "type Client struct",
+		"Client.Timeout":    "field Timeout int",
+		"Client.Base":       "embedded *Base",
+		"Client.Do":         "func (c *Client) Do(req string) (int, error)",
+		"New":               "func New(addr, name string) *Client",
+		"Max":               "const Max",
+		"Default":           "var Default Client",
+		"Doer":              "type Doer interface",
+		"Doer.Do":           "method Do(req string) (int, error)",
+		"Doer.fmt.Stringer": "embedded fmt.Stringer",
+	}
+
+	for name, sig := range want {
+		if got := api["."][name].sig; got != sig {
+			t.Errorf("%s = %q, want %q", name, got, sig)
+		}
+	}
+
+	if len(api["."]) != len(want) {
+		t.Errorf("root API has %v, want %d declarations", sortedKeys(api["."]), len(want))
+	}
+
+	if got := api["sub"]["ID"].sig; got != "type ID = string" {
+		t.Errorf("sub.ID = %q", got)
+	}
+
+	wantText := "Exported API of example.com/m@v1.0.0 (2 packages, 11 declarations):\n\n" +
+		"example.com/m (10):\n" +
+		"  const Max\n" +
+		"  var Default Client\n" +
+		"  func New(addr, name string) *Client\n" +
+		"  type Client struct\n" +
+		"    embedded *Base\n" +
+		"    field Timeout int\n" +
+		"    func (c *Client) Do(req string) (int, error)\n" +
+		"  type Doer interface\n" +
+		"    embedded fmt.Stringer\n" +
+		"    method Do(req string) (int, error)\n\n" +
+		"example.com/m/sub (1):\n" +
+		"  type ID = string\n"
+
+	if got := formatAPI("example.com/m", "v1.0.0", api); got != wantText {
+		t.Errorf("got:\n%s\nwant:\n%s", got, wantText)
+	}
+}
+
+func TestDiffAPI(t *testing.T) {
+	from := t.TempDir()
+	to := t.TempDir()
+
+	writeTree(t, from, map[string]string{
+		"m.go":     "package m\n\nfunc Renamed(a int) {}\n\nfunc Changed(a int) {}\n\nfunc Gone() {}\n",
+		"old/o.go": "package old\n\nfunc O() {}\n",
+	})
+	writeTree(t, to, map[string]string{
+		"m.go":     "package m\n\nfunc Renamed(b int) {}\n\nfunc Changed(a int, b string) {}\n\nfunc Added() {}\n",
+		"new/n.go": "package n\n\nfunc N() {}\n",
+	})
+
+	fromAPI, err := moduleAPI(dirFiles{root: from}, "")
+	mustf(t, err, "parse old API")
+
+	toAPI, err := moduleAPI(dirFiles{root: to}, "")
+	mustf(t, err, "parse new API")
+
+	var got []string
+
+	for _, c := range diffAPI(fromAPI, toAPI) {
+		got = append(got, string(c.kind)+c.pkg+":"+c.name)
+	}
+
+	want := []string{"+.:Added", "~.:Changed", "-.:Gone", "+new:", "-old:"}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
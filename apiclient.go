@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+const maxAPIResponseSize = 10 << 20 // 10 MB
+
+// errAPINotFound is returned when an API responds with 404.
+var errAPINotFound = errors.New("not found")
+
+// apiClient is the shared HTTP plumbing for metadata services other than
+// the module proxy (pkg.go.dev, deps.dev, ...).
+type apiClient struct {
+	baseURL string
+	client  *http.Client
+	offline bool
+}
+
+// get fetches baseURL+path and returns the response body.
+func (c *apiClient) get(ctx context.Context, path string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, path, nil)
+}
+
+// do sends a request to baseURL+path and returns the response body. A
+// non-nil body is sent as JSON.
+func (c *apiClient) do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	if c.offline {
+		return nil, ErrOffline
+	}
+
+	url := c.baseURL + path
+
+	if u, err := neturl.Parse(c.baseURL); err == nil {
+		noteBackend(ctx, u.Host)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", url, errAPINotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return data, nil
+}
+
+// getJSON fetches baseURL+path and decodes the JSON response into v.
+func (c *apiClient) getJSON(ctx context.Context, path string, v any) error {
+	body, err := c.get(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// postJSON sends in as a JSON POST to baseURL+path and decodes the JSON
+// response into out.
+func (c *apiClient) postJSON(ctx context.Context, path string, in, out any) error {
+	reqBody, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	body, err := c.do(ctx, http.MethodPost, path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	return nil
+}
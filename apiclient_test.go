@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIClient_GetJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"name":"value"}`))
+	}))
+	defer ts.Close()
+
+	c := &apiClient{baseURL: ts.URL, client: ts.Client()}
+
+	var v struct{ Name string }
+
+	mustf(t, c.getJSON(context.Background(), "/ok", &v), "getJSON")
+
+	if v.Name != "value" {
+		t.Errorf("Name = %q", v.Name)
+	}
+
+	if err := c.getJSON(context.Background(), "/missing", &v); !errors.Is(err, errAPINotFound) {
+		t.Errorf("got err=%v, want errAPINotFound", err)
+	}
+
+	c.offline = true
+
+	if _, err := c.get(context.Background(), "/ok"); !errors.Is(err, ErrOffline) {
+		t.Errorf("got err=%v, want ErrOffline", err)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/semver"
+)
+
+// maxAPIDiffChanges bounds the changes gomod_apidiff lists of each kind.
+const maxAPIDiffChanges = 500
+
+// breakingReason returns why an API change can break code using the old
+// version, or "" when it is compatible. The rules follow apidiff, as far
+// as signatures can tell without type checking: removing or changing a
+// declaration breaks callers, and adding a method to an interface breaks
+// the implementations outside the package. Additions are otherwise
+// compatible.
+func breakingReason(c apiChange, from map[string]packageAPI) string {
+	switch {
+	case c.kind == '-' && c.name == "":
+		return "package removed"
+	case c.kind == '-':
+		return "removed"
+	case c.kind == '~':
+		return "changed"
+	case c.name == "":
+		return ""
+	}
+
+	typ, _, ok := strings.Cut(c.name, ".")
+	if !ok {
+		return ""
+	}
+
+	iface, existed := from[c.pkg][typ]
+	if existed && isInterfaceSig(iface.sig) {
+		return "added to an existing interface, which its implementations must now provide"
+	}
+
+	return ""
+}
+
+// isInterfaceSig reports whether a type declaration's signature, as
+// addTypeAPI writes it, is that of an interface.
+func isInterfaceSig(sig string) bool {
+	return strings.HasPrefix(sig, "type ") && strings.HasSuffix(sig, " interface")
+}
+
+// classifyAPIChanges splits changes into breaking ones, with the reason
+// for each, and compatible ones.
+func classifyAPIChanges(changes []apiChange, from map[string]packageAPI) (breaking []apiChange, reasons []string,
+	compatible []apiChange,
+) {
+	for _, c := range changes {
+		if reason := breakingReason(c, from); reason != "" {
+			breaking = append(breaking, c)
+			reasons = append(reasons, reason)
+		} else {
+			compatible = append(compatible, c)
+		}
+	}
+
+	return breaking, reasons, compatible
+}
+
+// formatAPIDiff reports the changes between two versions, breaking ones
+// first.
+func formatAPIDiff(mod, fromVersion, toVersion string, breaking []apiChange, reasons []string,
+	compatible []apiChange,
+) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "API changes from %s@%s to %s: %d breaking, %d compatible.\n",
+		mod, fromVersion, toVersion, len(breaking), len(compatible))
+	sb.WriteString("Declarations are compared by signature, without type checking.\n")
+
+	if len(breaking) > 0 && semver.Major(fromVersion) == semver.Major(toVersion) && semver.Major(toVersion) != "v0" {
+		fmt.Fprintf(&sb, "Both versions are %s: semantic versioning keeps breaking changes for a new major version.\n",
+			semver.Major(toVersion))
+	}
+
+	more := "narrow with package"
+	writeAPIChanges(&sb, "Breaking", mod, breaking, reasons, maxAPIDiffChanges, more)
+	writeAPIChanges(&sb, "Compatible", mod, compatible, nil, maxAPIDiffChanges, more)
+
+	return sb.String()
+}
+
+// writeAPIChanges lists changes under title and their number, grouped by
+// package. With reasons, an added declaration is followed by why it breaks
+// callers; removals and changes say so themselves. At most limit changes
+// are listed, and more tells how to see the rest.
+func writeAPIChanges(
+	sb *strings.Builder, title, mod string, changes []apiChange, reasons []string, limit int, more string,
+) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "\n%s (%d):\n", title, len(changes))
+
+	pkg := ""
+
+	for i, c := range changes {
+		if i == limit {
+			fmt.Fprintf(sb, "  ... %d more; %s\n", len(changes)-i, more)
+
+			break
+		}
+
+		importPath := packageImportPath(mod, c.pkg)
+
+		if c.name == "" {
+			verb := "removed"
+			if c.kind == '+' {
+				verb = "added"
+			}
+
+			fmt.Fprintf(sb, "  %c package %s %s\n", c.kind, importPath, verb)
+			pkg = ""
+
+			continue
+		}
+
+		if c.pkg != pkg {
+			fmt.Fprintf(sb, "  %s:\n", importPath)
+			pkg = c.pkg
+		}
+
+		switch c.kind {
+		case '-':
+			fmt.Fprintf(sb, "    - %s\n", clipLine(c.from))
+		case '+':
+			fmt.Fprintf(sb, "    + %s\n", clipLine(c.to))
+		default:
+			fmt.Fprintf(sb, "    ~ %s\n      -> %s\n", clipLine(c.from), clipLine(c.to))
+		}
+
+		if c.kind == '+' && reasons != nil {
+			fmt.Fprintf(sb, "      (%s)\n", reasons[i])
+		}
+	}
+}
+
+type apiDiffInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	From    string `json:"from" jsonschema:"Old module version or 'latest'"`
+	To      string `json:"to" jsonschema:"New module version or 'latest'"`
+	Package string `json:"package,omitempty" jsonschema:"Only this package (import path or directory), default all"`
+}
+
+func handleAPIDiff(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input apiDiffInput,
+) (*mcp.CallToolResult, any, error) {
+	var dir, prefix string
+
+	if input.Package != "" {
+		importPath := packageImportPath(input.Module, input.Package)
+		if !inModule(importPath, input.Module) {
+			return errorResult(fmt.Sprintf("Package %s is not in module %s.", importPath, input.Module)), nil, nil
+		}
+
+		dir = "."
+		if importPath != input.Module {
+			dir = strings.TrimPrefix(importPath, input.Module+"/")
+			prefix = dir + "/"
+		}
+	}
+
+	var (
+		versions [2]string
+		apis     [2]map[string]packageAPI
+	)
+
+	for i, v := range []string{input.From, input.To} {
+		version, err := resolveVersion(ctx, proxy, input.Module, v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		versions[i] = version
+
+		mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if apis[i], err = moduleAPI(mf, prefix); err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", version, err)
+		}
+
+		if dir != "" {
+			pkg, ok := apis[i][dir]
+			apis[i] = map[string]packageAPI{}
+
+			if ok {
+				apis[i][dir] = pkg
+			}
+		}
+	}
+
+	if semver.Compare(versions[0], versions[1]) == 0 {
+		return errorResult(fmt.Sprintf("from and to are both %s.", versions[0])), nil, nil
+	}
+
+	breaking, reasons, compatible := classifyAPIChanges(diffAPI(apis[0], apis[1]), apis[0])
+
+	if len(breaking) > maxAPIDiffChanges || len(compatible) > maxAPIDiffChanges {
+		noteTruncated(ctx)
+	}
+
+	return textResult(formatAPIDiff(input.Module, versions[0], versions[1], breaking, reasons, compatible)), nil, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyAPIChanges(t *testing.T) {
+	from := t.TempDir()
+	to := t.TempDir()
+
+	writeTree(t, from, map[string]string{
+		"m.go": "package m\n\ntype Doer interface{ Do() }\n\ntype Opts struct{ A int }\n\n" +
+			"func Changed(a int) {}\n\nfunc Gone() {}\n",
+		"old/o.go": "package old\n\nfunc O() {}\n",
+	})
+	writeTree(t, to, map[string]string{
+		"m.go": "package m\n\ntype Doer interface{ Do(); Undo() }\n\ntype Opts struct{ A, B int }\n\n" +
+			"type Fresh interface{ F() }\n\nfunc Changed(a int, b string) {}\n",
+		"new/n.go": "package n\n\nfunc N() {}\n",
+	})
+
+	fromAPI, err := moduleAPI(dirFiles{root: from}, "")
+	mustf(t, err, "parse old API")
+
+	toAPI, err := moduleAPI(dirFiles{root: to}, "")
+	mustf(t, err, "parse new API")
+
+	breaking, reasons, compatible := classifyAPIChanges(diffAPI(fromAPI, toAPI), fromAPI)
+
+	var got []string
+
+	for i, c := range breaking {
+		got = append(got, string(c.kind)+c.pkg+":"+c.name+" "+reasons[i])
+	}
+
+	want := "~.:Changed changed, +.:Doer.Undo added to an existing interface, which its implementations must now " +
+		"provide, -.:Gone removed, -old: package removed"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("breaking = %s", strings.Join(got, ", "))
+	}
+
+	got = nil
+
+	for _, c := range compatible {
+		got = append(got, string(c.kind)+c.pkg+":"+c.name)
+	}
+
+	if strings.Join(got, ", ") != "+.:Fresh, +.:Fresh.F, +.:Opts.B, +new:" {
+		t.Errorf("compatible = %s", strings.Join(got, ", "))
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Outcomes recorded in the audit log.
+const (
+	auditOK     = "ok"     // the tool returned a result
+	auditError  = "error"  // the tool returned an error result
+	auditFailed = "failed" // the call was rejected, e.g. invalid arguments
+)
+
+// Backends noted for the audit log.
+const (
+	backendProxy     = "proxy"
+	backendMetaCache = "metadata-cache"
+	backendZipCache  = "zip-cache"
+	backendModCache  = "modcache"
+)
+
+// auditRecord is one line of the audit log.
+type auditRecord struct {
+	Time       time.Time       `json:"time"`
+	Session    string          `json:"session,omitempty"`
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Backends   []string        `json:"backends,omitempty"`
+	Bytes      int             `json:"bytes"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// AuditLog appends a JSON line per tool call to a file.
+type AuditLog struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// OpenAuditLog opens path for appending, creating it if needed. Records
+// are not buffered, so the file needs no closing.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+
+	return NewAuditLog(f), nil
+}
+
+// NewAuditLog creates an audit log writing to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w, now: time.Now}
+}
+
+// write appends rec. Each record is written with a single Write call, so
+// lines stay whole when several sessions log at once.
+func (l *AuditLog) write(rec *auditRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode audit record: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Middleware records every tools/call request passing through a server.
+// Other methods are not logged.
+func (l *AuditLog) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			start := l.now()
+			ctx, backends := withCallBackends(ctx)
+
+			result, err := next(ctx, method, req)
+
+			rec := &auditRecord{
+				Time:       start.UTC(),
+				Tool:       call.Params.Name,
+				Arguments:  call.Params.Arguments,
+				Backends:   backends.list(),
+				Outcome:    auditOK,
+				DurationMS: l.now().Sub(start).Milliseconds(),
+			}
+
+			if call.Session != nil {
+				rec.Session = call.Session.ID()
+			}
+
+			if res, ok := result.(*mcp.CallToolResult); ok && res != nil {
+				rec.Bytes = resultBytes(res)
+
+				if res.IsError {
+					rec.Outcome = auditError
+					rec.Error = resultSummary(res)
+				}
+			}
+
+			if err != nil {
+				rec.Outcome, rec.Error = auditFailed, err.Error()
+			}
+
+			if werr := l.write(rec); werr != nil {
+				log.Printf("warning: %v", werr)
+			}
+
+			return result, err
+		}
+	}
+}
+
+// resultBytes is the size of a tool result's content as returned to the
+// client.
+func resultBytes(res *mcp.CallToolResult) int {
+	n := 0
+
+	for _, c := range res.Content {
+		switch c := c.(type) {
+		case *mcp.TextContent:
+			n += len(c.Text)
+		case *mcp.ImageContent:
+			n += len(c.Data)
+		case *mcp.AudioContent:
+			n += len(c.Data)
+		}
+	}
+
+	return n
+}
+
+// resultSummary returns the first line of an error result's text.
+func resultSummary(res *mcp.CallToolResult) string {
+	for _, c := range res.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			line, _, _ := strings.Cut(tc.Text, "\n")
+
+			return line
+		}
+	}
+
+	return ""
+}
+
+type backendsKey struct{}
+
+// callBackends collects the backends a tool call used. Handlers may fetch
+// concurrently, so it is locked.
+type callBackends struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func (b *callBackends) add(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.names == nil {
+		b.names = make(map[string]bool)
+	}
+
+	b.names[name] = true
+}
+
+func (b *callBackends) list() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return sortedKeys(b.names)
+}
+
+// withCallBackends returns the collector of the tool call running in ctx,
+// adding one when there is none yet, so that the audit log and the perf
+// stats see the same backends.
+func withCallBackends(ctx context.Context) (context.Context, *callBackends) {
+	if b, ok := ctx.Value(backendsKey{}).(*callBackends); ok {
+		return ctx, b
+	}
+
+	b := &callBackends{}
+
+	return context.WithValue(ctx, backendsKey{}, b), b
+}
+
+// noteBackend records that the tool call running in ctx used a backend. It
+// does nothing outside a tool call.
+func noteBackend(ctx context.Context, name string) {
+	if b, ok := ctx.Value(backendsKey{}).(*callBackends); ok {
+		b.add(name)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type auditTestInput struct {
+	Module string `json:"module,omitempty"`
+}
+
+func TestAuditLog_Middleware(t *testing.T) {
+	var buf bytes.Buffer
+
+	audit := NewAuditLog(&buf)
+	audit.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "audit-test", Version: "0.0.1"}, nil)
+	server.AddReceivingMiddleware(audit.Middleware())
+
+	mcp.AddTool(server, &mcp.Tool{Name: "fetch"}, func(
+		ctx context.Context, _ *mcp.CallToolRequest, input auditTestInput,
+	) (*mcp.CallToolResult, any, error) {
+		noteBackend(ctx, backendProxy)
+		noteBackend(ctx, backendZipCache)
+		noteBackend(ctx, backendProxy)
+
+		if input.Module == "" {
+			return errorResult("module is required\nmore detail"), nil, nil
+		}
+
+		return textResult("hello " + input.Module), nil, nil
+	})
+
+	ctx := context.Background()
+	t1, t2 := mcp.NewInMemoryTransports()
+
+	_, err := server.Connect(ctx, t1, nil)
+	mustf(t, err, "connect server")
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+
+	session, err := client.Connect(ctx, t2, nil)
+	mustf(t, err, "connect client")
+
+	defer session.Close()
+
+	for _, args := range []map[string]any{{"module": "example.com/m"}, {}} {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "fetch", Arguments: args})
+		mustf(t, err, "call fetch")
+	}
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "missing"}); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+
+	if _, err := session.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d audit lines, want 3:\n%s", len(lines), buf.String())
+	}
+
+	var recs []auditRecord
+
+	for _, line := range lines {
+		var rec auditRecord
+
+		mustf(t, json.Unmarshal([]byte(line), &rec), "decode %s", line)
+
+		recs = append(recs, rec)
+	}
+
+	ok := recs[0]
+	if ok.Tool != "fetch" || ok.Outcome != auditOK || ok.Bytes != len("hello example.com/m") ||
+		string(ok.Arguments) != `{"module":"example.com/m"}` || !ok.Time.Equal(audit.now()) {
+		t.Errorf("unexpected ok record: %s", lines[0])
+	}
+
+	if got := strings.Join(ok.Backends, ","); got != "proxy,zip-cache" {
+		t.Errorf("backends = %s", got)
+	}
+
+	if recs[1].Outcome != auditError || recs[1].Error != "module is required" {
+		t.Errorf("unexpected error record: %s", lines[1])
+	}
+
+	if recs[2].Tool != "missing" || recs[2].Outcome != auditFailed || recs[2].Error == "" {
+		t.Errorf("unexpected failed record: %s", lines[2])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"unicode/utf8"
@@ -11,10 +12,16 @@ import (
 
 // ZipEntry holds a cached zip archive with pre-built file lookup.
 type ZipEntry struct {
+	data   []byte // raw archive, kept for re-serving
 	reader *zip.Reader
 	files  map[string]*zip.File // stripped path -> zip.File
 }
 
+// Data returns the raw zip archive bytes.
+func (e *ZipEntry) Data() []byte {
+	return e.data
+}
+
 // ListFiles returns file paths matching an optional prefix filter.
 func (e *ZipEntry) ListFiles(prefix string) []string {
 	var result []string
@@ -28,6 +35,32 @@ func (e *ZipEntry) ListFiles(prefix string) []string {
 	return result
 }
 
+// Open returns a reader for the raw content of a file in the zip archive.
+func (e *ZipEntry) Open(path string) (io.ReadCloser, error) {
+	f, ok := e.files[path]
+	if !ok {
+		return nil, fmt.Errorf("file not found in archive: %s", path)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open file in zip: %w", err)
+	}
+
+	return rc, nil
+}
+
+// Size returns the uncompressed size of a file recorded in the zip's
+// central directory.
+func (e *ZipEntry) Size(path string) (int64, error) {
+	f, ok := e.files[path]
+	if !ok {
+		return 0, fmt.Errorf("file not found in archive: %s", path)
+	}
+
+	return int64(f.UncompressedSize64), nil //nolint:gosec // Module zips are far below 8 EiB.
+}
+
 // ReadFile reads the content of a file from the zip archive.
 // Returns an error for binary files.
 func (e *ZipEntry) ReadFile(path string) (string, error) {
@@ -75,6 +108,14 @@ func (c *ZipCache) Get(module, version string) *ZipEntry {
 	return c.entries[module+"@"+version]
 }
 
+// Len returns the number of cached archives.
+func (c *ZipCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
 // Put parses and caches a zip archive. The prefix "module@version/" is stripped
 // from file paths in the lookup map.
 func (c *ZipCache) Put(module, version string, data []byte) (*ZipEntry, error) {
@@ -98,6 +139,7 @@ func (c *ZipCache) Put(module, version string, data []byte) (*ZipEntry, error) {
 	}
 
 	entry := &ZipEntry{
+		data:   data,
 		reader: r,
 		files:  files,
 	}
@@ -125,6 +125,27 @@ func TestZipEntry_ReadFile(t *testing.T) {
 	}
 }
 
+func TestZipEntry_Size(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"hello.go": "package main\n\nfunc main() {}\n",
+	})
+
+	cache := NewZipCache()
+	entry, _ := cache.Put("mod", "v1.0.0", data)
+
+	size, err := entry.Size("hello.go")
+
+	mustf(t, err, "size of hello.go")
+
+	if size != 29 {
+		t.Errorf("size = %d, want 29", size)
+	}
+
+	if _, err := entry.Size("missing.go"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
 func TestZipEntry_ReadFile_NotFound(t *testing.T) {
 	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
 		"hello.go": "package main\n",
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errToolFailed is returned by the call subcommand when the tool returns an
+// error result, which has already been printed.
+var errToolFailed = errors.New("tool returned an error")
+
+// toolParam is a property of a tool's input schema.
+type toolParam struct {
+	name        string
+	typ         string // JSON schema type; "array" params also have an item type
+	itemType    string
+	description string
+	required    bool
+}
+
+// runCall implements the call subcommand: it runs one tool through an
+// in-memory MCP session and prints the result. Server flags come before
+// the tool name, tool arguments after it:
+//
+//	claude-gomod call [server flags] <tool> [--name value | --name=value]...
+//
+// Without a tool name the tools are listed; "<tool> -h" shows its
+// arguments.
+func runCall(args []string) error {
+	fs := flag.NewFlagSet("call", flag.ExitOnError)
+	flags := registerServerFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	if err := applyConfigFile(fs, flags); err != nil {
+		return err
+	}
+
+	server, _, err := newServer(flags)
+	if err != nil {
+		return err
+	}
+
+	return runToolCall(context.Background(), server, fs.Args(), os.Stdout, os.Stderr)
+}
+
+// runToolCall connects to server, resolves the tool and its arguments from
+// args and writes the result text to stdout, or to stderr for error
+// results.
+func runToolCall(ctx context.Context, server *mcp.Server, args []string, stdout, stderr io.Writer) error {
+	session, err := connectInMemory(ctx, server)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("list tools: %w", err)
+	}
+
+	if len(args) == 0 {
+		writeToolList(stdout, tools.Tools)
+
+		return nil
+	}
+
+	var tool *mcp.Tool
+
+	for _, t := range tools.Tools {
+		if t.Name == args[0] {
+			tool = t
+		}
+	}
+
+	if tool == nil {
+		return fmt.Errorf("unknown tool %q (run without a tool name to list them)", args[0])
+	}
+
+	params := toolParams(tool.InputSchema)
+
+	if len(args) == 2 && (args[1] == "-h" || args[1] == "--help") {
+		writeToolHelp(stdout, tool, params)
+
+		return nil
+	}
+
+	arguments, err := parseToolArgs(params, args[1:])
+	if err != nil {
+		return fmt.Errorf("%s: %w", tool.Name, err)
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: tool.Name, Arguments: arguments})
+	if err != nil {
+		return fmt.Errorf("call %s: %w", tool.Name, err)
+	}
+
+	out := stdout
+	if result.IsError {
+		out = stderr
+	}
+
+	for _, c := range result.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			fmt.Fprint(out, tc.Text)
+
+			if !strings.HasSuffix(tc.Text, "\n") {
+				fmt.Fprintln(out)
+			}
+		}
+	}
+
+	if result.IsError {
+		return errToolFailed
+	}
+
+	return nil
+}
+
+func connectInMemory(ctx context.Context, server *mcp.Server) (*mcp.ClientSession, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		return nil, fmt.Errorf("connect server: %w", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "claude-gomod-call", Version: "0.1.0"}, nil)
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect client: %w", err)
+	}
+
+	return session, nil
+}
+
+// toolParams reads the properties of a tool input schema as received by a
+// client, sorted with required ones first.
+func toolParams(schema any) []toolParam {
+	m, _ := schema.(map[string]any)
+	props, _ := m["properties"].(map[string]any)
+
+	required := make(map[string]bool)
+
+	if list, ok := m["required"].([]any); ok {
+		for _, r := range list {
+			if name, ok := r.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	params := make([]toolParam, 0, len(props))
+
+	for name, raw := range props {
+		prop, _ := raw.(map[string]any)
+		p := toolParam{name: name, typ: schemaType(prop["type"]), required: required[name]}
+		p.description, _ = prop["description"].(string)
+
+		if items, ok := prop["items"].(map[string]any); ok {
+			p.itemType = schemaType(items["type"])
+		}
+
+		params = append(params, p)
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].required != params[j].required {
+			return params[i].required
+		}
+
+		return params[i].name < params[j].name
+	})
+
+	return params
+}
+
+// schemaType returns a schema's type, skipping "null" in type lists.
+func schemaType(t any) string {
+	switch t := t.(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
+
+// parseToolArgs converts "--name value" and "--name=value" arguments to
+// the JSON types of the tool's parameters. Dashes in names may stand for
+// underscores, booleans may omit the value, and arrays take repeated or
+// comma-separated values.
+func parseToolArgs(params []toolParam, args []string) (map[string]any, error) {
+	byName := make(map[string]toolParam, len(params))
+	for _, p := range params {
+		byName[p.name] = p
+	}
+
+	result := make(map[string]any)
+
+	for i := 0; i < len(args); i++ {
+		name, ok := strings.CutPrefix(args[i], "--")
+		if !ok {
+			if name, ok = strings.CutPrefix(args[i], "-"); !ok || name == "" {
+				return nil, fmt.Errorf("unexpected argument %q", args[i])
+			}
+		}
+
+		name, value, hasValue := strings.Cut(name, "=")
+
+		p, ok := byName[name]
+		if !ok {
+			if p, ok = byName[strings.ReplaceAll(name, "-", "_")]; !ok {
+				return nil, fmt.Errorf("unknown argument --%s", name)
+			}
+		}
+
+		if !hasValue {
+			// A boolean is set by its bare name unless the next word is an
+			// explicit value.
+			if next := i + 1; p.typ == "boolean" && (next == len(args) || !isBoolWord(args[next])) {
+				value = "true"
+			} else if next == len(args) {
+				return nil, fmt.Errorf("--%s needs a value", name)
+			} else {
+				value = args[next]
+				i++
+			}
+		}
+
+		if err := setToolArg(result, p, value); err != nil {
+			return nil, fmt.Errorf("--%s: %w", name, err)
+		}
+	}
+
+	return result, nil
+}
+
+func isBoolWord(s string) bool {
+	_, err := strconv.ParseBool(s)
+
+	return err == nil
+}
+
+func setToolArg(result map[string]any, p toolParam, value string) error {
+	if p.typ != "array" {
+		v, err := convertToolArg(p.typ, value)
+		if err != nil {
+			return err
+		}
+
+		result[p.name] = v
+
+		return nil
+	}
+
+	list, _ := result[p.name].([]any)
+
+	for _, item := range strings.Split(value, ",") {
+		v, err := convertToolArg(p.itemType, item)
+		if err != nil {
+			return err
+		}
+
+		list = append(list, v)
+	}
+
+	result[p.name] = list
+
+	return nil
+}
+
+func convertToolArg(typ, value string) (any, error) {
+	switch typ {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", value)
+		}
+
+		return n, nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value)
+		}
+
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", value)
+		}
+
+		return b, nil
+	case "object":
+		var v map[string]any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("invalid JSON object: %w", err)
+		}
+
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+func writeToolList(w io.Writer, tools []*mcp.Tool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, t := range tools {
+		fmt.Fprintf(tw, "%s\t%s\n", t.Name, firstSentence(t.Description))
+	}
+
+	_ = tw.Flush()
+}
+
+// firstSentence returns text up to the first period that is followed by a
+// capitalized word, so abbreviations like "e.g." do not end it.
+func firstSentence(text string) string {
+	for i := 0; i+2 < len(text); i++ {
+		if text[i] == '.' && text[i+1] == ' ' && text[i+2] >= 'A' && text[i+2] <= 'Z' {
+			return text[:i]
+		}
+	}
+
+	return strings.TrimSuffix(text, ".")
+}
+
+func writeToolHelp(w io.Writer, tool *mcp.Tool, params []toolParam) {
+	fmt.Fprintf(w, "%s: %s\n\nArguments:\n", tool.Name, tool.Description)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, p := range params {
+		typ := p.typ
+		if p.itemType != "" {
+			typ += " of " + p.itemType
+		}
+
+		if p.required {
+			typ += ", required"
+		}
+
+		fmt.Fprintf(tw, "  --%s\t%s\t%s\n", p.name, typ, p.description)
+	}
+
+	_ = tw.Flush()
+}
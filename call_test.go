@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type callTestInput struct {
+	Module  string   `json:"module" jsonschema:"Go module path"`
+	Limit   int      `json:"limit,omitempty" jsonschema:"Maximum results"`
+	Tests   bool     `json:"include_tests,omitempty" jsonschema:"Include tests"`
+	Symbols []string `json:"symbols,omitempty" jsonschema:"Symbols"`
+}
+
+func newCallTestServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "call-test", Version: "0.0.1"}, nil)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "echo", Description: "Echo the arguments (e.g. for tests). More text."}, func(
+		_ context.Context, _ *mcp.CallToolRequest, input callTestInput,
+	) (*mcp.CallToolResult, any, error) {
+		if input.Module == "bad" {
+			return errorResult("bad module"), nil, nil
+		}
+
+		return textResult(fmt.Sprintf("%s %d %v %q", input.Module, input.Limit, input.Tests, input.Symbols)), nil, nil
+	})
+
+	return server
+}
+
+func TestCallTool(t *testing.T) {
+	ctx := context.Background()
+
+	var stdout, stderr strings.Builder
+
+	err := runToolCall(ctx, newCallTestServer(), []string{
+		"echo", "--module", "example.com/m", "--limit=3", "--include-tests", "--symbols", "A,B", "-symbols=C",
+	}, &stdout, &stderr)
+	mustf(t, err, "call echo")
+
+	if want := "example.com/m 3 true [\"A\" \"B\" \"C\"]\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+
+	err = runToolCall(ctx, newCallTestServer(), []string{"echo", "--module", "bad"}, &stdout, &stderr)
+	if !errors.Is(err, errToolFailed) || stderr.String() != "bad module\n" || stdout.Len() != 0 {
+		t.Errorf("error result: err %v, stdout %q, stderr %q", err, stdout.String(), stderr.String())
+	}
+}
+
+func TestCallTool_ListAndHelp(t *testing.T) {
+	ctx := context.Background()
+
+	var stdout strings.Builder
+
+	mustf(t, runToolCall(ctx, newCallTestServer(), nil, &stdout, &stdout), "list tools")
+
+	if got := stdout.String(); got != "echo  Echo the arguments (e.g. for tests)\n" {
+		t.Errorf("tool list = %q", got)
+	}
+
+	stdout.Reset()
+
+	mustf(t, runToolCall(ctx, newCallTestServer(), []string{"echo", "-h"}, &stdout, &stdout), "help")
+
+	if text := stdout.String(); !strings.Contains(text, "--module         string, required  Go module path") ||
+		!strings.Contains(text, "--symbols        array of string") {
+		t.Errorf("unexpected help:\n%s", text)
+	}
+
+	if err := runToolCall(ctx, newCallTestServer(), []string{"missing"}, &stdout, &stdout); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+}
+
+func TestParseToolArgs_Errors(t *testing.T) {
+	params := []toolParam{{name: "limit", typ: "integer"}, {name: "path", typ: "string"}}
+
+	for _, args := range [][]string{
+		{"--limit", "many"},
+		{"--path"},
+		{"--other", "x"},
+		{"positional"},
+	} {
+		if _, err := parseToolArgs(params, args); err == nil {
+			t.Errorf("parseToolArgs(%q): expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Call kinds reported for each call site.
+const (
+	callStatic    = "static"
+	callInterface = "interface"
+	callDynamic   = "dynamic"
+)
+
+// callSite is a single call from a function in the module to a target
+// function.
+type callSite struct {
+	caller string // fully qualified caller, e.g. "(*example.com/m.T).Run"
+	file   string // path relative to the module root
+	line   int
+	kind   string
+}
+
+// callersReport is the result of a caller analysis.
+type callersReport struct {
+	targets     []string
+	sites       []callSite
+	loadErrors  int
+	packageSize int
+}
+
+// findCallers loads the module extracted at dir, builds SSA for all of
+// its packages and returns every call site inside the module that may
+// invoke the function or method named by symbol ("Func" or
+// "Type.Method"). Interface method calls are resolved with class
+// hierarchy analysis. If pkg is non-empty, only targets declared in that
+// package (import path or module-relative directory) are considered.
+//
+// The go command is kept off the network, so -offline and the module
+// policy cannot be sidestepped: dependencies come from the module cache
+// only, where the go command verified them, and those missing from it
+// count as load errors. GOTOOLCHAIN=local keeps a toolchain line from
+// downloading a Go release.
+func findCallers(ctx context.Context, dir, module, pkg, symbol string) (*callersReport, error) {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.LoadAllSyntax,
+		Dir:     dir,
+		Env: append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOPROXY=off", "GOSUMDB=off",
+			"GOTOOLCHAIN=local"),
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+
+	report := &callersReport{packageSize: len(pkgs)}
+
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if len(p.Errors) > 0 && inModule(p.PkgPath, module) {
+			report.loadErrors++
+		}
+	})
+
+	prog, _ := ssautil.AllPackages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	graph := cha.CallGraph(prog)
+
+	pkg = packageImportPath(module, pkg)
+
+	seen := make(map[callSite]bool)
+
+	for fn, node := range graph.Nodes {
+		if !matchesSymbol(fn, module, pkg, symbol) {
+			continue
+		}
+
+		report.targets = append(report.targets, fn.String())
+
+		collectCallSites(prog, node, module, dir, seen, make(map[*callgraph.Node]bool))
+	}
+
+	for site := range seen {
+		report.sites = append(report.sites, site)
+	}
+
+	sort.Strings(report.targets)
+	sort.Slice(report.sites, func(i, j int) bool {
+		a, b := report.sites[i], report.sites[j]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+
+		if a.line != b.line {
+			return a.line < b.line
+		}
+
+		return a.caller < b.caller
+	})
+
+	return report, nil
+}
+
+// collectCallSites records the in-module callers of node. Synthetic
+// wrappers (bound methods, pointer wrappers) are looked through so that
+// their callers are reported instead.
+func collectCallSites(
+	prog *ssa.Program, node *callgraph.Node, module, dir string,
+	seen map[callSite]bool, visited map[*callgraph.Node]bool,
+) {
+	if visited[node] {
+		return
+	}
+
+	visited[node] = true
+
+	for _, edge := range node.In {
+		caller := edge.Caller.Func
+
+		if caller.Synthetic != "" {
+			collectCallSites(prog, edge.Caller, module, dir, seen, visited)
+
+			continue
+		}
+
+		if caller.Pkg == nil || !inModule(caller.Pkg.Pkg.Path(), module) {
+			continue
+		}
+
+		pos := prog.Fset.Position(edge.Pos())
+
+		rel, err := filepath.Rel(dir, pos.Filename)
+		if err != nil {
+			rel = pos.Filename
+		}
+
+		seen[callSite{
+			caller: caller.String(),
+			file:   filepath.ToSlash(rel),
+			line:   pos.Line,
+			kind:   callKind(edge),
+		}] = true
+	}
+}
+
+func callKind(edge *callgraph.Edge) string {
+	if edge.Site == nil {
+		return callStatic
+	}
+
+	common := edge.Site.Common()
+
+	switch {
+	case common.IsInvoke():
+		return callInterface
+	case common.StaticCallee() == nil:
+		return callDynamic
+	default:
+		return callStatic
+	}
+}
+
+// matchesSymbol reports whether fn is the function or method named by
+// symbol, declared in the module (and in pkg, if set).
+func matchesSymbol(fn *ssa.Function, module, pkg, symbol string) bool {
+	if fn == nil {
+		return false
+	}
+
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+
+	if fn.Pkg == nil || fn.Synthetic != "" || fn.Parent() != nil {
+		return false
+	}
+
+	pkgPath := fn.Pkg.Pkg.Path()
+	if !inModule(pkgPath, module) || (pkg != "" && pkgPath != pkg) {
+		return false
+	}
+
+	typeName, name, isMethod := strings.Cut(symbol, ".")
+	recv := fn.Signature.Recv()
+
+	if !isMethod {
+		return recv == nil && fn.Name() == symbol
+	}
+
+	if recv == nil || fn.Name() != name {
+		return false
+	}
+
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+
+	return ok && named.Obj().Name() == typeName
+}
+
+// packageImportPath turns a package given either as an import path or as
+// a module-relative directory ("." for the root) into an import path.
+// An empty pkg is returned unchanged.
+func packageImportPath(module, pkg string) string {
+	if pkg == "" || inModule(pkg, module) {
+		return pkg
+	}
+
+	dir := strings.Trim(path.Clean(pkg), "/")
+	if dir == "." || dir == "" {
+		return module
+	}
+
+	return module + "/" + dir
+}
+
+// inModule reports whether the import path belongs to the module.
+func inModule(path, module string) bool {
+	return path == module || strings.HasPrefix(path, module+"/")
+}
+
+type callersInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Symbol  string `json:"symbol" jsonschema:"Function name or Type.Method to find callers of"`
+	Package string `json:"package,omitempty" jsonschema:"Optional package (import path or directory) declaring it"`
+}
+
+func handleCallers(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input callersInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.Symbol == "" {
+		return errorResult("A symbol is required, e.g. \"NewClient\" or \"Client.Do\"."), nil, nil
+	}
+
+	if err := proxy.policy.Check(input.Module); err != nil {
+		return nil, nil, err
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "claude-gomod-ssa-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create work dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := extractModule(mf, dir); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ensureGoMod(dir, input.Module); err != nil {
+		return nil, nil, err
+	}
+
+	report, err := findCallers(ctx, dir, input.Module, input.Package, input.Symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(report.targets) == 0 {
+		return errorResult(fmt.Sprintf(
+			"No function %q found in %s@%s.", input.Symbol, input.Module, version,
+		)), nil, nil
+	}
+
+	return textResult(formatCallers(input.Module, version, report)), nil, nil
+}
+
+// ensureGoMod writes a minimal go.mod for modules that predate modules
+// and ship without one, so that the go command can load them.
+func ensureGoMod(dir, module string) error {
+	goMod := filepath.Join(dir, "go.mod")
+
+	if _, err := os.Stat(goMod); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err := os.WriteFile(goMod, []byte("module "+module+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write go.mod: %w", err)
+	}
+
+	return nil
+}
+
+func formatCallers(module, version string, report *callersReport) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Callers of %s in %s@%s (%d call sites):\n",
+		strings.Join(report.targets, ", "), module, version, len(report.sites))
+
+	for _, site := range report.sites {
+		fmt.Fprintf(&sb, "%s:%d\t%s\t%s\n", site.file, site.line, site.caller, site.kind)
+	}
+
+	if report.loadErrors > 0 {
+		fmt.Fprintf(&sb, "\nNote: %d of %d packages had load errors; results may be incomplete.\n",
+			report.loadErrors, report.packageSize)
+	}
+
+	return sb.String()
+}
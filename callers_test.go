@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const callersTestSource = `package calls
+
+type Greeter interface {
+	Greet() string
+}
+
+type English struct{}
+
+func (English) Greet() string { return "hello" }
+
+func Direct() string {
+	return English{}.Greet()
+}
+
+func ViaInterface(g Greeter) string {
+	return g.Greet()
+}
+
+func Helper() int { return 1 }
+
+func UsesHelper() int {
+	f := Helper
+
+	return Helper() + f()
+}
+`
+
+func setupCallersModule(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	data := createTestZip(t, "example.com/calls@v1.0.0/", map[string]string{
+		"go.mod":   "module example.com/calls\n\ngo 1.21\n",
+		"calls.go": callersTestSource,
+	})
+
+	entry, err := NewZipCache().Put("example.com/calls", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+	_, err = extractModule(zipFiles{entry}, dir)
+
+	mustf(t, err, "extract module")
+
+	return dir
+}
+
+func TestFindCallers_Method(t *testing.T) {
+	dir := setupCallersModule(t)
+
+	report, err := findCallers(context.Background(), dir, "example.com/calls", "", "English.Greet")
+
+	mustf(t, err, "find callers")
+
+	if len(report.targets) != 1 {
+		t.Fatalf("got targets %v, want exactly one", report.targets)
+	}
+
+	kinds := make(map[string]string)
+
+	for _, site := range report.sites {
+		kinds[site.caller] = site.kind
+	}
+
+	if kinds["example.com/calls.Direct"] != callStatic {
+		t.Errorf("expected static call from Direct, got %v", report.sites)
+	}
+
+	if kinds["example.com/calls.ViaInterface"] != callInterface {
+		t.Errorf("expected interface call from ViaInterface, got %v", report.sites)
+	}
+}
+
+func TestFindCallers_Function(t *testing.T) {
+	dir := setupCallersModule(t)
+
+	report, err := findCallers(context.Background(), dir, "example.com/calls", ".", "Helper")
+
+	mustf(t, err, "find callers")
+
+	if len(report.sites) == 0 {
+		t.Fatal("expected call sites for Helper")
+	}
+
+	for _, site := range report.sites {
+		if site.caller != "example.com/calls.UsesHelper" || site.file != "calls.go" {
+			t.Errorf("unexpected call site: %+v", site)
+		}
+	}
+
+	if out := formatCallers("example.com/calls", "v1.0.0", report); !strings.Contains(out, "calls.go:24") {
+		t.Errorf("expected file:line in output: %s", out)
+	}
+}
+
+func TestMatchesSymbol_Unknown(t *testing.T) {
+	dir := setupCallersModule(t)
+
+	report, err := findCallers(context.Background(), dir, "example.com/calls", "", "Missing")
+
+	mustf(t, err, "find callers")
+
+	if len(report.targets) != 0 {
+		t.Errorf("expected no targets, got %v", report.targets)
+	}
+}
+
+func TestFindCallers_StaysOffline(t *testing.T) {
+	var requests atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	t.Setenv("GOPROXY", ts.URL)
+	t.Setenv("GOMODCACHE", t.TempDir())
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod": "module example.com/calls\n\ngo 1.21\n\nrequire example.com/missing v1.0.0\n",
+		"calls.go": "package calls\n\nimport \"example.com/missing\"\n\nfunc Helper() int { return 1 }\n\n" +
+			"func Uses() int { missing.Do(); return Helper() }\n",
+	})
+
+	report, err := findCallers(context.Background(), dir, "example.com/calls", "", "Helper")
+	mustf(t, err, "find callers")
+
+	if report.loadErrors == 0 {
+		t.Error("a dependency missing from the module cache should be a load error")
+	}
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d proxy requests for the missing dependency", n)
+	}
+}
+
+func TestHandleCallers_Denied(t *testing.T) {
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %s", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	policy, err := NewModulePolicy("", "example.com/calls")
+	mustf(t, err, "policy")
+
+	proxy.policy = policy
+
+	_, _, err = handleCallers(context.Background(), proxy, NewZipCache(), NewModCache(""), callersInput{
+		Module: "example.com/calls", Version: "v1.0.0", Symbol: "Helper",
+	})
+	if !errors.Is(err, ErrModuleDenied) {
+		t.Errorf("handleCallers of a denied module = %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// maxChangelogText bounds the changelog text gomod_changelog quotes,
+	// in bytes.
+	maxChangelogText = 16000
+	// maxChangelogVersions bounds the versions listed when a changelog has
+	// no entry for the one asked about.
+	maxChangelogVersions = 20
+)
+
+var (
+	// changelogFileRe matches the names of release notes files.
+	changelogFileRe = regexp.MustCompile(
+		`(?i)^(change[-_]?log|changes|history|news|releases|release[-_]notes)(\.(md|markdown|rst|txt|adoc))?$`)
+
+	// changelogVersionRe finds a version in a heading, with or without the
+	// "v" prefix and the patch number.
+	changelogVersionRe = regexp.MustCompile(`\bv?(\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.-]+)?)\b`)
+)
+
+// changelogEntry is the section of a changelog about one version.
+type changelogEntry struct {
+	version string
+	text    string // the heading and the lines below it
+}
+
+// findChangelog returns the path of the release notes at the module root,
+// preferring a file named like CHANGELOG over CHANGES, HISTORY and the
+// others, or "" if there are none.
+func findChangelog(mf moduleFiles) (string, error) {
+	paths, err := mf.ListFiles("")
+	if err != nil {
+		return "", err
+	}
+
+	var found []string
+
+	for _, p := range paths {
+		if !strings.Contains(p, "/") && changelogFileRe.MatchString(p) {
+			found = append(found, p)
+		}
+	}
+
+	if len(found) == 0 {
+		return "", nil
+	}
+
+	order := []string{"changelog", "change-log", "change_log", "changes", "history", "releases", "release"}
+	rank := func(p string) int {
+		for i, prefix := range order {
+			if strings.HasPrefix(strings.ToLower(p), prefix) {
+				return i
+			}
+		}
+
+		return len(order)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if ri, rj := rank(found[i]), rank(found[j]); ri != rj {
+			return ri < rj
+		}
+
+		return found[i] < found[j]
+	})
+
+	return found[0], nil
+}
+
+// changelogEntries splits a Markdown, reStructuredText or AsciiDoc
+// changelog into the sections whose headings name a version. Version
+// headings are those at the level of the first one: deeper headings are
+// part of a section, and same-level headings without a version, such as
+// "Unreleased", end it.
+func changelogEntries(text string) []changelogEntry {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var (
+		entries []changelogEntry
+		current *changelogEntry
+		body    []string
+		level   int
+		fenced  bool
+	)
+
+	flush := func() {
+		if current != nil {
+			current.text = strings.TrimSpace(strings.Join(body, "\n"))
+			entries = append(entries, *current)
+		}
+
+		current, body = nil, nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+
+		title, l, underlined := changelogHeading(lines, i)
+		if fenced || l == 0 || (level != 0 && l > level) {
+			body = append(body, line)
+
+			continue
+		}
+
+		version := headingVersion(title)
+		if version == "" && level == 0 {
+			body = append(body, line)
+
+			continue
+		}
+
+		flush()
+
+		if version == "" {
+			if underlined {
+				i++
+			}
+
+			continue
+		}
+
+		level = l
+		current = &changelogEntry{version: version}
+		body = []string{line}
+
+		if underlined {
+			i++
+			body = append(body, lines[i])
+		}
+	}
+
+	flush()
+
+	return entries
+}
+
+// changelogHeading returns the text and level of the heading on line i, 1
+// being the outermost, or level 0 if it is not a heading. Underlined
+// headings are ranked by underline character as in Markdown: "=" above
+// "-" above the rest.
+func changelogHeading(lines []string, i int) (string, int, bool) {
+	line := strings.TrimSpace(lines[i])
+
+	if title, ok := cutHeading(line); ok {
+		return title, len(line) - len(strings.TrimLeft(line, line[:1])), false
+	}
+
+	if line == "" || isUnderline(line) || i+1 >= len(lines) {
+		return "", 0, false
+	}
+
+	under := strings.TrimSpace(lines[i+1])
+	if !isUnderline(under) {
+		return "", 0, false
+	}
+
+	switch under[0] {
+	case '=':
+		return line, 1, true
+	case '-':
+		return line, 2, true
+	default:
+		return line, 3, true
+	}
+}
+
+// headingVersion returns the first semantic version in a changelog
+// heading, with the "v" prefix, or "".
+func headingVersion(title string) string {
+	m := changelogVersionRe.FindStringSubmatch(title)
+	if m == nil || !semver.IsValid("v"+m[1]) {
+		return ""
+	}
+
+	return "v" + m[1]
+}
+
+// changelogBetween returns the entries for versions after from, up to and
+// including to, in the changelog's order.
+func changelogBetween(entries []changelogEntry, from, to string) []changelogEntry {
+	var between []changelogEntry
+
+	for _, e := range entries {
+		if semver.Compare(e.version, from) > 0 && semver.Compare(e.version, to) <= 0 {
+			between = append(between, e)
+		}
+	}
+
+	return between
+}
+
+// changelogFit returns how many of the entries fit in limit bytes; the
+// first always does.
+func changelogFit(entries []changelogEntry, limit int) int {
+	written := 0
+
+	for i, e := range entries {
+		if written > 0 && written+len(e.text) > limit {
+			return i
+		}
+
+		written += len(e.text)
+	}
+
+	return len(entries)
+}
+
+// writeChangelog writes the first shown entries, and how many more there
+// are in file.
+func writeChangelog(sb *strings.Builder, entries []changelogEntry, shown int, file string) {
+	for i, e := range entries[:shown] {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(e.text)
+		sb.WriteString("\n")
+	}
+
+	if shown < len(entries) {
+		fmt.Fprintf(sb, "\n... %d more entries; read %s for the rest\n", len(entries)-shown, file)
+	}
+}
+
+type changelogInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'; its changelog is read"`
+	From    string `json:"from,omitempty" jsonschema:"Show every entry after this version, up to version"`
+}
+
+func handleChangelog(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input changelogInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.From != "" && !semver.IsValid(input.From) {
+		return errorResult(fmt.Sprintf("Invalid from version %q: want a semantic version such as v1.2.3.",
+			input.From)), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := findChangelog(mf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if file == "" {
+		return errorResult(fmt.Sprintf("No changelog at the root of %s@%s: no CHANGELOG, CHANGES, HISTORY, NEWS, "+
+			"RELEASES or RELEASE-NOTES file.", input.Module, version)), nil, nil
+	}
+
+	text, err := mf.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", file, err)
+	}
+
+	all := changelogEntries(text)
+
+	var (
+		entries []changelogEntry
+		about   = version
+	)
+
+	if input.From != "" {
+		entries = changelogBetween(all, input.From, version)
+		about = "versions after " + input.From + " up to " + version
+	} else {
+		for _, e := range all {
+			if e.version == version {
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	var sb strings.Builder
+
+	if len(entries) == 0 {
+		fmt.Fprintf(&sb, "%s in %s@%s has no entry for %s.\n", file, input.Module, version, about)
+		formatChangelogVersions(&sb, all, file)
+
+		return textResult(sb.String()), nil, nil
+	}
+
+	fmt.Fprintf(&sb, "%s in %s@%s, %d entries for %s:\n\n", file, input.Module, version, len(entries), about)
+
+	shown := changelogFit(entries, maxChangelogText)
+	if shown < len(entries) {
+		noteTruncated(ctx)
+	}
+
+	writeChangelog(&sb, entries, shown, file)
+
+	return textResult(sb.String()), nil, nil
+}
+
+// formatChangelogVersions lists the versions a changelog has entries for,
+// in its order.
+func formatChangelogVersions(sb *strings.Builder, entries []changelogEntry, file string) {
+	if len(entries) == 0 {
+		fmt.Fprintf(sb, "No heading in %s names a version; read it with gomod_read_file.\n", file)
+
+		return
+	}
+
+	versions := make([]string, 0, maxChangelogVersions)
+
+	for _, e := range entries[:min(len(entries), maxChangelogVersions)] {
+		versions = append(versions, e.version)
+	}
+
+	fmt.Fprintf(sb, "\nVersions with entries (%d): %s", len(entries), strings.Join(versions, ", "))
+
+	if len(entries) > maxChangelogVersions {
+		sb.WriteString(", ...")
+	}
+
+	sb.WriteString("\n")
+}
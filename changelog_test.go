@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindChangelog(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{
+		"NEWS":             "news\n",
+		"HISTORY.md":       "history\n",
+		"CHANGELOG.md":     "changes\n",
+		"docs/CHANGES.md":  "nested\n",
+		"changelog_gen.go": "package m\n",
+	})
+
+	p, err := findChangelog(dirFiles{root: dir})
+	mustf(t, err, "find changelog")
+
+	if p != "CHANGELOG.md" {
+		t.Errorf("changelog = %q, want CHANGELOG.md", p)
+	}
+
+	empty := t.TempDir()
+	writeTree(t, empty, map[string]string{"README.md": "readme\n"})
+
+	if p, err := findChangelog(dirFiles{root: empty}); err != nil || p != "" {
+		t.Errorf("changelog = %q, %v; want none", p, err)
+	}
+}
+
+func TestChangelogEntries(t *testing.T) {
+	text := "# Changelog\n\nAll notable changes.\n\n## [Unreleased]\n\n- wip\n\n" +
+		"## [1.2.0] - 2025-03-01\n\n### Added\n\n- feature\n\n```sh\n# not a heading 9.9.9\n```\n\n" +
+		"## v1.1.0\n\n- fix\n\n## 1.0.0\n\n- first\n"
+
+	entries := changelogEntries(text)
+
+	var versions []string
+	for _, e := range entries {
+		versions = append(versions, e.version)
+	}
+
+	if got := strings.Join(versions, " "); got != "v1.2.0 v1.1.0 v1.0.0" {
+		t.Fatalf("versions = %s", got)
+	}
+
+	if !strings.Contains(entries[0].text, "### Added") || !strings.Contains(entries[0].text, "not a heading") {
+		t.Errorf("v1.2.0 entry lost its subsections:\n%s", entries[0].text)
+	}
+
+	if strings.Contains(entries[0].text, "wip") {
+		t.Errorf("v1.2.0 entry includes Unreleased:\n%s", entries[0].text)
+	}
+
+	between := changelogBetween(entries, "v1.0.0", "v1.2.0")
+	if len(between) != 2 || between[0].version != "v1.2.0" || between[1].version != "v1.1.0" {
+		t.Errorf("between = %v", between)
+	}
+}
+
+func TestChangelogEntries_Underlined(t *testing.T) {
+	text := "Release history\n===============\n\nv0.3.0\n------\n\n* change\n\nv0.2.0\n------\n\n* older\n"
+
+	entries := changelogEntries(text)
+	if len(entries) != 2 || entries[0].version != "v0.3.0" || entries[1].version != "v0.2.0" {
+		t.Fatalf("entries = %v", entries)
+	}
+
+	if entries[0].text != "v0.3.0\n------\n\n* change" {
+		t.Errorf("v0.3.0 text = %q", entries[0].text)
+	}
+}
+
+func TestChangelogFit(t *testing.T) {
+	entries := []changelogEntry{{text: strings.Repeat("a", 30)}, {text: strings.Repeat("b", 30)}, {text: "c"}}
+
+	for limit, want := range map[int]int{10: 1, 60: 2, 61: 3} {
+		if got := changelogFit(entries, limit); got != want {
+			t.Errorf("changelogFit(%d) = %d, want %d", limit, got, want)
+		}
+	}
+}
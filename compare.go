@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fileSetDiff describes how a set of files differs from a base set.
+type fileSetDiff struct {
+	added     []string // only in the new set
+	removed   []string // only in the base set
+	modified  []string // in both, with different content
+	unchanged int
+}
+
+// compareFileSets compares the files under prefix in base and other by
+// content hash.
+func compareFileSets(base, other moduleFiles, prefix string) (*fileSetDiff, error) {
+	baseSums, err := fileDigests(base, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	otherSums, err := fileDigests(other, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &fileSetDiff{}
+
+	for name, sum := range otherSums {
+		baseSum, ok := baseSums[name]
+
+		switch {
+		case !ok:
+			diff.added = append(diff.added, name)
+		case baseSum != sum:
+			diff.modified = append(diff.modified, name)
+		default:
+			diff.unchanged++
+		}
+	}
+
+	for name := range baseSums {
+		if _, ok := otherSums[name]; !ok {
+			diff.removed = append(diff.removed, name)
+		}
+	}
+
+	sort.Strings(diff.added)
+	sort.Strings(diff.removed)
+	sort.Strings(diff.modified)
+
+	return diff, nil
+}
+
+// fileDigests returns the hex SHA-256 digest of every file under prefix.
+func fileDigests(mf moduleFiles, prefix string) (map[string]string, error) {
+	files, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string]string, len(files))
+
+	for _, name := range files {
+		sum, err := fileDigest(mf, name)
+		if err != nil {
+			return nil, err
+		}
+
+		sums[name] = sum
+	}
+
+	return sums, nil
+}
+
+func fileDigest(mf moduleFiles, name string) (string, error) {
+	rc, err := mf.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", fmt.Errorf("hash %s: %w", name, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// formatFileSetDiff renders a diff using git-status style M/A/D markers.
+func formatFileSetDiff(sb *strings.Builder, diff *fileSetDiff, addedLabel, removedLabel string) {
+	sections := []struct {
+		label  string
+		marker string
+		files  []string
+	}{
+		{"Modified", "M", diff.modified},
+		{addedLabel, "A", diff.added},
+		{removedLabel, "D", diff.removed},
+	}
+
+	for _, s := range sections {
+		if len(s.files) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(sb, "\n%s (%d):\n", s.label, len(s.files))
+
+		for _, f := range s.files {
+			fmt.Fprintf(sb, "  %s %s\n", s.marker, f)
+		}
+	}
+
+	fmt.Fprintf(sb, "\nUnchanged: %d files\n", diff.unchanged)
+}
+
+type compareLocalInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Published module version or 'latest'"`
+	Dir     string `json:"dir,omitempty" jsonschema:"Local directory to compare (default: match in -local-dir)"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+}
+
+func handleCompareLocal(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, local *LocalReader, input compareLocalInput,
+) (*mcp.CallToolResult, any, error) {
+	dir := input.Dir
+	if dir == "" {
+		dir = local.Dir(input.Module)
+	}
+
+	if dir == "" {
+		return errorResult(fmt.Sprintf(
+			"No local directory found for %q; pass dir explicitly.", input.Module,
+		)), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	published, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diff, err := compareFileSets(published, dirFiles{root: dir}, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Comparing %s against %s@%s", dir, input.Module, version)
+
+	if input.Path != "" {
+		fmt.Fprintf(&sb, " (prefix: %s)", input.Path)
+	}
+
+	sb.WriteString(":\n")
+
+	formatFileSetDiff(&sb, diff, "Added locally", "Removed locally")
+
+	return textResult(sb.String()), nil, nil
+}
+
+type compareVersionsInput struct {
+	Module string `json:"module" jsonschema:"Go module path"`
+	From   string `json:"from" jsonschema:"Old module version or 'latest'"`
+	To     string `json:"to" jsonschema:"New module version or 'latest'"`
+	Path   string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+}
+
+func handleCompareVersions(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input compareVersionsInput,
+) (*mcp.CallToolResult, any, error) {
+	var (
+		versions [2]string
+		files    [2]moduleFiles
+	)
+
+	for i, v := range []string{input.From, input.To} {
+		version, err := resolveVersion(ctx, proxy, input.Module, v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		versions[i] = version
+
+		if files[i], err = openModule(ctx, proxy, cache, modCache, input.Module, version); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	diff, err := compareFileSets(files[0], files[1], input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Comparing %s@%s against %s", input.Module, versions[1], versions[0])
+
+	if input.Path != "" {
+		fmt.Fprintf(&sb, " (prefix: %s)", input.Path)
+	}
+
+	sb.WriteString(":\n")
+
+	formatFileSetDiff(&sb, diff, "Added in "+versions[1], "Removed in "+versions[1])
+
+	if len(diff.modified) > 0 {
+		sb.WriteString("\nUse gomod_compare_file for the diff of a modified file.\n")
+	}
+
+	return textResult(sb.String()), nil, nil
+}
+
+type diffInput struct {
+	Module   string `json:"module" jsonschema:"Go module path"`
+	Path     string `json:"path" jsonschema:"File path within the module"`
+	VersionA string `json:"versionA" jsonschema:"Old module version or 'latest'"`
+	VersionB string `json:"versionB" jsonschema:"New module version or 'latest'"`
+	Context  *int   `json:"context,omitempty" jsonschema:"Lines of context around changes (default 3)"`
+}
+
+// handleDiff is gomod_compare_file with the versions named versionA and
+// versionB.
+func handleDiff(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input diffInput,
+) (*mcp.CallToolResult, any, error) {
+	return handleCompareFile(ctx, proxy, cache, modCache, compareFileInput{
+		Module: input.Module, Path: input.Path, From: input.VersionA, To: input.VersionB, Context: input.Context,
+	})
+}
+
+// defaultDiffContext is the number of context lines of gomod_compare_file
+// diffs, as in diff -u.
+const defaultDiffContext = 3
+
+type compareFileInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Path    string `json:"path" jsonschema:"File path within the module"`
+	From    string `json:"from" jsonschema:"Old module version or 'latest'"`
+	To      string `json:"to" jsonschema:"New module version or 'latest'"`
+	Context *int   `json:"context,omitempty" jsonschema:"Lines of context around changes (default 3)"`
+	Handle  int    `json:"handle,omitempty" jsonschema:"Handle from gomod_list_files for module, path and from"`
+}
+
+func handleCompareFile(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input compareFileInput,
+) (*mcp.CallToolResult, any, error) {
+	contextLines := defaultDiffContext
+	if input.Context != nil {
+		contextLines = max(*input.Context, 0)
+	}
+
+	var (
+		versions [2]string
+		names    [2]string
+		texts    [2]string
+		found    int
+	)
+
+	for i, v := range []string{input.From, input.To} {
+		version, err := resolveVersion(ctx, proxy, input.Module, v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		versions[i] = version
+
+		mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files, err := mf.ListFiles(input.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list files: %w", err)
+		}
+
+		if !slices.Contains(files, input.Path) {
+			continue
+		}
+
+		if texts[i], err = mf.ReadFile(input.Path); err != nil {
+			return errorResult(err.Error()), nil, nil
+		}
+
+		names[i] = input.Module + "@" + version + "/" + input.Path
+		found++
+	}
+
+	if found == 0 {
+		return errorResult(fmt.Sprintf("%s is not in %s at %s or %s.",
+			input.Path, input.Module, versions[0], versions[1])), nil, nil
+	}
+
+	diff := unifiedDiff(names[0], names[1], texts[0], texts[1], contextLines)
+	if diff == "" {
+		return textResult(fmt.Sprintf("%s is identical in %s@%s and %s.",
+			input.Path, input.Module, versions[0], versions[1])), nil, nil
+	}
+
+	return textResult(diff), nil, nil
+}
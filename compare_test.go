@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files below dir.
+func writeTree(tb testing.TB, dir string, files map[string]string) {
+	tb.Helper()
+
+	for name, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+
+		mustf(tb, os.MkdirAll(filepath.Dir(full), 0o755), "create parent dir for %s", name)
+		mustf(tb, os.WriteFile(full, []byte(content), 0o600), "write %s", name)
+	}
+}
+
+func TestDirFiles_ListFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{
+		"go.mod":             "module example.com/m\n",
+		"main.go":            "package main\n",
+		".git/HEAD":          "ref: refs/heads/main\n",
+		"vendor/x/x.go":      "package x\n",
+		"nested/go.mod":      "module example.com/m/nested\n",
+		"nested/nested.go":   "package nested\n",
+		"pkg/util/util.go":   "package util\n",
+		"pkg/util/README.md": "# util\n",
+	})
+
+	files, err := dirFiles{root: dir}.ListFiles("")
+
+	mustf(t, err, "list files")
+
+	got := strings.Join(files, ",")
+	want := "go.mod,main.go,pkg/util/README.md,pkg/util/util.go"
+
+	if got != want {
+		t.Errorf("ListFiles = %s, want %s", got, want)
+	}
+}
+
+func TestCompareFileSets(t *testing.T) {
+	data := createTestZip(t, "example.com/m@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/m\n",
+		"main.go": "package main\n",
+		"old.go":  "package main\n\nfunc old() {}\n",
+	})
+
+	entry, err := NewZipCache().Put("example.com/m", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{
+		"go.mod":  "module example.com/m\n",
+		"main.go": "package main\n\n// patched\n",
+		"new.go":  "package main\n",
+	})
+
+	diff, err := compareFileSets(zipFiles{entry}, dirFiles{root: dir}, "")
+
+	mustf(t, err, "compare file sets")
+
+	if strings.Join(diff.modified, ",") != "main.go" {
+		t.Errorf("modified = %v, want [main.go]", diff.modified)
+	}
+
+	if strings.Join(diff.added, ",") != "new.go" {
+		t.Errorf("added = %v, want [new.go]", diff.added)
+	}
+
+	if strings.Join(diff.removed, ",") != "old.go" {
+		t.Errorf("removed = %v, want [old.go]", diff.removed)
+	}
+
+	if diff.unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", diff.unchanged)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// configPollInterval is how often the config file is checked for changes.
+const configPollInterval = 5 * time.Second
+
+// reloadableFlags are the server flags a config reload applies to the
+// running server. Changes to the others are logged and need a restart.
+var reloadableFlags = map[string]bool{
+	"allow-modules": true,
+	"deny-modules":  true,
+	"local-dir":     true,
+	"metadata-ttl":  true,
+}
+
+// readConfigFile reads a config file: one server flag per line, written
+// "name = value" without the leading dash. Blank lines and lines starting
+// with # are ignored.
+func readConfigFile(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: want name = value", n)
+		}
+
+		name = strings.TrimLeft(strings.TrimSpace(name), "-")
+		if _, dup := values[name]; dup {
+			return nil, fmt.Errorf("line %d: %s is set twice", n, name)
+		}
+
+		values[name] = strings.TrimSpace(value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	return values, nil
+}
+
+// applyConfigFile sets the server flags in fs from the -config file,
+// except those given on the command line, which take precedence. It does
+// nothing without -config.
+func applyConfigFile(fs *flag.FlagSet, flags *serverFlags) error {
+	if flags.configFile == "" {
+		return nil
+	}
+
+	f, err := os.Open(flags.configFile)
+	if err != nil {
+		return fmt.Errorf("open config: %w", err)
+	}
+	defer f.Close()
+
+	values, err := readConfigFile(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.configFile, err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, name := range sortedKeys(values) {
+		if fs.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("%s: unknown setting %q", flags.configFile, name)
+		}
+
+		if explicit[name] {
+			continue
+		}
+
+		if err := fs.Set(name, values[name]); err != nil {
+			return fmt.Errorf("%s: %s: %w", flags.configFile, name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseServerFlags parses the server's command line and then its config
+// file.
+func parseServerFlags(name string, args []string, handling flag.ErrorHandling) (*flag.FlagSet, *serverFlags, error) {
+	fs := flag.NewFlagSet(name, handling)
+	flags := registerServerFlags(fs)
+
+	if handling == flag.ContinueOnError {
+		fs.SetOutput(io.Discard)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("parse flags: %w", err)
+	}
+
+	if err := applyConfigFile(fs, flags); err != nil {
+		return nil, nil, err
+	}
+
+	return fs, flags, nil
+}
+
+// configReloader applies changes of the config file to a running server,
+// when the file's modification time changes or the process gets SIGHUP.
+// The command line is parsed again each time, so it keeps precedence.
+type configReloader struct {
+	name string
+	args []string
+	svc  *services
+
+	mu      sync.Mutex // reloads come from the scheduler and signals
+	current *flag.FlagSet
+	modTime time.Time
+}
+
+func newConfigReloader(
+	name string, args []string, fs *flag.FlagSet, flags *serverFlags, svc *services,
+) *configReloader {
+	r := &configReloader{name: name, args: args, svc: svc, current: fs}
+
+	if info, err := os.Stat(flags.configFile); err == nil {
+		r.modTime = info.ModTime()
+	}
+
+	return r
+}
+
+// Schedule registers polling of the config file on s and reloads it on
+// SIGHUP until ctx is done. SIGHUP also works while s is paused, as in
+// -offline mode.
+func (r *configReloader) Schedule(ctx context.Context, s *Scheduler, configFile string) {
+	s.Every("config-reload", configPollInterval, func(context.Context) error {
+		info, err := os.Stat(configFile)
+		if err != nil {
+			return fmt.Errorf("check config: %w", err)
+		}
+
+		r.mu.Lock()
+		changed := !info.ModTime().Equal(r.modTime)
+		r.modTime = info.ModTime()
+		r.mu.Unlock()
+
+		if !changed {
+			return nil
+		}
+
+		return r.reloadAndLog()
+	})
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				if err := r.reloadAndLog(); err != nil {
+					log.Printf("warning: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *configReloader) reloadAndLog() error {
+	applied, restart, err := r.reload()
+	if err != nil {
+		return err
+	}
+
+	if len(applied) > 0 {
+		log.Printf("config reloaded: %s", strings.Join(applied, ", "))
+	}
+
+	if len(restart) > 0 {
+		log.Printf("warning: config changes to %s need a server restart", strings.Join(restart, ", "))
+	}
+
+	return nil
+}
+
+// reload parses the flags and config file again and applies the changed
+// reloadable settings. It returns the names of the applied settings and
+// of the changed ones that need a restart. On error nothing is applied.
+func (r *configReloader) reload() ([]string, []string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fs, flags, err := parseServerFlags(r.name, r.args, flag.ContinueOnError)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reload config: %w", err)
+	}
+
+	var applied, restart []string
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Value.String() == r.current.Lookup(f.Name).Value.String() {
+			return
+		}
+
+		if reloadableFlags[f.Name] {
+			applied = append(applied, f.Name)
+		} else {
+			restart = append(restart, f.Name)
+		}
+	})
+
+	if len(applied) > 0 {
+		if err := r.svc.reconfigure(flags); err != nil {
+			return nil, nil, fmt.Errorf("reload config: %w", err)
+		}
+	}
+
+	r.current = fs
+
+	return applied, restart, nil
+}
+
+// reconfigure applies the reloadable settings of flags to the running
+// services.
+func (svc *services) reconfigure(flags *serverFlags) error {
+	if svc.proxy.policy == nil {
+		return errors.New("the server has no module policy to update")
+	}
+
+	if err := svc.proxy.policy.Update(flags.allowModules, flags.denyModules); err != nil {
+		return err
+	}
+
+	svc.local.SetBaseDir(flags.localDir)
+
+	if svc.proxy.meta != nil {
+		svc.proxy.meta.SetTTL(flags.metadataTTL)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	values, err := readConfigFile(strings.NewReader(
+		"# shared daemon\n\nallow-modules = github.com/acme/*\n-offline=true\n  metadata-ttl =  1m \n"))
+	mustf(t, err, "read config")
+
+	want := map[string]string{"allow-modules": "github.com/acme/*", "offline": "true", "metadata-ttl": "1m"}
+	if len(values) != len(want) {
+		t.Fatalf("values = %v, want %v", values, want)
+	}
+
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+
+	for _, bad := range []string{"offline\n", "offline = true\noffline = false\n"} {
+		if _, err := readConfigFile(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func writeConfig(t *testing.T, file, text string) {
+	t.Helper()
+
+	mustf(t, os.WriteFile(file, []byte(text), 0o600), "write config")
+}
+
+func TestParseServerFlags_Config(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gomod.conf")
+	writeConfig(t, file, "deny-modules = example.com/secret\nlocal-dir = /from/config\n")
+
+	_, flags, err := parseServerFlags("test", []string{"-config", file, "-local-dir", "/from/args"}, flag.ContinueOnError)
+	mustf(t, err, "parse flags")
+
+	if flags.denyModules != "example.com/secret" {
+		t.Errorf("deny-modules = %q, want the config file's", flags.denyModules)
+	}
+
+	if flags.localDir != "/from/args" {
+		t.Errorf("local-dir = %q, want the command line's", flags.localDir)
+	}
+
+	writeConfig(t, file, "no-such-flag = 1\n")
+
+	if _, _, err := parseServerFlags("test", []string{"-config", file}, flag.ContinueOnError); err == nil {
+		t.Error("expected an error for an unknown setting")
+	}
+
+	writeConfig(t, file, "config = other.conf\n")
+
+	if _, _, err := parseServerFlags("test", []string{"-config", file}, flag.ContinueOnError); err == nil {
+		t.Error("expected an error for config in the config file")
+	}
+}
+
+func TestConfigReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "gomod.conf")
+	writeConfig(t, file, "deny-modules = example.com/a\nlocal-dir = "+dir+"\n")
+
+	args := []string{"-config", file}
+
+	fs, flags, err := parseServerFlags("test", args, flag.ContinueOnError)
+	mustf(t, err, "parse flags")
+
+	policy, err := NewModulePolicy(flags.allowModules, flags.denyModules)
+	mustf(t, err, "create policy")
+
+	svc := &services{
+		proxy: &ProxyClient{policy: policy, meta: NewMetadataCache(flags.metadataTTL)},
+		local: NewLocalReader(flags.localDir),
+	}
+	r := newConfigReloader("test", args, fs, flags, svc)
+
+	projects := filepath.Join(dir, "projects")
+	mustf(t, os.MkdirAll(filepath.Join(projects, "b"), 0o755), "create local module")
+	writeConfig(t, file, "deny-modules = example.com/b\nlocal-dir = "+projects+"\nmetadata-ttl = 1s\noffline = true\n")
+
+	applied, restart, err := r.reload()
+	mustf(t, err, "reload")
+
+	if strings.Join(applied, ",") != "deny-modules,local-dir,metadata-ttl" || strings.Join(restart, ",") != "offline" {
+		t.Errorf("applied %v, restart %v", applied, restart)
+	}
+
+	if policy.Check("example.com/a") != nil || policy.Check("example.com/b") == nil {
+		t.Error("the reloaded deny list was not applied")
+	}
+
+	if svc.local.Dir("example.com/b") == "" {
+		t.Error("the reloaded local dir was not applied")
+	}
+
+	if svc.proxy.meta.ttl != time.Second {
+		t.Errorf("metadata TTL = %v, want 1s", svc.proxy.meta.ttl)
+	}
+
+	writeConfig(t, file, "deny-modules = [\n")
+
+	if _, _, err := r.reload(); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+
+	if policy.Check("example.com/b") == nil {
+		t.Error("a failed reload changed the policy")
+	}
+
+	applied, restart, err = r.reload()
+	if err == nil || applied != nil || restart != nil {
+		t.Errorf("second failed reload: %v %v %v", applied, restart, err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// directiveIssue is a replace or exclude directive of the project whose
+// effect on the build may not be the intended one.
+type directiveIssue struct {
+	directive string // as written in go.mod
+	problem   string
+	chains    [][]module.Version // the requirements involved
+}
+
+// directiveIssues checks the project's replace and exclude directives
+// against its graph: directives about modules or versions the build does
+// not use, replacements that take a module below the version the graph
+// requires, and excluded versions that modules still require.
+func (g *moduleGraph) directiveIssues() []directiveIssue {
+	var issues []directiveIssue
+
+	for _, r := range g.replaces {
+		if issue, ok := g.replaceIssue(r); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	for _, x := range sortedVersions(slices.Collect(maps.Keys(g.excludedBy))) {
+		issue := directiveIssue{
+			directive: "exclude " + x.Path + " " + x.Version,
+			problem: fmt.Sprintf("required by %d module versions; the go command uses the next version "+
+				"that is not excluded instead (the highest other version here is %s)",
+				len(g.excludedBy[x]), cmp.Or(g.selected[x.Path], "none")),
+		}
+
+		for _, from := range sortedVersions(g.excludedBy[x]) {
+			issue.chains = append(issue.chains, append(g.chainTo(from), x))
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// replaceIssue reports a replace directive that does not apply or that
+// downgrades the module it replaces.
+func (g *moduleGraph) replaceIssue(r *modfile.Replace) (directiveIssue, bool) {
+	issue := directiveIssue{directive: "replace " + formatReplace(r)}
+
+	sel, inGraph := g.selected[r.Old.Path]
+
+	switch {
+	case !inGraph:
+		issue.problem = "has no effect: nothing in the module graph requires " + r.Old.Path
+	case r.Old.Version != "" && r.Old.Version != sel:
+		issue.problem = fmt.Sprintf("has no effect: %s is selected at %s, not %s", r.Old.Path, sel, r.Old.Version)
+	case r.Old.Version == "" && r.New.Path == r.Old.Path && semver.Compare(r.New.Version, sel) < 0:
+		issue.problem = fmt.Sprintf("downgrades %s: the build uses %s, but the graph requires %s",
+			r.Old.Path, r.New.Version, sel)
+	default:
+		return issue, false
+	}
+
+	if inGraph {
+		issue.chains = [][]module.Version{g.chainTo(module.Version{Path: r.Old.Path, Version: sel})}
+	}
+
+	return issue, true
+}
+
+// chainTo returns a shortest require chain from the main module to target.
+func (g *moduleGraph) chainTo(target module.Version) []module.Version {
+	if target == g.main {
+		return []module.Version{g.main}
+	}
+
+	return g.chain(func(m module.Version) bool { return m == target })
+}
+
+func formatReplace(r *modfile.Replace) string {
+	old, replacement := r.Old.Path, r.New.Path
+
+	if r.Old.Version != "" {
+		old += " " + r.Old.Version
+	}
+
+	if r.New.Version != "" {
+		replacement += " " + r.New.Version
+	}
+
+	return old + " => " + replacement
+}
+
+func formatGraphConflicts(sb *strings.Builder, g *moduleGraph) {
+	majors := g.majors()
+	issues := g.directiveIssues()
+
+	if len(majors) == 0 && len(issues) == 0 {
+		sb.WriteString("\nNo module is selected at several major versions, and the replace and exclude " +
+			"directives apply as written.\n")
+
+		return
+	}
+
+	if len(majors) > 0 {
+		fmt.Fprintf(sb, "\nModules selected at several major versions (%d); each major is a separate module "+
+			"whose types and global state are not shared with the others:\n", len(majors))
+
+		for _, prefix := range sortedKeys(majors) {
+			fmt.Fprintf(sb, "  %s:\n", prefix)
+
+			for _, m := range majors[prefix] {
+				fmt.Fprintf(sb, "    %s via %s\n", m, formatChain(g.chainTo(m)))
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		fmt.Fprintf(sb, "\nReplace and exclude directives with surprising effects (%d):\n", len(issues))
+
+		for _, issue := range issues {
+			fmt.Fprintf(sb, "  %s: %s\n", issue.directive, issue.problem)
+
+			for _, chain := range issue.chains {
+				fmt.Fprintf(sb, "    via %s\n", formatChain(chain))
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestFormatGraphConflicts(t *testing.T) {
+	proxy, ts := newTestProxy(graphProxy())
+	defer ts.Close()
+
+	goMod := graphGoMod + `
+replace example.com/b v0.9.0 => ./b
+
+replace example.com/x => example.com/x v0.9.0
+
+replace example.com/zzz => ./zzz
+
+exclude example.com/c v1.1.0
+`
+
+	mf, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	mustf(t, err, "parse go.mod")
+
+	g, err := buildModuleGraph(context.Background(), proxy, &project{mod: mf})
+	mustf(t, err, "build graph")
+
+	var sb strings.Builder
+
+	formatGraphConflicts(&sb, g)
+
+	text := sb.String()
+
+	for _, want := range []string{
+		"several major versions (1)",
+		"  example.com/x:\n" +
+			"    example.com/x@v1.0.0 via example.com/app -> example.com/b@v1.0.0 -> example.com/x@v1.0.0\n" +
+			"    example.com/x/v2@v2.0.0 via example.com/app -> example.com/x/v2@v2.0.0\n",
+		"surprising effects (4):\n",
+		"  replace example.com/b v0.9.0 => ./b: has no effect: example.com/b is selected at v1.0.0, not v0.9.0\n" +
+			"    via example.com/app -> example.com/b@v1.0.0\n",
+		"  replace example.com/x => example.com/x v0.9.0: downgrades example.com/x: the build uses v0.9.0, " +
+			"but the graph requires v1.0.0\n",
+		"  replace example.com/zzz => ./zzz: has no effect: nothing in the module graph requires example.com/zzz\n",
+		"  exclude example.com/c v1.1.0: required by 1 module versions",
+		"(the highest other version here is v1.2.0)\n" +
+			"    via example.com/app -> example.com/a@v1.0.0 -> example.com/c@v1.1.0\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	mf, err = modfile.Parse("go.mod", []byte("module example.com/app\n\nrequire example.com/c v1.1.0\n"), nil)
+	mustf(t, err, "parse go.mod")
+
+	g, err = buildModuleGraph(context.Background(), proxy, &project{mod: mf})
+	mustf(t, err, "build graph")
+
+	sb.Reset()
+	formatGraphConflicts(&sb, g)
+
+	if !strings.Contains(sb.String(), "apply as written") {
+		t.Errorf("expected no conflicts:\n%s", sb.String())
+	}
+}
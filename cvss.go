@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// Severity ratings, from most to least severe.
+const (
+	severityCritical = "CRITICAL"
+	severityHigh     = "HIGH"
+	severityMedium   = "MEDIUM"
+	severityLow      = "LOW"
+	severityUnknown  = "UNKNOWN"
+)
+
+var severityOrder = []string{severityCritical, severityHigh, severityMedium, severityLow, severityUnknown}
+
+// normalizeSeverity maps the severity labels used by advisory databases
+// (GitHub uses MODERATE) to the CVSS qualitative ratings.
+func normalizeSeverity(s string) string {
+	switch s = strings.ToUpper(strings.TrimSpace(s)); s {
+	case severityCritical, severityHigh, severityMedium, severityLow:
+		return s
+	case "MODERATE":
+		return severityMedium
+	default:
+		return severityUnknown
+	}
+}
+
+// cvss3Rating returns the qualitative rating for a CVSS v3 score.
+func cvss3Rating(score float64) string {
+	switch {
+	case score >= 9:
+		return severityCritical
+	case score >= 7:
+		return severityHigh
+	case score >= 4:
+		return severityMedium
+	case score > 0:
+		return severityLow
+	default:
+		return severityUnknown
+	}
+}
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore computes the base score of a CVSS v3.x vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". It reports false for
+// vectors it cannot parse.
+func cvss3BaseScore(vector string) (float64, bool) {
+	if !strings.HasPrefix(vector, "CVSS:3.") {
+		return 0, false
+	}
+
+	metrics := make(map[string]string)
+
+	for _, part := range strings.Split(vector, "/")[1:] {
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			metrics[k] = v
+		}
+	}
+
+	values := make(map[string]float64)
+
+	for name, weights := range cvss3Weights {
+		w, ok := weights[metrics[name]]
+		if !ok {
+			return 0, false
+		}
+
+		values[name] = w
+	}
+
+	changed := metrics["S"] == "C"
+
+	// Privileges Required weighs more when the scope changes.
+	var pr float64
+
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if changed {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if changed {
+			pr = 0.5
+		}
+	default:
+		return 0, false
+	}
+
+	iss := 1 - (1-values["C"])*(1-values["I"])*(1-values["A"])
+
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+
+	if impact <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * values["AV"] * values["AC"] * pr * values["UI"]
+
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// cvssRoundUp rounds up to one decimal as defined by the CVSS v3.1
+// specification, avoiding floating point artefacts.
+func cvssRoundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+
+	return (math.Floor(float64(i)/10000) + 1) / 10
+}
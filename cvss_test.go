@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", 7.5},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:R/S:C/C:L/I:L/A:N", 5.4},
+		{"CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+	}
+
+	for _, tt := range tests {
+		got, ok := cvss3BaseScore(tt.vector)
+		if !ok || got != tt.want {
+			t.Errorf("cvss3BaseScore(%s) = %v, %v; want %v", tt.vector, got, ok, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "CVSS:2.0/AV:N", "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"} {
+		if _, ok := cvss3BaseScore(bad); ok {
+			t.Errorf("cvss3BaseScore(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSeverityRatings(t *testing.T) {
+	if got := cvss3Rating(9.8); got != severityCritical {
+		t.Errorf("rating 9.8 = %s", got)
+	}
+
+	if got := cvss3Rating(5.4); got != severityMedium {
+		t.Errorf("rating 5.4 = %s", got)
+	}
+
+	if got := normalizeSeverity("moderate"); got != severityMedium {
+		t.Errorf("normalize moderate = %s", got)
+	}
+
+	if got := normalizeSeverity(""); got != severityUnknown {
+		t.Errorf("normalize empty = %s", got)
+	}
+}
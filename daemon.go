@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// idleCheckInterval is how often the HTTP server counts its sessions
+	// for -idle-timeout.
+	idleCheckInterval = 10 * time.Second
+
+	// shutdownTimeout bounds how long an idle shutdown waits for requests
+	// in flight.
+	shutdownTimeout = 5 * time.Second
+)
+
+// idleTracker decides when a server without sessions has been idle long
+// enough to exit.
+type idleTracker struct {
+	timeout   time.Duration
+	idleSince time.Time // zero while sessions are connected
+}
+
+func newIdleTracker(timeout time.Duration, now time.Time) *idleTracker {
+	return &idleTracker{timeout: timeout, idleSince: now}
+}
+
+// observe records the number of sessions connected at now and reports
+// whether there have been none for the whole timeout.
+func (t *idleTracker) observe(sessions int, now time.Time) bool {
+	if sessions > 0 {
+		t.idleSince = time.Time{}
+
+		return false
+	}
+
+	if t.idleSince.IsZero() {
+		t.idleSince = now
+	}
+
+	return now.Sub(t.idleSince) >= t.timeout
+}
+
+func countSessions(server *mcp.Server) int {
+	n := 0
+	for range server.Sessions() {
+		n++
+	}
+
+	return n
+}
+
+// listenMCP serves MCP over streamable HTTP on addr.
+func listenMCP(ctx context.Context, addr string, server *mcp.Server, idleTimeout time.Duration) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	log.Printf("serving MCP on http://%s", ln.Addr())
+
+	return serveMCP(ctx, ln, server, idleTimeout)
+}
+
+// serveMCP serves MCP over streamable HTTP on ln until ctx is done or,
+// with a positive idleTimeout, no session has been connected for that
+// long, counting from the start. GET /healthz answers supervisors
+// without opening a session.
+func serveMCP(ctx context.Context, ln net.Listener, server *mcp.Server, idleTimeout time.Duration) error {
+	mux := http.NewServeMux()
+	mux.Handle("/", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "ok, sessions: %d\n", countSessions(server))
+	})
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		var tick <-chan time.Time
+
+		if idleTimeout > 0 {
+			ticker := time.NewTicker(min(idleCheckInterval, idleTimeout/2))
+			defer ticker.Stop()
+
+			tick = ticker.C
+		}
+
+		idle := newIdleTracker(idleTimeout, time.Now())
+
+		for {
+			select {
+			case <-ctx.Done():
+			case now := <-tick:
+				if !idle.observe(countSessions(server), now) {
+					continue
+				}
+
+				log.Printf("no MCP sessions for %v, shutting down", idleTimeout)
+			}
+
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancelShutdown()
+
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				srv.Close()
+			}
+
+			return
+		}
+	}()
+
+	err := srv.Serve(ln)
+
+	cancel()
+	<-stopped
+
+	if !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	return nil
+}
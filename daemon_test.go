@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestIdleTracker(t *testing.T) {
+	start := time.Now()
+	idle := newIdleTracker(time.Minute, start)
+
+	if idle.observe(0, start.Add(30*time.Second)) {
+		t.Fatal("idle before the timeout")
+	}
+
+	if idle.observe(1, start.Add(2*time.Minute)) {
+		t.Fatal("idle with a session connected")
+	}
+
+	if idle.observe(0, start.Add(150*time.Second)) {
+		t.Fatal("idle time not reset by the session")
+	}
+
+	if !idle.observe(0, start.Add(210*time.Second)) {
+		t.Fatal("not idle a minute after the last session")
+	}
+}
+
+func TestServeMCP_IdleTimeout(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := "http://" + ln.Addr().String()
+	done := make(chan error, 1)
+
+	go func() { done <- serveMCP(context.Background(), ln, server, 200*time.Millisecond) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: url}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(url + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), "sessions: 1") {
+		t.Errorf("healthz = %q, want sessions: 1", body)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped with a session connected: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	session.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serveMCP: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the idle timeout")
+	}
+}
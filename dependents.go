@@ -0,0 +1,135 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// moduleReach is what deps.dev and pkg.go.dev know about who uses a module
+// version and how well its repository is kept. Missing data is left nil or
+// -1 rather than failing the report.
+type moduleReach struct {
+	dependents *depsDevDependents
+	importedBy int // of the module's root package on pkg.go.dev, -1 if unknown
+	repo       string
+	project    *depsDevProject
+}
+
+// lookupReach queries deps.dev for the dependents, source repository and
+// Scorecard of a module version, and pkg.go.dev for its importer count.
+func lookupReach(
+	ctx context.Context, pkgsite *PkgsiteClient, depsDev *DepsDevClient, mod, version string,
+) *moduleReach {
+	reach := &moduleReach{importedBy: -1}
+
+	if d, err := depsDev.Dependents(ctx, mod, version); err == nil {
+		reach.dependents = d
+	}
+
+	if v, err := depsDev.Version(ctx, mod, version); err == nil && v.sourceRepo() != "" {
+		reach.repo = v.sourceRepo()
+
+		if p, err := depsDev.Project(ctx, reach.repo); err == nil {
+			reach.project = p
+		}
+	}
+
+	if results, err := pkgsite.Search(ctx, mod, 5); err == nil {
+		for _, r := range results {
+			if r.path == mod {
+				reach.importedBy = r.importedBy
+
+				break
+			}
+		}
+	}
+
+	return reach
+}
+
+func formatReach(sb *strings.Builder, mod, version string, reach *moduleReach) {
+	fmt.Fprintf(sb, "Dependents of %s@%s:\n", mod, version)
+
+	if d := reach.dependents; d != nil {
+		fmt.Fprintf(sb, "  deps.dev: %d packages depend on this version (%d directly, %d indirectly)\n",
+			d.DependentCount, d.DirectDependentCount, d.IndirectDependentCount)
+	} else {
+		sb.WriteString("  deps.dev: dependents unknown\n")
+	}
+
+	if reach.importedBy >= 0 {
+		fmt.Fprintf(sb, "  pkg.go.dev: %s is imported by %d packages\n", mod, reach.importedBy)
+	} else {
+		sb.WriteString("  pkg.go.dev: importers unknown\n")
+	}
+
+	if reach.repo == "" {
+		sb.WriteString("\nNo source repository known to deps.dev, so no Scorecard.\n")
+
+		return
+	}
+
+	p := reach.project
+	if p == nil {
+		fmt.Fprintf(sb, "\nSource repository %s: no data on deps.dev.\n", reach.repo)
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\nSource repository %s: %d stars, %d forks, %d open issues\n",
+		reach.repo, p.StarsCount, p.ForksCount, p.OpenIssuesCount)
+
+	if p.Scorecard == nil {
+		sb.WriteString("\nNo OpenSSF Scorecard for the repository.\n")
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\nOpenSSF Scorecard: %.1f/10", p.Scorecard.OverallScore)
+
+	if !p.Scorecard.Date.IsZero() {
+		fmt.Fprintf(sb, ", from %s", p.Scorecard.Date.Format(time.DateOnly))
+	}
+
+	fmt.Fprintf(sb, " (%d checks):\n", len(p.Scorecard.Checks))
+
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+
+	for _, c := range p.Scorecard.Checks {
+		score := "n/a"
+		if c.Score >= 0 {
+			score = fmt.Sprint(c.Score)
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", c.Name, score, clipLine(c.Reason))
+	}
+
+	_ = tw.Flush()
+}
+
+type dependentsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version,omitempty" jsonschema:"Module version or 'latest' (default)"`
+}
+
+func handleDependents(
+	ctx context.Context, proxy *ProxyClient, pkgsite *PkgsiteClient,
+	depsDev *DepsDevClient, input dependentsInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, cmp.Or(input.Version, "latest"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	formatReach(&sb, input.Module, version, lookupReach(ctx, pkgsite, depsDev, input.Module, version))
+
+	return textResult(sb.String()), nil, nil
+}
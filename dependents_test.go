@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatReach(t *testing.T) {
+	for _, tt := range []struct {
+		reach *moduleReach
+		want  string
+	}{
+		{&moduleReach{importedBy: -1, repo: "github.com/o/m"},
+			"Dependents of m@v1.0.0:\n  deps.dev: dependents unknown\n  pkg.go.dev: importers unknown\n\n" +
+				"Source repository github.com/o/m: no data on deps.dev.\n"},
+		{&moduleReach{importedBy: 7, repo: "github.com/o/m", project: &depsDevProject{StarsCount: 2}},
+			"Dependents of m@v1.0.0:\n  deps.dev: dependents unknown\n  pkg.go.dev: m is imported by 7 packages\n\n" +
+				"Source repository github.com/o/m: 2 stars, 0 forks, 0 open issues\n\n" +
+				"No OpenSSF Scorecard for the repository.\n"},
+	} {
+		var sb strings.Builder
+
+		formatReach(&sb, "m", "v1.0.0", tt.reach)
+
+		if got := sb.String(); got != tt.want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultDepsDevURL = "https://api.deps.dev"
+
+// DepsDevClient queries the deps.dev v3 API for package, version and
+// source project metadata.
+type DepsDevClient struct {
+	apiClient
+}
+
+func NewDepsDevClient() *DepsDevClient {
+	return &DepsDevClient{apiClient{baseURL: defaultDepsDevURL, client: http.DefaultClient}}
+}
+
+// jsonInt decodes an integer sent either as a JSON number or, as protobuf
+// JSON does for int64 fields, as a string.
+type jsonInt int64
+
+func (n *jsonInt) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseInt(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse integer %s: %w", data, err)
+	}
+
+	*n = jsonInt(v)
+
+	return nil
+}
+
+type depsDevVersionKey struct {
+	Version string `json:"version"`
+}
+
+type depsDevVersion struct {
+	VersionKey      depsDevVersionKey `json:"versionKey"`
+	PublishedAt     time.Time         `json:"publishedAt"`
+	Licenses        []string          `json:"licenses"`
+	RelatedProjects []struct {
+		ProjectKey struct {
+			ID string `json:"id"`
+		} `json:"projectKey"`
+		RelationType string `json:"relationType"`
+	} `json:"relatedProjects"`
+}
+
+// sourceRepo returns the ID of the version's source repository project,
+// e.g. "github.com/owner/repo", or "".
+func (v *depsDevVersion) sourceRepo() string {
+	for _, p := range v.RelatedProjects {
+		if p.RelationType == "SOURCE_REPO" {
+			return p.ProjectKey.ID
+		}
+	}
+
+	return ""
+}
+
+type depsDevProject struct {
+	OpenIssuesCount jsonInt `json:"openIssuesCount"`
+	StarsCount      jsonInt `json:"starsCount"`
+	ForksCount      jsonInt `json:"forksCount"`
+	License         string  `json:"license"`
+	Description     string  `json:"description"`
+	Scorecard       *struct {
+		Date         time.Time `json:"date"`
+		OverallScore float64   `json:"overallScore"`
+		Checks       []struct {
+			Name   string `json:"name"`
+			Score  int    `json:"score"` // 0-10, or -1 when the check does not apply
+			Reason string `json:"reason"`
+		} `json:"checks"`
+	} `json:"scorecard"`
+}
+
+// maintainedScore returns the OpenSSF Scorecard "Maintained" check score
+// (0-10), or -1 if it is not available.
+func (p *depsDevProject) maintainedScore() int {
+	if p.Scorecard == nil {
+		return -1
+	}
+
+	for _, c := range p.Scorecard.Checks {
+		if c.Name == "Maintained" {
+			return c.Score
+		}
+	}
+
+	return -1
+}
+
+// Version returns licenses and related projects for a module version.
+func (c *DepsDevClient) Version(ctx context.Context, module, version string) (*depsDevVersion, error) {
+	var v depsDevVersion
+
+	path := "/v3/systems/go/packages/" + url.PathEscape(module) + "/versions/" + url.PathEscape(version)
+	if err := c.getJSON(ctx, path, &v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// depsDevDependents counts the packages that depend on a version.
+type depsDevDependents struct {
+	DependentCount         jsonInt `json:"dependentCount"`
+	DirectDependentCount   jsonInt `json:"directDependentCount"`
+	IndirectDependentCount jsonInt `json:"indirectDependentCount"`
+}
+
+// Dependents returns how many packages known to deps.dev depend on a
+// module version. The endpoint is only part of the v3alpha API.
+func (c *DepsDevClient) Dependents(ctx context.Context, module, version string) (*depsDevDependents, error) {
+	var d depsDevDependents
+
+	path := "/v3alpha/systems/go/packages/" + url.PathEscape(module) + "/versions/" + url.PathEscape(version) +
+		":dependents"
+	if err := c.getJSON(ctx, path, &d); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// Project returns repository metadata such as stars and the OpenSSF
+// Scorecard for a project ID like "github.com/owner/repo".
+func (c *DepsDevClient) Project(ctx context.Context, id string) (*depsDevProject, error) {
+	var p depsDevProject
+
+	if err := c.getJSON(ctx, "/v3/projects/"+url.PathEscape(id), &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONInt(t *testing.T) {
+	var v struct {
+		A jsonInt `json:"a"`
+		B jsonInt `json:"b"`
+	}
+
+	mustf(t, json.Unmarshal([]byte(`{"a": 12, "b": "345"}`), &v), "unmarshal")
+
+	if v.A != 12 || v.B != 345 {
+		t.Errorf("got %d, %d", v.A, v.B)
+	}
+}
+
+func TestDepsDevClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/go/packages/example.com%2Fmod/versions/v1.0.0":
+			_, _ = w.Write([]byte(`{
+				"licenses": ["MIT", "Apache-2.0"],
+				"relatedProjects": [
+					{"projectKey": {"id": "github.com/other/x"}, "relationType": "ISSUE_TRACKER"},
+					{"projectKey": {"id": "github.com/owner/mod"}, "relationType": "SOURCE_REPO"}
+				]
+			}`))
+		case "/v3/projects/github.com%2Fowner%2Fmod":
+			_, _ = w.Write([]byte(`{
+				"starsCount": "120",
+				"scorecard": {"checks": [{"name": "Maintained", "score": 7}]}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := &DepsDevClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+	ctx := context.Background()
+
+	v, err := client.Version(ctx, "example.com/mod", "v1.0.0")
+	mustf(t, err, "version")
+
+	if len(v.Licenses) != 2 || v.sourceRepo() != "github.com/owner/mod" {
+		t.Errorf("unexpected version: %+v", v)
+	}
+
+	p, err := client.Project(ctx, "github.com/owner/mod")
+	mustf(t, err, "project")
+
+	if p.StarsCount != 120 || p.maintainedScore() != 7 {
+		t.Errorf("stars = %d, maintained = %d", p.StarsCount, p.maintainedScore())
+	}
+
+	if (&depsDevProject{}).maintainedScore() != -1 {
+		t.Error("expected -1 without scorecard")
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffEdits bounds the edit distance the line diff searches. Beyond it
+// the differing middle of two texts is shown as one replacement, which is
+// correct but not minimal.
+const maxDiffEdits = 1000
+
+// diffOp is one line of a line diff: ' ' kept, '-' removed or '+' added.
+type diffOp struct {
+	kind byte
+	line string // including its newline, if any
+}
+
+// splitLines splits text into lines that keep their newline, so that a
+// missing final newline is a difference like any other.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the edits turning a into b.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b)-prefix-suffix)
+
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops
+}
+
+// myersDiff is Myers' O(ND) shortest edit script. Each round keeps the
+// frontier of the diagonals it reached, which is then walked back from
+// the end to recover the edits.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	var trace [][]int // trace[d] holds v[-d-1..d+1] before round d
+
+	for d := 0; d <= min(n+m, maxDiffEdits); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return myersBacktrack(trace, a, b)
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+
+	return ops
+}
+
+func myersBacktrack(trace [][]int, a, b []string) []diffOp {
+	var ops []diffOp
+
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		frontier := func(k int) int { return trace[d][k+d+1] }
+
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && frontier(k-1) < frontier(k+1)) {
+			prevK = k + 1
+		}
+
+		prevX := frontier(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[y-1]})
+		} else {
+			ops = append(ops, diffOp{'-', a[x-1]})
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// unifiedDiff returns a unified diff from oldText to newText with the
+// given number of context lines, or "" when they are equal. An empty
+// name is written as /dev/null.
+func unifiedDiff(oldName, newName, oldText, newText string, context int) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var changes []int
+
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", diffName(oldName), diffName(newName))
+
+	// oldLine and newLine are the line numbers before ops[i], counted
+	// incrementally as hunks are written in order.
+	oldLine, newLine, pos := 1, 1, 0
+	advance := func(to int) {
+		for ; pos < to; pos++ {
+			if ops[pos].kind != '+' {
+				oldLine++
+			}
+
+			if ops[pos].kind != '-' {
+				newLine++
+			}
+		}
+	}
+
+	for i := 0; i < len(changes); {
+		// A hunk takes in every later change whose context would overlap.
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context+1 {
+			j++
+		}
+
+		start := max(changes[i]-context, 0)
+		end := min(changes[j]+context+1, len(ops))
+
+		advance(start)
+		writeHunk(&sb, ops[start:end], oldLine, newLine)
+
+		i = j + 1
+	}
+
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp, oldStart, newStart int) {
+	oldLen, newLen := 0, 0
+
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldLen++
+		}
+
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+
+	for _, op := range ops {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+
+		if !strings.HasSuffix(op.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk's line range like diff -u: the length is left
+// out when it is 1, and an empty range starts at the line before it.
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	default:
+		return fmt.Sprintf("%d,%d", start, length)
+	}
+}
+
+func diffName(name string) string {
+	if name == "" {
+		return "/dev/null"
+	}
+
+	return name
+}
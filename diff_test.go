@@ -0,0 +1,128 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(n int, change map[int]string) string {
+		var sb strings.Builder
+
+		for i := 1; i <= n; i++ {
+			if s, ok := change[i]; ok {
+				sb.WriteString(s)
+
+				continue
+			}
+
+			sb.WriteString(strings.Repeat("x", i%7) + "\n")
+		}
+
+		return sb.String()
+	}
+
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", 3, ""},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", 1, "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"insert at start", "b\n", "a\nb\n", 0, "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{"delete at end", "a\nb\n", "a\n", 3, "--- old\n+++ new\n@@ -1,2 +1 @@\n a\n-b\n"},
+		{
+			"no final newline", "a\nb", "a\nb\n", 0,
+			"--- old\n+++ new\n@@ -2 +2 @@\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{"new file", "", "a\n", 3, "--- /dev/null\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{
+			"separate hunks", lines(20, nil), lines(20, map[int]string{3: "three\n", 18: "eighteen\n"}), 2,
+			"--- old\n+++ new\n@@ -1,5 +1,5 @@\n x\n xx\n-xxx\n+three\n xxxx\n xxxxx\n" +
+				"@@ -16,5 +16,5 @@\n xx\n xxx\n-xxxx\n+eighteen\n xxxxx\n xxxxxx\n",
+		},
+		{
+			"merged hunks", lines(10, nil), lines(10, map[int]string{3: "three\n", 8: "eight\n"}), 2,
+			"--- old\n+++ new\n@@ -1,10 +1,10 @@\n x\n xx\n-xxx\n+three\n xxxx\n xxxxx\n xxxxxx\n \n" +
+				"-x\n+eight\n xx\n xxx\n",
+		},
+	}
+
+	for _, tt := range tests {
+		oldName := "old"
+		if tt.old == "" {
+			oldName = ""
+		}
+
+		if got := unifiedDiff(oldName, "new", tt.old, tt.new, tt.context); got != tt.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDiffLines_Minimal checks random edits against an LCS table: the
+// script must rebuild both sides with the fewest changes.
+func TestDiffLines_Minimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	random := func() []string {
+		s := make([]string, rng.Intn(12))
+		for i := range s {
+			s[i] = string(rune('a' + rng.Intn(3)))
+		}
+
+		return s
+	}
+
+	for range 500 {
+		a, b := random(), random()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+
+		changes := 0
+
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+
+			if op.kind != ' ' {
+				changes++
+			}
+		}
+
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("diff of %v and %v does not rebuild them: %v", a, b, ops)
+		}
+
+		if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+			t.Fatalf("diff of %v and %v has %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	return table[0][0]
+}
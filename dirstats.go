@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dirStats holds file and line counts for one directory subtree. Lines of
+// Go code and of tests are counted separately; files covers every file.
+type dirStats struct {
+	dir       string
+	files     int
+	goFiles   int
+	goLines   int
+	testFiles int
+	testLines int
+}
+
+func (s *dirStats) add(o *dirStats) {
+	s.files += o.files
+	s.goFiles += o.goFiles
+	s.goLines += o.goLines
+	s.testFiles += o.testFiles
+	s.testLines += o.testLines
+}
+
+// statsDir returns the directory that p is counted under: its first depth
+// directory levels below prefix, or prefix itself for files directly in
+// it.
+func statsDir(p, prefix string, depth int) string {
+	rel := strings.TrimPrefix(p, prefix)
+
+	elems := strings.Split(rel, "/")
+	elems = elems[:len(elems)-1] // drop the file name
+
+	if len(elems) > depth {
+		elems = elems[:depth]
+	}
+
+	dir := strings.TrimSuffix(prefix, "/")
+	if len(elems) > 0 {
+		dir = strings.TrimPrefix(dir+"/"+strings.Join(elems, "/"), "/")
+	}
+
+	if dir == "" {
+		return "."
+	}
+
+	return dir
+}
+
+// computeDirStats counts the files of mf under prefix by directory,
+// sorted by directory. Go files in testdata and vendor directories count
+// as plain files.
+func computeDirStats(mf moduleFiles, prefix string, depth int) ([]*dirStats, error) {
+	// The prefix names a directory, so "internal" does not also count
+	// "internalx".
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+
+	byDir := make(map[string]*dirStats)
+
+	for _, p := range paths {
+		dir := statsDir(p, prefix, depth)
+
+		s := byDir[dir]
+		if s == nil {
+			s = &dirStats{dir: dir}
+			byDir[dir] = s
+		}
+
+		s.files++
+
+		if !isGoSource(p) {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		if strings.HasSuffix(p, "_test.go") {
+			s.testFiles++
+			s.testLines += strings.Count(src, "\n")
+		} else {
+			s.goFiles++
+			s.goLines += strings.Count(src, "\n")
+		}
+	}
+
+	result := make([]*dirStats, 0, len(byDir))
+
+	for _, dir := range sortedKeys(byDir) {
+		result = append(result, byDir[dir])
+	}
+
+	return result, nil
+}
+
+type dirStatsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional directory to break down instead of the module root"`
+	Depth   int    `json:"depth,omitempty" jsonschema:"Directory levels to break down (default 1)"`
+}
+
+func handleDirStats(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input dirStatsInput,
+) (*mcp.CallToolResult, any, error) {
+	depth := max(input.Depth, 1)
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats, err := computeDirStats(mf, input.Path, depth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(stats) == 0 {
+		return errorResult(fmt.Sprintf("No files under %q in %s@%s.", input.Path, input.Module, version)), nil, nil
+	}
+
+	return textResult(formatDirStats(input.Module, version, stats)), nil, nil
+}
+
+func formatDirStats(module, version string, stats []*dirStats) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Directory statistics for %s@%s (%d directories):\n\n", module, version, len(stats))
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "DIRECTORY\tFILES\tGO FILES\tGO LINES\tTEST FILES\tTEST LINES")
+
+	total := &dirStats{dir: "(total)"}
+
+	for _, s := range stats {
+		total.add(s)
+	}
+
+	for _, s := range append(stats, total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n",
+			s.dir, s.files, s.goFiles, s.goLines, s.testFiles, s.testLines)
+	}
+
+	_ = tw.Flush()
+
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestComputeDirStats(t *testing.T) {
+	mf := testModuleFiles(t, "v1.0.0", map[string]string{
+		"go.mod":                     "module example.com/m\n",
+		"m.go":                       "package m\n\nfunc M() {}\n",
+		"m_test.go":                  "package m\n",
+		"internal/a/a.go":            "package a\n",
+		"internal/b/b.go":            "package b\n\n",
+		"internal/b/b_test.go":       "package b\n\n\n",
+		"internal/b/testdata/x.go":   "package x\n",
+		"internalx/x.go":             "package x\n",
+		"cmd/tool/main.go":           "package main\n",
+		"cmd/tool/testdata/input.md": "# input\n",
+	})
+
+	stats, err := computeDirStats(mf, "", 1)
+	mustf(t, err, "compute stats")
+
+	want := "Directory statistics for example.com/m@v1.0.0 (4 directories):\n\n" +
+		"DIRECTORY  FILES  GO FILES  GO LINES  TEST FILES  TEST LINES\n" +
+		".          3      1         3         1           1\n" +
+		"cmd        2      1         1         0           0\n" +
+		"internal   4      2         3         1           3\n" +
+		"internalx  1      1         1         0           0\n" +
+		"(total)    10     5         8         2           4\n"
+
+	if got := formatDirStats("example.com/m", "v1.0.0", stats); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	stats, err = computeDirStats(mf, "internal", 1)
+	mustf(t, err, "compute stats under internal")
+
+	var dirs []string
+	for _, s := range stats {
+		dirs = append(dirs, s.dir)
+	}
+
+	if got := len(dirs); got != 2 || dirs[0] != "internal/a" || dirs[1] != "internal/b" {
+		t.Errorf("dirs under internal = %v", dirs)
+	}
+}
+
+func TestStatsDir(t *testing.T) {
+	tests := []struct {
+		path, prefix string
+		depth        int
+		want         string
+	}{
+		{"go.mod", "", 1, "."},
+		{"a/b/c.go", "", 1, "a"},
+		{"a/b/c.go", "", 2, "a/b"},
+		{"a/b/c.go", "a/", 1, "a/b"},
+		{"a/c.go", "a/", 1, "a"},
+	}
+
+	for _, tt := range tests {
+		if got := statsDir(tt.path, tt.prefix, tt.depth); got != tt.want {
+			t.Errorf("statsDir(%q, %q, %d) = %q, want %q", tt.path, tt.prefix, tt.depth, got, tt.want)
+		}
+	}
+}
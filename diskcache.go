@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/mod/module"
+)
+
+// backendDiskCache is noted for the audit log when the disk cache answers.
+const backendDiskCache = "disk-cache"
+
+// DiskCache keeps immutable module data, zips, go.mod and .info files,
+// across restarts in the -cache-dir directory. Each file is stored compressed
+// with zstd under the SHA-256 of its content, so one shared by many
+// versions, as go.mod files often are, is stored once:
+//
+//	index/<escaped module>/@v/<version>.zip   hex digest of the content
+//	objects/<first two digits>/<digest>.zst   the compressed content
+//
+// An object whose content no longer matches its name is dropped and read
+// from the proxy again.
+type DiskCache struct {
+	dir string
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+// OpenDiskCache creates the cache directory if needed.
+func OpenDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		return nil, fmt.Errorf("zstd encoder: %w", err)
+	}
+
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(2*maxZipSize))
+	if err != nil {
+		return nil, fmt.Errorf("zstd decoder: %w", err)
+	}
+
+	return &DiskCache{dir: dir, enc: enc, dec: dec}, nil
+}
+
+func (c *DiskCache) indexPath(mod, version, ext string) (string, error) {
+	escMod, err := module.EscapePath(mod)
+	if err != nil {
+		return "", fmt.Errorf("escape module path: %w", err)
+	}
+
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("escape version: %w", err)
+	}
+
+	return filepath.Join(c.dir, "index", filepath.FromSlash(escMod), "@v", escVersion+"."+ext), nil
+}
+
+func (c *DiskCache) objectPath(digest string) string {
+	return filepath.Join(c.dir, "objects", digest[:2], digest+".zst")
+}
+
+// Get returns the stored file of a module version, such as its "zip" or
+// "mod", and whether there was one.
+func (c *DiskCache) Get(mod, version, ext string) ([]byte, bool) {
+	index, err := c.indexPath(mod, version, ext)
+	if err != nil {
+		return nil, false
+	}
+
+	ref, err := os.ReadFile(index)
+	if err != nil {
+		return nil, false
+	}
+
+	digest := strings.TrimSpace(string(ref))
+	if len(digest) != sha256.Size*2 {
+		return nil, false
+	}
+
+	compressed, err := os.ReadFile(c.objectPath(digest))
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := c.dec.DecodeAll(compressed, nil)
+	if err == nil {
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) == digest {
+			return data, true
+		}
+
+		err = errors.New("content does not match its digest")
+	}
+
+	log.Printf("warning: dropping corrupt cache object for %s@%s.%s: %v", mod, version, ext, err)
+
+	_ = os.Remove(c.objectPath(digest))
+	_ = os.Remove(index)
+
+	return nil, false
+}
+
+// Put stores a file of a module version. Files are written to a temporary
+// name and renamed, so that readers never see part of one.
+func (c *DiskCache) Put(mod, version, ext string, data []byte) error {
+	index, err := c.indexPath(mod, version, ext)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	if object := c.objectPath(digest); !fileExists(object) {
+		if err := writeFileAtomic(object, c.enc.EncodeAll(data, nil)); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(index, []byte(digest+"\n"))
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	return nil
+}
+
+// diskCacheUsage is the size of the stored objects.
+type diskCacheUsage struct {
+	objects int
+	bytes   int64 // on disk, compressed
+}
+
+// Usage adds up the objects in the cache.
+func (c *DiskCache) Usage() (diskCacheUsage, error) {
+	var u diskCacheUsage
+
+	err := filepath.WalkDir(filepath.Join(c.dir, "objects"), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(p, ".zst") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		u.objects++
+		u.bytes += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return u, fmt.Errorf("walk cache dir: %w", err)
+	}
+
+	return u, nil
+}
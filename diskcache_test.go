@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskCache(t *testing.T) {
+	c, err := OpenDiskCache(t.TempDir())
+	mustf(t, err, "open disk cache")
+
+	if _, ok := c.Get("example.com/Mod", "v1.0.0", "mod"); ok {
+		t.Fatal("empty cache returned a file")
+	}
+
+	goMod := bytes.Repeat([]byte("module example.com/Mod\n"), 100)
+
+	mustf(t, c.Put("example.com/Mod", "v1.0.0", "mod", goMod), "put v1.0.0")
+	mustf(t, c.Put("example.com/Mod", "v1.0.1", "mod", goMod), "put v1.0.1")
+
+	for _, version := range []string{"v1.0.0", "v1.0.1"} {
+		if data, ok := c.Get("example.com/Mod", version, "mod"); !ok || !bytes.Equal(data, goMod) {
+			t.Errorf("Get %s = %q, %v", version, data, ok)
+		}
+	}
+
+	u, err := c.Usage()
+	mustf(t, err, "usage")
+
+	if u.objects != 1 || u.bytes >= int64(len(goMod)) {
+		t.Errorf("usage = %+v, want one object smaller than %d bytes", u, len(goMod))
+	}
+
+	if _, err := os.Stat(filepath.Join(c.dir, "index", "example.com", "!mod", "@v", "v1.0.0.mod")); err != nil {
+		t.Errorf("index file not at the escaped path: %v", err)
+	}
+
+	objects, _ := filepath.Glob(filepath.Join(c.dir, "objects", "*", "*.zst"))
+	mustf(t, os.WriteFile(objects[0], c.enc.EncodeAll([]byte("tampered"), nil), 0o600), "corrupt object")
+
+	if _, ok := c.Get("example.com/Mod", "v1.0.0", "mod"); ok {
+		t.Error("corrupt object was returned")
+	}
+
+	if _, err := os.Stat(objects[0]); !os.IsNotExist(err) {
+		t.Errorf("corrupt object was not removed: %v", err)
+	}
+}
+
+func TestProxyClient_DiskCache(t *testing.T) {
+	requests := 0
+
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		_, _ = w.Write([]byte("module example.com/testmod\n"))
+	}))
+	defer ts.Close()
+
+	disk, err := OpenDiskCache(t.TempDir())
+	mustf(t, err, "open disk cache")
+
+	proxy.disk = disk
+
+	for range 2 {
+		if _, err := proxy.ReadMod(context.Background(), "example.com/testmod", "v1.0.0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxy.offline = true
+
+	data, err := proxy.ReadMod(context.Background(), "example.com/testmod", "v1.0.0")
+	if err != nil || data != "module example.com/testmod\n" || requests != 1 {
+		t.Errorf("ReadMod = %q, %v after %d requests; want one request and the cached go.mod", data, err, requests)
+	}
+}
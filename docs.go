@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// docFile is an entry of a module's documentation index.
+type docFile struct {
+	path  string
+	title string
+	bytes int
+	lines int
+}
+
+var docExtensions = map[string]bool{
+	".md": true, ".markdown": true, ".rst": true, ".adoc": true, ".txt": true,
+}
+
+// isDocFile reports whether p is long-form documentation: markdown and
+// similar files anywhere in the module, plain text only inside doc
+// directories. License files, vendored code and test fixtures are left out.
+func isDocFile(p string) bool {
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		if elem == "testdata" || elem == "vendor" {
+			return false
+		}
+	}
+
+	if isLicenseFile(p) {
+		return false
+	}
+
+	ext := strings.ToLower(path.Ext(p))
+	if !docExtensions[ext] {
+		return false
+	}
+
+	if ext != ".txt" {
+		return true
+	}
+
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		switch strings.ToLower(elem) {
+		case "doc", "docs", "documentation":
+			return true
+		}
+	}
+
+	return false
+}
+
+// docTitle returns the first heading of a markdown, reStructuredText or
+// AsciiDoc document, skipping YAML front matter unless it sets a title.
+func docTitle(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			line := strings.TrimSpace(lines[i])
+			if line == "---" {
+				lines = lines[i+1:]
+
+				break
+			}
+
+			if title, ok := strings.CutPrefix(line, "title:"); ok {
+				return strings.Trim(strings.TrimSpace(title), `"'`)
+			}
+		}
+	}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if title, ok := cutHeading(line); ok {
+			return title
+		}
+
+		// Setext and reStructuredText headings are underlined.
+		if line != "" && i+1 < len(lines) && isUnderline(strings.TrimSpace(lines[i+1])) {
+			return line
+		}
+	}
+
+	return ""
+}
+
+// cutHeading returns the text of a markdown ("# ") or AsciiDoc ("= ")
+// heading line.
+func cutHeading(line string) (string, bool) {
+	for _, marker := range []byte{'#', '='} {
+		trimmed := strings.TrimLeft(line, string(marker))
+		if len(trimmed) < len(line) && strings.HasPrefix(trimmed, " ") {
+			return strings.TrimSpace(strings.TrimRight(trimmed, " #")), true
+		}
+	}
+
+	return "", false
+}
+
+func isUnderline(line string) bool {
+	if len(line) < 3 {
+		return false
+	}
+
+	return strings.Trim(line, string(line[0])) == "" && strings.ContainsRune("=-~^*", rune(line[0]))
+}
+
+// packageSynopsis returns the first sentence of the package comment in a
+// Go file.
+func packageSynopsis(p, src string) string {
+	_, synopsis := packageClause(p, src)
+
+	return synopsis
+}
+
+// packageClause returns the package name of a Go file and the first
+// sentence of its package comment.
+func packageClause(p, src string) (name, synopsis string) {
+	f, err := parser.ParseFile(token.NewFileSet(), p, src, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return "", ""
+	}
+
+	if f.Doc != nil {
+		synopsis = new(doc.Package).Synopsis(f.Doc.Text())
+	}
+
+	return f.Name.Name, synopsis
+}
+
+// findDocs indexes the documentation files and doc.go package comments
+// under prefix. doc.go files without a package comment are skipped.
+func findDocs(mf moduleFiles, prefix string) ([]*docFile, []*docFile, error) {
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(paths)
+
+	var docs, pkgDocs []*docFile
+
+	for _, p := range paths {
+		isDocGo := path.Base(p) == "doc.go" && isGoSource(p)
+		if !isDocGo && !isDocFile(p) {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		d := &docFile{path: p, bytes: len(src), lines: strings.Count(src, "\n")}
+
+		if isDocGo {
+			if d.title = packageSynopsis(p, src); d.title != "" {
+				pkgDocs = append(pkgDocs, d)
+			}
+
+			continue
+		}
+
+		d.title = docTitle(src)
+		docs = append(docs, d)
+	}
+
+	return docs, pkgDocs, nil
+}
+
+func formatDocs(module, version string, docs, pkgDocs []*docFile) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Documentation in %s@%s (%d files, %d doc.go):\n", module, version, len(docs), len(pkgDocs))
+
+	if len(docs) == 0 && len(pkgDocs) == 0 {
+		sb.WriteString("\nNo documentation files found.\n")
+
+		return sb.String()
+	}
+
+	if len(docs) > 0 {
+		sb.WriteString("\n")
+
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(tw, "FILE\tLINES\tBYTES\tTITLE")
+
+		for _, d := range docs {
+			title := d.title
+			if title == "" {
+				title = "-"
+			}
+
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", d.path, d.lines, d.bytes, title)
+		}
+
+		_ = tw.Flush()
+	}
+
+	if len(pkgDocs) > 0 {
+		sb.WriteString("\nPackage documentation (doc.go):\n")
+
+		for _, d := range pkgDocs {
+			fmt.Fprintf(&sb, "  %s (%d lines): %s\n", d.path, d.lines, d.title)
+		}
+	}
+
+	return sb.String()
+}
+
+type docsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+}
+
+func handleDocs(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input docsInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	docs, pkgDocs, err := findDocs(mf, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(formatDocs(input.Module, version, docs, pkgDocs)), nil, nil
+}
+
+// findReadme returns the README among paths that is directly in dir, "."
+// being the module root, preferring a markdown one when there are several,
+// or "".
+func findReadme(paths []string, dir string) string {
+	var readmes []string
+
+	for _, p := range paths {
+		if path.Dir(p) == dir && strings.HasPrefix(strings.ToLower(path.Base(p)), "readme") {
+			readmes = append(readmes, p)
+		}
+	}
+
+	sort.Slice(readmes, func(i, j int) bool {
+		mi, mj := strings.EqualFold(path.Ext(readmes[i]), ".md"), strings.EqualFold(path.Ext(readmes[j]), ".md")
+		if mi != mj {
+			return mi
+		}
+
+		return readmes[i] < readmes[j]
+	})
+
+	if len(readmes) == 0 {
+		return ""
+	}
+
+	return readmes[0]
+}
+
+// cutAtLine returns the longest prefix of text of at most limit bytes that
+// ends a line, or the first limit bytes when even the first line is
+// longer.
+func cutAtLine(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+
+	if i := strings.LastIndexByte(text[:limit], '\n'); i >= 0 {
+		return text[:i+1]
+	}
+
+	return text[:limit]
+}
+
+type readmeInput struct {
+	Module   string `json:"module" jsonschema:"Go module path"`
+	Version  string `json:"version" jsonschema:"Module version or 'latest'"`
+	Dir      string `json:"dir,omitempty" jsonschema:"Directory whose README to read (default: module root)"`
+	MaxBytes int    `json:"max_bytes,omitempty" jsonschema:"Return at most this many bytes, cut at the end of a line"`
+}
+
+func handleReadme(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input readmeInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, prefix := ".", ""
+	if d := strings.Trim(path.Clean("/"+input.Dir), "/"); d != "" {
+		dir, prefix = d, d+"/"
+	}
+
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	readme := findReadme(paths, dir)
+	if readme == "" {
+		return errorResult(fmt.Sprintf("No README in %s of %s@%s.", dir, input.Module, version)), nil, nil
+	}
+
+	text, err := mf.ReadFile(readme)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", readme, err)
+	}
+
+	if input.MaxBytes > 0 && len(text) > input.MaxBytes {
+		cut := cutAtLine(text, input.MaxBytes)
+		rest := len(text) - len(cut)
+
+		noteFullSize(ctx, len(text))
+		noteTruncated(ctx)
+
+		if !strings.HasSuffix(cut, "\n") {
+			cut += "\n"
+		}
+
+		text = cut + fmt.Sprintf("[... %d more bytes of %s; raise max_bytes or read it with gomod_read_file]\n",
+			rest, readme)
+	}
+
+	return textResult(text), nil, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocTitle(t *testing.T) {
+	tests := map[string]string{
+		"# Project\n\nIntro.\n":                      "Project",
+		"<!-- badge -->\n\n## Design notes ##\n":     "Design notes",
+		"Architecture\n============\n\nText.\n":      "Architecture",
+		"---\nlayout: page\ntitle: \"Guide\"\n---\n": "Guide",
+		"---\nlayout: page\n---\n\n# After matter\n": "After matter",
+		"= AsciiDoc Title\n:toc:\n":                  "AsciiDoc Title",
+		"Just some text without a heading.\n":        "",
+		"#hashtag is not a heading\nmore\n":          "",
+	}
+
+	for src, want := range tests {
+		if got := docTitle(src); got != want {
+			t.Errorf("docTitle(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestFindDocs(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"README.md":           "# Mod\n\nDoes things.\n",
+		"LICENSE.md":          "# MIT License\n",
+		"docs/design.md":      "# Design\n",
+		"docs/notes.txt":      "Notes\n-----\n",
+		"notes.txt":           "scratch\n",
+		"internal/api/API.md": "API\n===\n",
+		"testdata/golden.md":  "# Golden\n",
+		"doc.go":              "// Package mod does things. It has details.\npackage mod\n",
+		"sub/doc.go":          "package sub\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+	mustf(t, err, "put zip in cache")
+
+	docs, pkgDocs, err := findDocs(zipFiles{entry}, "")
+	mustf(t, err, "find docs")
+
+	var paths []string
+	for _, d := range docs {
+		paths = append(paths, d.path+"="+d.title)
+	}
+
+	want := "README.md=Mod,docs/design.md=Design,docs/notes.txt=Notes,internal/api/API.md=API"
+	if got := strings.Join(paths, ","); got != want {
+		t.Errorf("docs = %s, want %s", got, want)
+	}
+
+	if len(pkgDocs) != 1 || pkgDocs[0].title != "Package mod does things." {
+		t.Errorf("unexpected package docs: %+v", pkgDocs)
+	}
+
+	out := formatDocs("mod", "v1.0.0", docs, pkgDocs)
+	if !strings.Contains(out, "(4 files, 1 doc.go)") ||
+		!strings.Contains(out, "  doc.go (2 lines): Package mod does things.") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestFindReadme(t *testing.T) {
+	paths := []string{"README", "README.md", "docs/README.rst", "docs/guide.md", "readme.txt"}
+
+	for dir, want := range map[string]string{".": "README.md", "docs": "docs/README.rst", "cmd": ""} {
+		if got := findReadme(paths, dir); got != want {
+			t.Errorf("findReadme(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestCutAtLine(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"one\ntwo\nthree\n", 100, "one\ntwo\nthree\n"},
+		{"one\ntwo\nthree\n", 9, "one\ntwo\n"},
+		{"a long first line\n", 6, "a long"},
+	}
+
+	for _, tt := range tests {
+		if got := cutAtLine(tt.text, tt.limit); got != tt.want {
+			t.Errorf("cutAtLine(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
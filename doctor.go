@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSumDBURL    = "https://sum.golang.org"
+	doctorProbeModule  = "golang.org/x/mod"
+	doctorProbeTimeout = 10 * time.Second
+)
+
+// Check results, in increasing severity.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "FAIL"
+)
+
+// doctorCheck is one line of the doctor report.
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+}
+
+// doctor diagnoses an installation. Its fields are the server's flags and
+// the environment it would run in.
+type doctor struct {
+	flags      *serverFlags
+	proxy      *ProxyClient
+	goproxyErr error // from parsing GOPROXY into proxy's list
+	sumDBURL   string
+	client     *http.Client
+	modCache   string
+	lookPath   func(string) (string, error)
+}
+
+// runDoctor implements the doctor subcommand. It accepts the server's
+// flags so that the checks match the configuration being diagnosed.
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	flags := registerServerFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	if err := applyConfigFile(fs, flags); err != nil {
+		return err
+	}
+
+	// A bad -ca-bundle is reported by its check; probe without it.
+	client, err := newHTTPClient(flags.caBundle)
+	if err != nil {
+		client = &http.Client{}
+	}
+
+	client.Timeout = doctorProbeTimeout
+
+	d := &doctor{
+		flags:    flags,
+		proxy:    NewProxyClient(),
+		sumDBURL: defaultSumDBURL,
+		client:   client,
+		modCache: discoverModCache(),
+		lookPath: exec.LookPath,
+	}
+	d.proxy.client = d.client
+	d.proxy.offline = flags.offline
+	d.proxy.proxies, d.goproxyErr = parseGOPROXY(goEnv("GOPROXY"))
+
+	checks := d.run(context.Background())
+	writeDoctorReport(os.Stdout, checks)
+
+	for _, c := range checks {
+		if c.status == checkFail {
+			return errors.New("some checks failed")
+		}
+	}
+
+	return nil
+}
+
+// run performs all checks in report order.
+func (d *doctor) run(ctx context.Context) []doctorCheck {
+	checks := []doctorCheck{
+		d.checkGo(ctx),
+		d.checkModCache(),
+		d.checkProxy(ctx),
+		d.checkSumDB(ctx),
+		d.checkLocalDir(),
+		d.checkBinary("govulncheck", d.flags.govulncheck, "needed by gomod_govulncheck"),
+		d.checkBinary("git", "git", "needed by gomod_nested_modules"),
+	}
+
+	if d.flags.auditLog != "" {
+		checks = append(checks, d.checkAuditLog())
+	}
+
+	if d.flags.caBundle != "" {
+		checks = append(checks, d.checkCABundle())
+	}
+
+	if _, err := NewModulePolicy(d.flags.allowModules, d.flags.denyModules); err != nil {
+		checks = append(checks, doctorCheck{"module policy", checkFail, err.Error()})
+	}
+
+	if _, err := ParseModuleAliases(d.flags.moduleAliases); err != nil {
+		checks = append(checks, doctorCheck{"module aliases", checkFail, err.Error()})
+	}
+
+	return checks
+}
+
+func (d *doctor) checkGo(ctx context.Context) doctorCheck {
+	path, err := d.lookPath("go")
+	if err != nil {
+		return doctorCheck{"go command", checkWarn,
+			"not on PATH; set GOMODCACHE so the module cache can be found"}
+	}
+
+	out, err := exec.CommandContext(ctx, path, "version").Output() //nolint:gosec // The path comes from LookPath.
+	if err != nil {
+		return doctorCheck{"go command", checkWarn, fmt.Sprintf("%s: go version failed: %v", path, err)}
+	}
+
+	return doctorCheck{"go command", checkOK, strings.TrimSpace(string(out)) + " at " + path}
+}
+
+func (d *doctor) checkModCache() doctorCheck {
+	if d.modCache == "" {
+		return doctorCheck{"module cache", checkWarn, "GOMODCACHE unknown; modules are always downloaded"}
+	}
+
+	info, err := os.Stat(d.modCache)
+	if err != nil {
+		return doctorCheck{"module cache", checkWarn, fmt.Sprintf("%s: %v", d.modCache, err)}
+	}
+
+	if !info.IsDir() {
+		return doctorCheck{"module cache", checkFail, d.modCache + " is not a directory"}
+	}
+
+	if _, err := os.ReadDir(d.modCache); err != nil {
+		return doctorCheck{"module cache", checkFail, fmt.Sprintf("%s is not readable: %v", d.modCache, err)}
+	}
+
+	download := filepath.Join(d.modCache, "cache", "download")
+	if _, err := os.Stat(download); err != nil {
+		return doctorCheck{"module cache", checkWarn,
+			d.modCache + " has no cache/download directory; the GOPROXY endpoint serves no local files"}
+	}
+
+	return doctorCheck{"module cache", checkOK, d.modCache}
+}
+
+func (d *doctor) checkProxy(ctx context.Context) doctorCheck {
+	if d.flags.offline {
+		return doctorCheck{"module proxy", checkWarn, "skipped (-offline)"}
+	}
+
+	if d.goproxyErr != nil {
+		return doctorCheck{"module proxy", checkFail, d.goproxyErr.Error()}
+	}
+
+	endpoints := d.proxy.Endpoints()
+	if len(endpoints) == 0 {
+		return doctorCheck{"module proxy", checkFail, "GOPROXY lists no proxy, only direct or off"}
+	}
+
+	start := time.Now()
+
+	info, err := d.proxy.Latest(ctx, doctorProbeModule)
+	if err != nil {
+		return doctorCheck{"module proxy", checkFail, fmt.Sprintf("%s: %v", strings.Join(endpoints, ", "), err)}
+	}
+
+	return doctorCheck{"module proxy", checkOK, fmt.Sprintf("%s (%s@%s in %s%s)",
+		strings.Join(endpoints, ", "), doctorProbeModule, info.Version, time.Since(start).Round(time.Millisecond),
+		egressProxy(endpoints[0]))}
+}
+
+// egressProxy describes the proxy from HTTPS_PROXY or HTTP_PROXY that
+// requests to rawURL go through, or returns "" when they go direct.
+func egressProxy(rawURL string) string {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return ""
+	}
+
+	u, err := http.ProxyFromEnvironment(req)
+	if err != nil || u == nil {
+		return ""
+	}
+
+	return ", via proxy " + u.Redacted()
+}
+
+func (d *doctor) checkSumDB(ctx context.Context) doctorCheck {
+	if d.flags.offline {
+		return doctorCheck{"checksum database", checkWarn, "skipped (-offline)"}
+	}
+
+	if err := probeSumDB(ctx, d.client, d.sumDBURL); err != nil {
+		return doctorCheck{"checksum database", checkFail, err.Error()}
+	}
+
+	return doctorCheck{"checksum database", checkOK, d.sumDBURL}
+}
+
+func (d *doctor) checkLocalDir() doctorCheck {
+	info, err := os.Stat(d.flags.localDir)
+	if err != nil {
+		return doctorCheck{"local dir", checkWarn,
+			fmt.Sprintf("%s: %v (local fallback suggestions disabled)", d.flags.localDir, err)}
+	}
+
+	if !info.IsDir() {
+		return doctorCheck{"local dir", checkFail, d.flags.localDir + " is not a directory"}
+	}
+
+	return doctorCheck{"local dir", checkOK, d.flags.localDir}
+}
+
+// checkBinary checks an optional external command. A missing one only
+// disables the tool that needs it.
+func (d *doctor) checkBinary(name, bin, purpose string) doctorCheck {
+	path, err := d.lookPath(bin)
+	if err != nil {
+		return doctorCheck{name, checkWarn, fmt.Sprintf("%s not found (%s)", bin, purpose)}
+	}
+
+	return doctorCheck{name, checkOK, path}
+}
+
+func (d *doctor) checkAuditLog() doctorCheck {
+	f, err := os.OpenFile(filepath.Clean(d.flags.auditLog), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return doctorCheck{"audit log", checkFail, err.Error()}
+	}
+
+	_ = f.Close()
+
+	return doctorCheck{"audit log", checkOK, d.flags.auditLog + " is writable"}
+}
+
+func (d *doctor) checkCABundle() doctorCheck {
+	if _, err := loadCABundle(d.flags.caBundle); err != nil {
+		return doctorCheck{"CA bundle", checkFail, err.Error()}
+	}
+
+	return doctorCheck{"CA bundle", checkOK, d.flags.caBundle + " is trusted along with the system roots"}
+}
+
+func writeDoctorReport(w io.Writer, checks []doctorCheck) {
+	width := 0
+	for _, c := range checks {
+		width = max(width, len(c.name))
+	}
+
+	failed, warned := 0, 0
+
+	for _, c := range checks {
+		fmt.Fprintf(w, "[%-4s] %-*s  %s\n", c.status, width, c.name, c.detail)
+
+		switch c.status {
+		case checkFail:
+			failed++
+		case checkWarn:
+			warned++
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d checks: %d failed, %d warnings\n", len(checks), failed, warned)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang.org/x/mod/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v0.33.0"}`))
+		case "/sumdb/latest":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	modCache := t.TempDir()
+	mustf(t, os.MkdirAll(filepath.Join(modCache, "cache", "download"), 0o750), "create download dir")
+
+	d := &doctor{
+		flags: &serverFlags{
+			localDir:    filepath.Join(t.TempDir(), "missing"),
+			govulncheck: "govulncheck",
+			auditLog:    filepath.Join(t.TempDir(), "audit.jsonl"),
+			denyModules: "example.com/[bad",
+
+			moduleAliases: "grpc",
+		},
+		proxy:    &ProxyClient{baseURL: ts.URL, client: ts.Client()},
+		sumDBURL: ts.URL + "/sumdb",
+		client:   ts.Client(),
+		modCache: modCache,
+		lookPath: func(bin string) (string, error) {
+			if bin == "git" {
+				return "/usr/bin/git", nil
+			}
+
+			return "", errors.New("not found")
+		},
+	}
+
+	checks := d.run(context.Background())
+
+	want := map[string]string{
+		"go command":        checkWarn,
+		"module cache":      checkOK,
+		"module proxy":      checkOK,
+		"checksum database": checkFail,
+		"local dir":         checkWarn,
+		"govulncheck":       checkWarn,
+		"git":               checkOK,
+		"audit log":         checkOK,
+		"module policy":     checkFail,
+		"module aliases":    checkFail,
+	}
+
+	if len(checks) != len(want) {
+		t.Fatalf("got %d checks, want %d: %+v", len(checks), len(want), checks)
+	}
+
+	for _, c := range checks {
+		if want[c.name] != c.status {
+			t.Errorf("%s: status %s (%s), want %s", c.name, c.status, c.detail, want[c.name])
+		}
+	}
+
+	var sb strings.Builder
+
+	writeDoctorReport(&sb, checks)
+
+	if text := sb.String(); !strings.Contains(text, "[FAIL] checksum database  ") ||
+		!strings.Contains(text, "10 checks: 3 failed, 3 warnings") {
+		t.Errorf("unexpected report:\n%s", text)
+	}
+}
+
+func TestDoctor_Offline(t *testing.T) {
+	d := &doctor{flags: &serverFlags{offline: true}}
+
+	for _, c := range []doctorCheck{d.checkProxy(context.Background()), d.checkSumDB(context.Background())} {
+		if c.status != checkWarn || !strings.Contains(c.detail, "-offline") {
+			t.Errorf("%s: got %s %q, want a skipped warning", c.name, c.status, c.detail)
+		}
+	}
+}
+
+func TestDoctor_CABundle(t *testing.T) {
+	d := &doctor{flags: &serverFlags{caBundle: filepath.Join(t.TempDir(), "missing.pem")}}
+
+	if c := d.checkCABundle(); c.status != checkFail || !strings.Contains(c.detail, "read CA bundle") {
+		t.Errorf("got %s %q, want a failure", c.status, c.detail)
+	}
+}
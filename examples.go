@@ -0,0 +1,152 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxExamplesText bounds the source gomod_examples returns. Examples past
+// it are listed by name.
+const maxExamplesText = 32000
+
+// exampleSource returns the source of an example with its file and line:
+// the function with its doc comment and expected output, or the whole
+// test file for an example that go/doc shows as a whole file.
+func (pd *packageDoc) exampleSource(e packageExample) (string, bool) {
+	pos := pd.fset.Position(e.Code.Pos())
+
+	for _, f := range pd.files {
+		if f.path != pos.Filename {
+			continue
+		}
+
+		if _, ok := e.Code.(*ast.File); ok {
+			return fmt.Sprintf("// %s (whole file: the example uses its other declarations)\n%s", f.path, f.src), true
+		}
+
+		for _, decl := range f.ast.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body == e.Code {
+				line := pd.fset.Position(fn.Pos()).Line
+
+				return fmt.Sprintf("// %s:%d\n%s\n", f.path, line, declSource(pd.fset, f, fn, fn.Doc)), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// matchExamples returns the examples of symbol: those of a function or
+// method, or those of a type with its constructors and methods, as
+// gomod_doc shows them together. An empty symbol matches every example.
+func matchExamples(examples []packageExample, symbol string) []packageExample {
+	if symbol == "" {
+		return examples
+	}
+
+	var matched []packageExample
+
+	for _, e := range examples {
+		if e.symbol == symbol || (e.typ == symbol && !strings.Contains(symbol, ".")) {
+			matched = append(matched, e)
+		}
+	}
+
+	return matched
+}
+
+// formatExamples prints the source of the examples within
+// maxExamplesText, and the names of those that did not fit. It reports
+// whether any were left out.
+func (pd *packageDoc) formatExamples(title string, examples []packageExample) (string, bool) {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s (%d):\n", title, len(examples))
+
+	for i, e := range examples {
+		src, ok := pd.exampleSource(e)
+		if !ok {
+			continue
+		}
+
+		if i > 0 && sb.Len()+len(src) > maxExamplesText {
+			names := make([]string, 0, len(examples)-i)
+			for _, rest := range examples[i:] {
+				names = append(names, "Example"+rest.Name)
+			}
+
+			fmt.Fprintf(&sb, "\n[... %d more: %s; pass symbol to narrow]\n", len(names), strings.Join(names, ", "))
+
+			return sb.String(), true
+		}
+
+		sb.WriteString("\n" + src)
+	}
+
+	return sb.String(), false
+}
+
+type examplesInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Package string `json:"package,omitempty" jsonschema:"Package (import path or directory), default root"`
+	Symbol  string `json:"symbol,omitempty" jsonschema:"Only the examples of this declaration: Name or Type.Method"`
+}
+
+func handleExamples(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input examplesInput,
+) (*mcp.CallToolResult, any, error) {
+	importPath := cmp.Or(packageImportPath(input.Module, input.Package), input.Module)
+	if !inModule(importPath, input.Module) {
+		return errorResult(fmt.Sprintf("Package %s is not in module %s.", importPath, input.Module)), nil, nil
+	}
+
+	dir := "."
+	if importPath != input.Module {
+		dir = strings.TrimPrefix(importPath, input.Module+"/")
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pd, err := loadPackageDoc(mf, importPath, dir)
+	if err != nil {
+		return errorResult(fmt.Sprintf("%s@%s: %v.", input.Module, version, err)), nil, nil
+	}
+
+	all := pd.examples()
+	if len(all) == 0 {
+		return errorResult(fmt.Sprintf("No examples in %s at %s.", importPath, version)), nil, nil
+	}
+
+	examples := matchExamples(all, input.Symbol)
+	if len(examples) == 0 {
+		return errorResult(fmt.Sprintf("No examples of %s in %s at %s; the package has %s.",
+			input.Symbol, importPath, version, strings.Join(pd.exampleNames(), ", "))), nil, nil
+	}
+
+	title := fmt.Sprintf("Examples in %s at %s", importPath, version)
+	if input.Symbol != "" {
+		title = fmt.Sprintf("Examples of %s in %s at %s", input.Symbol, importPath, version)
+	}
+
+	text, truncated := pd.formatExamples(title, examples)
+	if truncated {
+		noteTruncated(ctx)
+	}
+
+	return textResult(text), nil, nil
+}
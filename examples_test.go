@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testExamplesDoc(t *testing.T, files map[string]string) *packageDoc {
+	t.Helper()
+
+	files["go.mod"] = "module example.com/m\n"
+	files["client/client.go"] = "package client\n\ntype Client struct{}\n\nfunc New() *Client { return nil }\n\n" +
+		"func (c *Client) Do() {}\n\nfunc Helper() {}\n"
+
+	pd, err := loadPackageDoc(testModuleFiles(t, "v1.0.0", files), "example.com/m/client", "client")
+	mustf(t, err, "load package doc")
+
+	return pd
+}
+
+func exampleNamesOf(examples []packageExample) string {
+	names := make([]string, 0, len(examples))
+	for _, e := range examples {
+		names = append(names, e.Name)
+	}
+
+	return strings.Join(names, " ")
+}
+
+func TestMatchExamples(t *testing.T) {
+	pd := testExamplesDoc(t, map[string]string{
+		"client/example_test.go": "package client_test\n\nfunc Example() {}\n\nfunc ExampleNew() {}\n\n" +
+			"func ExampleClient() {}\n\nfunc ExampleClient_Do() {}\n\nfunc ExampleClient_Do_retry() {}\n\n" +
+			"func ExampleHelper() {}\n",
+	})
+
+	for _, tt := range []struct{ symbol, want string }{
+		{"", " Helper Client New Client_Do Client_Do_retry"},
+		{"Client", "Client New Client_Do Client_Do_retry"},
+		{"Client.Do", "Client_Do Client_Do_retry"},
+		{"New", "New"},
+		{"Helper", "Helper"},
+		{"Missing", ""},
+	} {
+		if got := exampleNamesOf(matchExamples(pd.examples(), tt.symbol)); got != tt.want {
+			t.Errorf("matchExamples(%q) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestExampleSource(t *testing.T) {
+	pd := testExamplesDoc(t, map[string]string{
+		"client/example_test.go": "package client_test\n\nimport \"fmt\"\n\nfunc TestX() {}\n\n" +
+			"// This example sends one request.\nfunc ExampleClient_Do() {\n\tfmt.Println(\"ok\")\n" +
+			"\t// Output: ok\n}\n",
+		"client/whole_test.go": "package client_test\n\ntype server struct{}\n\nfunc ExampleNew() {\n\t_ = server{}\n}\n",
+	})
+
+	examples := pd.examples()
+	if len(examples) != 2 {
+		t.Fatalf("examples = %q", exampleNamesOf(examples))
+	}
+
+	src, ok := pd.exampleSource(examples[1])
+	if want := "// client/example_test.go:8\n// This example sends one request.\nfunc ExampleClient_Do() {\n" +
+		"\tfmt.Println(\"ok\")\n\t// Output: ok\n}\n"; !ok || src != want {
+		t.Errorf("source = %q, want %q", src, want)
+	}
+
+	src, ok = pd.exampleSource(examples[0])
+	if !ok || !strings.HasPrefix(src, "// client/whole_test.go (whole file") || !strings.Contains(src, "type server") {
+		t.Errorf("whole-file source = %q", src)
+	}
+}
+
+func TestFormatExamplesTruncates(t *testing.T) {
+	body := strings.Repeat("\t_ = 1\n", maxExamplesText/len("\t_ = 1\n")/3)
+	pd := testExamplesDoc(t, map[string]string{
+		"client/example_test.go": "package client_test\n\nfunc Example() {\n" + body + "}\n\nfunc Example_second() {\n" +
+			body + "}\n\nfunc Example_third() {\n" + body + "}\n\nfunc TestX() {}\n",
+	})
+
+	text, truncated := pd.formatExamples("Examples", pd.examples())
+	if !truncated || !strings.HasPrefix(text, "Examples (3):\n\n// client/example_test.go:3\n") ||
+		!strings.HasSuffix(text, "\n[... 1 more: Example_third; pass symbol to narrow]\n") {
+		t.Errorf("unexpected output (truncated %v): ...%s", truncated, text[len(text)-200:])
+	}
+}
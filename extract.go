@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// extractModule writes every file of a module version below dir,
+// preserving the module's directory layout, and returns the number of
+// files written. Paths that would escape dir are rejected.
+func extractModule(mf moduleFiles, dir string) (int, error) {
+	files, err := mf.ListFiles("")
+	if err != nil {
+		return 0, err
+	}
+
+	for i, name := range files {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return i, fmt.Errorf("refusing to extract non-local path: %s", name)
+		}
+
+		if err := extractFile(mf, name, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return i, err
+		}
+	}
+
+	return len(files), nil
+}
+
+func extractFile(mf moduleFiles, name, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return fmt.Errorf("create directory for %s: %w", name, err)
+	}
+
+	rc, err := mf.Open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", name, err)
+	}
+
+	return nil
+}
+
+type extractInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Dir     string `json:"dir,omitempty" jsonschema:"Empty or new directory to extract into (default: a new temp dir)"`
+}
+
+func handleExtract(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input extractInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir := input.Dir
+
+	if dir == "" {
+		pattern := fmt.Sprintf("gomod-%s@%s-*", lastPathSegment(input.Module), version)
+
+		dir, err = os.MkdirTemp("", pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create extract dir: %w", err)
+		}
+	} else if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return errorResult(fmt.Sprintf("Directory %s is not empty; refusing to overwrite it.", dir)), nil, nil
+	}
+
+	n, err := extractModule(mf, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(fmt.Sprintf("Extracted %d files of %s@%s to %s\n", n, input.Module, version, dir)), nil, nil
+}
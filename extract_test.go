@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractModule(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"go.mod":     "module mod\n",
+		"pkg/a/a.go": "package a\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+
+	dir := t.TempDir()
+
+	n, err := extractModule(zipFiles{entry}, dir)
+
+	mustf(t, err, "extract module")
+
+	if n != 2 {
+		t.Errorf("extracted %d files, want 2", n)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "pkg", "a", "a.go"))
+
+	mustf(t, err, "read extracted file")
+
+	if string(content) != "package a\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestExtractModule_RejectsEscapingPaths(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"../evil.go": "package evil\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+
+	if _, err := extractModule(zipFiles{entry}, t.TempDir()); err == nil {
+		t.Fatal("expected error for path escaping the target dir")
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxFindSymbolMatches bounds the declarations gomod_find_symbol returns
+// the source of. The rest are listed by location.
+const maxFindSymbolMatches = 20
+
+// definitionSource returns the source of the declaration a tag names,
+// headed by its location. A struct field or interface method is shown
+// within its type.
+func definitionSource(f *goFile, t tag) string {
+	symbol := t.name
+	if t.receiver != "" {
+		symbol = t.receiver + "." + t.name
+	}
+
+	if t.kind == tagField || t.kind == tagIfaceFun {
+		src, err := symbolSource(f.path, string(f.src), t.receiver)
+		if err != nil {
+			return fmt.Sprintf("// %s:%d: %v\n", t.path, t.line, err)
+		}
+
+		return fmt.Sprintf("// %s %s is declared at %s:%d, in type %s:\n%s",
+			tagKindNames[t.kind], symbol, t.path, t.line, t.receiver, src)
+	}
+
+	src, err := symbolSource(f.path, string(f.src), symbol)
+	if err != nil {
+		return fmt.Sprintf("// %s:%d: %v\n", t.path, t.line, err)
+	}
+
+	return src
+}
+
+// formatDefinitions prints the source of the declarations found, at most
+// maxFindSymbolMatches of them, and the locations of the rest.
+func formatDefinitions(title string, files map[string]*goFile, tags []tag) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s (%d):\n", title, len(tags))
+
+	for i, t := range tags {
+		if i == maxFindSymbolMatches {
+			locations := make([]string, 0, len(tags)-i)
+			for _, rest := range tags[i:] {
+				locations = append(locations, fmt.Sprintf("%s:%d", rest.path, rest.line))
+			}
+
+			fmt.Fprintf(&sb, "\n[... %d more at %s; narrow with path]\n", len(locations), strings.Join(locations, ", "))
+
+			break
+		}
+
+		sb.WriteString("\n" + definitionSource(files[t.path], t))
+	}
+
+	return sb.String()
+}
+
+type findSymbolInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Symbol  string `json:"symbol" jsonschema:"Declared name, or Type.Method / Type.Field"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter, such as a package directory"`
+	Tests   bool   `json:"tests,omitempty" jsonschema:"Also search _test.go files"`
+}
+
+func handleFindSymbol(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input findSymbolInput,
+) (*mcp.CallToolResult, any, error) {
+	symbol := strings.NewReplacer("(", "", ")", "", "*", "").Replace(strings.TrimSpace(input.Symbol))
+	if symbol == "" {
+		return errorResult("Symbol is required."), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset, parsed, err := parseGoFiles(mf, input.Path, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make(map[string]*goFile, len(parsed))
+
+	for _, f := range parsed {
+		if input.Tests || !strings.HasSuffix(f.path, "_test.go") {
+			files[f.path] = f
+		}
+	}
+
+	var (
+		found  []tag
+		others []string // Type.Name declarations of a bare name
+		match  = symbolMatcher(symbol)
+	)
+
+	for _, t := range collectTags(fset, parsed) {
+		if files[t.path] == nil {
+			continue
+		}
+
+		switch {
+		case match(t):
+			found = append(found, t)
+		case t.receiver != "" && t.name == symbol:
+			others = append(others, t.receiver+"."+t.name)
+		}
+	}
+
+	if len(found) == 0 {
+		msg := fmt.Sprintf("No declaration of %s in %s@%s", symbol, input.Module, version)
+		if input.Path != "" {
+			msg += " under " + input.Path
+		}
+
+		if len(others) > 0 {
+			slices.Sort(others)
+			msg += fmt.Sprintf("; it is declared as %s", strings.Join(slices.Compact(others), ", "))
+		}
+
+		return errorResult(msg + "."), nil, nil
+	}
+
+	if len(found) > maxFindSymbolMatches {
+		noteTruncated(ctx)
+	}
+
+	title := fmt.Sprintf("Declarations of %s in %s@%s", symbol, input.Module, version)
+
+	return textResult(formatDefinitions(title, files, found)), nil, nil
+}
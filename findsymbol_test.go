@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefinitionSource(t *testing.T) {
+	files, tags := parseTagsTestModule(t)
+	byPath := map[string]*goFile{}
+
+	for _, f := range files {
+		byPath[f.path] = f
+	}
+
+	for _, tt := range []struct{ symbol, want string }{
+		{"Answer", "// lib/lib.go:3-3\nconst Answer = 42\n"},
+		{"Client.Do", "// lib/lib.go:17-17\nfunc (c *Client) Do() error { return nil }\n"},
+		{"Client.Name", "// field Client.Name is declared at lib/lib.go:8, in type Client:\n" +
+			"// lib/lib.go:7-9\ntype Client struct {\n\tName string\n}\n"},
+		{"Doer.Do", "// interface method Doer.Do is declared at lib/lib.go:12, in type Doer:\n" +
+			"// lib/lib.go:11-13\ntype Doer interface {\n\tDo() error\n}\n"},
+	} {
+		match := symbolMatcher(tt.symbol)
+
+		var got []string
+
+		for _, tg := range tags {
+			if match(tg) {
+				got = append(got, definitionSource(byPath[tg.path], tg))
+			}
+		}
+
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("definitionSource(%s) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDefinitionsCaps(t *testing.T) {
+	files, tags := parseTagsTestModule(t)
+	byPath := map[string]*goFile{}
+
+	for _, f := range files {
+		byPath[f.path] = f
+	}
+
+	answer := tags[slices.IndexFunc(tags, func(tg tag) bool { return tg.name == "Answer" })]
+	found := slices.Repeat([]tag{answer}, maxFindSymbolMatches+2)
+
+	text := formatDefinitions("Declarations", byPath, found)
+	if !strings.HasPrefix(text, "Declarations (22):\n\n// lib/lib.go:3-3\n") ||
+		!strings.HasSuffix(text, "\n[... 2 more at lib/lib.go:3, lib/lib.go:3; narrow with path]\n") {
+		t.Errorf("unexpected output: %s", text)
+	}
+}
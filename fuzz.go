@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fuzzTarget is a Fuzz function together with its seeds.
+type fuzzTarget struct {
+	testFunc
+
+	seedCalls int      // number of f.Add calls in the function body
+	corpus    []string // files under testdata/fuzz/<name>/
+}
+
+// collectFuzzTargets returns the fuzz targets among funcs, attaching the
+// seed corpus files found in paths. Corpus files live next to the test in
+// testdata/fuzz/<FuzzName>/.
+func collectFuzzTargets(funcs []testFunc, paths []string) []fuzzTarget {
+	var targets []fuzzTarget
+
+	for _, tf := range funcs {
+		if tf.kind != kindFuzz {
+			continue
+		}
+
+		corpusDir := path.Join(path.Dir(tf.file.path), "testdata", "fuzz", tf.name) + "/"
+
+		var corpus []string
+
+		for _, p := range paths {
+			if strings.HasPrefix(p, corpusDir) {
+				corpus = append(corpus, p)
+			}
+		}
+
+		sort.Strings(corpus)
+
+		targets = append(targets, fuzzTarget{
+			testFunc:  tf,
+			seedCalls: countSeedCalls(tf.decl),
+			corpus:    corpus,
+		})
+	}
+
+	return targets
+}
+
+// countSeedCalls counts calls to Add on the *testing.F parameter.
+func countSeedCalls(fn *ast.FuncDecl) int {
+	if fn.Body == nil || len(fn.Type.Params.List[0].Names) == 0 {
+		return 0
+	}
+
+	param := fn.Type.Params.List[0].Names[0].Name
+	count := 0
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Add" {
+			return true
+		}
+
+		if x, ok := sel.X.(*ast.Ident); ok && x.Name == param {
+			count++
+		}
+
+		return true
+	})
+
+	return count
+}
+
+type listFuzzInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+}
+
+func handleListFuzz(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input listFuzzInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset, files, err := parseGoFiles(mf, input.Path, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths, err := mf.ListFiles(input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := collectFuzzTargets(collectTestFuncs(fset, files), paths)
+
+	return textResult(formatFuzzTargets(input.Module, version, targets)), nil, nil
+}
+
+func formatFuzzTargets(module, version string, targets []fuzzTarget) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Fuzz targets in %s@%s (%d targets):\n", module, version, len(targets))
+
+	lastDir := ""
+
+	for i, t := range targets {
+		if dir := path.Dir(t.file.path); i == 0 || dir != lastDir {
+			fmt.Fprintf(&sb, "\n%s:\n", dir)
+
+			lastDir = dir
+		}
+
+		fmt.Fprintf(&sb, "  %s\t%s:%d (%d f.Add seeds, %d corpus files)\n",
+			t.name, t.file.path, t.line, t.seedCalls, len(t.corpus))
+
+		for _, c := range t.corpus {
+			fmt.Fprintf(&sb, "    %s\n", c)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollectFuzzTargets(t *testing.T) {
+	files := map[string]string{
+		"parse.go": "package mod\n",
+		"parse_test.go": "package mod\n\nimport \"testing\"\n\n" +
+			"func FuzzParse(f *testing.F) {\n\tf.Add(\"a\")\n\tf.Add(\"b\")\n\tf.Fuzz(func(t *testing.T, s string) {})\n}\n\n" +
+			"func TestParse(t *testing.T) {}\n",
+		"testdata/fuzz/FuzzParse/seed1":    "go test fuzz v1\nstring(\"x\")\n",
+		"testdata/fuzz/FuzzParse/seed2":    "go test fuzz v1\nstring(\"y\")\n",
+		"testdata/fuzz/FuzzOther/seed1":    "go test fuzz v1\nstring(\"z\")\n",
+		"sub/sub_test.go":                  "package sub\n\nimport \"testing\"\n\nfunc FuzzSub(f *testing.F) {}\n",
+		"sub/testdata/fuzz/FuzzSub/corpus": "go test fuzz v1\n[]byte(\"\")\n",
+	}
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", createTestZip(t, "mod@v1.0.0/", files))
+
+	mustf(t, err, "put zip in cache")
+
+	mf := zipFiles{entry}
+
+	fset, parsed, err := parseGoFiles(mf, "", 0)
+
+	mustf(t, err, "parse go files")
+
+	paths, err := mf.ListFiles("")
+
+	mustf(t, err, "list files")
+
+	targets := collectFuzzTargets(collectTestFuncs(fset, parsed), paths)
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(targets))
+	}
+
+	parse, sub := targets[0], targets[1]
+
+	if parse.name != "FuzzParse" || parse.seedCalls != 2 || len(parse.corpus) != 2 {
+		t.Errorf("unexpected FuzzParse target: %+v", parse)
+	}
+
+	if sub.name != "FuzzSub" || len(sub.corpus) != 1 || sub.corpus[0] != "sub/testdata/fuzz/FuzzSub/corpus" {
+		t.Errorf("unexpected FuzzSub target: %+v", sub)
+	}
+
+	out := formatFuzzTargets("mod", "v1.0.0", targets)
+	if !strings.Contains(out, "FuzzParse\tparse_test.go:5 (2 f.Add seeds, 2 corpus files)") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
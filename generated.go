@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Values of the generated file filter accepted by file listing and
+// statistics tools.
+const (
+	generatedInclude = "include"
+	generatedExclude = "exclude"
+	generatedOnly    = "only"
+)
+
+// generatedMarkerRe matches the standard marker for generated Go files
+// (https://go.dev/s/generatedcode).
+var generatedMarkerRe = regexp.MustCompile(`(?m)^// Code generated (.*)DO NOT EDIT\.$`)
+
+// knownGenerators maps substrings of a marker's "by ..." text to a
+// canonical generator name.
+var knownGenerators = []struct{ match, name string }{
+	{"protoc-gen-go-grpc", "protoc-gen-go-grpc"},
+	{"protoc-gen-grpc-gateway", "grpc-gateway"},
+	{"protoc-gen-go", "protoc-gen-go"},
+	{"protoc-gen-gogo", "protoc-gen-gogo"},
+	{"protoc", "protoc"},
+	{"stringer", "stringer"},
+	{"mockgen", "mockgen"},
+	{"mockery", "mockery"},
+	{"easyjson", "easyjson"},
+	{"sqlc", "sqlc"},
+	{"ent", "entc"},
+	{"controller-gen", "controller-gen"},
+	{"deepcopy-gen", "deepcopy-gen"},
+	{"go-bindata", "go-bindata"},
+}
+
+// maxGeneratedHeader bounds how much of a file is read looking for the
+// generated code marker when the package clause comes later.
+const maxGeneratedHeader = 8 << 10
+
+// readGoHeader returns the start of a Go file up to its package clause,
+// or its first maxGeneratedHeader bytes, which is all generatedBy looks
+// at. Like go/build, it does not read the rest of the file.
+func readGoHeader(mf moduleFiles, p string) (string, error) {
+	rc, err := mf.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	r := bufio.NewReader(io.LimitReader(rc, maxGeneratedHeader))
+
+	var sb strings.Builder
+
+	for {
+		line, err := r.ReadString('\n')
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+
+		sb.WriteString(line)
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", p, err)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// generatedFileName recognizes generators by the file names they produce,
+// for markers that do not say which tool wrote them.
+func generatedFileName(p string) string {
+	base := path.Base(p)
+
+	switch {
+	case strings.HasSuffix(base, "_grpc.pb.go"):
+		return "protoc-gen-go-grpc"
+	case strings.HasSuffix(base, ".pb.gw.go"):
+		return "grpc-gateway"
+	case strings.HasSuffix(base, ".pb.go"):
+		return "protoc-gen-go"
+	case strings.HasSuffix(base, "_string.go"):
+		return "stringer"
+	case strings.HasPrefix(base, "mock_") || strings.HasSuffix(base, "_mock.go"):
+		return "mockgen"
+	default:
+		return ""
+	}
+}
+
+// generatedBy reports whether the Go file at p is generated and, if the
+// marker or file name identifies it, by which tool. Only the header before
+// the package clause is searched, as the convention requires.
+func generatedBy(p, src string) (bool, string) {
+	if !strings.HasSuffix(p, ".go") {
+		return false, ""
+	}
+
+	header := "\n" + src
+	if i := strings.Index(header, "\npackage "); i >= 0 {
+		header = header[:i]
+	}
+
+	m := generatedMarkerRe.FindStringSubmatch(header)
+	if m == nil {
+		return false, ""
+	}
+
+	if by, ok := strings.CutPrefix(m[1], "by "); ok {
+		by = strings.ToLower(by)
+
+		for _, g := range knownGenerators {
+			if containsWord(by, g.match) {
+				return true, g.name
+			}
+		}
+	}
+
+	return true, generatedFileName(p)
+}
+
+// containsWord reports whether s contains word delimited by non-name
+// characters, so "ent" does not match "parent".
+func containsWord(s, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+
+		start, end := i+j, i+j+len(word)
+
+		if (start == 0 || !isNameByte(s[start-1])) && (end == len(s) || !isNameByte(s[end])) {
+			return true
+		}
+
+		i = start + 1
+	}
+}
+
+func isNameByte(b byte) bool {
+	return b == '-' || b == '_' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+}
+
+// checkGeneratedFilter validates a generated file filter, where the empty
+// string means generatedInclude.
+func checkGeneratedFilter(filter string) error {
+	switch filter {
+	case "", generatedInclude, generatedExclude, generatedOnly:
+		return nil
+	default:
+		return fmt.Errorf("unknown generated filter %q (use %q, %q or %q)",
+			filter, generatedInclude, generatedExclude, generatedOnly)
+	}
+}
+
+// keepGenerated reports whether a file passes the generated file filter.
+func keepGenerated(filter string, generated bool) bool {
+	switch filter {
+	case generatedExclude:
+		return !generated
+	case generatedOnly:
+		return generated
+	default:
+		return true
+	}
+}
+
+// generatedTag formats the annotation for a generated file in listings.
+func generatedTag(generator string) string {
+	if generator == "" {
+		return " [generated]"
+	}
+
+	return " [generated: " + generator + "]"
+}
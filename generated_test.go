@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedBy(t *testing.T) {
+	tests := []struct {
+		path, src string
+		generated bool
+		generator string
+	}{
+		{"a.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\n\npackage a\n", true, "protoc-gen-go"},
+		{"a_grpc.pb.go", "// Code generated by protoc-gen-go-grpc. DO NOT EDIT.\n\npackage a\n", true, "protoc-gen-go-grpc"},
+		{"kind_string.go", "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage a\n", true, "stringer"},
+		{"mocks.go", "// Code generated by MockGen. DO NOT EDIT.\n// Source: x.go\n\npackage a\n", true, "mockgen"},
+		{"ent.go", "// Code generated by ent, DO NOT EDIT.\n\npackage a\n", true, "entc"},
+		{"parent.go", "// Code generated by parent-tool. DO NOT EDIT.\n\npackage a\n", true, ""},
+		{"x.pb.go", "// Code generated DO NOT EDIT.\n\npackage a\n", true, "protoc-gen-go"},
+		{"a.go", "// Package a does things.\npackage a\n", false, ""},
+		{"late.go", "package a\n\n// Code generated by hand. DO NOT EDIT.\n", false, ""},
+		{"README.md", "// Code generated by x. DO NOT EDIT.\n", false, ""},
+	}
+
+	for _, tt := range tests {
+		generated, generator := generatedBy(tt.path, tt.src)
+		if generated != tt.generated || generator != tt.generator {
+			t.Errorf("generatedBy(%s) = %v, %q, want %v, %q", tt.path, generated, generator, tt.generated, tt.generator)
+		}
+	}
+}
+
+func TestGeneratedFilter(t *testing.T) {
+	if err := checkGeneratedFilter("skip"); err == nil {
+		t.Error("expected error for unknown filter")
+	}
+
+	for _, tt := range []struct {
+		filter          string
+		generated, keep bool
+	}{
+		{"", true, true},
+		{generatedInclude, false, true},
+		{generatedExclude, true, false},
+		{generatedExclude, false, true},
+		{generatedOnly, true, true},
+		{generatedOnly, false, false},
+	} {
+		if err := checkGeneratedFilter(tt.filter); err != nil {
+			t.Errorf("checkGeneratedFilter(%q): %v", tt.filter, err)
+		}
+
+		if got := keepGenerated(tt.filter, tt.generated); got != tt.keep {
+			t.Errorf("keepGenerated(%q, %v) = %v", tt.filter, tt.generated, got)
+		}
+	}
+}
+
+func TestReadGoHeader(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"gen.go":     "// Code generated by stringer; DO NOT EDIT.\n\npackage a\n\nvar x = 1\n",
+		"nopkg.go":   "// Code generated by mockgen. DO NOT EDIT.\n",
+		"big.go":     "package a\n\n" + strings.Repeat("// filler\n", 2000),
+		"license.go": strings.Repeat("// license text\n", 1000) + "// Code generated by x. DO NOT EDIT.\n\npackage a\n",
+	}
+
+	for name, src := range files {
+		mustf(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600), "write %s", name)
+	}
+
+	for name, want := range map[string]string{
+		"gen.go":   "// Code generated by stringer; DO NOT EDIT.\n\n",
+		"nopkg.go": "// Code generated by mockgen. DO NOT EDIT.\n",
+		"big.go":   "",
+	} {
+		if got, err := readGoHeader(dirFiles{root: dir}, name); err != nil || got != want {
+			t.Errorf("readGoHeader(%s) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	// A marker past maxGeneratedHeader is not read.
+	header, err := readGoHeader(dirFiles{root: dir}, "license.go")
+	if err != nil || len(header) > maxGeneratedHeader {
+		t.Errorf("readGoHeader(license.go) read %d bytes, %v", len(header), err)
+	}
+
+	if generated, _ := generatedBy("license.go", header); generated {
+		t.Error("a marker past the header limit was found")
+	}
+}
@@ -2,10 +2,16 @@ module github.com/hugowetterberg/claude-gomod
 
 go 1.25.6
 
-require github.com/modelcontextprotocol/go-sdk v1.1.0
+require (
+	github.com/google/jsonschema-go v0.3.0
+	github.com/klauspost/compress v1.20.1
+	github.com/modelcontextprotocol/go-sdk v1.1.0
+	golang.org/x/mod v0.33.0
+	golang.org/x/tools v0.42.0
+)
 
 require (
-	github.com/google/jsonschema-go v0.3.0 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
 )
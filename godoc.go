@@ -0,0 +1,359 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// docIndent indents doc comments under their declaration, as go doc does.
+const docIndent = "    "
+
+// packageDoc is a package's documentation computed by go/doc, with what
+// is needed to print its declarations.
+type packageDoc struct {
+	pkg   *doc.Package
+	fset  *token.FileSet
+	files []*goFile // the files documented, test files included
+}
+
+// loadPackageDoc parses the Go files of the package in dir and computes
+// its documentation. Examples come from the package's test files. When a
+// directory holds several packages, such as a generator built with
+// //go:build ignore, the one most files belong to is documented.
+func loadPackageDoc(mf moduleFiles, importPath, dir string) (*packageDoc, error) {
+	prefix := ""
+	if dir != "." {
+		prefix = dir + "/"
+	}
+
+	fset, parsed, err := parseGoFiles(mf, prefix, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		files []*goFile
+		count = make(map[string]int)
+		name  string
+	)
+
+	for _, f := range parsed {
+		if path.Dir(f.path) != dir || f.ast.Name == nil {
+			continue
+		}
+
+		files = append(files, f)
+
+		if !strings.HasSuffix(f.path, "_test.go") {
+			count[f.ast.Name.Name]++
+
+			if count[f.ast.Name.Name] > count[name] {
+				name = f.ast.Name.Name
+			}
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("no Go package in %s", dir)
+	}
+
+	pd := &packageDoc{fset: fset}
+
+	var astFiles []*ast.File
+
+	for _, f := range files {
+		if pkg := f.ast.Name.Name; pkg == name || (pkg == name+"_test" && strings.HasSuffix(f.path, "_test.go")) {
+			pd.files = append(pd.files, f)
+			astFiles = append(astFiles, f.ast)
+		}
+	}
+
+	pd.pkg, err = doc.NewFromFiles(fset, astFiles, importPath)
+	if err != nil {
+		return nil, fmt.Errorf("compute documentation: %w", err)
+	}
+
+	return pd, nil
+}
+
+// text formats a doc comment, indented.
+func (pd *packageDoc) text(comment string) string {
+	if comment == "" {
+		return ""
+	}
+
+	p := pd.pkg.Printer()
+	p.TextPrefix = docIndent
+	p.TextCodePrefix = docIndent + "\t"
+
+	return string(p.Text(pd.pkg.Parser().Parse(comment)))
+}
+
+// decl prints a declaration without its doc comment and, for functions,
+// without the body. The printer keeps the doc comments of struct fields
+// and notes where go/doc filtered unexported ones out.
+func (pd *packageDoc) decl(node ast.Decl) string {
+	var (
+		sb      strings.Builder
+		printed any = node
+	)
+
+	switch d := node.(type) {
+	case *ast.FuncDecl:
+		fn := *d
+		fn.Doc, fn.Body = nil, nil
+		printed = &fn
+	case *ast.GenDecl:
+		gd := *d
+		gd.Doc = nil
+		printed = &gd
+	}
+
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 4}
+	if err := cfg.Fprint(&sb, pd.fset, printed); err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+
+	return sb.String()
+}
+
+func (pd *packageDoc) writeDecl(sb *strings.Builder, node ast.Decl, comment string) {
+	sb.WriteString(pd.decl(node) + "\n")
+	sb.WriteString(pd.text(comment))
+	sb.WriteString("\n")
+}
+
+func (pd *packageDoc) writeValues(sb *strings.Builder, values []*doc.Value) {
+	for _, v := range values {
+		pd.writeDecl(sb, v.Decl, v.Doc)
+	}
+}
+
+func (pd *packageDoc) writeFuncs(sb *strings.Builder, funcs []*doc.Func) {
+	for _, f := range funcs {
+		pd.writeDecl(sb, f.Decl, f.Doc)
+	}
+}
+
+func (pd *packageDoc) writeType(sb *strings.Builder, t *doc.Type) {
+	pd.writeDecl(sb, t.Decl, t.Doc)
+	pd.writeValues(sb, t.Consts)
+	pd.writeValues(sb, t.Vars)
+	pd.writeFuncs(sb, t.Funcs)
+	pd.writeFuncs(sb, t.Methods)
+}
+
+// format prints the whole package in the layout of go doc -all.
+func (pd *packageDoc) format() string {
+	var sb strings.Builder
+
+	p := pd.pkg
+
+	fmt.Fprintf(&sb, "package %s // import %q\n\n", p.Name, p.ImportPath)
+	sb.WriteString(pd.text(p.Doc) + "\n")
+
+	sections := []struct {
+		title string
+		empty bool
+		write func()
+	}{
+		{"CONSTANTS", len(p.Consts) == 0, func() { pd.writeValues(&sb, p.Consts) }},
+		{"VARIABLES", len(p.Vars) == 0, func() { pd.writeValues(&sb, p.Vars) }},
+		{"FUNCTIONS", len(p.Funcs) == 0, func() { pd.writeFuncs(&sb, p.Funcs) }},
+		{"TYPES", len(p.Types) == 0, func() {
+			for _, t := range p.Types {
+				pd.writeType(&sb, t)
+			}
+		}},
+	}
+
+	for _, s := range sections {
+		if s.empty {
+			continue
+		}
+
+		sb.WriteString(s.title + "\n\n")
+		s.write()
+	}
+
+	if names := pd.exampleNames(); len(names) > 0 {
+		fmt.Fprintf(&sb, "EXAMPLES (%d)\n\n  %s\n", len(names), strings.Join(names, "\n  "))
+	}
+
+	return sb.String()
+}
+
+// packageExample is an example of a package, with the declaration it
+// belongs to.
+type packageExample struct {
+	*doc.Example
+	symbol string // Name or Type.Method, or "" for the package
+	typ    string // the type the symbol is a method or constructor of
+}
+
+// examples returns the package's examples in the order go/doc sorts them:
+// those of the package first, then those of functions and of types, each
+// type followed by its constructors and methods.
+func (pd *packageDoc) examples() []packageExample {
+	var examples []packageExample
+
+	add := func(list []*doc.Example, symbol, typ string) {
+		for _, e := range list {
+			examples = append(examples, packageExample{Example: e, symbol: symbol, typ: typ})
+		}
+	}
+
+	add(pd.pkg.Examples, "", "")
+
+	for _, f := range pd.pkg.Funcs {
+		add(f.Examples, f.Name, "")
+	}
+
+	for _, t := range pd.pkg.Types {
+		add(t.Examples, t.Name, t.Name)
+
+		for _, f := range t.Funcs {
+			add(f.Examples, f.Name, t.Name)
+		}
+
+		for _, m := range t.Methods {
+			add(m.Examples, t.Name+"."+m.Name, t.Name)
+		}
+	}
+
+	return examples
+}
+
+// exampleNames lists the package's examples, such as ExampleClient_Do, in the
+// order go/doc sorts them.
+func (pd *packageDoc) exampleNames() []string {
+	examples := pd.examples()
+	names := make([]string, 0, len(examples))
+
+	for _, e := range examples {
+		names = append(names, "Example"+e.Name)
+	}
+
+	return names
+}
+
+// formatSymbol prints the documentation of one declaration: a constant,
+// variable, function or type, or a method as Type.Method. A type comes
+// with its constructors and methods, like go doc. It returns false when
+// the package declares no such symbol.
+func (pd *packageDoc) formatSymbol(symbol string) (string, bool) {
+	var sb strings.Builder
+
+	p := pd.pkg
+	typeName, method, isMethod := strings.Cut(symbol, ".")
+
+	for _, t := range p.Types {
+		if t.Name != typeName {
+			continue
+		}
+
+		if !isMethod {
+			pd.writeType(&sb, t)
+
+			return sb.String(), true
+		}
+
+		for _, m := range append(append([]*doc.Func(nil), t.Methods...), t.Funcs...) {
+			if m.Name == method {
+				pd.writeDecl(&sb, m.Decl, m.Doc)
+
+				return sb.String(), true
+			}
+		}
+
+		return "", false
+	}
+
+	if isMethod {
+		return "", false
+	}
+
+	for _, f := range p.Funcs {
+		if f.Name == symbol {
+			pd.writeDecl(&sb, f.Decl, f.Doc)
+
+			return sb.String(), true
+		}
+	}
+
+	values := append(append([]*doc.Value(nil), p.Consts...), p.Vars...)
+
+	for _, t := range p.Types {
+		values = append(append(values, t.Consts...), t.Vars...)
+	}
+
+	for _, v := range values {
+		for _, name := range v.Names {
+			if name == symbol {
+				pd.writeDecl(&sb, v.Decl, v.Doc)
+
+				return sb.String(), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+type docInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Package string `json:"package,omitempty" jsonschema:"Package (import path or directory), default root"`
+	Symbol  string `json:"symbol,omitempty" jsonschema:"Show only this declaration: Name or Type.Method"`
+}
+
+func handleDoc(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input docInput,
+) (*mcp.CallToolResult, any, error) {
+	importPath := cmp.Or(packageImportPath(input.Module, input.Package), input.Module)
+	if !inModule(importPath, input.Module) {
+		return errorResult(fmt.Sprintf("Package %s is not in module %s.", importPath, input.Module)), nil, nil
+	}
+
+	dir := "."
+	if importPath != input.Module {
+		dir = strings.TrimPrefix(importPath, input.Module+"/")
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pd, err := loadPackageDoc(mf, importPath, dir)
+	if err != nil {
+		return errorResult(fmt.Sprintf("%s@%s: %v.", input.Module, version, err)), nil, nil
+	}
+
+	if input.Symbol == "" {
+		return textResult(pd.format()), nil, nil
+	}
+
+	text, ok := pd.formatSymbol(input.Symbol)
+	if !ok {
+		return errorResult(fmt.Sprintf("No exported %s in %s at %s.", input.Symbol, importPath, version)), nil, nil
+	}
+
+	return textResult(text), nil, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPackageDoc(t *testing.T) *packageDoc {
+	t.Helper()
+
+	mf := testModuleFiles(t, "v1.0.0", map[string]string{
+		"go.mod": "module example.com/m\n",
+		"client/client.go": `// Package client talks to the service.
+//
+// Create one with [New]:
+//
+//	c := client.New()
+package client
+
+// DefaultTimeout is used when no timeout is set.
+const DefaultTimeout = 10
+
+// ErrClosed is returned after Close.
+var ErrClosed = error(nil)
+
+// Client sends requests.
+type Client struct {
+	// Name identifies the client.
+	Name string
+	conn int
+}
+
+// New returns a client.
+func New() *Client { return &Client{} }
+
+// Do sends a request.
+func (c *Client) Do(req string) error { return nil }
+
+func (c *Client) reset() {}
+
+// Helper is a function.
+func Helper(n int) int { return n }
+`,
+		"client/gen.go":          "//go:build ignore\n\npackage main\n\nfunc main() {}\n",
+		"client/example_test.go": "package client_test\n\nfunc ExampleClient_Do() {}\n\nfunc Example() {}\n",
+		"client/sub/sub.go":      "// Package sub is elsewhere.\npackage sub\n\nfunc Sub() {}\n",
+	})
+
+	pd, err := loadPackageDoc(mf, "example.com/m/client", "client")
+	mustf(t, err, "load package doc")
+
+	return pd
+}
+
+func TestPackageDoc_Format(t *testing.T) {
+	got := testPackageDoc(t).format()
+	want := `package client // import "example.com/m/client"
+
+    Package client talks to the service.
+
+    Create one with New:
+
+    	c := client.New()
+
+CONSTANTS
+
+const DefaultTimeout = 10
+    DefaultTimeout is used when no timeout is set.
+
+VARIABLES
+
+var ErrClosed = error(nil)
+    ErrClosed is returned after Close.
+
+FUNCTIONS
+
+func Helper(n int) int
+    Helper is a function.
+
+TYPES
+
+type Client struct {
+	// Name identifies the client.
+	Name string
+	// contains filtered or unexported fields
+}
+    Client sends requests.
+
+func New() *Client
+    New returns a client.
+
+func (c *Client) Do(req string) error
+    Do sends a request.
+
+EXAMPLES (2)
+
+  Example
+  ExampleClient_Do
+`
+
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPackageDoc_FormatSymbol(t *testing.T) {
+	pd := testPackageDoc(t)
+
+	for symbol, want := range map[string]string{
+		"Client.Do":      "func (c *Client) Do(req string) error\n    Do sends a request.\n",
+		"Client.New":     "func New() *Client\n",
+		"DefaultTimeout": "const DefaultTimeout = 10\n    DefaultTimeout is used when no timeout is set.\n",
+		"Helper":         "func Helper(n int) int\n",
+		"Client":         "type Client struct {",
+	} {
+		if got, ok := pd.formatSymbol(symbol); !ok || !strings.HasPrefix(got, want) {
+			t.Errorf("%s: got %q, want it to start with %q", symbol, got, want)
+		}
+	}
+
+	if got, _ := pd.formatSymbol("Client"); !strings.Contains(got, "func New() *Client") {
+		t.Errorf("a type should come with its constructors: %q", got)
+	}
+
+	for _, symbol := range []string{"Client.reset", "Missing", "Sub", "Helper.X"} {
+		if got, ok := pd.formatSymbol(symbol); ok {
+			t.Errorf("%s: expected no match, got %q", symbol, got)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+const defaultGoProxyAddr = "localhost:7070"
+
+// goProxyHandler serves the GOPROXY protocol from this server's caches,
+// so that the go command can share downloads with the agent. Immutable
+// files (.info, .mod, .zip) are served from the module download cache or
+// the zip cache when present; version lists and @latest always go
+// upstream first, since the local copies only know downloaded versions.
+type goProxyHandler struct {
+	proxy    *ProxyClient
+	cache    *ZipCache
+	modCache *ModCache
+}
+
+func (h *goProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	mod, file, ok := parseGoProxyPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	data, err := h.lookup(r, mod, file)
+
+	switch {
+	case errors.Is(err, ErrModuleDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	case errors.Is(err, ErrModuleNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", goProxyContentType(file))
+	_, _ = w.Write(data)
+}
+
+func (h *goProxyHandler) lookup(r *http.Request, mod, file string) ([]byte, error) {
+	ctx := r.Context()
+
+	// The zip cache does not know the policy, so check before reading it.
+	if err := h.proxy.policy.Check(mod); err != nil {
+		return nil, err
+	}
+
+	switch file {
+	case "@latest":
+		return h.proxy.Fetch(ctx, mod, "@latest")
+	case "list":
+		data, err := h.proxy.Fetch(ctx, mod, "@v/list")
+		if err == nil {
+			return data, nil
+		}
+
+		if local, readErr := h.readDownloadFile(mod, file); readErr == nil {
+			return local, nil
+		}
+
+		return nil, err
+	}
+
+	if data, err := h.readDownloadFile(mod, file); err == nil {
+		return data, nil
+	}
+
+	if version, ok := strings.CutSuffix(file, ".zip"); ok {
+		if entry := h.cache.Get(mod, version); entry != nil {
+			return entry.Data(), nil
+		}
+
+		data, err := h.proxy.DownloadZip(ctx, mod, version)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := h.cache.Put(mod, version, data); err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	}
+
+	return h.proxy.Fetch(ctx, mod, "@v/"+file)
+}
+
+func (h *goProxyHandler) readDownloadFile(mod, file string) ([]byte, error) {
+	path := h.modCache.DownloadFile(mod, file)
+	if path == "" {
+		return nil, ErrModuleNotFound
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read download cache: %w", err)
+	}
+
+	return data, nil
+}
+
+// parseGoProxyPath splits a GOPROXY request path into the module path and
+// the requested file: "list", "<version>.info|.mod|.zip" or "@latest".
+// Escaped module paths and versions are decoded.
+func parseGoProxyPath(urlPath string) (mod, file string, ok bool) {
+	urlPath = strings.TrimPrefix(urlPath, "/")
+
+	if escaped, found := strings.CutSuffix(urlPath, "/@latest"); found {
+		mod, err := module.UnescapePath(escaped)
+
+		return mod, "@latest", err == nil
+	}
+
+	escaped, file, found := strings.Cut(urlPath, "/@v/")
+	if !found || file == "" || strings.Contains(file, "/") {
+		return "", "", false
+	}
+
+	mod, err := module.UnescapePath(escaped)
+	if err != nil {
+		return "", "", false
+	}
+
+	if file == "list" {
+		return mod, file, true
+	}
+
+	for _, ext := range []string{".info", ".mod", ".zip"} {
+		if v, found := strings.CutSuffix(file, ext); found {
+			version, err := module.UnescapeVersion(v)
+
+			return mod, version + ext, err == nil
+		}
+	}
+
+	return "", "", false
+}
+
+func goProxyContentType(file string) string {
+	switch {
+	case strings.HasSuffix(file, ".zip"):
+		return "application/zip"
+	case strings.HasSuffix(file, ".info"), file == "@latest":
+		return "application/json"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// runServeProxy implements the serve-proxy subcommand.
+func runServeProxy(args []string) error {
+	fs := flag.NewFlagSet("serve-proxy", flag.ExitOnError)
+	addr := fs.String("addr", defaultGoProxyAddr, "Address to listen on")
+	allowModules := fs.String("allow-modules", "", "Comma-separated module path globs that may be served")
+	denyModules := fs.String("deny-modules", "", "Comma-separated module path globs that are never served")
+	caBundle := fs.String("ca-bundle", "", "PEM file of extra root certificates to trust for outbound HTTPS")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	policy, err := NewModulePolicy(*allowModules, *denyModules)
+	if err != nil {
+		return err
+	}
+
+	client, err := newHTTPClient(*caBundle)
+	if err != nil {
+		return err
+	}
+
+	handler := &goProxyHandler{
+		proxy:    NewProxyClient(),
+		cache:    NewZipCache(),
+		modCache: NewModCache(discoverModCache()),
+	}
+	handler.proxy.client = client
+	handler.proxy.policy = policy
+
+	if handler.proxy.proxies, err = parseGOPROXY(goEnv("GOPROXY")); err != nil {
+		return err
+	}
+
+	handler.proxy.proxies = withoutAddr(handler.proxy.proxies, *addr)
+	handler.proxy.noproxy = cmp.Or(goEnv("GONOPROXY"), goEnv("GOPRIVATE"))
+	handler.modCache.policy = policy
+
+	return listenGoProxy(*addr, handler)
+}
+
+// serveGoProxy runs the GOPROXY endpoint next to the MCP server, sharing
+// its caches. Failures are logged rather than fatal.
+func serveGoProxy(addr string, handler *goProxyHandler) {
+	if err := listenGoProxy(addr, handler); err != nil {
+		log.Printf("warning: GOPROXY endpoint stopped: %v", err)
+	}
+}
+
+func listenGoProxy(addr string, handler *goProxyHandler) error {
+	log.Printf("serving GOPROXY protocol on http://%s (use GOPROXY=http://%s)", addr, addr)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if err := srv.ListenAndServe(); err != nil {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseGoProxyPath(t *testing.T) {
+	tests := []struct {
+		path, mod, file string
+		ok              bool
+	}{
+		{"/example.com/m/@v/list", "example.com/m", "list", true},
+		{"/example.com/m/@latest", "example.com/m", "@latest", true},
+		{"/github.com/!azure/sdk/@v/v1.0.0.mod", "github.com/Azure/sdk", "v1.0.0.mod", true},
+		{"/example.com/m/@v/v1.0.0-!r!c1.zip", "example.com/m", "v1.0.0-RC1.zip", true},
+		{"/example.com/m/@v/v1.0.0.txt", "", "", false},
+		{"/example.com/m", "", "", false},
+		{"/example.com/Bad/@v/list", "", "", false},
+	}
+
+	for _, tt := range tests {
+		mod, file, ok := parseGoProxyPath(tt.path)
+		if mod != tt.mod || file != tt.file || ok != tt.ok {
+			t.Errorf("parseGoProxyPath(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.path, mod, file, ok, tt.mod, tt.file, tt.ok)
+		}
+	}
+}
+
+func TestGoProxyHandler(t *testing.T) {
+	var upstreamHits atomic.Int32
+
+	zipData := createTestZip(t, "example.com/m@v1.0.0/", map[string]string{"go.mod": "module example.com/m\n"})
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHits.Add(1)
+
+		switch r.URL.Path {
+		case "/example.com/m/@v/list":
+			_, _ = w.Write([]byte("v1.0.0\nv1.1.0\n"))
+		case "/example.com/m/@v/v1.0.0.zip":
+			_, _ = w.Write(zipData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	modCacheDir := t.TempDir()
+	modFile := filepath.Join(modCacheDir, "cache", "download", "example.com", "m", "@v", "v1.0.0.mod")
+
+	mustf(t, os.MkdirAll(filepath.Dir(modFile), 0o755), "create download dir")
+	mustf(t, os.WriteFile(modFile, []byte("module example.com/m\n"), 0o600), "write mod file")
+
+	cache := NewZipCache()
+	handler := &goProxyHandler{
+		proxy:    &ProxyClient{baseURL: upstream.URL, client: upstream.Client()},
+		cache:    cache,
+		modCache: NewModCache(modCacheDir),
+	}
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+
+		resp, err := srv.Client().Get(srv.URL + path)
+
+		mustf(t, err, "GET %s", path)
+
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+
+		mustf(t, err, "read body of %s", path)
+
+		return resp.StatusCode, string(body)
+	}
+
+	if code, body := get("/example.com/m/@v/v1.0.0.mod"); code != http.StatusOK || body != "module example.com/m\n" {
+		t.Errorf("mod: got %d %q", code, body)
+	}
+
+	if upstreamHits.Load() != 0 {
+		t.Error("mod file should have been served from the download cache")
+	}
+
+	if code, body := get("/example.com/m/@v/list"); code != http.StatusOK || !strings.Contains(body, "v1.1.0") {
+		t.Errorf("list: got %d %q", code, body)
+	}
+
+	if code, _ := get("/example.com/m/@v/v1.0.0.zip"); code != http.StatusOK {
+		t.Errorf("zip: got %d", code)
+	}
+
+	if cache.Get("example.com/m", "v1.0.0") == nil {
+		t.Error("downloaded zip should be added to the zip cache")
+	}
+
+	hits := upstreamHits.Load()
+
+	if code, _ := get("/example.com/m/@v/v1.0.0.zip"); code != http.StatusOK || upstreamHits.Load() != hits {
+		t.Errorf("second zip request should be served from cache (code %d)", code)
+	}
+
+	if code, _ := get("/example.com/missing/@v/v1.0.0.info"); code != http.StatusNotFound {
+		t.Errorf("missing module: got %d, want 404", code)
+	}
+}
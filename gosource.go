@@ -0,0 +1,113 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// goFile is a parsed Go source file from a module.
+type goFile struct {
+	path      string
+	src       string
+	ast       *ast.File
+	generated bool
+	generator string // tool that generated the file, if known
+}
+
+// parseGoFiles parses every .go file under prefix. Files inside testdata
+// and vendor directories are skipped, as are files that cannot be read or
+// parsed at all. The result is sorted by path.
+func parseGoFiles(mf moduleFiles, prefix string, mode parser.Mode) (*token.FileSet, []*goFile, error) {
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(paths)
+
+	fset := token.NewFileSet()
+
+	var files []*goFile
+
+	for _, p := range paths {
+		if !isGoSource(p) {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, p, src, mode)
+		if f == nil && err != nil {
+			continue
+		}
+
+		generated, generator := generatedBy(p, src)
+		files = append(files, &goFile{path: p, src: src, ast: f, generated: generated, generator: generator})
+	}
+
+	return fset, files, nil
+}
+
+// isGoSource reports whether p is a .go file that belongs to the module's
+// own packages, as opposed to test fixtures or vendored code.
+func isGoSource(p string) bool {
+	if !strings.HasSuffix(p, ".go") {
+		return false
+	}
+
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		if elem == "testdata" || elem == "vendor" || strings.HasPrefix(elem, "_") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// receiverName returns the base type name of a method receiver, without
+// pointer or type parameters. E.g. "*Client[T]" -> "Client".
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := fn.Recv.List[0].Type
+
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// declSource returns the source text of a declaration, including its doc
+// comment if it has one.
+func declSource(fset *token.FileSet, f *goFile, decl ast.Node, doc *ast.CommentGroup) string {
+	start := decl.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+
+	tf := fset.File(start)
+	if tf == nil {
+		return ""
+	}
+
+	return f.src[tf.Offset(start):tf.Offset(decl.End())]
+}
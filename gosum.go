@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned for a download whose hash differs from
+// the one recorded for it in a -verify-go-sum file.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// goSums are the h1: hashes of the go.sum files that downloads must match,
+// keyed like go.sum lines by "path version" or "path version/go.mod".
+// Module versions the files do not list are not checked.
+type goSums struct {
+	hashes map[string][]string
+	files  map[string]string // the file each key was first read from
+}
+
+// loadGoSums reads a comma-separated list of go.sum files.
+func loadGoSums(list string) (*goSums, error) {
+	s := &goSums{hashes: make(map[string][]string), files: make(map[string]string)}
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("read go.sum: %w", err)
+		}
+
+		s.add(name, data)
+	}
+
+	return s, nil
+}
+
+func (s *goSums) add(name string, data []byte) {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "h1:") {
+			continue
+		}
+
+		key := fields[0] + " " + fields[1]
+		if !slices.Contains(s.hashes[key], fields[2]) {
+			s.hashes[key] = append(s.hashes[key], fields[2])
+		}
+
+		if _, ok := s.files[key]; !ok {
+			s.files[key] = name
+		}
+	}
+}
+
+// has reports whether the go.sum files list a hash for the zip of a
+// module version, or with goMod for its go.mod file.
+func (s *goSums) has(mod, version string, goMod bool) bool {
+	if s == nil {
+		return false
+	}
+
+	return len(s.hashes[sumKey(mod, version, goMod)]) > 0
+}
+
+// verify checks the hash of a download against the go.sum files. It
+// returns nil when they do not list the module version.
+func (s *goSums) verify(mod, version string, goMod bool, hash string) error {
+	key := sumKey(mod, version, goMod)
+
+	want := s.hashes[key]
+	if len(want) == 0 || slices.Contains(want, hash) {
+		return nil
+	}
+
+	what := "zip"
+	if goMod {
+		what = "go.mod"
+	}
+
+	return fmt.Errorf("verify %s@%s %s: %w: downloaded %s, %s has %s",
+		mod, version, what, ErrChecksumMismatch, hash, s.files[key], strings.Join(want, ", "))
+}
+
+func sumKey(mod, version string, goMod bool) string {
+	if goMod {
+		return mod + " " + version + "/go.mod"
+	}
+
+	return mod + " " + version
+}
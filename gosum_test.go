@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+func TestZipHash(t *testing.T) {
+	data := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod": "module example.com/testmod\n", "a/b.go": "package a\n",
+	})
+
+	name := filepath.Join(t.TempDir(), "m.zip")
+	mustf(t, os.WriteFile(name, data, 0o600), "write zip")
+
+	want, err := dirhash.HashZip(name, dirhash.Hash1)
+	mustf(t, err, "hash zip file")
+
+	if got, err := zipHash(data); err != nil || got != want {
+		t.Errorf("zipHash = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestGoSums(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.sum"), filepath.Join(dir, "b.sum")
+
+	mustf(t, os.WriteFile(a, []byte("example.com/m v1.0.0 h1:zip=\nexample.com/m v1.0.0/go.mod h1:mod=\n"), 0o600), "a")
+	mustf(t, os.WriteFile(b, []byte("example.com/n v0.1.0 h1:n=\nexample.com/m v1.0.0 h1:other=\n"), 0o600), "b")
+
+	sums, err := loadGoSums(a + ", " + b)
+	mustf(t, err, "load go.sums")
+
+	for _, tt := range []struct {
+		mod, version string
+		goMod        bool
+		hash         string
+		ok           bool
+	}{
+		{"example.com/m", "v1.0.0", false, "h1:zip=", true},
+		{"example.com/m", "v1.0.0", false, "h1:other=", true},
+		{"example.com/m", "v1.0.0", true, "h1:mod=", true},
+		{"example.com/m", "v1.0.0", true, "h1:zip=", false},
+		{"example.com/n", "v0.1.0", false, "h1:bad=", false},
+		{"example.com/n", "v0.1.0", true, "h1:any=", true}, // no go.mod line
+		{"example.com/o", "v1.0.0", false, "h1:any=", true},
+	} {
+		err := sums.verify(tt.mod, tt.version, tt.goMod, tt.hash)
+		if (err == nil) != tt.ok || err != nil && !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("verify %s@%s go.mod %v %s: %v", tt.mod, tt.version, tt.goMod, tt.hash, err)
+		}
+	}
+
+	err = sums.verify("example.com/m", "v1.0.0", true, "h1:bad=")
+	if err == nil || !strings.Contains(err.Error(), "go.mod: checksum mismatch: downloaded h1:bad=, "+a+" has h1:mod=") {
+		t.Errorf("mismatch error = %v", err)
+	}
+
+	if _, err := loadGoSums(filepath.Join(dir, "missing.sum")); err == nil {
+		t.Error("expected an error for a missing go.sum")
+	}
+}
+
+func TestProxyClient_VerifySum(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"go.mod": "module example.com/testmod\n"})
+
+	zipSum, err := zipHash(zipData)
+	mustf(t, err, "zip hash")
+
+	modSum, err := goModHash("module example.com/testmod\n\ngo 1.21\n")
+	mustf(t, err, "go.mod hash")
+
+	proxy, ts := newTestProxy(fakeProxy(zipData))
+	defer ts.Close()
+
+	proxy.sums = &goSums{hashes: map[string][]string{}, files: map[string]string{}}
+	proxy.sums.add("go.sum", []byte("example.com/testmod v1.0.0 "+zipSum+"\n"+
+		"example.com/testmod v1.0.0/go.mod "+modSum+"\n"))
+
+	ctx := context.Background()
+
+	if _, err := proxy.DownloadZip(ctx, "example.com/testmod", "v1.0.0"); err != nil {
+		t.Errorf("matching zip: %v", err)
+	}
+
+	if _, err := proxy.ReadMod(ctx, "example.com/testmod", "v1.0.0"); err != nil {
+		t.Errorf("matching go.mod: %v", err)
+	}
+
+	proxy.sums.hashes["example.com/testmod v1.0.0/go.mod"] = []string{"h1:tampered="}
+
+	if _, err := proxy.ReadMod(ctx, "example.com/testmod", "v1.0.0"); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("tampered go.mod: got %v, want ErrChecksumMismatch", err)
+	}
+}
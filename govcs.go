@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// defaultGOVCS is the go command's rule set, applied after the user's:
+// public modules may use Git and Mercurial, private modules anything.
+const defaultGOVCS = "public:git|hg,private:all"
+
+// ErrVCSDisallowed is returned when GOVCS does not allow running a version
+// control command for a module.
+var ErrVCSDisallowed = errors.New("disallowed by GOVCS")
+
+// vcsRule is one pattern:vcslist entry of GOVCS.
+type vcsRule struct {
+	pattern string   // module path patterns, "public" or "private"
+	allowed []string // VCS names, "all" or "off"
+}
+
+// VCSPolicy is the GOVCS setting, which limits the version control
+// commands the go command runs for a module, as described by "go help
+// vcs". The server runs git only where the user's go command would. The
+// "private" pattern matches the modules in GOPRIVATE and "public" all
+// others. A nil policy allows everything.
+type VCSPolicy struct {
+	rules   []vcsRule
+	private string
+}
+
+// NewVCSPolicy parses the GOVCS and GOPRIVATE settings. Like the go
+// command, it rejects empty entries, entries without a colon or VCS list,
+// and patterns that are listed twice.
+func NewVCSPolicy(govcs, goprivate string) (*VCSPolicy, error) {
+	p := &VCSPolicy{private: goprivate}
+
+	seen := make(map[string]bool)
+
+	for i, list := range []string{govcs, defaultGOVCS} {
+		if list == "" {
+			continue
+		}
+
+		for _, item := range strings.Split(list, ",") {
+			rule, err := parseVCSRule(item)
+			if err != nil {
+				return nil, err
+			}
+
+			if seen[rule.pattern] {
+				if i == 0 {
+					return nil, fmt.Errorf("invalid GOVCS: pattern %q is listed twice", rule.pattern)
+				}
+
+				continue
+			}
+
+			seen[rule.pattern] = true
+			p.rules = append(p.rules, rule)
+		}
+	}
+
+	return p, nil
+}
+
+func parseVCSRule(item string) (vcsRule, error) {
+	item = strings.TrimSpace(item)
+	if item == "" {
+		return vcsRule{}, errors.New("invalid GOVCS: empty entry")
+	}
+
+	pattern, list, ok := strings.Cut(item, ":")
+	if !ok {
+		return vcsRule{}, fmt.Errorf("invalid GOVCS: entry %q has no colon", item)
+	}
+
+	rule := vcsRule{pattern: strings.TrimSpace(pattern)}
+	if rule.pattern == "" {
+		return vcsRule{}, fmt.Errorf("invalid GOVCS: entry %q has no pattern", item)
+	}
+
+	for _, vcs := range strings.Split(list, "|") {
+		vcs = strings.TrimSpace(vcs)
+		if vcs == "" {
+			return vcsRule{}, fmt.Errorf("invalid GOVCS: entry %q has an empty VCS name", item)
+		}
+
+		rule.allowed = append(rule.allowed, vcs)
+	}
+
+	if len(rule.allowed) > 1 && (slices.Contains(rule.allowed, "all") || slices.Contains(rule.allowed, "off")) {
+		return vcsRule{}, fmt.Errorf("invalid GOVCS: entry %q combines all or off with other VCS names", item)
+	}
+
+	return rule, nil
+}
+
+// Check returns an error wrapping ErrVCSDisallowed unless the first rule
+// matching mod allows vcs. A module that no rule matches is allowed.
+func (p *VCSPolicy) Check(mod, vcs string) error {
+	if p == nil {
+		return nil
+	}
+
+	private := p.private != "" && module.MatchPrefixPatterns(p.private, mod)
+
+	for _, r := range p.rules {
+		switch r.pattern {
+		case "public":
+			if private {
+				continue
+			}
+		case "private":
+			if !private {
+				continue
+			}
+		default:
+			if !module.MatchPrefixPatterns(r.pattern, mod) {
+				continue
+			}
+		}
+
+		if slices.Contains(r.allowed, "all") || slices.Contains(r.allowed, vcs) {
+			return nil
+		}
+
+		kind := "public"
+		if private {
+			kind = "private"
+		}
+
+		return fmt.Errorf("%w: %s is not allowed for %s module %s (rule %s:%s)",
+			ErrVCSDisallowed, vcs, kind, mod, r.pattern, strings.Join(r.allowed, "|"))
+	}
+
+	return nil
+}
+
+// goEnv returns a go environment variable from the environment or else
+// from the go command, which also knows the values set by "go env -w". It
+// returns empty string if neither has it.
+func goEnv(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+
+	out, err := exec.Command("go", "env", name).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVCSPolicy_Check(t *testing.T) {
+	policy, err := NewVCSPolicy("github.com/acme:git, evil.com:off, private:git|svn", "*.corp.example.com")
+	mustf(t, err, "new policy")
+
+	for _, tc := range []struct {
+		mod, vcs string
+		allowed  bool
+	}{
+		{"github.com/acme/tool", "git", true},
+		{"github.com/acme/tool", "hg", false},
+		{"evil.com/x", "git", false},
+		{"git.corp.example.com/team/mod", "svn", true},
+		{"git.corp.example.com/team/mod", "bzr", false},
+		{"github.com/other/tool", "hg", true}, // default public:git|hg
+		{"github.com/other/tool", "svn", false},
+	} {
+		if err := policy.Check(tc.mod, tc.vcs); (err == nil) != tc.allowed ||
+			err != nil && !errors.Is(err, ErrVCSDisallowed) {
+			t.Errorf("Check(%s, %s) = %v, want allowed %v", tc.mod, tc.vcs, err, tc.allowed)
+		}
+	}
+}
+
+func TestVCSPolicy_Defaults(t *testing.T) {
+	policy, err := NewVCSPolicy("", "example.com/private")
+	mustf(t, err, "new policy")
+
+	if err := policy.Check("example.com/private/mod", "fossil"); err != nil {
+		t.Errorf("private module with default rules: %v", err)
+	}
+
+	if err := policy.Check("example.com/public", "fossil"); !errors.Is(err, ErrVCSDisallowed) {
+		t.Errorf("public module with default rules: got %v, want ErrVCSDisallowed", err)
+	}
+
+	var none *VCSPolicy
+	if err := none.Check("example.com/public", "fossil"); err != nil {
+		t.Errorf("nil policy denied: %v", err)
+	}
+}
+
+func TestNewVCSPolicy_Invalid(t *testing.T) {
+	for _, govcs := range []string{
+		"github.com:git,",
+		"github.com",
+		":git",
+		"github.com:",
+		"github.com:git||hg",
+		"github.com:all|git",
+		"github.com:git,github.com:hg",
+	} {
+		if _, err := NewVCSPolicy(govcs, ""); err == nil {
+			t.Errorf("NewVCSPolicy(%q): expected an error", govcs)
+		}
+	}
+
+	if _, err := NewVCSPolicy(defaultGOVCS, ""); err != nil {
+		t.Errorf("NewVCSPolicy(%q): %v", defaultGOVCS, err)
+	}
+}
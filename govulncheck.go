@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const defaultGovulncheck = "govulncheck"
+
+// govulncheckFrame is one entry of a finding's call trace. The first
+// frame is the vulnerable symbol, the last the entry point in the
+// scanned code.
+type govulncheckFrame struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Receiver string `json:"receiver"`
+	Position *struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+	} `json:"position"`
+}
+
+func (f govulncheckFrame) symbol() string {
+	name := f.Function
+	if f.Receiver != "" {
+		name = strings.TrimPrefix(f.Receiver, "*") + "." + name
+	}
+
+	if f.Package != "" {
+		name = f.Package + "." + name
+	}
+
+	return name
+}
+
+type govulncheckFinding struct {
+	OSV          string             `json:"osv"`
+	FixedVersion string             `json:"fixed_version"`
+	Trace        []govulncheckFrame `json:"trace"`
+}
+
+// govulncheckMessage is one object of govulncheck's -format json stream.
+type govulncheckMessage struct {
+	OSV     *osvVuln            `json:"osv"`
+	Finding *govulncheckFinding `json:"finding"`
+}
+
+// reachability levels, from a finding's first trace frame.
+const (
+	reachSymbol  = "symbol"  // a vulnerable function is called
+	reachPackage = "package" // a vulnerable package is imported
+	reachModule  = "module"  // a vulnerable module is required
+)
+
+// govulncheckVuln aggregates the findings for one vulnerability.
+type govulncheckVuln struct {
+	id      string
+	vuln    *osvVuln
+	level   string
+	module  string // module@version
+	fixed   string
+	traces  [][]govulncheckFrame // symbol-level call traces
+	symbols map[string]bool
+}
+
+func findingLevel(f *govulncheckFinding) string {
+	if len(f.Trace) == 0 {
+		return reachModule
+	}
+
+	switch {
+	case f.Trace[0].Function != "":
+		return reachSymbol
+	case f.Trace[0].Package != "":
+		return reachPackage
+	default:
+		return reachModule
+	}
+}
+
+var reachRank = map[string]int{reachSymbol: 0, reachPackage: 1, reachModule: 2}
+
+// parseGovulncheck reads a govulncheck JSON stream and returns one entry
+// per vulnerability at its most precise reachability level.
+func parseGovulncheck(r io.Reader) ([]*govulncheckVuln, error) {
+	dec := json.NewDecoder(r)
+	records := make(map[string]*osvVuln)
+	vulns := make(map[string]*govulncheckVuln)
+
+	for {
+		var msg govulncheckMessage
+
+		err := dec.Decode(&msg)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("decode govulncheck output: %w", err)
+		}
+
+		if msg.OSV != nil {
+			records[msg.OSV.ID] = msg.OSV
+		}
+
+		f := msg.Finding
+		if f == nil {
+			continue
+		}
+
+		level := findingLevel(f)
+
+		v, ok := vulns[f.OSV]
+		if !ok || reachRank[level] < reachRank[v.level] {
+			v = &govulncheckVuln{id: f.OSV, level: level, fixed: f.FixedVersion, symbols: make(map[string]bool)}
+			vulns[f.OSV] = v
+		}
+
+		if len(f.Trace) > 0 && v.module == "" {
+			v.module = f.Trace[0].Module + "@" + f.Trace[0].Version
+		}
+
+		if level == reachSymbol && level == v.level {
+			v.traces = append(v.traces, f.Trace)
+			v.symbols[f.Trace[0].symbol()] = true
+		}
+	}
+
+	result := make([]*govulncheckVuln, 0, len(vulns))
+
+	for _, id := range sortedKeys(vulns) {
+		v := vulns[id]
+		v.vuln = records[id]
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+// runGovulncheck runs the govulncheck binary on every package in dir.
+func runGovulncheck(ctx context.Context, bin, dir string) ([]*govulncheckVuln, error) {
+	var stdout, stderr bytes.Buffer
+
+	noteBackend(ctx, "govulncheck")
+
+	//nolint:gosec // The binary is set by the server operator, not the client.
+	cmd := exec.CommandContext(ctx, bin, "-format", "json", "-scan", "symbol", "./...")
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || stdout.Len() == 0 {
+			return nil, fmt.Errorf("run %s: %w: %s", bin, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return parseGovulncheck(&stdout)
+}
+
+// formatTrace renders a call trace from the entry point to the vulnerable
+// symbol.
+func formatTrace(trace []govulncheckFrame) string {
+	parts := make([]string, 0, len(trace))
+
+	for i := len(trace) - 1; i >= 0; i-- {
+		parts = append(parts, trace[i].symbol())
+	}
+
+	s := strings.Join(parts, " -> ")
+
+	if entry := trace[len(trace)-1]; entry.Position != nil {
+		s = fmt.Sprintf("%s:%d: %s", entry.Position.Filename, entry.Position.Line, s)
+	}
+
+	return s
+}
+
+func formatGovulncheck(sb *strings.Builder, vulns []*govulncheckVuln, showAll bool) {
+	counts := make(map[string]int)
+	for _, v := range vulns {
+		counts[v.level]++
+	}
+
+	fmt.Fprintf(sb, "%d reachable vulnerabilities "+
+		"(%d in imported packages but not called, %d in required modules only).\n",
+		counts[reachSymbol], counts[reachPackage], counts[reachModule])
+
+	sort.SliceStable(vulns, func(i, j int) bool { return reachRank[vulns[i].level] < reachRank[vulns[j].level] })
+
+	for _, v := range vulns {
+		if v.level != reachSymbol && !showAll {
+			continue
+		}
+
+		title := ""
+		if v.vuln != nil {
+			title = ": " + v.vuln.title()
+		}
+
+		fmt.Fprintf(sb, "\n%s [%s]%s\n", v.id, v.level, title)
+		fmt.Fprintf(sb, "  module: %s", v.module)
+
+		if v.fixed != "" {
+			fmt.Fprintf(sb, " (fixed in %s)", v.fixed)
+		}
+
+		sb.WriteString("\n")
+
+		if len(v.symbols) > 0 {
+			fmt.Fprintf(sb, "  vulnerable symbols: %s\n", strings.Join(sortedKeys(v.symbols), ", "))
+		}
+
+		for _, trace := range v.traces {
+			fmt.Fprintf(sb, "  %s\n", formatTrace(trace))
+		}
+	}
+}
+
+type govulncheckInput struct {
+	Dir string `json:"dir,omitempty" jsonschema:"Project directory to analyze (default: bound project)"`
+	All bool   `json:"all,omitempty" jsonschema:"Also list vulnerabilities that are imported or required but not called"`
+}
+
+func handleGovulncheck(
+	ctx context.Context, bin string, offline bool, binding *projectBinding, input govulncheckInput,
+) (*mcp.CallToolResult, any, error) {
+	// govulncheck reads vuln.go.dev and the module proxy itself.
+	if offline {
+		return errorResult(fmt.Sprintf("Cannot run govulncheck: %v; it queries vuln.go.dev and the module proxy.",
+			ErrOffline)), nil, nil
+	}
+
+	dir := input.Dir
+	if dir == "" {
+		dir = binding.Dir()
+	}
+
+	if dir == "" {
+		return errorResult("A project directory is required (or bind one with gomod_bind_project)."), nil, nil
+	}
+
+	if _, err := exec.LookPath(bin); err != nil {
+		return errorResult(fmt.Sprintf(
+			"govulncheck not found (%v). Install it with: go install golang.org/x/vuln/cmd/govulncheck@latest",
+			err,
+		)), nil, nil
+	}
+
+	vulns, err := runGovulncheck(ctx, bin, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "govulncheck of %s: ", dir)
+	formatGovulncheck(&sb, vulns, input.All)
+
+	return textResult(sb.String()), nil, nil
+}
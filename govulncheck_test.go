@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const testGovulncheckOutput = `{"config":{"protocol_version":"v1.0.0","scanner_name":"govulncheck"}}
+{"progress":{"message":"Scanning your code..."}}
+{"osv":{"id":"GO-2024-0001","summary":"Panic in Parse"}}
+{"osv":{"id":"GO-2024-0002","summary":"Leak in Server"}}
+{"osv":{"id":"GO-2024-0003","summary":"Unused module bug"}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v1.0.1",
+  "trace":[{"module":"example.com/a","version":"v1.0.0","package":"example.com/a"}]}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v1.0.1","trace":[
+  {"module":"example.com/a","version":"v1.0.0","package":"example.com/a","function":"Parse"},
+  {"module":"example.com/app","package":"example.com/app","function":"main",
+   "position":{"filename":"main.go","line":12}}]}}
+{"finding":{"osv":"GO-2024-0002","trace":[
+  {"module":"example.com/b","version":"v0.3.0","package":"example.com/b"}]}}
+{"finding":{"osv":"GO-2024-0003","trace":[{"module":"example.com/c","version":"v2.0.0"}]}}
+`
+
+func TestParseGovulncheck(t *testing.T) {
+	vulns, err := parseGovulncheck(strings.NewReader(testGovulncheckOutput))
+	mustf(t, err, "parse")
+
+	if len(vulns) != 3 {
+		t.Fatalf("got %d vulns, want 3", len(vulns))
+	}
+
+	first := vulns[0]
+	if first.id != "GO-2024-0001" || first.level != reachSymbol || len(first.traces) != 1 ||
+		first.module != "example.com/a@v1.0.0" || first.vuln.Summary != "Panic in Parse" {
+		t.Errorf("unexpected symbol-level vuln: %+v", first)
+	}
+
+	if vulns[1].level != reachPackage || vulns[2].level != reachModule {
+		t.Errorf("levels = %s, %s", vulns[1].level, vulns[2].level)
+	}
+
+	if got := formatTrace(first.traces[0]); got != "main.go:12: example.com/app.main -> example.com/a.Parse" {
+		t.Errorf("formatTrace = %q", got)
+	}
+}
+
+func TestFormatGovulncheck(t *testing.T) {
+	vulns, err := parseGovulncheck(strings.NewReader(testGovulncheckOutput))
+	mustf(t, err, "parse")
+
+	var sb strings.Builder
+
+	formatGovulncheck(&sb, vulns, false)
+
+	text := sb.String()
+
+	if !strings.HasPrefix(text, "1 reachable vulnerabilities (1 in imported packages but not called, "+
+		"1 in required modules only).") {
+		t.Errorf("unexpected summary:\n%s", text)
+	}
+
+	if !strings.Contains(text, "GO-2024-0001 [symbol]: Panic in Parse\n  module: example.com/a@v1.0.0 (fixed in v1.0.1)") {
+		t.Errorf("missing reachable vuln:\n%s", text)
+	}
+
+	if strings.Contains(text, "GO-2024-0002") {
+		t.Errorf("unreachable vuln listed without all:\n%s", text)
+	}
+
+	sb.Reset()
+	formatGovulncheck(&sb, vulns, true)
+
+	if !strings.Contains(sb.String(), "GO-2024-0003 [module]") {
+		t.Errorf("expected module-level vuln with all:\n%s", sb.String())
+	}
+}
+
+func TestHandleGovulncheck(t *testing.T) {
+	binDir := t.TempDir()
+	outFile := filepath.Join(binDir, "out.json")
+	bin := filepath.Join(binDir, "govulncheck")
+
+	mustf(t, os.WriteFile(outFile, []byte(testGovulncheckOutput), 0o600), "write output")
+	mustf(t, os.WriteFile(bin, []byte("#!/bin/sh\ncat "+outFile+"\n"), 0o700), "write fake govulncheck")
+
+	dir := t.TempDir()
+	binding := &projectBinding{}
+	binding.Set(dir)
+
+	result, _, err := handleGovulncheck(context.Background(), bin, false, binding, govulncheckInput{})
+	mustf(t, err, "govulncheck")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "govulncheck of "+dir+": 1 reachable") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	result, _, err = handleGovulncheck(context.Background(), filepath.Join(binDir, "missing"), false, binding,
+		govulncheckInput{})
+	mustf(t, err, "govulncheck missing binary")
+
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "go install") {
+		t.Errorf("expected install hint for a missing binary")
+	}
+
+	result, _, err = handleGovulncheck(context.Background(), bin, true, binding, govulncheckInput{})
+	mustf(t, err, "govulncheck offline")
+
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, ErrOffline.Error()) {
+		t.Errorf("expected govulncheck to refuse to run offline")
+	}
+}
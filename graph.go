@@ -0,0 +1,738 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// maxGraphModules bounds the module versions loaded into a graph; the
+	// requirements of any beyond it are not read.
+	maxGraphModules = 5000
+	// maxCachedGraphs bounds the project graphs kept between calls.
+	maxCachedGraphs = 8
+	// maxGraphEdgeLines bounds the requirements the edges query lists.
+	maxGraphEdgeLines = 2000
+)
+
+// Queries of gomod_project_graph.
+const (
+	graphSummary   = "summary"
+	graphChain     = "chain"
+	graphRequirers = "requirers"
+	graphMajors    = "majors"
+	graphForces    = "forces"
+	graphConflicts = "conflicts"
+	graphEdges     = "edges"
+	graphBuildList = "build_list"
+)
+
+var graphQueries = []string{
+	graphSummary, graphChain, graphRequirers, graphMajors, graphForces, graphConflicts, graphEdges, graphBuildList,
+}
+
+// moduleGraph is the requirement graph of a project: the module versions
+// reachable from its go.mod, each with the requirements of its own go.mod,
+// as "go mod graph" prints it without pruning. Selected is the version
+// minimal version selection picks for each module other than the main
+// one: the highest one in the graph. Replace directives of the project
+// apply to every module, and requirements on excluded versions are left
+// out rather than raised to the next version.
+type moduleGraph struct {
+	main      module.Version // the project's module, with a version when it is a published one
+	reqs      map[module.Version][]module.Version
+	selected  map[string]string
+	failed    map[module.Version]error // go.mod files that could not be read
+	truncated bool
+
+	replaces   []*modfile.Replace                  // the project's replace directives
+	excludedBy map[module.Version][]module.Version // excluded versions to the modules requiring them
+}
+
+// buildModuleGraph reads the go.mod of every module version reachable from
+// the project, a level at a time.
+func buildModuleGraph(ctx context.Context, proxy *ProxyClient, proj *project) (*moduleGraph, error) {
+	if proj.mod == nil {
+		return nil, errors.New("a go.mod is required to build the module graph")
+	}
+
+	g := &moduleGraph{
+		main:     module.Version{Path: proj.modulePath(), Version: proj.version},
+		reqs:     make(map[module.Version][]module.Version),
+		selected: make(map[string]string),
+		failed:   make(map[module.Version]error),
+
+		replaces:   proj.mod.Replace,
+		excludedBy: make(map[module.Version][]module.Version),
+	}
+
+	excluded := make(map[module.Version]bool)
+	for _, x := range proj.mod.Exclude {
+		excluded[x.Mod] = true
+	}
+
+	replaced := make(map[module.Version]module.Version)
+	for _, r := range proj.mod.Replace {
+		replaced[r.Old] = r.New
+	}
+
+	requirements := func(from module.Version, reqs []*modfile.Require) []module.Version {
+		var mods []module.Version
+
+		for _, r := range reqs {
+			if excluded[r.Mod] {
+				g.excludedBy[r.Mod] = append(g.excludedBy[r.Mod], from)
+
+				continue
+			}
+
+			mods = append(mods, r.Mod)
+		}
+
+		return mods
+	}
+
+	g.reqs[g.main] = requirements(g.main, proj.mod.Require)
+	level := g.reqs[g.main]
+
+	for len(level) > 0 && ctx.Err() == nil {
+		var next []module.Version
+
+		queued := make(map[module.Version]bool)
+
+		for _, m := range level {
+			if _, ok := g.reqs[m]; ok || queued[m] {
+				continue
+			}
+
+			if len(g.reqs)+len(next) >= maxGraphModules {
+				g.truncated = true
+
+				break
+			}
+
+			queued[m] = true
+			next = append(next, m)
+		}
+
+		loaded := make([][]*modfile.Require, len(next))
+		errs := make([]error, len(next))
+
+		parallelEach(next, func(i int, m module.Version) {
+			loaded[i], errs[i] = graphRequirements(ctx, proxy, proj.dir, m, replaced)
+		})
+
+		level = nil
+
+		for i, m := range next {
+			if errs[i] != nil {
+				g.failed[m] = errs[i]
+			}
+
+			g.reqs[m] = requirements(m, loaded[i])
+			level = append(level, g.reqs[m]...)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for m := range g.reqs {
+		if m.Path != g.main.Path && semver.Compare(m.Version, g.selected[m.Path]) > 0 {
+			g.selected[m.Path] = m.Version
+		}
+	}
+
+	return g, nil
+}
+
+// graphRequirements returns the requirements of m, read from the go.mod of
+// its replacement if the project replaces it.
+func graphRequirements(
+	ctx context.Context, proxy *ProxyClient, dir string, m module.Version, replaced map[module.Version]module.Version,
+) ([]*modfile.Require, error) {
+	target, ok := replaced[m]
+	if !ok {
+		target, ok = replaced[module.Version{Path: m.Path}]
+	}
+
+	if !ok {
+		target = m
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+
+	switch {
+	case target.Version != "":
+		var text string
+
+		text, err = proxy.ReadMod(ctx, target.Path, target.Version)
+		data = []byte(text)
+	case dir == "":
+		return nil, nil // a local replacement of go.mod content passed in
+	default:
+		local := target.Path
+		if !filepath.IsAbs(local) {
+			local = filepath.Join(dir, local)
+		}
+
+		data, err = os.ReadFile(filepath.Join(local, "go.mod"))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	mf, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return mf.Require, nil
+}
+
+// isSelected reports whether m is the version of its module in the build.
+func (g *moduleGraph) isSelected(m module.Version) bool {
+	return m == g.main || g.selected[m.Path] == m.Version
+}
+
+// edges returns the number of requirements in the graph.
+func (g *moduleGraph) edges() int {
+	n := 0
+	for _, reqs := range g.reqs {
+		n += len(reqs)
+	}
+
+	return n
+}
+
+// chain returns a shortest require chain from the main module to a module
+// version done reports as the one sought, or nil if there is none. Ties
+// are broken by module path and version, so the answer is stable.
+func (g *moduleGraph) chain(done func(module.Version) bool) []module.Version {
+	parent := map[module.Version]module.Version{g.main: {}}
+	level := []module.Version{g.main}
+
+	for len(level) > 0 {
+		var next []module.Version
+
+		for _, m := range level {
+			for _, r := range sortedVersions(g.reqs[m]) {
+				if _, seen := parent[r]; seen {
+					continue
+				}
+
+				parent[r] = m
+
+				if done(r) {
+					chain := []module.Version{r}
+					for p := m; p != g.main; p = parent[p] {
+						chain = append(chain, p)
+					}
+
+					chain = append(chain, g.main)
+					slices.Reverse(chain)
+
+					return chain
+				}
+
+				next = append(next, r)
+			}
+		}
+
+		level = next
+	}
+
+	return nil
+}
+
+// requirers returns the module versions whose go.mod requires target, with
+// the version each requires, sorted.
+func (g *moduleGraph) requirers(target string) [][2]module.Version {
+	var found [][2]module.Version
+
+	for m, reqs := range g.reqs {
+		for _, r := range reqs {
+			if r.Path == target {
+				found = append(found, [2]module.Version{m, r})
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return versionLess(found[i][0], found[j][0])
+	})
+
+	return found
+}
+
+// majors returns the modules selected at more than one major version,
+// such as example.com/m and example.com/m/v2, keyed by the path without a
+// major version suffix.
+func (g *moduleGraph) majors() map[string][]module.Version {
+	byPrefix := make(map[string][]module.Version)
+
+	for p, v := range g.selected {
+		prefix, _, ok := module.SplitPathVersion(p)
+		if !ok {
+			prefix = p
+		}
+
+		byPrefix[prefix] = append(byPrefix[prefix], module.Version{Path: p, Version: v})
+	}
+
+	for prefix, mods := range byPrefix {
+		if len(mods) < 2 {
+			delete(byPrefix, prefix)
+
+			continue
+		}
+
+		sort.Slice(mods, func(i, j int) bool { return versionLess(mods[i], mods[j]) })
+	}
+
+	return byPrefix
+}
+
+func sortedVersions(mods []module.Version) []module.Version {
+	sorted := append([]module.Version(nil), mods...)
+	sort.Slice(sorted, func(i, j int) bool { return versionLess(sorted[i], sorted[j]) })
+
+	return sorted
+}
+
+func versionLess(a, b module.Version) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+
+	return semver.Compare(a.Version, b.Version) < 0
+}
+
+func formatChain(chain []module.Version) string {
+	parts := make([]string, len(chain))
+	for i, m := range chain {
+		parts[i] = m.String()
+	}
+
+	return strings.Join(parts, " -> ")
+}
+
+// moduleGraphs keeps the graphs built for projects, keyed by directory and
+// go.mod content, so that a series of queries reads the go.mod files of
+// the dependencies once. The oldest graph is dropped when it is full.
+type moduleGraphs struct {
+	mu     sync.Mutex
+	graphs map[string]*moduleGraph
+	order  []string
+}
+
+func newModuleGraphs() *moduleGraphs {
+	return &moduleGraphs{graphs: make(map[string]*moduleGraph)}
+}
+
+// get returns the project's graph, building it unless it is kept already.
+func (s *moduleGraphs) get(ctx context.Context, proxy *ProxyClient, proj *project) (*moduleGraph, bool, error) {
+	if proj.mod == nil {
+		return nil, false, errors.New("a go.mod is required to build the module graph")
+	}
+
+	key := proj.dir + "\x00" + proj.version + "\x00" + string(modfile.Format(proj.mod.Syntax))
+
+	s.mu.Lock()
+	g, ok := s.graphs[key]
+	s.mu.Unlock()
+
+	if ok {
+		return g, true, nil
+	}
+
+	g, err := buildModuleGraph(ctx, proxy, proj)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.graphs[key]; !ok {
+		if len(s.order) == maxCachedGraphs {
+			delete(s.graphs, s.order[0])
+			s.order = s.order[1:]
+		}
+
+		s.order = append(s.order, key)
+	}
+
+	s.graphs[key] = g
+
+	return g, false, nil
+}
+
+type projectGraphInput struct {
+	Dir   string `json:"dir,omitempty" jsonschema:"Project directory with go.mod (default: bound project)"`
+	GoMod string `json:"go_mod,omitempty" jsonschema:"go.mod content, instead of dir"`
+	Root  string `json:"root,omitempty" jsonschema:"Graph this module@version as a dependency, instead of a project"`
+	Query string `json:"query,omitempty" jsonschema:"Query to answer (default summary); see the tool description"`
+
+	Module   string `json:"module,omitempty" jsonschema:"Module path the chain, requirers and forces queries are about"`
+	Resolved bool   `json:"resolved,omitempty" jsonschema:"For edges: only requirements of selected versions, as selected"`
+}
+
+func handleProjectGraph(
+	ctx context.Context, proxy *ProxyClient, binding *projectBinding,
+	graphs *moduleGraphs, input projectGraphInput,
+) (*mcp.CallToolResult, any, error) {
+	query := input.Query
+	if query == "" {
+		query = graphSummary
+	}
+
+	if !slices.Contains(graphQueries, query) {
+		return errorResult(fmt.Sprintf("Unknown query %q; use %s.", query, strings.Join(graphQueries, ", "))), nil, nil
+	}
+
+	if input.Module == "" && (query == graphChain || query == graphRequirers || query == graphForces) {
+		return errorResult(fmt.Sprintf("The %s query needs a module.", query)), nil, nil
+	}
+
+	proj, err := graphProject(ctx, proxy, binding, input)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	g, cached, err := graphs.get(ctx, proxy, proj)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Module graph of %s: %d module versions, %d requirements, %d modules selected",
+		cmp.Or(g.main.String(), "the project"), len(g.reqs)-1, g.edges(), len(g.selected))
+
+	if cached {
+		sb.WriteString(" (cached)")
+	}
+
+	sb.WriteString(".\n")
+
+	if proj.version != "" {
+		sb.WriteString("Its replace and exclude directives are left out, as in the build of a module depending on it.\n")
+	}
+
+	if g.truncated {
+		fmt.Fprintf(&sb, "The graph stopped at %d module versions; the requirements of the rest are missing.\n",
+			maxGraphModules)
+	}
+
+	if len(g.failed) > 0 {
+		fmt.Fprintf(&sb, "The go.mod of %d module versions could not be read, so their requirements are missing.\n",
+			len(g.failed))
+	}
+
+	switch query {
+	case graphSummary:
+		formatGraphSummary(&sb, g)
+	case graphChain:
+		formatGraphChain(&sb, g, input.Module)
+	case graphRequirers:
+		formatGraphRequirers(&sb, g, input.Module)
+	case graphMajors:
+		formatGraphMajors(&sb, g)
+	case graphForces:
+		formatGraphForces(&sb, g, input.Module)
+	case graphConflicts:
+		formatGraphConflicts(&sb, g)
+	case graphEdges:
+		if formatGraphEdges(&sb, g, input.Resolved) {
+			noteTruncated(ctx)
+		}
+	case graphBuildList:
+		formatGraphBuildList(&sb, g)
+	}
+
+	return textResult(sb.String()), nil, nil
+}
+
+// graphProject returns the project whose graph is queried: the go.mod of
+// a published module version when root is set, else the project input.
+func graphProject(
+	ctx context.Context, proxy *ProxyClient, binding *projectBinding, input projectGraphInput,
+) (*project, error) {
+	if input.Root == "" {
+		return projectFromInput(binding, input.Dir, input.GoMod, "")
+	}
+
+	if input.Dir != "" || input.GoMod != "" {
+		return nil, errors.New("root cannot be combined with dir or go_mod")
+	}
+
+	path, version, _ := strings.Cut(input.Root, "@")
+	if err := module.CheckPath(path); err != nil {
+		return nil, fmt.Errorf("root %q: %w", input.Root, err)
+	}
+
+	version, err := resolveVersion(ctx, proxy, path, cmp.Or(version, "latest"))
+	if err != nil {
+		return nil, err
+	}
+
+	return publishedProject(ctx, proxy, path, version)
+}
+
+func formatGraphSummary(sb *strings.Builder, g *moduleGraph) {
+	var raised []string
+
+	for _, r := range sortedVersions(g.reqs[g.main]) {
+		if sel := g.selected[r.Path]; sel != r.Version {
+			raised = append(raised, fmt.Sprintf("  %s: go.mod requires %s, selected %s", r.Path, r.Version, sel))
+		}
+	}
+
+	fmt.Fprintf(sb, "\nDirect requirements: %d\n", len(g.reqs[g.main]))
+
+	if len(raised) > 0 {
+		fmt.Fprintf(sb, "\nRequirements raised by other modules (%d):\n%s\n", len(raised), strings.Join(raised, "\n"))
+	}
+
+	if majors := g.majors(); len(majors) > 0 {
+		fmt.Fprintf(sb, "\nModules selected at several major versions: %d (query majors to list them)\n", len(majors))
+	}
+
+	if issues := g.directiveIssues(); len(issues) > 0 {
+		fmt.Fprintf(sb, "\nReplace and exclude directives with surprising effects: %d (query conflicts to list them)\n",
+			len(issues))
+	}
+
+	if len(g.failed) > 0 {
+		sb.WriteString("\nUnreadable go.mod files:\n")
+
+		failed := make([]module.Version, 0, len(g.failed))
+		for m := range g.failed {
+			failed = append(failed, m)
+		}
+
+		for _, m := range sortedVersions(failed) {
+			fmt.Fprintf(sb, "  %s: %v\n", m, g.failed[m])
+		}
+	}
+
+	sb.WriteString("\nQuery chain, requirers or forces with a module, or majors and conflicts, for details.\n")
+}
+
+// formatGraphChain explains why the graph holds a module, as go mod why
+// -m does. A target of module@version asks about that version, which
+// need not be the one selected.
+func formatGraphChain(sb *strings.Builder, g *moduleGraph, target string) {
+	path, version, exact := strings.Cut(target, "@")
+
+	sel, ok := g.selected[path]
+	if !ok {
+		fmt.Fprintf(sb, "\n%s is not in the module graph: %s does not need it.\n",
+			path, cmp.Or(g.main.String(), "the project"))
+
+		return
+	}
+
+	if !exact {
+		chain := g.chain(func(m module.Version) bool { return m.Path == path })
+		fmt.Fprintf(sb, "\nShortest require chain to %s (selected %s):\n  %s\n", path, sel, formatChain(chain))
+
+		return
+	}
+
+	want := module.Version{Path: path, Version: version}
+
+	chain := g.chain(func(m module.Version) bool { return m == want })
+	if chain == nil {
+		fmt.Fprintf(sb, "\nNothing in the module graph requires %s (selected %s).\n", want, sel)
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\nShortest require chain to %s:\n  %s\n", want, formatChain(chain))
+
+	if sel != version {
+		fmt.Fprintf(sb, "It is not built: %s is selected.\n", sel)
+	}
+}
+
+func formatGraphRequirers(sb *strings.Builder, g *moduleGraph, target string) {
+	found := g.requirers(target)
+	if len(found) == 0 {
+		fmt.Fprintf(sb, "\nNothing in the module graph requires %s.\n", target)
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\nModule versions requiring %s (%d; selected %s):\n", target, len(found), g.selected[target])
+
+	for _, f := range found {
+		fmt.Fprintf(sb, "  %s requires %s", f[0], f[1].Version)
+
+		if !g.isSelected(f[0]) {
+			fmt.Fprintf(sb, " (superseded by %s)", g.selected[f[0].Path])
+		}
+
+		sb.WriteString("\n")
+	}
+}
+
+func formatGraphMajors(sb *strings.Builder, g *moduleGraph) {
+	majors := g.majors()
+	if len(majors) == 0 {
+		sb.WriteString("\nNo module is selected at more than one major version.\n")
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\nModules selected at several major versions (%d):\n", len(majors))
+
+	for _, prefix := range sortedKeys(majors) {
+		parts := make([]string, len(majors[prefix]))
+		for i, m := range majors[prefix] {
+			parts[i] = m.String()
+		}
+
+		fmt.Fprintf(sb, "  %s: %s\n", prefix, strings.Join(parts, ", "))
+	}
+}
+
+func formatGraphForces(sb *strings.Builder, g *moduleGraph, target string) {
+	sel, ok := g.selected[target]
+	if !ok {
+		fmt.Fprintf(sb, "\n%s is not in the module graph.\n", target)
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\n%s is selected at %s, the highest version required by:\n", target, sel)
+
+	for _, f := range g.requirers(target) {
+		if f[1].Version != sel {
+			continue
+		}
+
+		if f[0] == g.main {
+			fmt.Fprintf(sb, "  %s (its own go.mod)\n", cmp.Or(g.main.String(), "the project"))
+
+			continue
+		}
+
+		forcer := f[0]
+		chain := g.chain(func(m module.Version) bool { return m == forcer })
+		fmt.Fprintf(sb, "  %s, via %s\n", forcer, formatChain(chain))
+	}
+}
+
+// formatGraphEdges lists the requirements as go mod graph does, one "from
+// to" line each, the main module's first. Resolved keeps only the
+// requirements of selected versions and shows each at the version
+// selected, which is the graph the build uses. It reports whether the
+// list was cut at maxGraphEdgeLines.
+func formatGraphEdges(sb *strings.Builder, g *moduleGraph, resolved bool) bool {
+	seen := make(map[[2]module.Version]bool)
+
+	var edges [][2]module.Version
+
+	for m, reqs := range g.reqs {
+		if resolved {
+			if !g.isSelected(m) {
+				continue
+			}
+
+			reqs = g.resolvedRequirements(m)
+		}
+
+		for _, r := range reqs {
+			if e := [2]module.Version{m, r}; !seen[e] {
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if a, b := edges[i][0], edges[j][0]; a != b {
+			if a == g.main || b == g.main {
+				return a == g.main
+			}
+
+			return versionLess(a, b)
+		}
+
+		return versionLess(edges[i][1], edges[j][1])
+	})
+
+	kind := "Requirements"
+	if resolved {
+		kind = "Requirements of the selected versions, at the versions selected"
+	}
+
+	fmt.Fprintf(sb, "\n%s (%d):\n", kind, len(edges))
+
+	for _, e := range edges[:min(len(edges), maxGraphEdgeLines)] {
+		fmt.Fprintf(sb, "  %s %s\n", e[0], e[1])
+	}
+
+	if len(edges) > maxGraphEdgeLines {
+		fmt.Fprintf(sb, "  ... %d more; query requirers or chain for a module\n", len(edges)-maxGraphEdgeLines)
+
+		return true
+	}
+
+	return false
+}
+
+// formatGraphBuildList lists the module versions minimal version selection
+// picks, the main module first, as go list -m all does.
+func formatGraphBuildList(sb *strings.Builder, g *moduleGraph) {
+	fmt.Fprintf(sb, "\nBuild list (%d):\n  %s\n", len(g.selected)+1, cmp.Or(g.main.String(), "the project"))
+
+	for _, m := range g.buildList() {
+		fmt.Fprintf(sb, "  %s %s\n", m.Path, m.Version)
+	}
+}
+
+// buildList returns the selected module versions other than the main
+// module, sorted by path.
+func (g *moduleGraph) buildList() []module.Version {
+	mods := make([]module.Version, 0, len(g.selected))
+	for _, p := range sortedKeys(g.selected) {
+		mods = append(mods, module.Version{Path: p, Version: g.selected[p]})
+	}
+
+	return mods
+}
+
+// resolvedRequirements returns the requirements of m at the versions
+// selected, leaving out the main module.
+func (g *moduleGraph) resolvedRequirements(m module.Version) []module.Version {
+	var reqs []module.Version
+
+	for _, r := range g.reqs[m] {
+		if r.Path != g.main.Path {
+			reqs = append(reqs, module.Version{Path: r.Path, Version: g.selected[r.Path]})
+		}
+	}
+
+	return reqs
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// graphProxy serves the go.mod files of a small module graph, in which
+// example.com/a is replaced by a fork and example.com/d has no go.mod.
+// The replace directive of example.com/lib only applies when it is the
+// main module.
+func graphProxy() http.Handler {
+	mods := map[string]string{
+		"/example.com/afork/@v/v1.0.1.mod": "module example.com/a\n\nrequire example.com/c v1.1.0\n",
+		"/example.com/a/@v/v1.0.0.mod":     "module example.com/a\n\nrequire example.com/unreplaced v1.0.0\n",
+		"/example.com/b/@v/v1.0.0.mod": "module example.com/b\n\nrequire (\n\texample.com/c v1.2.0\n" +
+			"\texample.com/x v1.0.0\n)\n",
+		"/example.com/c/@v/v1.1.0.mod":    "module example.com/c\n",
+		"/example.com/c/@v/v1.2.0.mod":    "module example.com/c\n\nrequire example.com/d v0.1.0\n",
+		"/example.com/x/@v/v1.0.0.mod":    "module example.com/x\n",
+		"/example.com/x/v2/@v/v2.0.0.mod": "module example.com/x/v2\n",
+		"/example.com/lib/@v/v1.0.0.mod": "module example.com/lib\n\nrequire example.com/b v1.0.0\n\n" +
+			"replace example.com/b => example.com/afork v1.0.1\n",
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if data, ok := mods[r.URL.Path]; ok {
+			_, _ = w.Write([]byte(data))
+
+			return
+		}
+
+		http.NotFound(w, r)
+	})
+}
+
+const graphGoMod = `module example.com/app
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.0.0
+	example.com/x/v2 v2.0.0
+)
+
+replace example.com/a v1.0.0 => example.com/afork v1.0.1
+`
+
+func TestBuildModuleGraph(t *testing.T) {
+	proxy, ts := newTestProxy(graphProxy())
+	defer ts.Close()
+
+	mf, err := modfile.Parse("go.mod", []byte(graphGoMod), nil)
+	mustf(t, err, "parse go.mod")
+
+	g, err := buildModuleGraph(context.Background(), proxy, &project{mod: mf})
+	mustf(t, err, "build graph")
+
+	if len(g.reqs) != 8 || len(g.selected) != 6 {
+		t.Errorf("graph has %d nodes and %d selected modules, want 8 and 6", len(g.reqs), len(g.selected))
+	}
+
+	if g.selected["example.com/c"] != "v1.2.0" {
+		t.Errorf("selected c %s, want v1.2.0", g.selected["example.com/c"])
+	}
+
+	if _, ok := g.selected["example.com/unreplaced"]; ok {
+		t.Error("the requirements of a replaced module were read from the original")
+	}
+
+	if _, ok := g.failed[module.Version{Path: "example.com/d", Version: "v0.1.0"}]; !ok || len(g.failed) != 1 {
+		t.Errorf("failed = %v, want example.com/d@v0.1.0", g.failed)
+	}
+
+	chain := formatChain(g.chain(func(m module.Version) bool { return m.Path == "example.com/d" }))
+	if chain != "example.com/app -> example.com/b@v1.0.0 -> example.com/c@v1.2.0 -> example.com/d@v0.1.0" {
+		t.Errorf("chain = %s", chain)
+	}
+
+	var requirers []string
+	for _, f := range g.requirers("example.com/c") {
+		requirers = append(requirers, f[0].String()+" "+f[1].Version)
+	}
+
+	if got := strings.Join(requirers, ", "); got != "example.com/a@v1.0.0 v1.1.0, example.com/b@v1.0.0 v1.2.0" {
+		t.Errorf("requirers = %s", got)
+	}
+
+	majors := g.majors()
+	if len(majors) != 1 || len(majors["example.com/x"]) != 2 {
+		t.Errorf("majors = %v", majors)
+	}
+}
+
+func TestModuleGraphs_Cache(t *testing.T) {
+	proxy, ts := newTestProxy(graphProxy())
+	defer ts.Close()
+
+	graphs := newModuleGraphs()
+
+	for i := range maxCachedGraphs + 1 {
+		mf, err := modfile.Parse("go.mod", []byte(graphGoMod+strings.Repeat("\n// pad", i)), nil)
+		mustf(t, err, "parse go.mod")
+
+		_, cached, err := graphs.get(context.Background(), proxy, &project{mod: mf})
+		mustf(t, err, "get graph")
+
+		if cached {
+			t.Errorf("graph %d was cached before it was built", i)
+		}
+	}
+
+	if len(graphs.graphs) != maxCachedGraphs || len(graphs.order) != maxCachedGraphs {
+		t.Errorf("kept %d graphs, want %d", len(graphs.graphs), maxCachedGraphs)
+	}
+}
+
+func TestBuildModuleGraph_Published(t *testing.T) {
+	proxy, ts := newTestProxy(graphProxy())
+	defer ts.Close()
+
+	proj, err := publishedProject(context.Background(), proxy, "example.com/lib", "v1.0.0")
+	mustf(t, err, "read published go.mod")
+
+	g, err := buildModuleGraph(context.Background(), proxy, proj)
+	mustf(t, err, "build graph")
+
+	if g.main.String() != "example.com/lib@v1.0.0" {
+		t.Errorf("main = %s", g.main)
+	}
+
+	if len(g.reqs) != 5 || g.selected["example.com/c"] != "v1.2.0" {
+		t.Errorf("graph has %d nodes and selects c %s, want 5 and v1.2.0; the replace was applied?",
+			len(g.reqs), g.selected["example.com/c"])
+	}
+
+	var sb strings.Builder
+
+	if formatGraphEdges(&sb, g, true) {
+		t.Error("edges were truncated")
+	}
+
+	if !strings.Contains(sb.String(), "  example.com/b@v1.0.0 example.com/c@v1.2.0\n") {
+		t.Errorf("unexpected edges:\n%s", sb.String())
+	}
+}
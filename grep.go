@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultGrepMaxMatches = 100
+	maxGrepContext        = 20
+	maxGrepLineWidth      = 300
+)
+
+// grepOptions bound a search: context lines around each match and the
+// number of matches reported per file and overall. Zero limits mean no
+// limit.
+type grepOptions struct {
+	before, after int
+	maxPerFile    int
+	maxMatches    int
+	generated     string
+	goOnly        bool
+}
+
+// grepResult is the outcome of a search over a module's files.
+type grepResult struct {
+	out       strings.Builder
+	matches   int // reported matches
+	files     int // files with a reported match
+	truncated bool
+	capped    []string // files whose matches were cut at maxPerFile
+}
+
+// grepModule searches the text files of mf under prefix for re, writing
+// matches as file:line:text like grep -n, context lines as file-line-text
+// and "--" between groups that are not adjacent.
+func grepModule(mf moduleFiles, prefix string, re *regexp.Regexp, opts grepOptions) (*grepResult, error) {
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+
+	sort.Strings(paths)
+
+	res := &grepResult{}
+
+	for _, p := range paths {
+		if opts.maxMatches > 0 && res.matches >= opts.maxMatches {
+			res.truncated = true
+
+			break
+		}
+
+		if opts.goOnly && !strings.HasSuffix(p, ".go") {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue // binary or unreadable
+		}
+
+		if generated, _ := generatedBy(p, src); !keepGenerated(opts.generated, generated) {
+			continue
+		}
+
+		grepFile(res, p, src, re, opts)
+	}
+
+	return res, nil
+}
+
+func grepFile(res *grepResult, p, src string, re *regexp.Regexp, opts grepOptions) {
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+
+	var hits []int
+
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+
+		if opts.maxPerFile > 0 && len(hits) == opts.maxPerFile {
+			res.capped = append(res.capped, p)
+
+			break
+		}
+
+		if opts.maxMatches > 0 && res.matches+len(hits) == opts.maxMatches {
+			res.truncated = true
+
+			break
+		}
+
+		hits = append(hits, i)
+	}
+
+	if len(hits) == 0 {
+		return
+	}
+
+	if res.files > 0 {
+		res.out.WriteString("--\n")
+	}
+
+	res.files++
+	res.matches += len(hits)
+
+	isHit := make(map[int]bool, len(hits))
+	for _, h := range hits {
+		isHit[h] = true
+	}
+
+	next := 0 // first line not written yet
+
+	for i, h := range hits {
+		start := max(h-opts.before, next)
+		if i > 0 && start > next {
+			res.out.WriteString("--\n")
+		}
+
+		end := min(h+opts.after, len(lines)-1)
+		if i+1 < len(hits) {
+			end = min(end, hits[i+1]-1)
+		}
+
+		for n := start; n <= end; n++ {
+			sep := "-"
+			if isHit[n] {
+				sep = ":"
+			}
+
+			fmt.Fprintf(&res.out, "%s%s%d%s%s\n", p, sep, n+1, sep, clipLine(lines[n]))
+		}
+
+		next = end + 1
+	}
+}
+
+// clipLine shortens very long lines, such as minified data, so one match
+// cannot flood the result.
+func clipLine(line string) string {
+	if len(line) <= maxGrepLineWidth {
+		return line
+	}
+
+	cut := maxGrepLineWidth
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+
+	return line[:cut] + fmt.Sprintf("... [%d more bytes]", len(line)-cut)
+}
+
+type grepInput struct {
+	Module     string `json:"module" jsonschema:"Go module path"`
+	Version    string `json:"version" jsonschema:"Module version or 'latest'"`
+	Pattern    string `json:"pattern" jsonschema:"Regular expression (RE2 syntax) matched against each line"`
+	Fixed      bool   `json:"fixed,omitempty" jsonschema:"Match the pattern as a literal string, like grep -F"`
+	IgnoreCase bool   `json:"ignore_case,omitempty" jsonschema:"Match regardless of case, like grep -i"`
+	GoOnly     bool   `json:"go_only,omitempty" jsonschema:"Search only .go files"`
+	Path       string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Context    int    `json:"context,omitempty" jsonschema:"Lines of context before and after each match, like grep -C"`
+	Before     int    `json:"before,omitempty" jsonschema:"Lines of context before each match (overrides context)"`
+	After      int    `json:"after,omitempty" jsonschema:"Lines of context after each match (overrides context)"`
+	MaxPerFile int    `json:"max_per_file,omitempty" jsonschema:"Maximum matches reported per file"`
+	MaxMatches int    `json:"max_matches,omitempty" jsonschema:"Maximum matches reported overall (default 100)"`
+	Generated  string `json:"generated,omitempty" jsonschema:"Generated files: include (default), exclude or only"`
+}
+
+func handleGrep(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input grepInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.Pattern == "" {
+		return errorResult("A pattern is required."), nil, nil
+	}
+
+	re, err := compileGrepPattern(input.Pattern, input.Fixed, input.IgnoreCase)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Invalid pattern: %v", err)), nil, nil
+	}
+
+	if err := checkGeneratedFilter(input.Generated); err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	opts := grepOptions{
+		before:     input.Context,
+		after:      input.Context,
+		maxPerFile: input.MaxPerFile,
+		maxMatches: input.MaxMatches,
+		generated:  input.Generated,
+		goOnly:     input.GoOnly,
+	}
+
+	if input.Before > 0 {
+		opts.before = input.Before
+	}
+
+	if input.After > 0 {
+		opts.after = input.After
+	}
+
+	if opts.before < 0 || opts.after < 0 || opts.before > maxGrepContext || opts.after > maxGrepContext {
+		return errorResult(fmt.Sprintf("Context lines must be between 0 and %d.", maxGrepContext)), nil, nil
+	}
+
+	if opts.maxMatches <= 0 {
+		opts.maxMatches = defaultGrepMaxMatches
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := grepModule(mf, input.Path, re, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.truncated || len(res.capped) > 0 {
+		noteTruncated(ctx)
+	}
+
+	return textResult(formatGrep(input.Module, version, input.Pattern, res, opts)), nil, nil
+}
+
+// compileGrepPattern compiles a search pattern, quoting it first when it
+// is a fixed string.
+func compileGrepPattern(pattern string, fixed, ignoreCase bool) (*regexp.Regexp, error) {
+	if fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+func formatGrep(module, version, pattern string, res *grepResult, opts grepOptions) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d matches for %q in %d files of %s@%s", res.matches, pattern, res.files, module, version)
+
+	if res.matches == 0 {
+		sb.WriteString(".\n")
+
+		return sb.String()
+	}
+
+	sb.WriteString(":\n\n")
+	sb.WriteString(res.out.String())
+
+	if len(res.capped) > 0 {
+		fmt.Fprintf(&sb, "\nMatches were capped at %d per file in %s.\n", opts.maxPerFile, strings.Join(res.capped, ", "))
+	}
+
+	if res.truncated {
+		fmt.Fprintf(&sb, "\nStopped after %d matches; narrow the pattern or path, or raise max_matches.\n",
+			opts.maxMatches)
+	}
+
+	return sb.String()
+}
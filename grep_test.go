@@ -0,0 +1,96 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGrepModule(t *testing.T) {
+	mf := testModuleFiles(t, "v1.0.0", map[string]string{
+		"go.mod": "module example.com/m\n",
+		"a.go":   "package m\n\n// Dial connects.\nfunc Dial() {}\n\nfunc x() {}\n\nfunc y() {}\n\nfunc Dial2() {}\n",
+		"b.go":   "package m\n\nfunc DialContext() {}\n",
+		"gen.go": "// Code generated by stringer. DO NOT EDIT.\n\npackage m\n\nfunc DialGen() {}\n",
+	})
+
+	re := regexp.MustCompile(`func Dial`)
+
+	res, err := grepModule(mf, "", re, grepOptions{before: 1, after: 1, generated: generatedExclude})
+	mustf(t, err, "grep")
+
+	want := "a.go-3-// Dial connects.\n" +
+		"a.go:4:func Dial() {}\n" +
+		"a.go-5-\n" +
+		"--\n" +
+		"a.go-9-\n" +
+		"a.go:10:func Dial2() {}\n" +
+		"--\n" +
+		"b.go-2-\n" +
+		"b.go:3:func DialContext() {}\n"
+
+	if got := res.out.String(); got != want || res.matches != 3 || res.files != 2 {
+		t.Errorf("got %d matches in %d files:\n%s\nwant:\n%s", res.matches, res.files, got, want)
+	}
+
+	res, err = grepModule(mf, "", re, grepOptions{maxPerFile: 1, maxMatches: 2})
+	mustf(t, err, "grep with limits")
+
+	text := formatGrep("example.com/m", "v1.0.0", "func Dial", res, grepOptions{maxPerFile: 1, maxMatches: 2})
+
+	for _, want := range []string{
+		"2 matches for \"func Dial\" in 2 files of example.com/m@v1.0.0:\n\na.go:4:func Dial() {}\n--\nb.go:3:",
+		"Matches were capped at 1 per file in a.go.",
+		"Stopped after 2 matches",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestGrepModes(t *testing.T) {
+	mf := testModuleFiles(t, "v1.0.0", map[string]string{
+		"go.mod":    "module example.com/m\n",
+		"m.go":      "package m\n\n// Deprecated: use New.\nvar Old = a.b(c)\n",
+		"README.md": "Old is deprecated: see a.b(c).\n",
+	})
+
+	for _, tc := range []struct {
+		pattern           string
+		fixed, ignoreCase bool
+		goOnly            bool
+		want              int
+	}{
+		{pattern: "deprecated", want: 1},
+		{pattern: "deprecated", ignoreCase: true, want: 2},
+		{pattern: "DEPRECATED", ignoreCase: true, goOnly: true, want: 1},
+		{pattern: "a.b(c)", fixed: true, want: 2},
+		{pattern: "a.b(c)", fixed: true, goOnly: true, want: 1},
+		{pattern: "a+b", fixed: true, want: 0},
+	} {
+		re, err := compileGrepPattern(tc.pattern, tc.fixed, tc.ignoreCase)
+		mustf(t, err, "compile %q", tc.pattern)
+
+		res, err := grepModule(mf, "", re, grepOptions{goOnly: tc.goOnly})
+		mustf(t, err, "grep %q", tc.pattern)
+
+		if res.matches != tc.want {
+			t.Errorf("%+v: %d matches, want %d:\n%s", tc, res.matches, tc.want, res.out.String())
+		}
+	}
+
+	if _, err := compileGrepPattern("a.b(c", false, false); err == nil {
+		t.Error("expected an error for an unbalanced regexp")
+	}
+}
+
+func TestClipLine(t *testing.T) {
+	line := strings.Repeat("x", maxGrepLineWidth-1) + "é" + strings.Repeat("y", 50)
+
+	got := clipLine(line)
+	if !strings.HasPrefix(got, strings.Repeat("x", maxGrepLineWidth-1)+"...") ||
+		!strings.HasSuffix(got, "[52 more bytes]") {
+		t.Errorf("clipLine = %q", got[maxGrepLineWidth-10:])
+	}
+}
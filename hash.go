@@ -0,0 +1,209 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// moduleHash computes the h1: dirhash of a module version's files, the
+// same value recorded in go.sum and the checksum database.
+func moduleHash(mf moduleFiles, module, version string) (string, error) {
+	files, err := mf.ListFiles("")
+	if err != nil {
+		return "", err
+	}
+
+	prefix := module + "@" + version + "/"
+	names := make([]string, len(files))
+
+	for i, f := range files {
+		names[i] = prefix + f
+	}
+
+	sum, err := dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return mf.Open(strings.TrimPrefix(name, prefix))
+	})
+	if err != nil {
+		return "", fmt.Errorf("compute module hash: %w", err)
+	}
+
+	return sum, nil
+}
+
+// goModHash computes the h1: hash of a module version's go.mod file, the
+// value recorded in go.sum on the line ending in /go.mod. Unlike in the
+// module hash, the file is named without a module@version prefix.
+func goModHash(data string) (string, error) {
+	sum, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(data)), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("compute go.mod hash: %w", err)
+	}
+
+	return sum, nil
+}
+
+// zipHash computes the h1: dirhash of a module zip, like dirhash.HashZip
+// but without writing the zip to a file first.
+func zipHash(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("open zip: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	names := make([]string, 0, len(zr.File))
+
+	for _, f := range zr.File {
+		files[f.Name] = f
+		names = append(names, f.Name)
+	}
+
+	sum, err := dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return files[name].Open()
+	})
+	if err != nil {
+		return "", fmt.Errorf("compute zip hash: %w", err)
+	}
+
+	return sum, nil
+}
+
+type hashInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter for the per-file digests"`
+}
+
+func handleHash(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input hashInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	modHash, err := moduleHash(mf, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	goMod, err := proxy.ReadMod(ctx, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	goModSum, err := goModHash(goMod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digests, err := fileDigests(mf, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(formatHashes(input.Module, version, input.Path, modHash, goModSum, digests)), nil, nil
+}
+
+func formatHashes(module, version, prefix, modHash, goModSum string, digests map[string]string) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Hashes of %s@%s, as go.sum lines:\n\n", module, version)
+	fmt.Fprintf(&sb, "%s %s %s\n", module, version, modHash)
+	fmt.Fprintf(&sb, "%s %s/go.mod %s\n", module, version, goModSum)
+
+	sb.WriteString("\nFile SHA-256 digests")
+
+	if prefix != "" {
+		fmt.Fprintf(&sb, " (prefix: %s)", prefix)
+	}
+
+	fmt.Fprintf(&sb, " (%d files):\n\n", len(digests))
+
+	if len(digests) == 0 {
+		return sb.String()
+	}
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "FILE\tSHA256")
+
+	for _, name := range sortedKeys(digests) {
+		fmt.Fprintf(tw, "%s\t%s\n", name, digests[name])
+	}
+
+	_ = tw.Flush()
+
+	return sb.String()
+}
+
+type verifyLocalInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Published module version or 'latest'"`
+	Dir     string `json:"dir,omitempty" jsonschema:"Local directory to verify (default: match in -local-dir)"`
+}
+
+func handleVerifyLocal(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, local *LocalReader, input verifyLocalInput,
+) (*mcp.CallToolResult, any, error) {
+	dir := input.Dir
+	if dir == "" {
+		dir = local.Dir(input.Module)
+	}
+
+	if dir == "" {
+		return errorResult(fmt.Sprintf(
+			"No local directory found for %q; pass dir explicitly.", input.Module,
+		)), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	published, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publishedHash, err := moduleHash(published, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	localHash, err := moduleHash(dirFiles{root: dir}, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	if localHash == publishedHash {
+		fmt.Fprintf(&sb, "YES: %s is identical to %s@%s.\n", dir, input.Module, version)
+	} else {
+		fmt.Fprintf(&sb, "NO: %s differs from %s@%s. Use gomod_compare_local to see which files.\n",
+			dir, input.Module, version)
+	}
+
+	fmt.Fprintf(&sb, "\nPublished: %s\nLocal:     %s\n", publishedHash, localHash)
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestModuleHash_ZipAndDirAgree(t *testing.T) {
+	files := map[string]string{
+		"go.mod":      "module example.com/m\n",
+		"main.go":     "package main\n",
+		"lib/util.go": "package lib\n",
+	}
+
+	entry, err := NewZipCache().Put("example.com/m", "v1.0.0", createTestZip(t, "example.com/m@v1.0.0/", files))
+
+	mustf(t, err, "put zip in cache")
+
+	dir := t.TempDir()
+
+	writeTree(t, dir, files)
+
+	zipHash, err := moduleHash(zipFiles{entry}, "example.com/m", "v1.0.0")
+
+	mustf(t, err, "hash zip")
+
+	dirHash, err := moduleHash(dirFiles{root: dir}, "example.com/m", "v1.0.0")
+
+	mustf(t, err, "hash dir")
+
+	if zipHash != dirHash {
+		t.Errorf("zip hash %s != dir hash %s", zipHash, dirHash)
+	}
+
+	writeTree(t, dir, map[string]string{"main.go": "package main // changed\n"})
+
+	changed, err := moduleHash(dirFiles{root: dir}, "example.com/m", "v1.0.0")
+
+	mustf(t, err, "hash changed dir")
+
+	if changed == zipHash {
+		t.Error("expected hash to change after modifying a file")
+	}
+}
+
+func TestGoModHash(t *testing.T) {
+	// From this repository's go.sum.
+	sum, err := goModHash("module github.com/yosida95/uritemplate/v3\n\ngo 1.14\n")
+
+	mustf(t, err, "hash go.mod")
+
+	if want := "h1:ILOh0sOhIJR3+L/8afwt/kE++YT040gmv5BQTMR2HP4="; sum != want {
+		t.Errorf("goModHash = %s, want %s", sum, want)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// newHTTPClient returns the client for all outbound requests: the module
+// proxy, the checksum database, vanity import pages and the metadata APIs.
+// Requests go through the proxy named by HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY. When caBundle names a PEM file, its certificates are trusted
+// in addition to the system roots, as an egress proxy that inspects TLS
+// needs.
+func newHTTPClient(caBundle string) (*http.Client, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected default HTTP transport")
+	}
+
+	transport = transport.Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if caBundle != "" {
+		pool, err := loadCABundle(caBundle)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// loadCABundle returns the system roots with the certificates of the PEM
+// file added.
+func loadCABundle(name string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s: no PEM certificates found", name)
+	}
+
+	return pool, nil
+}
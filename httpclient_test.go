@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHTTPClient_CABundle(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	mustf(t, os.WriteFile(bundle, certPEM, 0o600), "write CA bundle")
+
+	client, err := newHTTPClient(bundle)
+	mustf(t, err, "new client")
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("request with the bundle: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	client, err = newHTTPClient("")
+	mustf(t, err, "new client")
+
+	if transport, ok := client.Transport.(*http.Transport); !ok || transport.Proxy == nil {
+		t.Error("outbound requests should use the proxy from the environment")
+	}
+
+	if resp, err := client.Get(ts.URL); err == nil {
+		_ = resp.Body.Close()
+
+		t.Error("request without the bundle should fail certificate verification")
+	}
+}
+
+func TestNewHTTPClient_BadBundle(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	mustf(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600), "write CA bundle")
+
+	for _, name := range []string{filepath.Join(dir, "missing.pem"), notPEM} {
+		if _, err := newHTTPClient(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+)
+
+// depHygiene is the retraction and deprecation status of one dependency.
+type depHygiene struct {
+	dep        dependency
+	retracted  *modfile.Retract
+	deprecated string
+	suggestion string // replacement module@version, if any
+	err        error
+}
+
+// checkDependency looks up the dependency's latest go.mod and reports
+// whether the used version is retracted or the module deprecated. For a
+// retracted version the latest non-retracted version is suggested; for a
+// deprecated module, the successor named in the notice, at its latest
+// version.
+func checkDependency(ctx context.Context, proxy *ProxyClient, dep dependency) depHygiene {
+	h := depHygiene{dep: dep}
+
+	_, mf, err := latestModFile(ctx, proxy, dep.Path)
+	if err != nil {
+		h.err = err
+
+		return h
+	}
+
+	h.retracted = retraction(mf, dep.Version.Version)
+	h.deprecated = moduleDeprecation(mf)
+
+	switch {
+	case h.deprecated != "":
+		if successor := successorFromDeprecation(h.deprecated, dep.Path); successor != "" {
+			if latest, err := proxy.ResolveLatest(ctx, successor); err == nil {
+				h.suggestion = successor + "@" + latest
+			}
+		}
+	case h.retracted != nil:
+		versions, err := proxy.ListVersions(ctx, dep.Path)
+		if err != nil {
+			h.err = fmt.Errorf("list versions: %w", err)
+
+			return h
+		}
+
+		if v := latestAllowed(versions, mf); v != "" {
+			h.suggestion = dep.Path + "@" + v
+		}
+	}
+
+	return h
+}
+
+func formatHygiene(sb *strings.Builder, results []depHygiene) {
+	var retracted, deprecated, failed []depHygiene
+
+	for _, h := range results {
+		switch {
+		case h.err != nil:
+			failed = append(failed, h)
+		case h.deprecated != "":
+			deprecated = append(deprecated, h)
+		}
+
+		if h.err == nil && h.retracted != nil {
+			retracted = append(retracted, h)
+		}
+	}
+
+	if len(retracted) == 0 && len(deprecated) == 0 {
+		sb.WriteString("\nNo retracted or deprecated dependencies found.\n")
+	}
+
+	sections := []struct {
+		title   string
+		results []depHygiene
+		detail  func(h depHygiene) string
+	}{
+		{"Retracted versions", retracted, func(h depHygiene) string {
+			detail := "retracted " + formatVersionInterval(h.retracted.Low, h.retracted.High)
+			if h.retracted.Rationale != "" {
+				detail += ": " + h.retracted.Rationale
+			}
+
+			return detail
+		}},
+		{"Deprecated modules", deprecated, func(h depHygiene) string { return h.deprecated }},
+		{"Errors", failed, func(h depHygiene) string { return h.err.Error() }},
+	}
+
+	for _, s := range sections {
+		if len(s.results) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(sb, "\n%s (%d):\n", s.title, len(s.results))
+
+		for _, h := range s.results {
+			indirect := ""
+			if h.dep.indirect {
+				indirect = " (indirect)"
+			}
+
+			fmt.Fprintf(sb, "  %s%s: %s\n", h.dep.Version, indirect, s.detail(h))
+
+			if h.suggestion != "" && h.err == nil {
+				fmt.Fprintf(sb, "    suggested: %s\n", h.suggestion)
+			}
+		}
+	}
+}
+
+type hygieneInput struct {
+	Dir   string `json:"dir,omitempty" jsonschema:"Project directory with go.mod and go.sum (default: bound project)"`
+	GoMod string `json:"go_mod,omitempty" jsonschema:"go.mod content, instead of dir"`
+	GoSum string `json:"go_sum,omitempty" jsonschema:"go.sum content, used when neither dir nor go_mod is given"`
+}
+
+func handleHygiene(
+	ctx context.Context, proxy *ProxyClient, binding *projectBinding, input hygieneInput,
+) (*mcp.CallToolResult, any, error) {
+	proj, err := projectFromInput(binding, input.Dir, input.GoMod, input.GoSum)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	deps := proj.dependencies()
+	results := make([]depHygiene, len(deps))
+
+	parallelEach(deps, func(i int, dep dependency) {
+		results[i] = checkDependency(ctx, proxy, dep)
+	})
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Checked %d dependencies", len(deps))
+
+	if name := proj.modulePath(); name != "" {
+		fmt.Fprintf(&sb, " of %s", name)
+	}
+
+	sb.WriteString(" for retractions and deprecations.\n")
+
+	formatHygiene(&sb, results)
+
+	return textResult(sb.String()), nil, nil
+}
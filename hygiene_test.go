@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// hygieneProxy serves a retracted module, a deprecated module and its
+// successor.
+func hygieneProxy() http.Handler {
+	files := map[string]string{
+		"/example.com/a/@v/list":          "v1.0.0\nv1.1.0\nv1.2.0\n",
+		"/example.com/a/@latest":          `{"Version":"v1.2.0"}`,
+		"/example.com/a/@v/v1.2.0.mod":    "module example.com/a\n\nretract v1.1.0 // Data race.\n",
+		"/example.com/old/@latest":        `{"Version":"v0.9.0"}`,
+		"/example.com/old/@v/v0.9.0.mod":  "// Deprecated: use example.com/new instead.\nmodule example.com/old\n",
+		"/example.com/new/@latest":        `{"Version":"v2.0.0"}`,
+		"/example.com/fine/@latest":       `{"Version":"v1.0.0"}`,
+		"/example.com/fine/@v/v1.0.0.mod": "module example.com/fine\n",
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestHandleHygiene(t *testing.T) {
+	proxy, ts := newTestProxy(hygieneProxy())
+	defer ts.Close()
+
+	goMod := `module example.com/app
+
+require (
+	example.com/a v1.1.0
+	example.com/old v0.9.0 // indirect
+	example.com/fine v1.0.0
+	example.com/missing v1.0.0
+)
+`
+
+	result, _, err := handleHygiene(context.Background(), proxy, nil, hygieneInput{GoMod: goMod})
+	mustf(t, err, "hygiene")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	for _, want := range []string{
+		"Checked 4 dependencies of example.com/app",
+		"Retracted versions (1):\n  example.com/a@v1.1.0: retracted v1.1.0: Data race.\n    suggested: example.com/a@v1.2.0",
+		"Deprecated modules (1):\n  example.com/old@v0.9.0 (indirect): use example.com/new instead.\n" +
+			"    suggested: example.com/new@v2.0.0",
+		"Errors (1):\n  example.com/missing@v1.0.0:",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "example.com/fine") {
+		t.Errorf("healthy dependency reported:\n%s", text)
+	}
+}
+
+func TestHandleHygiene_Clean(t *testing.T) {
+	proxy, ts := newTestProxy(hygieneProxy())
+	defer ts.Close()
+
+	result, _, err := handleHygiene(context.Background(), proxy, nil, hygieneInput{
+		GoMod: "module example.com/app\n\nrequire example.com/fine v1.0.0\n",
+	})
+	mustf(t, err, "hygiene")
+
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "No retracted or deprecated") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/parser"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+)
+
+// isStandardImport reports whether an import path is in the standard
+// library, by the go command's rule: its first element has no dot. The
+// cgo pseudo-package C counts as standard.
+func isStandardImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+
+	return !strings.Contains(first, ".")
+}
+
+// requiredModule returns the requirement in goMod that provides
+// importPath: the one with the longest matching module path.
+func requiredModule(goMod *modfile.File, importPath string) *modfile.Require {
+	var found *modfile.Require
+
+	for _, r := range goMod.Require {
+		if inModule(importPath, r.Mod.Path) && (found == nil || len(r.Mod.Path) > len(found.Mod.Path)) {
+			found = r
+		}
+	}
+
+	return found
+}
+
+// packageImports are the imports of a package's files, each counted once.
+type packageImports struct {
+	files    int
+	imports  []string // of the non-test files, sorted
+	testOnly []string // of the test files alone, sorted
+}
+
+// collectImports reads the imports of the Go files at p, a module-relative
+// .go file or package directory ("." for the root). A directory's test
+// files count only with tests.
+func collectImports(mf moduleFiles, p string, tests bool) (*packageImports, error) {
+	file := ""
+
+	dir := p
+	if path.Ext(p) == ".go" {
+		file, dir = p, path.Dir(p)
+	}
+
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+
+	_, files, err := parseGoFiles(mf, prefix, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res      = &packageImports{}
+		code     = map[string]bool{}
+		testCode = map[string]bool{}
+	)
+
+	for _, f := range files {
+		isTest := strings.HasSuffix(f.path, "_test.go")
+
+		switch {
+		case file != "" && f.path != file:
+			continue
+		case file == "" && (path.Dir(f.path) != dir || (isTest && !tests)):
+			continue
+		}
+
+		res.files++
+
+		for _, spec := range f.ast.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			if isTest {
+				testCode[importPath] = true
+			} else {
+				code[importPath] = true
+			}
+		}
+	}
+
+	res.imports = sortedKeys(code)
+
+	for _, importPath := range sortedKeys(testCode) {
+		if !code[importPath] {
+			res.testOnly = append(res.testOnly, importPath)
+		}
+	}
+
+	return res, nil
+}
+
+// formatImports groups imports into the standard library, the module's
+// own packages and other modules, the latter with the requirement of
+// goMod that provides them.
+func formatImports(sb *strings.Builder, title, mod string, goMod *modfile.File, imports []string) {
+	if len(imports) == 0 {
+		return
+	}
+
+	var std, own, external []string
+
+	for _, importPath := range imports {
+		switch {
+		case isStandardImport(importPath):
+			std = append(std, importPath)
+		case inModule(importPath, mod):
+			own = append(own, importPath)
+		default:
+			line := importPath
+
+			if goMod != nil {
+				if r := requiredModule(goMod, importPath); r != nil {
+					line += fmt.Sprintf("  (%s %s)", r.Mod.Path, r.Mod.Version)
+				} else {
+					line += "  (no requirement in go.mod)"
+				}
+			}
+
+			external = append(external, line)
+		}
+	}
+
+	for _, group := range []struct {
+		name  string
+		paths []string
+	}{
+		{"standard library", std},
+		{"module " + mod, own},
+		{"other modules", external},
+	} {
+		if len(group.paths) > 0 {
+			fmt.Fprintf(sb, "\n%s, %s (%d):\n  %s\n", title, group.name, len(group.paths),
+				strings.Join(group.paths, "\n  "))
+		}
+	}
+}
+
+type importsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Package directory or .go file (default: module root)"`
+	Tests   bool   `json:"tests,omitempty" jsonschema:"Also list the imports of the package's _test.go files"`
+}
+
+func handleImports(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input importsInput,
+) (*mcp.CallToolResult, any, error) {
+	p := "."
+	if input.Path != "" {
+		p = strings.TrimPrefix(packageImportPath(input.Module, input.Path), input.Module)
+		p = cmp.Or(strings.TrimPrefix(p, "/"), ".")
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := collectImports(mf, p, input.Tests)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.files == 0 {
+		return errorResult(fmt.Sprintf("No Go files at %s in %s@%s.", p, input.Module, version)), nil, nil
+	}
+
+	var goMod *modfile.File
+
+	if data, err := mf.ReadFile("go.mod"); err == nil {
+		goMod, _ = modfile.ParseLax("go.mod", []byte(data), nil)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Imports of %s in %s@%s: %d, from %d files.\n",
+		p, input.Module, version, len(res.imports)+len(res.testOnly), res.files)
+
+	formatImports(&sb, "Imports", input.Module, goMod, res.imports)
+	formatImports(&sb, "Test-only imports", input.Module, goMod, res.testOnly)
+
+	return textResult(sb.String()), nil, nil
+}
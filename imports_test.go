@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestIsStandardImport(t *testing.T) {
+	for importPath, want := range map[string]bool{
+		"fmt":                     true,
+		"net/http":                true,
+		"C":                       true,
+		"golang.org/x/mod":        false,
+		"example.com/m/internal":  false,
+		"gopkg.in/yaml.v3/nested": false,
+	} {
+		if got := isStandardImport(importPath); got != want {
+			t.Errorf("isStandardImport(%q) = %v, want %v", importPath, got, want)
+		}
+	}
+}
+
+func TestRequiredModule(t *testing.T) {
+	goMod, err := modfile.ParseLax("go.mod", []byte("module m\n\nrequire (\n\tgithub.com/a/b v1.0.0\n"+
+		"\tgithub.com/a/b/c v0.1.0\n)\n"), nil)
+	mustf(t, err, "parse go.mod")
+
+	for importPath, want := range map[string]string{
+		"github.com/a/b":       "github.com/a/b",
+		"github.com/a/b/x":     "github.com/a/b",
+		"github.com/a/b/c/d":   "github.com/a/b/c",
+		"github.com/a/bb":      "",
+		"golang.org/x/mod/zip": "",
+	} {
+		got := ""
+		if r := requiredModule(goMod, importPath); r != nil {
+			got = r.Mod.Path
+		}
+
+		if got != want {
+			t.Errorf("requiredModule(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Reference license texts, one file per SPDX identifier.
+//
+//go:embed licenses/*.txt
+var licenseTexts embed.FS
+
+const (
+	// minLicenseConfidence is the share of a reference text that must be
+	// present in a file for the license to be reported.
+	minLicenseConfidence = 0.8
+	// lowLicenseCoverage flags files with a significant amount of text not
+	// explained by the detected licenses.
+	lowLicenseCoverage = 0.9
+)
+
+var (
+	licenseFileRe = regexp.MustCompile(`(?i)^(licen[cs]e|copying|unlicense)([-._][a-z0-9.\-]*)?$`)
+	licenseWordRe = regexp.MustCompile(`[a-z0-9]+`)
+	dualLicenseRe = regexp.MustCompile(`(?i)dual[- ]licen[cs]ed|licen[cs]ed under either`)
+)
+
+// referenceLicense is a known license text reduced to its word trigrams.
+type referenceLicense struct {
+	id    string
+	grams map[string]bool
+}
+
+var referenceLicenses = sync.OnceValue(func() []referenceLicense {
+	entries, err := licenseTexts.ReadDir("licenses")
+	if err != nil {
+		panic(err)
+	}
+
+	refs := make([]referenceLicense, 0, len(entries))
+
+	for _, e := range entries {
+		data, err := licenseTexts.ReadFile("licenses/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+
+		words := licenseWords(string(data))
+		grams := make(map[string]bool, len(words))
+
+		for i := 0; i+2 < len(words); i++ {
+			grams[trigram(words, i)] = true
+		}
+
+		refs = append(refs, referenceLicense{id: strings.TrimSuffix(e.Name(), ".txt"), grams: grams})
+	}
+
+	return refs
+})
+
+// licenseWords returns the lowercased words of a license text. Copyright
+// lines are dropped since they differ in every copy of a license.
+func licenseWords(text string) []string {
+	var words []string
+
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		trimmed := strings.TrimLeft(line, " \t#/*-")
+		if strings.HasPrefix(trimmed, "copyright") || strings.HasPrefix(trimmed, "(c)") ||
+			strings.HasPrefix(trimmed, "©") {
+			continue
+		}
+
+		words = append(words, licenseWordRe.FindAllString(line, -1)...)
+	}
+
+	return words
+}
+
+func trigram(words []string, i int) string {
+	return words[i] + " " + words[i+1] + " " + words[i+2]
+}
+
+// licenseMatch is a reference license found in a file.
+type licenseMatch struct {
+	id         string
+	confidence float64 // share of the reference text present in the file
+}
+
+// detectLicenses matches text against the reference licenses. Licenses are
+// picked greedily by the number of matched trigrams minus the number of
+// missing ones, so a text containing BSD-3-Clause is not reported as the
+// shorter BSD-2-Clause, and each pick only counts text not claimed by an
+// earlier one, so concatenated licenses are all found. It also returns the
+// coverage: the share of the file's words explained by the matches.
+func detectLicenses(text string) ([]licenseMatch, float64) {
+	words := licenseWords(text)
+	if len(words) < 3 {
+		return nil, 0
+	}
+
+	grams := make([]string, len(words)-2)
+	for i := range grams {
+		grams[i] = trigram(words, i)
+	}
+
+	covered := make([]bool, len(words))
+	used := make(map[string]bool)
+
+	var matches []licenseMatch
+
+	for {
+		var (
+			best      *referenceLicense
+			bestScore int
+			bestConf  float64
+		)
+
+		refs := referenceLicenses()
+
+		for i := range refs {
+			ref := &refs[i]
+			if used[ref.id] {
+				continue
+			}
+
+			found := make(map[string]bool)
+
+			for j, g := range grams {
+				if !covered[j] && ref.grams[g] {
+					found[g] = true
+				}
+			}
+
+			conf := float64(len(found)) / float64(len(ref.grams))
+			score := 2*len(found) - len(ref.grams)
+
+			if conf >= minLicenseConfidence && score > bestScore {
+				best, bestScore, bestConf = ref, score, conf
+			}
+		}
+
+		if best == nil {
+			break
+		}
+
+		used[best.id] = true
+		matches = append(matches, licenseMatch{id: best.id, confidence: bestConf})
+
+		for j, g := range grams {
+			if !covered[j] && best.grams[g] {
+				covered[j], covered[j+1], covered[j+2] = true, true, true
+			}
+		}
+	}
+
+	n := 0
+
+	for _, c := range covered {
+		if c {
+			n++
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].id < matches[j].id })
+
+	return matches, float64(n) / float64(len(words))
+}
+
+// licenseExpression joins SPDX expressions with op ("AND" or "OR"),
+// parenthesizing compound operands.
+func licenseExpression(exprs []string, op string) string {
+	seen := make(map[string]bool)
+	parts := make([]string, 0, len(exprs))
+
+	for _, e := range exprs {
+		if seen[e] {
+			continue
+		}
+
+		seen[e] = true
+
+		if len(exprs) > 1 && strings.Contains(e, " ") {
+			e = "(" + e + ")"
+		}
+
+		parts = append(parts, e)
+	}
+
+	return strings.Join(parts, " "+op+" ")
+}
+
+func isLicenseFile(p string) bool {
+	return !isGoSource(p) && licenseFileRe.MatchString(path.Base(p))
+}
+
+// licenseFile is the detection result for one license file.
+type licenseFile struct {
+	path     string
+	matches  []licenseMatch
+	coverage float64
+	expr     string
+	dual     bool // the text says the licenses are alternatives
+}
+
+func detectLicenseFile(p, text string) *licenseFile {
+	matches, coverage := detectLicenses(text)
+	lf := &licenseFile{path: p, matches: matches, coverage: coverage, dual: dualLicenseRe.MatchString(text)}
+
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.id
+	}
+
+	op := "AND"
+	if lf.dual {
+		op = "OR"
+	}
+
+	lf.expr = licenseExpression(ids, op)
+
+	return lf
+}
+
+// moduleLicense combines the license files at the module root. Several
+// files are alternatives when one of them says so or when they follow the
+// LICENSE-<ID> naming used for dual licensing; otherwise all apply.
+func moduleLicense(files []*licenseFile) string {
+	var (
+		exprs   []string
+		dual    = len(files) > 1
+		anyDual bool
+	)
+
+	for _, f := range files {
+		if f.expr == "" {
+			continue
+		}
+
+		exprs = append(exprs, f.expr)
+		anyDual = anyDual || f.dual
+
+		if !strings.ContainsAny(path.Base(f.path), "-_") {
+			dual = false
+		}
+	}
+
+	if dual || anyDual {
+		return licenseExpression(exprs, "OR")
+	}
+
+	return licenseExpression(exprs, "AND")
+}
+
+type licensesInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Text    bool   `json:"text,omitempty" jsonschema:"Also return the text of the license files at the module root"`
+}
+
+func handleLicenses(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input licensesInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, root, err := findLicenses(mf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text := formatLicenses(input.Module, version, moduleLicense(root), files)
+
+	if input.Text {
+		for _, f := range root {
+			content, err := mf.ReadFile(f.path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("read %s: %w", f.path, err)
+			}
+
+			text += fmt.Sprintf("\n%s (%s):\n\n%s\n", f.path, cmp.Or(f.expr, "unknown"), strings.TrimRight(content, "\n"))
+		}
+	}
+
+	return textResult(text), nil, nil
+}
+
+// findLicenses detects the license files of a module, sorted by path, and
+// returns them along with those at the module root.
+func findLicenses(mf moduleFiles) ([]*licenseFile, []*licenseFile, error) {
+	paths, err := mf.ListFiles("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(paths)
+
+	var files, root []*licenseFile
+
+	for _, p := range paths {
+		if !isLicenseFile(p) {
+			continue
+		}
+
+		text, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		lf := detectLicenseFile(p, text)
+		files = append(files, lf)
+
+		if !strings.Contains(p, "/") {
+			root = append(root, lf)
+		}
+	}
+
+	return files, root, nil
+}
+
+func formatLicenses(module, version, expr string, files []*licenseFile) string {
+	var sb strings.Builder
+
+	if expr == "" {
+		expr = "unknown"
+	}
+
+	fmt.Fprintf(&sb, "License of %s@%s: %s\n", module, version, expr)
+
+	if len(files) == 0 {
+		sb.WriteString("\nNo license files found.\n")
+
+		return sb.String()
+	}
+
+	sb.WriteString("\n")
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tLICENSE\tCOVERAGE\tCONFIDENCE")
+
+	lowCoverage := false
+
+	for _, f := range files {
+		fileExpr := f.expr
+		if fileExpr == "" {
+			fileExpr = "unknown"
+		}
+
+		confidences := make([]string, len(f.matches))
+		for i, m := range f.matches {
+			confidences[i] = fmt.Sprintf("%s %.1f%%", m.id, 100*m.confidence)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%\t%s\n", f.path, fileExpr, 100*f.coverage, strings.Join(confidences, ", "))
+
+		lowCoverage = lowCoverage || f.coverage < lowLicenseCoverage
+	}
+
+	_ = tw.Flush()
+
+	if lowCoverage {
+		fmt.Fprintf(&sb, "\nFiles with coverage below %.0f%% contain text the detected licenses do not explain "+
+			"(additional terms or an unrecognized license); read them before relying on the result.\n",
+			100*lowLicenseCoverage)
+	}
+
+	return sb.String()
+}
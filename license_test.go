@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func readReferenceLicense(t *testing.T, id string) string {
+	t.Helper()
+
+	data, err := licenseTexts.ReadFile("licenses/" + id + ".txt")
+	mustf(t, err, "read reference %s", id)
+
+	return string(data)
+}
+
+func TestDetectLicenses(t *testing.T) {
+	mit := "Copyright (c) 2024 Jane Doe\n\n" + readReferenceLicense(t, "MIT")
+	bsd3 := strings.Replace(readReferenceLicense(t, "BSD-3-Clause"), "<owner>", "Acme", 1)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+		full bool // expect coverage of at least lowLicenseCoverage
+	}{
+		{"mit", mit, "MIT", true},
+		{"bsd2", readReferenceLicense(t, "BSD-2-Clause"), "BSD-2-Clause", true},
+		{"bsd3 is not bsd2", bsd3, "BSD-3-Clause", true},
+		{"concatenated", mit + "\n\n" + readReferenceLicense(t, "Apache-2.0"), "Apache-2.0 AND MIT", true},
+		{
+			"dual licensed",
+			"This project is dual licensed under MIT or Apache-2.0.\n\n" + mit + "\n" +
+				readReferenceLicense(t, "Apache-2.0"),
+			"Apache-2.0 OR MIT", true,
+		},
+		{"extra terms", mit + strings.Repeat("\nYou must also sing a song about each bug you find.", 10), "MIT", false},
+		{"unknown", "All rights reserved. Do not copy this software.", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := detectLicenseFile("LICENSE", tt.text)
+
+			if lf.expr != tt.want {
+				t.Errorf("expr = %q, want %q (matches %+v)", lf.expr, tt.want, lf.matches)
+			}
+
+			if full := lf.coverage >= lowLicenseCoverage; full != tt.full {
+				t.Errorf("coverage = %.3f, want full=%v", lf.coverage, tt.full)
+			}
+
+			for _, m := range lf.matches {
+				if m.confidence < minLicenseConfidence || m.confidence > 1 {
+					t.Errorf("%s confidence = %.3f", m.id, m.confidence)
+				}
+			}
+		})
+	}
+}
+
+func TestModuleLicense(t *testing.T) {
+	file := func(p, expr string) *licenseFile { return &licenseFile{path: p, expr: expr} }
+
+	tests := []struct {
+		name  string
+		files []*licenseFile
+		want  string
+	}{
+		{"none", nil, ""},
+		{"single", []*licenseFile{file("LICENSE", "MIT")}, "MIT"},
+		{"dual files", []*licenseFile{file("LICENSE-APACHE", "Apache-2.0"), file("LICENSE-MIT", "MIT")}, "Apache-2.0 OR MIT"},
+		{"separate terms", []*licenseFile{file("LICENSE", "MIT"), file("COPYING", "GPL-3.0")}, "MIT AND GPL-3.0"},
+		{
+			"dual file text",
+			[]*licenseFile{{path: "LICENSE", expr: "MIT", dual: true}, file("COPYING", "GPL-3.0")},
+			"MIT OR GPL-3.0",
+		},
+		{"compound", []*licenseFile{file("LICENSE", "MIT OR ISC"), file("COPYING", "GPL-3.0")}, "(MIT OR ISC) AND GPL-3.0"},
+		{"unknown skipped", []*licenseFile{file("LICENSE", "MIT"), file("LICENSE.txt", "")}, "MIT"},
+	}
+
+	for _, tt := range tests {
+		if got := moduleLicense(tt.files); got != tt.want {
+			t.Errorf("%s: moduleLicense = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	for p, want := range map[string]bool{
+		"LICENSE":              true,
+		"LICENSE.md":           true,
+		"LICENSE-APACHE":       true,
+		"licence.txt":          true,
+		"COPYING":              true,
+		"UNLICENSE":            true,
+		"vendor/x/LICENSE":     true,
+		"license.go":           false,
+		"LICENSE_test.go":      false,
+		"licenses/MIT.txt":     false,
+		"docs/licensing.md":    false,
+		"internal/licenses.go": false,
+	} {
+		if got := isLicenseFile(p); got != want {
+			t.Errorf("isLicenseFile(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // LocalReader checks for local copies of modules in a base directory.
 type LocalReader struct {
+	mu      sync.RWMutex
 	baseDir string
 }
 
@@ -16,15 +18,34 @@ func NewLocalReader(baseDir string) *LocalReader {
 	return &LocalReader{baseDir: baseDir}
 }
 
+// SetBaseDir changes the base directory, as on a config reload.
+func (r *LocalReader) SetBaseDir(dir string) {
+	r.mu.Lock()
+	r.baseDir = dir
+	r.mu.Unlock()
+}
+
+// Dir returns the local directory matching the module's last path
+// segment, or empty string if there is none.
+func (r *LocalReader) Dir(module string) string {
+	r.mu.RLock()
+	dir := filepath.Join(r.baseDir, lastPathSegment(module))
+	r.mu.RUnlock()
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return ""
+	}
+
+	return dir
+}
+
 // Suggest checks if a local directory exists for the given module path
 // and returns a suggestion string pointing to it. Returns empty string
 // if no local directory is found.
 func (r *LocalReader) Suggest(module string) string {
-	name := lastPathSegment(module)
-	dir := filepath.Join(r.baseDir, name)
-
-	info, err := os.Stat(dir)
-	if err != nil || !info.IsDir() {
+	dir := r.Dir(module)
+	if dir == "" {
 		return ""
 	}
 
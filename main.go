@@ -1,47 +1,318 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func main() {
+// serverFlags are the flags of the MCP server, shared with print-config.
+type serverFlags struct {
+	localDir      string
+	goProxyAddr   string
+	watchInterval time.Duration
+	metadataTTL   time.Duration
+	pollJitter    float64
+	govulncheck   string
+	offline       bool
+	allowModules  string
+	denyModules   string
+	auditLog      string
+	moduleAliases string
+	outputFormat  string
+	writeModCache bool
+	configFile    string
+	httpAddr      string
+	idleTimeout   time.Duration
+	keepAlive     time.Duration
+	prefetch      string
+	cacheDir      string
+	caBundle      string
+	perfLog       time.Duration
+
+	includePrerelease bool
+	verifyGoSum       string
+}
+
+func registerServerFlags(fs *flag.FlagSet) *serverFlags {
 	homeDir, _ := os.UserHomeDir()
-	defaultLocalDir := filepath.Join(homeDir, "Projects")
 
-	localDir := flag.String("local-dir", defaultLocalDir, "Base directory for local module fallback")
+	f := &serverFlags{}
+
+	fs.StringVar(&f.localDir, "local-dir", filepath.Join(homeDir, "Projects"), "Base directory for local module fallback")
+	fs.StringVar(&f.goProxyAddr, "goproxy-addr", "", "Also serve the GOPROXY protocol on this address")
+	fs.DurationVar(&f.watchInterval, "watch-interval", defaultWatchInterval, "Polling interval for watched modules")
+	fs.DurationVar(&f.metadataTTL, "metadata-ttl", defaultMetadataTTL, "How long version lists and @latest are cached")
+	fs.Float64Var(&f.pollJitter, "poll-jitter", defaultPollJitter, "Random jitter applied to polling intervals (fraction)")
+	fs.StringVar(&f.govulncheck, "govulncheck", defaultGovulncheck, "govulncheck binary used by gomod_govulncheck")
+	fs.BoolVar(&f.offline, "offline", false, "Disable network access and pause background polling")
+	fs.StringVar(&f.allowModules, "allow-modules", "", "Comma-separated module path globs that may be fetched or served")
+	fs.StringVar(&f.denyModules, "deny-modules", "", "Comma-separated module path globs that are never fetched or served")
+	fs.StringVar(&f.auditLog, "audit-log", "", "Append a JSON line per tool call to this file")
+	fs.StringVar(&f.moduleAliases, "module-aliases", "", "Comma-separated name=module/path aliases for module arguments")
+	fs.StringVar(&f.outputFormat, "output-format", outputPlain, "Default tool output format: plain or markdown")
+	fs.BoolVar(&f.writeModCache, "write-modcache", false, "Store downloaded module zips in GOMODCACHE for the go command")
+	fs.StringVar(&f.configFile, "config", "", "Read flags from this file, one name = value per line; reloaded on change")
+	fs.StringVar(&f.httpAddr, "http-addr", "", "Serve MCP over streamable HTTP on this address instead of stdio")
+	fs.DurationVar(&f.idleTimeout, "idle-timeout", 0, "With -http-addr, exit after this long without sessions (0 never)")
+	fs.DurationVar(&f.keepAlive, "keepalive", 0, "Ping sessions at this interval and close those that do not answer")
+	fs.StringVar(&f.cacheDir, "cache-dir", "", "Keep downloaded zips and go.mod files in this directory across restarts")
+	fs.StringVar(&f.caBundle, "ca-bundle", "", "PEM file of extra root certificates to trust for outbound HTTPS")
+	fs.DurationVar(&f.perfLog, "perf-log-interval", 0, "Log a summary of tool latency and cache hits this often (0 never)")
+	fs.BoolVar(&f.includePrerelease, "include-prerelease", false, "Let latest resolve to a prerelease that @latest names")
+	fs.StringVar(&f.verifyGoSum, "verify-go-sum", "", "Check downloads against these go.sum files (comma-separated)")
+	fs.StringVar(&f.prefetch, "prefetch", "", "Modules (path[@version]) and project go.mod paths to download at startup")
+
+	return f
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		var run func([]string) error
+
+		switch os.Args[1] {
+		case "serve-proxy":
+			run = runServeProxy
+		case "print-config":
+			run = runPrintConfig
+		case "doctor":
+			run = runDoctor
+		case "call":
+			run = runCall
+		}
+
+		if run != nil {
+			err := run(os.Args[2:])
+			if errors.Is(err, errToolFailed) {
+				os.Exit(1)
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			return
+		}
+	}
+
+	fs, flags, err := parseServerFlags(os.Args[0], os.Args[1:], flag.ExitOnError)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server, svc, err := newServer(flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	if flags.configFile != "" {
+		newConfigReloader(os.Args[0], os.Args[1:], fs, flags, svc).Schedule(ctx, svc.scheduler, flags.configFile)
+	}
+
+	go svc.scheduler.Run(ctx)
+
+	if flags.prefetch != "" {
+		pl, err := parsePrefetchList(flags.prefetch)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go svc.prefetch(ctx, pl)
+	}
+
+	if flags.goProxyAddr != "" {
+		go serveGoProxy(flags.goProxyAddr, &goProxyHandler{proxy: svc.proxy, cache: svc.cache, modCache: svc.modCache})
+	}
+
+	if flags.idleTimeout > 0 && flags.httpAddr == "" {
+		log.Printf("warning: -idle-timeout only applies with -http-addr")
+	}
+
+	if flags.httpAddr != "" {
+		if err := listenMCP(ctx, flags.httpAddr, server, flags.idleTimeout); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newServer wires the services configured by flags and registers the
+// tools on a new MCP server. Background jobs are scheduled but the
+// scheduler is not started.
+func newServer(flags *serverFlags) (*mcp.Server, *services, error) {
+	policy, err := NewModulePolicy(flags.allowModules, flags.denyModules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aliases, err := ParseModuleAliases(flags.moduleAliases)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkOutputFormat(flags.outputFormat, textFormats); err != nil {
+		return nil, nil, err
+	}
 
-	flag.Parse()
+	govcs, err := NewVCSPolicy(goEnv("GOVCS"), goEnv("GOPRIVATE"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := newHTTPClient(flags.caBundle)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	proxy := NewProxyClient()
-	cache := NewZipCache()
-	local := NewLocalReader(*localDir)
+	proxy.client = client
 
-	var modCacheDir string
+	if proxy.proxies, err = parseGOPROXY(goEnv("GOPROXY")); err != nil {
+		return nil, nil, err
+	}
 
-	if out, err := exec.Command("go", "env", "GOMODCACHE").Output(); err != nil {
-		log.Printf("warning: could not determine GOMODCACHE: %v (mod cache disabled)", err)
-	} else {
-		modCacheDir = strings.TrimSpace(string(out))
+	if flags.goProxyAddr != "" {
+		proxy.proxies = withoutAddr(proxy.proxies, flags.goProxyAddr)
 	}
 
-	modCache := NewModCache(modCacheDir)
+	proxy.noproxy = cmp.Or(goEnv("GONOPROXY"), goEnv("GOPRIVATE"))
+
+	proxy.meta = NewMetadataCache(flags.metadataTTL)
+	proxy.policy = policy
+	proxy.offline = flags.offline
+	proxy.includePrerelease = flags.includePrerelease
+	proxy.vanity = NewVanityResolver()
+	proxy.vanity.client = client
+	proxy.vanity.offline = flags.offline
+	proxy.health = NewHealthTracker()
+	proxy.govcs = govcs
+
+	if flags.verifyGoSum != "" {
+		if proxy.sums, err = loadGoSums(flags.verifyGoSum); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// GOFLAGS=-insecure and GONOSUMCHECK=1 turn the checksum database off,
+	// as GOSUMDB=off does.
+	insecure := slices.Contains(strings.Fields(goEnv("GOFLAGS")), "-insecure") || goEnv("GONOSUMCHECK") == "1"
+
+	if !flags.offline && !insecure {
+		proxy.sumdb, err = NewChecksumDB(goEnv("GOSUMDB"), cmp.Or(goEnv("GONOSUMDB"), goEnv("GOPRIVATE")), client)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if flags.cacheDir != "" {
+		if proxy.disk, err = OpenDiskCache(flags.cacheDir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cache := NewZipCache()
+	local := NewLocalReader(flags.localDir)
+	modCache := NewModCache(discoverModCache())
+	modCache.policy = policy
+
+	if flags.writeModCache {
+		modCache.write = true
+		modCache.cacheRW = slices.Contains(strings.Fields(goEnv("GOFLAGS")), "-modcacherw")
+	}
 
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "claude-gomod",
 		Version: "0.1.0",
-	}, nil)
+	}, &mcp.ServerOptions{HasResources: true, KeepAlive: flags.keepAlive})
 
-	registerTools(server, proxy, cache, local, modCache)
+	if flags.auditLog != "" {
+		audit, err := OpenAuditLog(flags.auditLog)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-		log.Fatal(err)
+		server.AddReceivingMiddleware(audit.Middleware())
+	}
+
+	scheduler := NewScheduler(flags.pollJitter)
+	scheduler.SetPaused(flags.offline)
+
+	watcher := NewWatcher(proxy, flags.watchInterval)
+	watcher.SetNotifier(notifySessions(server))
+	watcher.Schedule(scheduler)
+	proxy.meta.Schedule(scheduler)
+
+	perf := NewPerfStats()
+	perf.Schedule(scheduler, flags.perfLog)
+
+	pkgsite := NewPkgsiteClient()
+	pkgsite.client = client
+	pkgsite.offline = flags.offline
+	depsDev := NewDepsDevClient()
+	depsDev.client = client
+	depsDev.offline = flags.offline
+	osv := NewOSVClient()
+	osv.client = client
+	osv.offline = flags.offline
+
+	svc := &services{
+		proxy:     proxy,
+		cache:     cache,
+		local:     local,
+		modCache:  modCache,
+		watcher:   watcher,
+		scheduler: scheduler,
+		pkgsite:   pkgsite,
+		depsDev:   depsDev,
+		osv:       osv,
+		aliases:   aliases,
+		output:    newOutputFormatter(flags.outputFormat),
+		perf:      perf,
+		sumDBURL:  defaultSumDBURL,
+
+		govulncheck: flags.govulncheck,
+	}
+
+	if proxy.sumdb != nil {
+		svc.sumDBURL = proxy.sumdb.url
 	}
+
+	registerTools(server, svc)
+
+	return server, svc, nil
+}
+
+// discoverModCache returns GOMODCACHE from the environment or else asks
+// the go command for it. It returns empty string, disabling the mod cache,
+// if that fails.
+func discoverModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		log.Printf("warning: could not determine GOMODCACHE: %v (mod cache disabled)", err)
+
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
 }
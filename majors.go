@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// majorProbeWindow is how many major versions are probed at a time.
+	// Probing stops after a window in which none exists.
+	majorProbeWindow = 4
+
+	// maxMajorVersion bounds the probing of modules with many majors.
+	maxMajorVersion = 100
+)
+
+// majorModule is one major version of a module found on the proxy.
+type majorModule struct {
+	path     string
+	major    int
+	latest   string
+	time     time.Time
+	versions int
+}
+
+// majorPath returns the module path of major version n of the module
+// with path prefix: the prefix itself for v0 and v1, with a /vN suffix
+// above, or a .vN suffix for gopkg.in.
+func majorPath(prefix string, n int) string {
+	switch {
+	case strings.HasPrefix(prefix, "gopkg.in/"):
+		return fmt.Sprintf("%s.v%d", prefix, n)
+	case n <= 1:
+		return prefix
+	}
+
+	return fmt.Sprintf("%s/v%d", prefix, n)
+}
+
+// splitMajor splits a module path into the prefix shared by its major
+// versions and its own major version.
+func splitMajor(mod string) (string, int, bool) {
+	prefix, pathMajor, ok := module.SplitPathVersion(mod)
+	if !ok {
+		return "", 0, false
+	}
+
+	n := 1
+	if pathMajor != "" {
+		n, _ = strconv.Atoi(strings.TrimLeft(pathMajor, "/.v"))
+	}
+
+	return prefix, n, true
+}
+
+// probeMajor looks major version n of prefix up on the proxy. It returns
+// nil if the proxy has no such module.
+func probeMajor(ctx context.Context, proxy *ProxyClient, prefix string, n int) (*majorModule, error) {
+	m := &majorModule{path: majorPath(prefix, n), major: n}
+
+	latest, err := proxy.ResolveLatest(ctx, m.path)
+	if errors.Is(err, ErrModuleNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("probe %s: %w", m.path, err)
+	}
+
+	m.latest = latest
+
+	if versions, err := proxy.ListVersions(ctx, m.path); err == nil {
+		m.versions = len(versions)
+	}
+
+	if info, err := proxy.Info(ctx, m.path, latest); err == nil {
+		m.time = info.Time
+	}
+
+	return m, nil
+}
+
+// findMajors probes the major versions of prefix, a window at a time from
+// v1, until a window past the major version asked about has none. It
+// returns those found and the highest major probed.
+func findMajors(ctx context.Context, proxy *ProxyClient, prefix string, asked int) ([]*majorModule, int, error) {
+	var (
+		found []*majorModule
+		last  int
+	)
+
+	for start := 1; start <= maxMajorVersion; start += majorProbeWindow {
+		var majors []int
+		for n := start; n < start+majorProbeWindow && n <= maxMajorVersion; n++ {
+			majors = append(majors, n)
+		}
+
+		results := make([]*majorModule, len(majors))
+		errs := make([]error, len(majors))
+
+		parallelEach(majors, func(i, n int) {
+			results[i], errs[i] = probeMajor(ctx, proxy, prefix, n)
+		})
+
+		if err := errors.Join(errs...); err != nil {
+			return nil, 0, err
+		}
+
+		hit := false
+
+		for _, m := range results {
+			if m != nil {
+				found = append(found, m)
+				hit = true
+			}
+		}
+
+		last = majors[len(majors)-1]
+
+		if !hit && last >= asked {
+			break
+		}
+	}
+
+	return found, last, nil
+}
+
+// incompatibleMajors returns the major versions of +incompatible
+// versions in a version list, such as v2 for v2.1.0+incompatible.
+func incompatibleMajors(versions []string) []string {
+	var majors []string
+
+	for _, v := range versions {
+		if !strings.HasSuffix(v, "+incompatible") {
+			continue
+		}
+
+		if major := semver.Major(v); len(majors) == 0 || majors[len(majors)-1] != major {
+			majors = append(majors, major)
+		}
+	}
+
+	return majors
+}
+
+func formatMajors(sb *strings.Builder, mod string, found []*majorModule, probed int, incompatible []string) {
+	fmt.Fprintf(sb, "Major versions of %s on the proxy (%d found, probed through v%d):\n", mod, len(found), probed)
+
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+
+	for _, m := range found {
+		date := ""
+		if !m.time.IsZero() {
+			date = m.time.Format(time.DateOnly)
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%d versions\n", m.path, m.latest, date, m.versions)
+	}
+
+	_ = tw.Flush()
+
+	if len(incompatible) > 0 {
+		fmt.Fprintf(sb, "\n%s also has +incompatible versions of %s, tagged before the module had a go.mod.\n",
+			found[0].path, strings.Join(incompatible, ", "))
+	}
+
+	if newest := found[len(found)-1]; newest.path != mod {
+		fmt.Fprintf(sb, "\nThe newest major version is %s (latest %s); go get -u never moves %s to it.\n",
+			newest.path, newest.latest, mod)
+	}
+}
+
+type majorVersionsInput struct {
+	Module string `json:"module" jsonschema:"Go module path, of any major version"`
+}
+
+func handleMajorVersions(
+	ctx context.Context, proxy *ProxyClient, input majorVersionsInput,
+) (*mcp.CallToolResult, any, error) {
+	prefix, asked, ok := splitMajor(input.Module)
+	if !ok {
+		return errorResult(fmt.Sprintf("Invalid module path %q.", input.Module)), nil, nil
+	}
+
+	found, probed, err := findMajors(ctx, proxy, prefix, asked)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(found) == 0 {
+		return errorResult(fmt.Sprintf("No major version of %s is on the proxy (probed through v%d).",
+			input.Module, probed)), nil, nil
+	}
+
+	var incompatible []string
+
+	if found[0].major <= 1 {
+		if versions, err := proxy.ListVersions(ctx, found[0].path); err == nil {
+			incompatible = incompatibleMajors(versions)
+		}
+	}
+
+	var sb strings.Builder
+
+	formatMajors(&sb, input.Module, found, probed, incompatible)
+
+	return textResult(sb.String()), nil, nil
+}
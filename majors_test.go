@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSplitMajor(t *testing.T) {
+	for _, tt := range []struct {
+		mod, prefix string
+		major       int
+		v3          string
+	}{
+		{"example.com/mod", "example.com/mod", 1, "example.com/mod/v3"},
+		{"example.com/mod/v2", "example.com/mod", 2, "example.com/mod/v3"},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml", 2, "gopkg.in/yaml.v3"},
+	} {
+		prefix, major, ok := splitMajor(tt.mod)
+		if !ok || prefix != tt.prefix || major != tt.major || majorPath(prefix, 3) != tt.v3 {
+			t.Errorf("splitMajor(%q) = %q, %d, %v; v3 %q", tt.mod, prefix, major, ok, majorPath(prefix, 3))
+		}
+	}
+
+	if got := majorPath("example.com/mod", 1); got != "example.com/mod" {
+		t.Errorf("majorPath v1 = %q", got)
+	}
+}
+
+func TestIncompatibleMajors(t *testing.T) {
+	got := incompatibleMajors([]string{"v1.0.0", "v2.0.0+incompatible", "v2.1.0+incompatible", "v3.0.0+incompatible"})
+	if strings.Join(got, ",") != "v2,v3" {
+		t.Errorf("incompatibleMajors = %q", got)
+	}
+}
+
+// majorsProxy serves example.com/mod at the versions listed per module.
+func majorsProxy(lists map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mod, endpoint, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/@")
+
+		list, ok := lists[mod]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		versions := strings.Fields(list)
+
+		switch {
+		case endpoint == "v/list":
+			_, _ = w.Write([]byte(list))
+		case endpoint == "latest":
+			_, _ = w.Write([]byte(`{"Version":"` + versions[len(versions)-1] + `"}`))
+		case strings.HasSuffix(endpoint, ".info"):
+			version := strings.TrimSuffix(endpoint[len("v/"):], ".info")
+			_, _ = w.Write([]byte(`{"Version":"` + version + `","Time":"2025-03-01T00:00:00Z"}`))
+		case strings.HasSuffix(endpoint, ".mod"):
+			_, _ = w.Write([]byte("module " + mod + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestFindMajors(t *testing.T) {
+	proxy, ts := newTestProxy(majorsProxy(map[string]string{
+		"example.com/mod":    "v1.0.0\nv1.2.0\n",
+		"example.com/mod/v2": "v2.0.0\n",
+		"example.com/mod/v6": "v6.0.0\nv6.1.0\n",
+	}))
+	defer ts.Close()
+
+	found, probed, err := findMajors(context.Background(), proxy, "example.com/mod", 1)
+	mustf(t, err, "find majors")
+
+	var got []string
+	for _, m := range found {
+		got = append(got, m.path+"@"+m.latest)
+	}
+
+	// v6 is found in the second window; the third has none.
+	want := "example.com/mod@v1.2.0 example.com/mod/v2@v2.0.0 example.com/mod/v6@v6.1.0"
+	if strings.Join(got, " ") != want {
+		t.Errorf("found %q, want %q", got, want)
+	}
+
+	if probed != 12 {
+		t.Errorf("probed through v%d, want v12", probed)
+	}
+
+	// Probing goes on past the major asked about.
+	if _, probed, _ := findMajors(context.Background(), proxy, "example.com/mod", 15); probed != 16 {
+		t.Errorf("probed through v%d for v15, want v16", probed)
+	}
+}
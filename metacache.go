@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultMetadataTTL = 5 * time.Minute
+
+type metaEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// MetadataCache holds short-lived proxy responses that can change over time
+// (version lists and @latest), keyed by URL. Immutable data such as zips
+// and go.mod files is not stored here.
+type MetadataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]metaEntry
+}
+
+// NewMetadataCache creates a cache whose entries expire after ttl.
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	return &MetadataCache{
+		ttl:     ttl,
+		entries: make(map[string]metaEntry),
+	}
+}
+
+// SetTTL changes the TTL of entries stored from now on, as on a config
+// reload.
+func (c *MetadataCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+}
+
+// Get returns the cached body for key if it has not expired.
+func (c *MetadataCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+
+	return e.body, true
+}
+
+// Put stores body under key for the cache's TTL.
+func (c *MetadataCache) Put(key string, body []byte) {
+	c.mu.Lock()
+	c.entries[key] = metaEntry{body: body, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+// Expire removes entries that expired before now and returns how many
+// were removed.
+func (c *MetadataCache) Expire(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Len returns the number of cached entries, including expired ones not
+// yet removed.
+func (c *MetadataCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// Schedule registers periodic removal of expired entries on s.
+func (c *MetadataCache) Schedule(s *Scheduler) {
+	s.Every("metadata-expiry", c.ttl, func(context.Context) error {
+		c.Expire(time.Now())
+
+		return nil
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetadataCache(t *testing.T) {
+	c := NewMetadataCache(time.Minute)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	c.Put("a", []byte("v1.0.0\n"))
+
+	body, ok := c.Get("a")
+	if !ok || string(body) != "v1.0.0\n" {
+		t.Fatalf("Get = %q, %v", body, ok)
+	}
+
+	if n := c.Expire(time.Now()); n != 0 {
+		t.Errorf("expired %d fresh entries", n)
+	}
+
+	if n := c.Expire(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Errorf("Expire removed %d entries, want 1", n)
+	}
+
+	if c.Len() != 0 {
+		t.Errorf("Len = %d after expiry, want 0", c.Len())
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// packageMetrics holds size and complexity figures for one package
+// directory. Test files are counted separately and excluded from the
+// function and complexity figures.
+type packageMetrics struct {
+	dir         string
+	files       int
+	generated   int // generated files, included in files
+	testFiles   int
+	lines       int
+	bytes       int
+	largestFile string
+	largestSize int
+	funcs       int
+	totalCC     int
+	maxCC       int
+	maxCCFunc   string
+}
+
+func (m *packageMetrics) avgCC() float64 {
+	if m.funcs == 0 {
+		return 0
+	}
+
+	return float64(m.totalCC) / float64(m.funcs)
+}
+
+// computeMetrics groups the files by directory and computes metrics for
+// each package, sorted by directory.
+func computeMetrics(files []*goFile) []*packageMetrics {
+	byDir := make(map[string]*packageMetrics)
+
+	for _, f := range files {
+		dir := path.Dir(f.path)
+
+		m := byDir[dir]
+		if m == nil {
+			m = &packageMetrics{dir: dir}
+			byDir[dir] = m
+		}
+
+		if strings.HasSuffix(f.path, "_test.go") {
+			m.testFiles++
+
+			continue
+		}
+
+		m.files++
+
+		if f.generated {
+			m.generated++
+		}
+		m.lines += strings.Count(f.src, "\n")
+		m.bytes += len(f.src)
+
+		if len(f.src) > m.largestSize {
+			m.largestSize = len(f.src)
+			m.largestFile = path.Base(f.path)
+		}
+
+		for _, decl := range f.ast.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+
+			cc := cyclomaticComplexity(fn)
+
+			m.funcs++
+			m.totalCC += cc
+
+			if cc > m.maxCC {
+				m.maxCC = cc
+				m.maxCCFunc = funcDisplayName(fn)
+			}
+		}
+	}
+
+	result := make([]*packageMetrics, 0, len(byDir))
+
+	for _, m := range byDir {
+		result = append(result, m)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].dir < result[j].dir })
+
+	return result
+}
+
+// cyclomaticComplexity returns 1 plus the number of branch points in the
+// function: if, for, range, non-default case and select clauses, and the
+// && and || operators.
+func cyclomaticComplexity(fn *ast.FuncDecl) int {
+	cc := 1
+
+	if fn.Body == nil {
+		return cc
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			cc++
+		case *ast.CaseClause:
+			if n.List != nil {
+				cc++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				cc++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				cc++
+			}
+		}
+
+		return true
+	})
+
+	return cc
+}
+
+// funcDisplayName returns "Name" for functions and "Type.Name" for
+// methods.
+func funcDisplayName(fn *ast.FuncDecl) string {
+	if recv := receiverName(fn); recv != "" {
+		return recv + "." + fn.Name.Name
+	}
+
+	return fn.Name.Name
+}
+
+type metricsInput struct {
+	Module    string `json:"module" jsonschema:"Go module path"`
+	Version   string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path      string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Generated string `json:"generated,omitempty" jsonschema:"Generated files: include (default), exclude or only"`
+}
+
+func handleMetrics(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input metricsInput,
+) (*mcp.CallToolResult, any, error) {
+	if err := checkGeneratedFilter(input.Generated); err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, parsed, err := parseGoFiles(mf, input.Path, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []*goFile
+
+	for _, f := range parsed {
+		if keepGenerated(input.Generated, f.generated) {
+			files = append(files, f)
+		}
+	}
+
+	return textResult(formatMetrics(input.Module, version, computeMetrics(files))), nil, nil
+}
+
+func formatMetrics(module, version string, metrics []*packageMetrics) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Package metrics for %s@%s (%d packages):\n\n", module, version, len(metrics))
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PACKAGE\tFILES\tGENERATED\tTESTS\tLINES\tBYTES\tFUNCS\tAVG CC\tMAX CC\tLARGEST FILE")
+
+	for _, m := range metrics {
+		maxCC := "-"
+		if m.maxCCFunc != "" {
+			maxCC = fmt.Sprintf("%d (%s)", m.maxCC, m.maxCCFunc)
+		}
+
+		largest := "-"
+		if m.largestFile != "" {
+			largest = fmt.Sprintf("%s (%d bytes)", m.largestFile, m.largestSize)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f\t%s\t%s\n",
+			m.dir, m.files, m.generated, m.testFiles, m.lines, m.bytes, m.funcs, m.avgCC(), maxCC, largest)
+	}
+
+	_ = tw.Flush()
+
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestCyclomaticComplexity(t *testing.T) {
+	src := `package p
+
+func simple() {}
+
+func branches(a, b bool, xs []int) int {
+	if a && b {
+		return 1
+	}
+
+	for _, x := range xs {
+		switch x {
+		case 1, 2:
+		case 3:
+		default:
+		}
+	}
+
+	return 0
+}
+`
+
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+
+	mustf(t, err, "parse source")
+
+	want := map[string]int{"simple": 1, "branches": 6}
+
+	for _, decl := range f.Decls {
+		fn := decl.(*ast.FuncDecl)
+		if got := cyclomaticComplexity(fn); got != want[fn.Name.Name] {
+			t.Errorf("cyclomaticComplexity(%s) = %d, want %d", fn.Name.Name, got, want[fn.Name.Name])
+		}
+	}
+}
+
+func TestComputeMetrics(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"a.go":        "package mod\n\nfunc A() {}\n\nfunc B(x bool) {\n\tif x {\n\t}\n}\n",
+		"a_test.go":   "package mod\n\nfunc TestA() {}\n",
+		"sub/b.go":    "package sub\n\ntype T struct{}\n\nfunc (T) M() {}\n",
+		"sub/doc.txt": "not go",
+		"sub/kind_string.go": "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage sub\n\n" +
+			"var _kindNames = \"AB\"\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+
+	_, files, err := parseGoFiles(zipFiles{entry}, "", 0)
+
+	mustf(t, err, "parse go files")
+
+	metrics := computeMetrics(files)
+	if len(metrics) != 2 {
+		t.Fatalf("got %d packages, want 2", len(metrics))
+	}
+
+	root, sub := metrics[0], metrics[1]
+
+	if root.dir != "." || root.files != 1 || root.testFiles != 1 || root.funcs != 2 {
+		t.Errorf("unexpected root metrics: %+v", root)
+	}
+
+	if root.maxCC != 2 || root.maxCCFunc != "B" || root.avgCC() != 1.5 {
+		t.Errorf("unexpected root complexity: %+v", root)
+	}
+
+	if sub.dir != "sub" || sub.files != 2 || sub.generated != 1 || sub.funcs != 1 || sub.maxCCFunc != "T.M" {
+		t.Errorf("unexpected sub metrics: %+v", sub)
+	}
+
+	out := formatMetrics("mod", "v1.0.0", metrics)
+	if !strings.Contains(out, "2 packages") || !strings.Contains(out, "2 (B)") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
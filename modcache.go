@@ -2,16 +2,23 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"unicode/utf8"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 // ModCache reads module files directly from the local Go module cache
 // ($GOMODCACHE), avoiding network requests when modules are already downloaded.
 type ModCache struct {
-	dir string
+	dir     string
+	policy  *ModulePolicy // optional; denied modules are treated as absent
+	write   bool          // store downloaded zips in the cache (-write-modcache)
+	cacheRW bool          // leave stored modules writable, as GOFLAGS=-modcacherw does
 }
 
 // NewModCache creates a ModCache rooted at the given directory.
@@ -20,31 +27,123 @@ func NewModCache(dir string) *ModCache {
 	return &ModCache{dir: dir}
 }
 
-// ModDir returns the on-disk path for a module version in the cache.
-func (m *ModCache) ModDir(module, version string) string {
-	return filepath.Join(m.dir, encodePath(module)+"@"+version)
+// ModDir returns the on-disk path for a module version in the cache, or
+// an empty string if the path or version cannot be escaped.
+func (m *ModCache) ModDir(mod, version string) string {
+	escPath, err := module.EscapePath(mod)
+	if err != nil {
+		return ""
+	}
+
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(m.dir, escPath+"@"+escVersion)
+}
+
+// DownloadFile returns the path of a file in the module download cache
+// ($GOMODCACHE/cache/download), which uses the GOPROXY layout. Name is
+// relative to the module's @v directory, e.g. "v1.0.0.mod" or "list".
+// Returns empty string if the cache is disabled, the module is denied by
+// policy or the module path or version is invalid.
+func (m *ModCache) DownloadFile(mod, name string) string {
+	if m.dir == "" || m.policy.Check(mod) != nil {
+		return ""
+	}
+
+	escPath, err := module.EscapePath(mod)
+	if err != nil {
+		return ""
+	}
+
+	name, err = escapeVersionFile(name)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(m.dir, "cache", "download", escPath, "@v", name)
+}
+
+// CachedVersions returns the versions of a module extracted in the cache,
+// in semver order, unescaping the version part of the directory names.
+func (m *ModCache) CachedVersions(mod string) []string {
+	if m.dir == "" || m.policy.Check(mod) != nil {
+		return nil
+	}
+
+	escPath, err := module.EscapePath(mod)
+	if err != nil {
+		return nil
+	}
+
+	parent, base := filepath.Split(filepath.Join(m.dir, filepath.FromSlash(escPath)))
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return nil
+	}
+
+	var versions []string
+
+	for _, e := range entries {
+		escVersion, ok := strings.CutPrefix(e.Name(), base+"@")
+		if !ok || !e.IsDir() {
+			continue
+		}
+
+		if v, err := module.UnescapeVersion(escVersion); err == nil && semver.IsValid(v) {
+			versions = append(versions, v)
+		}
+	}
+
+	semver.Sort(versions)
+
+	return versions
 }
 
-// HasModule reports whether the module version directory exists in the cache.
-func (m *ModCache) HasModule(module, version string) bool {
-	if m.dir == "" {
+// HasModule reports whether the module version directory exists in the
+// cache. A directory the go command, or Store, is still extracting into
+// has a .partial file next to it and does not count.
+func (m *ModCache) HasModule(mod, version string) bool {
+	dir := m.ModDir(mod, version)
+	if m.dir == "" || dir == "" || m.policy.Check(mod) != nil {
 		return false
 	}
 
-	info, err := os.Stat(m.ModDir(module, version))
+	info, err := os.Stat(dir)
+
+	return err == nil && info.IsDir() && !fileExists(dir+".partial")
+}
+
+// moduleRoot is ModDir for file access, failing instead of returning a
+// relative path when the cache is disabled or the version is invalid.
+func (m *ModCache) moduleRoot(mod, version string) (string, error) {
+	if err := m.policy.Check(mod); err != nil {
+		return "", err
+	}
+
+	dir := m.ModDir(mod, version)
+	if m.dir == "" || dir == "" {
+		return "", fmt.Errorf("%s@%s: %w", mod, version, ErrModuleNotFound)
+	}
 
-	return err == nil && info.IsDir()
+	return dir, nil
 }
 
 // ListFiles walks the extracted module directory and returns file paths
 // relative to the module root. Only regular files are included.
 // If prefix is non-empty, only paths starting with prefix are returned.
-func (m *ModCache) ListFiles(module, version, prefix string) ([]string, error) {
-	root := m.ModDir(module, version)
+func (m *ModCache) ListFiles(mod, version, prefix string) ([]string, error) {
+	root, err := m.moduleRoot(mod, version)
+	if err != nil {
+		return nil, err
+	}
 
 	var files []string
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -73,12 +172,46 @@ func (m *ModCache) ListFiles(module, version, prefix string) ([]string, error) {
 	return files, nil
 }
 
+// Open returns a reader for the raw content of a file in the extracted
+// module directory.
+func (m *ModCache) Open(mod, version, path string) (io.ReadCloser, error) {
+	root, err := m.moduleRoot(mod, version)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, fmt.Errorf("open file in mod cache: %w", err)
+	}
+
+	return f, nil
+}
+
+// Size returns the size of a file in the extracted module directory.
+func (m *ModCache) Size(mod, version, path string) (int64, error) {
+	root, err := m.moduleRoot(mod, version)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return 0, fmt.Errorf("stat file in mod cache: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
 // ReadFile reads a file from the extracted module directory.
 // Returns an error if the file contains non-UTF-8 (binary) content.
-func (m *ModCache) ReadFile(module, version, path string) (string, error) {
-	full := filepath.Join(m.ModDir(module, version), filepath.FromSlash(path))
+func (m *ModCache) ReadFile(mod, version, path string) (string, error) {
+	root, err := m.moduleRoot(mod, version)
+	if err != nil {
+		return "", err
+	}
 
-	data, err := os.ReadFile(full)
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
 	if err != nil {
 		return "", fmt.Errorf("read file from mod cache: %w", err)
 	}
@@ -28,6 +28,40 @@ func TestModDir_NoUppercase(t *testing.T) {
 	}
 }
 
+func TestModDir_EscapesVersion(t *testing.T) {
+	mc := NewModCache("/cache")
+
+	got := mc.ModDir("example.com/mod", "v1.0.0-RC1")
+	if want := filepath.Join("/cache", "example.com/mod@v1.0.0-!r!c1"); got != want {
+		t.Errorf("ModDir = %q, want %q", got, want)
+	}
+
+	if got := mc.ModDir("example.com/../mod", "v1.0.0"); got != "" {
+		t.Errorf("ModDir for invalid path = %q, want empty", got)
+	}
+
+	if _, err := mc.ReadFile("example.com/../mod", "v1.0.0", "go.mod"); err == nil {
+		t.Error("expected ReadFile to fail for an invalid module path")
+	}
+}
+
+func TestCachedVersions(t *testing.T) {
+	dir := t.TempDir()
+	mc := NewModCache(dir)
+
+	for _, name := range []string{
+		"github.com/!foo/bar@v1.10.0", "github.com/!foo/bar@v1.2.0", "github.com/!foo/bar@v2.0.0-!r!c1",
+		"github.com/!foo/bar@v1.9.9.partial", "github.com/!foo/barx@v1.0.0", "github.com/foo/bar@v1.0.0",
+	} {
+		mustf(t, os.MkdirAll(filepath.Join(dir, filepath.FromSlash(name)), 0o750), "create %s", name)
+	}
+
+	got := strings.Join(mc.CachedVersions("github.com/Foo/bar"), " ")
+	if want := "v1.2.0 v1.10.0 v2.0.0-RC1"; got != want {
+		t.Errorf("CachedVersions = %s, want %s", got, want)
+	}
+}
+
 func TestHasModule_Exists(t *testing.T) {
 	dir := t.TempDir()
 	mc := NewModCache(dir)
@@ -133,6 +167,24 @@ func TestReadFile_Text(t *testing.T) {
 	}
 }
 
+func TestModCacheSize(t *testing.T) {
+	dir := t.TempDir()
+	mc := NewModCache(dir)
+
+	modDir := filepath.Join(dir, "example.com/mod@v1.0.0")
+
+	mustf(t, os.MkdirAll(modDir, 0o755), "create mod dir")
+	mustf(t, os.WriteFile(filepath.Join(modDir, "main.go"), []byte("package main\n"), 0o600), "write main.go")
+
+	size, err := mc.Size("example.com/mod", "v1.0.0", "main.go")
+
+	mustf(t, err, "size of main.go")
+
+	if size != 13 {
+		t.Errorf("size = %d, want 13", size)
+	}
+}
+
 func TestReadFile_Binary(t *testing.T) {
 	dir := t.TempDir()
 	mc := NewModCache(dir)
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+// lockFile is a no-op where flock is not available; the module cache is
+// then written without coordinating with the go command.
+func lockFile(string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on name, creating it if needed, like
+// the go command's lockedfile.MutexAt. It blocks until the lock is free.
+func lockFile(name string) (func(), error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o666) //nolint:gosec // The go command's mode.
+	if err != nil {
+		return nil, fmt.Errorf("open lock file: %w", err)
+	}
+
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR { //nolint:errorlint // Flock returns a bare Errno.
+			break
+		}
+	}
+
+	if err != nil {
+		f.Close()
+
+		return nil, fmt.Errorf("lock %s: %w", name, err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// Store writes a downloaded module version into the module cache the way
+// the go command does: its .info, .mod, the zip and its .ziphash go to
+// cache/download, and the files are extracted to module@version, marked
+// by a .partial file until extraction is complete, with read-only
+// directories unless GOFLAGS has -modcacherw. The version's .lock file in
+// cache/download is held throughout, so a concurrent go command waits for
+// the server and vice versa. Store does nothing unless writing is
+// enabled.
+func (m *ModCache) Store(mod, version string, info, goMod, data []byte) error {
+	if !m.write || m.dir == "" || m.policy.Check(mod) != nil {
+		return nil
+	}
+
+	dir := m.ModDir(mod, version)
+
+	zipFile := m.DownloadFile(mod, version+".zip")
+	if dir == "" || zipFile == "" {
+		return fmt.Errorf("%s@%s: invalid module path or version", mod, version)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(zipFile), 0o777); err != nil { //nolint:gosec // The go command's mode.
+		return fmt.Errorf("create download dir: %w", err)
+	}
+
+	unlock, err := lockFile(m.DownloadFile(mod, version+".lock"))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := storeFile(m.DownloadFile(mod, version+".info"), info); err != nil {
+		return err
+	}
+
+	if err := storeFile(m.DownloadFile(mod, version+".mod"), goMod); err != nil {
+		return err
+	}
+
+	if err := m.storeZip(zipFile, data); err != nil {
+		return err
+	}
+
+	return m.extract(dir, module.Version{Path: mod, Version: version}, zipFile)
+}
+
+// storeFile writes a file of cache/download unless it is there, through a
+// temporary file renamed into place like the zip.
+func storeFile(name string, data []byte) error {
+	if fileExists(name) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp %s: %w", filepath.Ext(name), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("write temp %s: %w", filepath.Ext(name), err)
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("move %s into place: %w", filepath.Ext(name), err)
+	}
+
+	return nil
+}
+
+// storeZip writes the zip and its .ziphash unless both are there. The
+// hash is computed from the written file, which is then renamed into
+// place, so that an interrupted write never leaves a truncated zip.
+func (m *ModCache) storeZip(zipFile string, data []byte) error {
+	hashFile := zipFile + "hash"
+
+	if fileExists(zipFile) && fileExists(hashFile) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(zipFile), filepath.Base(zipFile)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp zip: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("write temp zip: %w", err)
+	}
+
+	sum, err := dirhash.HashZip(tmp.Name(), dirhash.Hash1)
+	if err != nil {
+		return fmt.Errorf("hash zip: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), zipFile); err != nil {
+		return fmt.Errorf("move zip into place: %w", err)
+	}
+
+	if err := os.WriteFile(hashFile, []byte(sum), 0o666); err != nil { //nolint:gosec // The go command's mode.
+		return fmt.Errorf("write ziphash: %w", err)
+	}
+
+	return nil
+}
+
+// extract unzips the module into dir unless a complete extraction is
+// there. A leftover .partial file means an earlier one was interrupted,
+// so dir is removed first.
+func (m *ModCache) extract(dir string, mv module.Version, zipFile string) error {
+	partial := dir + ".partial"
+
+	if fileExists(dir) && !fileExists(partial) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o777); err != nil { //nolint:gosec // The go command's mode.
+		return fmt.Errorf("create module dir: %w", err)
+	}
+
+	if err := os.WriteFile(partial, nil, 0o666); err != nil { //nolint:gosec // The go command's mode.
+		return fmt.Errorf("mark extraction: %w", err)
+	}
+
+	makeDirsWritable(dir)
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove incomplete extraction: %w", err)
+	}
+
+	if err := modzip.Unzip(dir, mv, zipFile); err != nil {
+		return fmt.Errorf("extract %s: %w", mv, err)
+	}
+
+	if !m.cacheRW {
+		makeDirsReadOnly(dir)
+	}
+
+	if err := os.Remove(partial); err != nil {
+		return fmt.Errorf("finish extraction: %w", err)
+	}
+
+	return nil
+}
+
+// storeDownload is Store for a zip that was just downloaded, with the
+// version's .info and .mod from the proxy. Failures are logged: the
+// download itself succeeded and is served from memory.
+func storeDownload(
+	ctx context.Context, proxy *ProxyClient, modCache *ModCache, mod, version string, entry *ZipEntry,
+) {
+	if !modCache.write || modCache.HasModule(mod, version) || ctx.Err() != nil {
+		return
+	}
+
+	err := func() error {
+		info, err := proxy.Info(ctx, mod, version)
+		if err != nil {
+			return fmt.Errorf("read info: %w", err)
+		}
+
+		infoJSON, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("encode info: %w", err)
+		}
+
+		goMod, err := proxy.ReadMod(ctx, mod, version)
+		if err != nil {
+			return fmt.Errorf("read go.mod: %w", err)
+		}
+
+		return modCache.Store(mod, version, infoJSON, []byte(goMod), entry.Data())
+	}()
+	if err != nil {
+		log.Printf("warning: could not write %s@%s to the module cache: %v", mod, version, err)
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+
+	return err == nil
+}
+
+// makeDirsReadOnly clears the write bits of the directories below dir, as
+// the go command does for extracted modules; the files are read-only
+// already.
+func makeDirsReadOnly(dir string) {
+	chmodDirs(dir, func(mode fs.FileMode) fs.FileMode { return mode &^ 0o222 })
+}
+
+// makeDirsWritable undoes makeDirsReadOnly so that dir can be removed.
+func makeDirsWritable(dir string) {
+	chmodDirs(dir, func(mode fs.FileMode) fs.FileMode { return mode | 0o200 })
+}
+
+func chmodDirs(dir string, change func(fs.FileMode) fs.FileMode) {
+	var dirs []string
+
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+
+		return nil
+	})
+
+	// Deepest first, so that a read-only parent does not get in the way.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if info, err := os.Stat(dirs[i]); err == nil {
+			_ = os.Chmod(dirs[i], change(info.Mode()))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModCacheStore(t *testing.T) {
+	files := map[string]string{
+		"go.mod":      "module example.com/Store\n",
+		"main.go":     "package main\n",
+		"lib/util.go": "package lib\n",
+	}
+	zipData := createTestZip(t, "example.com/Store@v1.0.0/", files)
+
+	root := t.TempDir()
+	mc := NewModCache(root)
+	mc.write = true
+
+	dir := mc.ModDir("example.com/Store", "v1.0.0")
+	t.Cleanup(func() { makeDirsWritable(dir) })
+
+	must := func(err error) {
+		t.Helper()
+		mustf(t, err, "store")
+	}
+
+	infoJSON := `{"Version":"v1.0.0","Time":"2024-01-01T00:00:00Z"}`
+	must(mc.Store("example.com/Store", "v1.0.0", []byte(infoJSON), []byte(files["go.mod"]), zipData))
+
+	if filepath.Base(dir) != "!store@v1.0.0" {
+		t.Errorf("module dir %s is not escaped", dir)
+	}
+
+	got, err := mc.ReadFile("example.com/Store", "v1.0.0", "lib/util.go")
+	mustf(t, err, "read stored file")
+
+	if got != "package lib\n" {
+		t.Errorf("stored lib/util.go = %q", got)
+	}
+
+	if !mc.HasModule("example.com/Store", "v1.0.0") {
+		t.Error("stored module not found in cache")
+	}
+
+	entry, err := NewZipCache().Put("example.com/Store", "v1.0.0", zipData)
+	mustf(t, err, "put zip")
+
+	want, err := moduleHash(zipFiles{entry}, "example.com/Store", "v1.0.0")
+	mustf(t, err, "module hash")
+
+	ziphash, err := os.ReadFile(mc.DownloadFile("example.com/Store", "v1.0.0.ziphash"))
+	mustf(t, err, "read ziphash")
+
+	if string(ziphash) != want {
+		t.Errorf("ziphash = %q, want %q", ziphash, want)
+	}
+
+	if _, err := os.Stat(mc.DownloadFile("example.com/Store", "v1.0.0.zip")); err != nil {
+		t.Errorf("zip not stored: %v", err)
+	}
+
+	for file, want := range map[string]string{"v1.0.0.info": infoJSON, "v1.0.0.mod": files["go.mod"]} {
+		if got, err := os.ReadFile(mc.DownloadFile("example.com/Store", file)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", file, got, err, want)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "lib"))
+	mustf(t, err, "stat lib")
+
+	if info.Mode().Perm()&0o222 != 0 {
+		t.Errorf("lib mode = %v, want read-only", info.Mode())
+	}
+
+	if _, err := os.Stat(dir + ".partial"); !os.IsNotExist(err) {
+		t.Errorf(".partial left behind: %v", err)
+	}
+}
+
+func TestModCacheStore_RedoesPartialExtraction(t *testing.T) {
+	zipData := createTestZip(t, "example.com/m@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/m\n",
+		"main.go": "package main\n",
+	})
+
+	mc := NewModCache(t.TempDir())
+	mc.write = true
+	mc.cacheRW = true
+
+	dir := mc.ModDir("example.com/m", "v1.0.0")
+
+	// An interrupted extraction: a stray file and the .partial marker.
+	writeTree(t, dir, map[string]string{"stale.go": "package stale\n"})
+	mustf(t, os.WriteFile(dir+".partial", nil, 0o600), "write .partial")
+
+	if mc.HasModule("example.com/m", "v1.0.0") {
+		t.Error("a partial extraction counts as cached")
+	}
+
+	mustf(t, mc.Store("example.com/m", "v1.0.0", nil, []byte("module example.com/m\n"), zipData), "store")
+
+	if _, err := os.Stat(filepath.Join(dir, "stale.go")); !os.IsNotExist(err) {
+		t.Error("stale file from the partial extraction kept")
+	}
+
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm()&0o200 == 0 {
+		t.Errorf("%s should stay writable with GOFLAGS=-modcacherw: %v", dir, err)
+	}
+}
+
+func TestOpenModule_WritesModCache(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	var svc *services
+
+	env := setupTestEnv(t, fakeProxy(zipData), func(s *services) {
+		s.modCache.write = true
+		s.modCache.cacheRW = true
+		svc = s
+	})
+	defer env.close()
+
+	callTool(t, env, "gomod_list_files", map[string]any{"module": "example.com/testmod", "version": "v1.0.0"})
+
+	if !svc.modCache.HasModule("example.com/testmod", "v1.0.0") {
+		t.Error("downloaded module was not written to the module cache")
+	}
+
+	for _, file := range []string{"v1.0.0.info", "v1.0.0.mod"} {
+		if _, err := os.Stat(svc.modCache.DownloadFile("example.com/testmod", file)); err != nil {
+			t.Errorf("%s not written: %v", file, err)
+		}
+	}
+
+	mf, err := openModule(context.Background(), svc.proxy, NewZipCache(), svc.modCache, "example.com/testmod", "v1.0.0")
+	mustf(t, err, "open module")
+
+	if _, ok := mf.(*modCacheFiles); !ok {
+		t.Errorf("module opened from %T, want the module cache", mf)
+	}
+}
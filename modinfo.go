@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// latestModFile resolves a module's latest version and parses its go.mod,
+// which carries the module's current retractions and deprecation notice.
+func latestModFile(ctx context.Context, proxy *ProxyClient, mod string) (string, *modfile.File, error) {
+	latest, err := proxy.ResolveLatest(ctx, mod)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolve latest version: %w", err)
+	}
+
+	data, err := proxy.ReadMod(ctx, mod, latest)
+	if err != nil {
+		return "", nil, fmt.Errorf("read latest go.mod: %w", err)
+	}
+
+	mf, err := modfile.ParseLax("go.mod", []byte(data), nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse latest go.mod: %w", err)
+	}
+
+	return latest, mf, nil
+}
+
+// moduleDeprecation returns the module's Deprecated notice, or "".
+func moduleDeprecation(mf *modfile.File) string {
+	if mf.Module == nil {
+		return ""
+	}
+
+	return mf.Module.Deprecated
+}
+
+// retraction returns the retract directive covering version, or nil.
+func retraction(mf *modfile.File, version string) *modfile.Retract {
+	for _, r := range mf.Retract {
+		if semver.Compare(r.Low, version) <= 0 && semver.Compare(version, r.High) <= 0 {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// formatVersionInterval renders a retract interval, collapsing
+// single-version intervals.
+func formatVersionInterval(low, high string) string {
+	if low == high {
+		return low
+	}
+
+	return "[" + low + ", " + high + "]"
+}
+
+// latestAllowed returns the highest version in versions that is not
+// retracted by mf, preferring releases over pre-releases. It returns ""
+// if every version is retracted.
+func latestAllowed(versions []string, mf *modfile.File) string {
+	sorted := append([]string(nil), versions...)
+	semver.Sort(sorted)
+
+	var prerelease string
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		v := sorted[i]
+		if !semver.IsValid(v) || retraction(mf, v) != nil {
+			continue
+		}
+
+		if semver.Prerelease(v) == "" {
+			return v
+		}
+
+		if prerelease == "" {
+			prerelease = v
+		}
+	}
+
+	return prerelease
+}
+
+// successorFromDeprecation extracts the first module path mentioned in a
+// deprecation notice, as in "Deprecated: use example.com/new instead.".
+func successorFromDeprecation(msg, self string) string {
+	for _, word := range strings.Fields(msg) {
+		word = strings.Trim(word, ".,;:()[]\"'`")
+
+		first, _, hasSlash := strings.Cut(word, "/")
+		if !hasSlash || !strings.Contains(first, ".") || word == self {
+			continue
+		}
+
+		if module.CheckPath(word) == nil {
+			return word
+		}
+	}
+
+	return ""
+}
+
+// nextMajorPath returns the module path of the next major version, e.g.
+// "example.com/mod/v2" for "example.com/mod" or "example.com/mod/v3" for
+// "example.com/mod/v2". gopkg.in paths are not handled.
+func nextMajorPath(mod string) string {
+	if strings.HasPrefix(mod, "gopkg.in/") {
+		return ""
+	}
+
+	prefix, major, ok := module.SplitPathVersion(mod)
+	if !ok {
+		return ""
+	}
+
+	n := 1
+	if major != "" {
+		n, _ = strconv.Atoi(strings.TrimPrefix(major, "/v"))
+	}
+
+	return fmt.Sprintf("%s/v%d", prefix, n+1)
+}
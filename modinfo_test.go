@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func parseTestModFile(tb testing.TB, data string) *modfile.File {
+	tb.Helper()
+
+	mf, err := modfile.ParseLax("go.mod", []byte(data), nil)
+	mustf(tb, err, "parse go.mod")
+
+	return mf
+}
+
+func TestRetraction(t *testing.T) {
+	mf := parseTestModFile(t, `module example.com/mod
+
+retract (
+	v1.1.0 // Broken.
+	[v1.3.0, v1.4.0]
+)
+`)
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.0.0", false},
+		{"v1.1.0", true},
+		{"v1.2.0", false},
+		{"v1.3.5", true},
+		{"v1.4.0", true},
+		{"v1.5.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := retraction(mf, tt.version) != nil; got != tt.want {
+			t.Errorf("retraction(%s) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+
+	if r := retraction(mf, "v1.1.0"); r.Rationale != "Broken." {
+		t.Errorf("rationale = %q", r.Rationale)
+	}
+}
+
+func TestLatestAllowed(t *testing.T) {
+	mf := parseTestModFile(t, "module example.com/mod\n\nretract v1.2.0\n")
+
+	if got := latestAllowed([]string{"v1.0.0", "v1.2.0", "v1.1.0", "v1.3.0-rc.1"}, mf); got != "v1.1.0" {
+		t.Errorf("latestAllowed = %q, want v1.1.0", got)
+	}
+
+	if got := latestAllowed([]string{"v1.2.0", "v1.3.0-rc.1"}, mf); got != "v1.3.0-rc.1" {
+		t.Errorf("latestAllowed with only a pre-release = %q, want v1.3.0-rc.1", got)
+	}
+
+	if got := latestAllowed([]string{"v1.2.0"}, mf); got != "" {
+		t.Errorf("latestAllowed with everything retracted = %q, want empty", got)
+	}
+}
+
+func TestSuccessorFromDeprecation(t *testing.T) {
+	tests := []struct {
+		msg, want string
+	}{
+		{"use example.com/mod/v2 instead.", "example.com/mod/v2"},
+		{"Moved to `github.com/new/thing`.", "github.com/new/thing"},
+		{"see https://example.com for details", ""},
+		{"no longer maintained", ""},
+		{"example.com/mod is frozen", ""},
+	}
+
+	for _, tt := range tests {
+		if got := successorFromDeprecation(tt.msg, "example.com/mod"); got != tt.want {
+			t.Errorf("successorFromDeprecation(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestFormatVersionInterval(t *testing.T) {
+	if got := formatVersionInterval("v1.0.0", "v1.0.0"); got != "v1.0.0" {
+		t.Errorf("single version = %q", got)
+	}
+
+	if got := formatVersionInterval("v1.0.0", "v1.2.0"); got != "[v1.0.0, v1.2.0]" {
+		t.Errorf("interval = %q", got)
+	}
+}
+
+func TestNextMajorPath(t *testing.T) {
+	tests := []struct {
+		mod, want string
+	}{
+		{"example.com/mod", "example.com/mod/v2"},
+		{"example.com/mod/v2", "example.com/mod/v3"},
+		{"gopkg.in/yaml.v3", ""},
+	}
+
+	for _, tt := range tests {
+		if got := nextMajorPath(tt.mod); got != tt.want {
+			t.Errorf("nextMajorPath(%q) = %q, want %q", tt.mod, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+// moduleRootTools are the tools whose module argument may be a package
+// path, mapped to the argument that takes a directory within the module:
+// a path prefix, a package or a directory. Tools mapped to "" act on the
+// whole module, so the package directory is dropped.
+var moduleRootTools = map[string]string{
+	"gomod_list_files":       "path",
+	"gomod_read_file":        "path",
+	"gomod_grep":             "path",
+	"gomod_packages":         "path",
+	"gomod_docs":             "path",
+	"gomod_dir_stats":        "path",
+	"gomod_hash":             "path",
+	"gomod_list_fuzz":        "path",
+	"gomod_metrics":          "path",
+	"gomod_proto_map":        "path",
+	"gomod_specs":            "path",
+	"gomod_tags":             "path",
+	"gomod_list_tests":       "path",
+	"gomod_list_benchmarks":  "path",
+	"gomod_find_symbol":      "path",
+	"gomod_imports":          "path",
+	"gomod_compare_versions": "path",
+	"gomod_doc":              "package",
+	"gomod_examples":         "package",
+	"gomod_api":              "package",
+	"gomod_apidiff":          "package",
+	"gomod_callers":          "package",
+	"gomod_usage_examples":   "package",
+	"gomod_readme":           "dir",
+	"gomod_list_versions":    "",
+	"gomod_read_mod":         "",
+	"gomod_licenses":         "",
+	"gomod_changelog":        "",
+	"gomod_upgrade_report":   "",
+	"gomod_dependents":       "",
+	"gomod_major_versions":   "",
+}
+
+// moduleRoots finds the modules containing package paths, remembering
+// the answers: a path that is a module stays one, and a version of the
+// module containing a package keeps containing it. Paths the proxy does
+// not answer for are asked about again.
+type moduleRoots struct {
+	proxy *ProxyClient
+
+	mu      sync.Mutex
+	modules map[string]bool       // paths known to be modules
+	roots   map[string]moduleRoot // by package path@version
+}
+
+type moduleRoot struct {
+	module, dir string
+}
+
+func newModuleRoots(proxy *ProxyClient) *moduleRoots {
+	return &moduleRoots{
+		proxy:   proxy,
+		modules: make(map[string]bool),
+		roots:   make(map[string]moduleRoot),
+	}
+}
+
+// find finds the module that contains the package path p when p is not a
+// module itself, by asking the proxy for each parent path, longest first.
+// A specific version must exist for the parent too. It returns the module
+// and the directory of p within it. Whether p is a module is asked with
+// @latest, which for "latest" is the answer the tool then resolves the
+// version with, from the metadata cache.
+func (r *moduleRoots) find(ctx context.Context, p, version string) (string, string, bool) {
+	latest := version == "" || strings.EqualFold(version, "latest")
+	if latest {
+		version = "latest"
+	}
+
+	r.mu.Lock()
+	isModule := r.modules[p]
+	root, found := r.roots[p+"@"+version]
+	r.mu.Unlock()
+
+	if isModule {
+		return "", "", false
+	}
+
+	if found {
+		return root.module, root.dir, true
+	}
+
+	if _, err := r.proxy.Latest(ctx, p); !errors.Is(err, ErrModuleNotFound) {
+		if err == nil {
+			r.mu.Lock()
+			r.modules[p] = true
+			r.mu.Unlock()
+		}
+
+		return "", "", false
+	}
+
+	known := func(mod string) bool {
+		var err error
+		if latest {
+			_, err = r.proxy.Latest(ctx, mod)
+		} else {
+			_, err = r.proxy.Info(ctx, mod, version)
+		}
+
+		return err == nil
+	}
+
+	for i := strings.LastIndex(p, "/"); i > 0; i = strings.LastIndex(p[:i], "/") {
+		mod := p[:i]
+		if !strings.Contains(mod, "/") {
+			break // a bare host is not a module
+		}
+
+		if module.CheckPath(mod) == nil && known(mod) {
+			r.mu.Lock()
+			r.roots[p+"@"+version] = moduleRoot{module: mod, dir: p[i+1:]}
+			r.mu.Unlock()
+
+			return mod, p[i+1:], true
+		}
+	}
+
+	return "", "", false
+}
+
+// importModule finds the module that provides an import path, as go get
+// does: the longest of the path and its parents that the proxy knows. It
+// returns the module with its latest version, and ErrModuleNotFound when
+// no candidate is a module.
+func importModule(ctx context.Context, proxy *ProxyClient, importPath string) (string, *VersionInfo, error) {
+	for p := importPath; strings.Contains(p, "/"); p = p[:strings.LastIndex(p, "/")] {
+		if module.CheckPath(p) != nil {
+			continue
+		}
+
+		info, err := proxy.Latest(ctx, p)
+		if err == nil {
+			return p, info, nil
+		}
+
+		if !errors.Is(err, ErrModuleNotFound) {
+			return "", nil, err
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: no module provides %s", ErrModuleNotFound, importPath)
+}
+
+// moduleRootMiddleware rewrites tool calls that pass a package path such
+// as github.com/foo/bar/pkg/util as the module: the module becomes
+// github.com/foo/bar and the tool's path, package or directory is taken
+// relative to pkg/util.
+func moduleRootMiddleware(proxy *ProxyClient) mcp.Middleware {
+	roots := newModuleRoots(proxy)
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || method != "tools/call" || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			arg, ok := moduleRootTools[call.Params.Name]
+			if !ok {
+				return next(ctx, method, req)
+			}
+
+			err := rewriteArguments(call, func(args map[string]any) bool {
+				mod, _ := args["module"].(string)
+				if mod == "" || proxy.policy.Check(mod) != nil {
+					return false
+				}
+
+				version, _ := args["version"].(string)
+
+				root, dir, ok := roots.find(ctx, mod, version)
+				if !ok {
+					return false
+				}
+
+				args["module"] = root
+
+				switch p, _ := args[arg].(string); {
+				case arg == "":
+				case inModule(p, root):
+					// Already an import path within the module.
+				case p != "":
+					args[arg] = dir + "/" + strings.TrimPrefix(p, "/")
+				case arg == "path":
+					args[arg] = dir + "/"
+				default:
+					args[arg] = dir
+				}
+
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
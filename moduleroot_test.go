@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestModuleRoots_Find(t *testing.T) {
+	var requests atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.URL.Path {
+		case "/github.com/foo/bar/@latest", "/github.com/foo/bar/@v/v1.2.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		case "/github.com/foo/bar/pkg/util/@latest":
+			http.NotFound(w, r)
+		case "/example.com/down/pkg/@latest":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+	roots := newModuleRoots(proxy)
+
+	for _, tc := range []struct {
+		path, version string
+		root, dir     string
+	}{
+		{"github.com/foo/bar/pkg/util", "latest", "github.com/foo/bar", "pkg/util"},
+		{"github.com/foo/bar/pkg/util", "v1.2.0", "github.com/foo/bar", "pkg/util"},
+		{"github.com/foo/bar/pkg/util", "v9.0.0", "", ""}, // no such version of the parent
+		{"github.com/foo/bar", "latest", "", ""},          // already a module
+		{"example.com/down/pkg", "latest", "", ""},        // proxy error, not a 404
+		{"github.com/nobody/x/y", "", "", ""},
+	} {
+		root, dir, ok := roots.find(context.Background(), tc.path, tc.version)
+		if root != tc.root || dir != tc.dir || ok != (tc.root != "") {
+			t.Errorf("find(%s, %s) = %q, %q, %v; want %q, %q",
+				tc.path, tc.version, root, dir, ok, tc.root, tc.dir)
+		}
+	}
+
+	// Answers found are not asked for again.
+	requests.Store(0)
+
+	for _, version := range []string{"latest", "v1.2.0"} {
+		if root, _, ok := roots.find(context.Background(), "github.com/foo/bar/pkg/util", version); !ok ||
+			root != "github.com/foo/bar" {
+			t.Errorf("find again at %s = %q, %v", version, root, ok)
+		}
+	}
+
+	if _, _, ok := roots.find(context.Background(), "github.com/foo/bar", "v1.0.0"); ok {
+		t.Error("a module was mapped to a parent")
+	}
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d requests for known answers", n)
+	}
+}
+
+func TestImportModule(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/foo/bar/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		case "/github.com/foo/bar/sub/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v0.3.0"}`))
+		case "/example.com/down/pkg/@latest":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+
+	for _, tc := range []struct{ path, mod, version string }{
+		{"github.com/foo/bar", "github.com/foo/bar", "v1.2.0"},
+		{"github.com/foo/bar/pkg/util", "github.com/foo/bar", "v1.2.0"},
+		{"github.com/foo/bar/sub/x", "github.com/foo/bar/sub", "v0.3.0"},
+	} {
+		mod, info, err := importModule(context.Background(), proxy, tc.path)
+		if err != nil || mod != tc.mod || info.Version != tc.version {
+			t.Errorf("importModule(%s) = %q, %+v, %v; want %s %s", tc.path, mod, info, err, tc.mod, tc.version)
+		}
+	}
+
+	_, _, err := importModule(context.Background(), proxy, "github.com/nobody/x/y")
+	if !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("unknown path: got %v", err)
+	}
+
+	if _, _, err := importModule(context.Background(), proxy, "example.com/down/pkg"); err == nil ||
+		errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("proxy failure: got %v", err)
+	}
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// nestedModule is a module found in a repository.
+type nestedModule struct {
+	path      string
+	prefix    string // tag prefix, "" for the root module
+	latestTag string
+	versions  []string // tagged versions, in tag order
+	sources   []string // how the module was found
+	latest    string   // latest version on the proxy, "" if not found
+}
+
+// repoRoot returns the module path of a repository's root and its clone
+// URL for a module. The proxy's origin information is used when available;
+// otherwise hosting sites with host/owner/repo layouts are assumed, and
+// other hosts are asked for their go-import meta tags.
+func repoRoot(ctx context.Context, proxy *ProxyClient, mod string) (string, string) {
+	if info, err := proxy.Latest(ctx, mod); err == nil && info.Origin != nil && info.Origin.URL != "" {
+		root, _, _ := module.SplitPathVersion(mod)
+		if sub := info.Origin.Subdir; sub != "" {
+			root = strings.TrimSuffix(strings.TrimSuffix(root, sub), "/")
+		}
+
+		return root, info.Origin.URL
+	}
+
+	root, _, _ := module.SplitPathVersion(mod)
+
+	switch elems := strings.Split(root, "/"); elems[0] {
+	case "github.com", "gitlab.com", "bitbucket.org", "codeberg.org":
+		if len(elems) >= 3 {
+			root = strings.Join(elems[:3], "/")
+		}
+	default:
+		if proxy.vanity != nil {
+			if imp, err := proxy.vanity.Resolve(ctx, mod); err == nil && imp.vcs != "mod" {
+				return imp.prefix, imp.repo
+			}
+		}
+	}
+
+	return root, "https://" + root
+}
+
+// repoPathFromURL converts a clone URL to a module path by dropping the
+// scheme and a trailing ".git".
+func repoPathFromURL(url string) string {
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		url = rest
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+}
+
+// listTags returns the tag names of a remote repository.
+func listTags(ctx context.Context, url string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+
+	noteBackend(ctx, "git")
+
+	//nolint:gosec // The URL is passed after "--", so it cannot add options.
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--tags", "--refs", "--", url)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git ls-remote %s: %w: %s", url, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var tags []string
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			tags = append(tags, strings.TrimPrefix(ref, "refs/tags/"))
+		}
+	}
+
+	return tags, nil
+}
+
+// modulesFromTags groups semver tags by prefix. A tag "sub/v1.2.3" belongs
+// to the module in directory sub; from v2 on, the module path carries a
+// major version suffix that is not part of the tag prefix.
+func modulesFromTags(root string, tags []string) map[string]*nestedModule {
+	mods := make(map[string]*nestedModule)
+
+	for _, tag := range tags {
+		prefix, version := "", tag
+		if i := strings.LastIndex(tag, "/"); i >= 0 {
+			prefix, version = tag[:i], tag[i+1:]
+		}
+
+		if !semver.IsValid(version) || semver.Build(version) != "" {
+			continue
+		}
+
+		path := root
+		if prefix != "" {
+			path += "/" + prefix
+		}
+
+		if major := semver.Major(version); major != "v0" && major != "v1" {
+			path += "/" + major
+		}
+
+		m := mods[path]
+		if m == nil {
+			m = &nestedModule{path: path, prefix: prefix, sources: []string{"tags"}}
+			mods[path] = m
+		}
+
+		m.versions = append(m.versions, version)
+
+		if m.latestTag == "" || semver.Compare(version, m.latestTag) > 0 {
+			m.latestTag = version
+		}
+	}
+
+	return mods
+}
+
+// tagVersions lists the versions of mod tagged in its origin repository,
+// which include releases nobody has requested from the proxy yet. It also
+// returns the repository URL.
+func tagVersions(ctx context.Context, proxy *ProxyClient, mod string) ([]string, string, error) {
+	if proxy.offline {
+		return nil, "", ErrOffline
+	}
+
+	root, url := repoRoot(ctx, proxy, mod)
+
+	if err := proxy.govcs.Check(root, "git"); err != nil {
+		return nil, url, err
+	}
+
+	tags, err := listTags(ctx, url)
+	if err != nil {
+		return nil, url, err
+	}
+
+	m := modulesFromTags(root, tags)[mod]
+	if m == nil {
+		return nil, url, nil
+	}
+
+	semver.Sort(m.versions)
+
+	return m.versions, url, nil
+}
+
+// modulesFromReplaces finds modules of the repository that the root
+// go.mod replaces with a directory inside the repository.
+func modulesFromReplaces(root string, mf *modfile.File, mods map[string]*nestedModule) {
+	for _, r := range mf.Replace {
+		if !strings.HasPrefix(r.Old.Path, root+"/") || !modfile.IsDirectoryPath(r.New.Path) {
+			continue
+		}
+
+		if m := mods[r.Old.Path]; m != nil {
+			m.sources = append(m.sources, "replace")
+
+			continue
+		}
+
+		mods[r.Old.Path] = &nestedModule{
+			path:    r.Old.Path,
+			prefix:  strings.TrimPrefix(strings.TrimPrefix(r.New.Path, "./"), "/"),
+			sources: []string{"replace"},
+		}
+	}
+}
+
+// latestMainModFile parses the go.mod of a module's latest version as a
+// main module. Unlike latestModFile, which parses it as a dependency's,
+// this keeps the replace directives.
+func latestMainModFile(ctx context.Context, proxy *ProxyClient, mod string) (*modfile.File, error) {
+	latest, err := proxy.ResolveLatest(ctx, mod)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := proxy.ReadMod(ctx, mod, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	mf, err := modfile.Parse("go.mod", []byte(data), nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse go.mod: %w", err)
+	}
+
+	return mf, nil
+}
+
+// ownerModule finds the nested module that holds p, a path that is not
+// in mod's zip because a go.mod file below mod's root cuts it out. The
+// candidates are mod joined with each directory of p, deepest first; the
+// nested module's version is version when it is tagged along with mod,
+// and its latest version otherwise. It returns "" if no candidate is a
+// module the proxy knows.
+func ownerModule(ctx context.Context, proxy *ProxyClient, mod, version, p string) (string, string, string) {
+	for i := strings.LastIndex(p, "/"); i > 0; i = strings.LastIndex(p[:i], "/") {
+		dir := p[:i]
+		nested := mod + "/" + dir
+		if module.CheckPath(nested) != nil {
+			continue
+		}
+
+		rel := strings.TrimPrefix(p, dir+"/")
+
+		if _, err := proxy.Info(ctx, nested, version); err == nil {
+			return nested, version, rel
+		}
+
+		if latest, err := proxy.ResolveLatest(ctx, nested); err == nil {
+			return nested, latest, rel
+		}
+	}
+
+	return "", "", ""
+}
+
+// nestedModuleHint returns a message routing a request for p, which is
+// not in mod@version, to the nested module that holds it, or "".
+func nestedModuleHint(ctx context.Context, proxy *ProxyClient, mod, version, p string) string {
+	nested, nestedVersion, rel := ownerModule(ctx, proxy, mod, version, p)
+	if nested == "" {
+		return ""
+	}
+
+	hint := fmt.Sprintf("%s is not part of %s@%s: it belongs to the nested module %s. Use module %q, version %q",
+		p, mod, version, nested, nested, nestedVersion)
+	if rel != "" {
+		hint += fmt.Sprintf(" and path %q", rel)
+	}
+
+	return hint + " instead."
+}
+
+// findNestedModules lists the modules of the repository containing mod, or
+// cloned from url, and checks which of them the proxy knows. The root
+// module comes first.
+func findNestedModules(
+	ctx context.Context, proxy *ProxyClient, mod, url string,
+) (string, string, []*nestedModule, error) {
+	root := repoPathFromURL(url)
+
+	if mod != "" {
+		var derived string
+		if root, derived = repoRoot(ctx, proxy, mod); url == "" {
+			url = derived
+		}
+	}
+
+	if err := proxy.policy.Check(root); err != nil {
+		return root, url, nil, err
+	}
+
+	if err := proxy.govcs.Check(root, "git"); err != nil {
+		return root, url, nil, err
+	}
+
+	tags, err := listTags(ctx, url)
+	if err != nil {
+		return root, url, nil, err
+	}
+
+	mods := modulesFromTags(root, tags)
+
+	if mf, err := latestMainModFile(ctx, proxy, root); err == nil {
+		modulesFromReplaces(root, mf, mods)
+	}
+
+	if mods[root] == nil {
+		mods[root] = &nestedModule{path: root}
+	}
+
+	result := []*nestedModule{mods[root]}
+
+	for _, path := range sortedKeys(mods) {
+		if path != root {
+			result = append(result, mods[path])
+		}
+	}
+
+	parallelEach(result, func(_ int, m *nestedModule) {
+		if latest, err := proxy.ResolveLatest(ctx, m.path); err == nil {
+			m.latest = latest
+		}
+	})
+
+	return root, url, result, nil
+}
+
+func formatNestedModules(sb *strings.Builder, root, url string, mods []*nestedModule) {
+	fmt.Fprintf(sb, "Modules in repository %s (root module %s): %d\n\n", url, root, len(mods))
+
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "MODULE\tTAG PREFIX\tLATEST TAG\tPROXY LATEST\tTAG SERIES\tFOUND VIA")
+
+	for _, m := range mods {
+		prefix := "(root)"
+		if m.prefix != "" {
+			prefix = m.prefix + "/"
+		}
+
+		latestTag, latest := m.latestTag, m.latest
+		if latestTag == "" {
+			latestTag = "-"
+		}
+
+		if latest == "" {
+			latest = "not found"
+		}
+
+		sources := strings.Join(m.sources, ", ")
+		if sources == "" {
+			sources = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", m.path, prefix, latestTag, latest, tagSeries(m.versions), sources)
+	}
+
+	_ = tw.Flush()
+
+	if lockstep := lockstepModules(mods); lockstep > 0 {
+		fmt.Fprintf(sb, "\n%d of %d nested modules are tagged %s like the root module and are released with it.\n",
+			lockstep, len(mods)-1, mods[0].latestTag)
+	}
+
+	if len(mods) > 1 {
+		sb.WriteString("\nFiles of a nested module are only in that module's own archive: request them with " +
+			"its module path, not the root module's.\n")
+	}
+}
+
+// tagSeries summarizes the tagged versions of a module by major version,
+// such as "v0.1.0..v0.9.2, v1.0.0..v1.4.0 (14 tags)".
+func tagSeries(versions []string) string {
+	if len(versions) == 0 {
+		return "-"
+	}
+
+	sorted := slices.Clone(versions)
+	semver.Sort(sorted)
+
+	var series []string
+
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && semver.Major(sorted[j]) == semver.Major(sorted[i]) {
+			j++
+		}
+
+		if j-i == 1 {
+			series = append(series, sorted[i])
+		} else {
+			series = append(series, sorted[i]+".."+sorted[j-1])
+		}
+
+		i = j
+	}
+
+	tags := "tags"
+	if len(sorted) == 1 {
+		tags = "tag"
+	}
+
+	return fmt.Sprintf("%s (%d %s)", strings.Join(series, ", "), len(sorted), tags)
+}
+
+// lockstepModules counts the nested modules whose latest tag is the root
+// module's, as in repositories that release all modules together.
+func lockstepModules(mods []*nestedModule) int {
+	if len(mods) == 0 || mods[0].latestTag == "" {
+		return 0
+	}
+
+	n := 0
+
+	for _, m := range mods[1:] {
+		if m.latestTag == mods[0].latestTag {
+			n++
+		}
+	}
+
+	return n
+}
+
+// importOwner returns the module of the repository that provides the
+// package importPath: the one with the longest path that is a prefix of
+// it. It returns nil if none is.
+func importOwner(mods []*nestedModule, importPath string) *nestedModule {
+	var owner *nestedModule
+
+	for _, m := range mods {
+		if importPath != m.path && !strings.HasPrefix(importPath, m.path+"/") {
+			continue
+		}
+
+		if owner == nil || len(m.path) > len(owner.path) {
+			owner = m
+		}
+	}
+
+	return owner
+}
+
+func formatImportOwner(sb *strings.Builder, importPath string, mods []*nestedModule) {
+	owner := importOwner(mods, importPath)
+	if owner == nil {
+		fmt.Fprintf(sb, "\n%s is not in any module of this repository.\n", importPath)
+
+		return
+	}
+
+	fmt.Fprintf(sb, "\n%s is provided by module %s", importPath, owner.path)
+
+	if version := cmp.Or(owner.latest, owner.latestTag); version != "" {
+		fmt.Fprintf(sb, ": go get %s@%s", owner.path, version)
+	}
+
+	sb.WriteString("\n")
+}
+
+type nestedModulesInput struct {
+	Module string `json:"module,omitempty" jsonschema:"Any module of the repository (e.g. the repo-root module)"`
+	Repo   string `json:"repo,omitempty" jsonschema:"VCS URL of the repository, instead of deriving it from module"`
+	Import string `json:"import,omitempty" jsonschema:"Import path of a package, to find which module provides it"`
+}
+
+func handleNestedModules(
+	ctx context.Context, proxy *ProxyClient, input nestedModulesInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.Module == "" && input.Repo == "" {
+		return errorResult("Either module or repo is required."), nil, nil
+	}
+
+	if strings.HasPrefix(input.Repo, "-") {
+		return errorResult(fmt.Sprintf("Invalid repository URL %q.", input.Repo)), nil, nil
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return errorResult(fmt.Sprintf("git not found (%v); it is needed to list repository tags.", err)), nil, nil
+	}
+
+	root, url, mods, err := findNestedModules(ctx, proxy, input.Module, input.Repo)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return errorResult(fmt.Sprintf("Cannot list tags of %s: %v", url, err)), nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	formatNestedModules(&sb, root, url, mods)
+
+	if input.Import != "" {
+		formatImportOwner(&sb, input.Import, mods)
+	}
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestModulesFromTags(t *testing.T) {
+	mods := modulesFromTags("example.com/mono", []string{
+		"v1.0.0", "v1.1.0", "sub/v1.2.3", "sub/v1.10.0", "tools/v2.0.1", "a/b/v0.1.0",
+		"release-2024", "sub/v1.3.0+meta",
+	})
+
+	want := map[string][2]string{
+		"example.com/mono":          {"", "v1.1.0"},
+		"example.com/mono/sub":      {"sub", "v1.10.0"},
+		"example.com/mono/tools/v2": {"tools", "v2.0.1"},
+		"example.com/mono/a/b":      {"a/b", "v0.1.0"},
+	}
+
+	if len(mods) != len(want) {
+		t.Fatalf("got %d modules, want %d: %v", len(mods), len(want), sortedKeys(mods))
+	}
+
+	for path, w := range want {
+		m := mods[path]
+		if m == nil || m.prefix != w[0] || m.latestTag != w[1] {
+			t.Errorf("%s = %+v, want prefix %q latest %q", path, m, w[0], w[1])
+		}
+	}
+}
+
+func TestModulesFromReplaces(t *testing.T) {
+	mf, err := modfile.Parse("go.mod", []byte(`module example.com/mono
+
+require example.com/mono/sub v1.0.0
+
+replace (
+	example.com/mono/sub => ./sub
+	example.com/mono/internal/gen => ./internal/gen
+	example.com/other => ../other
+	example.com/mono/fork => example.com/fork v1.0.0
+)
+`), nil)
+	mustf(t, err, "parse go.mod")
+
+	mods := map[string]*nestedModule{
+		"example.com/mono/sub": {path: "example.com/mono/sub", prefix: "sub", sources: []string{"tags"}},
+	}
+
+	modulesFromReplaces("example.com/mono", mf, mods)
+
+	if got := strings.Join(mods["example.com/mono/sub"].sources, ","); got != "tags,replace" {
+		t.Errorf("sub sources = %s", got)
+	}
+
+	if m := mods["example.com/mono/internal/gen"]; m == nil || m.prefix != "internal/gen" {
+		t.Errorf("internal/gen = %+v", m)
+	}
+
+	if len(mods) != 2 {
+		t.Errorf("got modules %v", sortedKeys(mods))
+	}
+}
+
+func TestRepoPathFromURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://github.com/owner/repo":      "github.com/owner/repo",
+		"https://github.com/owner/repo.git":  "github.com/owner/repo",
+		"https://go.googlesource.com/tools/": "go.googlesource.com/tools",
+	} {
+		if got := repoPathFromURL(url); got != want {
+			t.Errorf("repoPathFromURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestRepoRoot(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/example.com/mono/sub/v2/@latest" {
+			_, _ = w.Write([]byte(`{"Version":"v2.0.0","Origin":{"VCS":"git",` +
+				`"URL":"https://git.example.com/mono","Subdir":"sub"}}`))
+
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, "/vanity.dev/kube") && r.URL.Query().Get("go-get") == "1" {
+			_, _ = w.Write([]byte(`<meta name="go-import" content="vanity.dev/kube git https://git.example.com/kube">`))
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+	proxy.vanity = NewVanityResolver()
+	proxy.vanity.baseURL = ts.URL
+
+	for mod, want := range map[string][2]string{
+		"example.com/mono/sub/v2":      {"example.com/mono", "https://git.example.com/mono"},
+		"vanity.dev/kube/api":          {"vanity.dev/kube", "https://git.example.com/kube"},
+		"github.com/owner/repo/sub/v3": {"github.com/owner/repo", "https://github.com/owner/repo"},
+		"example.org/vanity":           {"example.org/vanity", "https://example.org/vanity"},
+	} {
+		root, url := repoRoot(context.Background(), proxy, mod)
+		if root != want[0] || url != want[1] {
+			t.Errorf("repoRoot(%s) = %s, %s; want %s, %s", mod, root, url, want[0], want[1])
+		}
+	}
+}
+
+func TestOwnerModule(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mono/examples/@v/v1.2.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		case "/example.com/mono/tools/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v0.3.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+
+	for p, want := range map[string][3]string{
+		"examples/basic/main.go": {"example.com/mono/examples", "v1.2.0", "basic/main.go"},
+		"tools/gen/main.go":      {"example.com/mono/tools", "v0.3.0", "gen/main.go"},
+		"tools/":                 {"example.com/mono/tools", "v0.3.0", ""},
+		"internal/x.go":          {"", "", ""},
+		"main.go":                {"", "", ""},
+	} {
+		nested, version, rel := ownerModule(context.Background(), proxy, "example.com/mono", "v1.2.0", p)
+		if got := [3]string{nested, version, rel}; got != want {
+			t.Errorf("ownerModule(%s) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+// createTaggedRepo creates a git repository with the given tags on a
+// single commit.
+func createTaggedRepo(t *testing.T, tags ...string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+
+	mustf(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/mono\n"), 0o600), "write go.mod")
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+
+		out, err := cmd.CombinedOutput()
+		mustf(t, err, "git %v: %s", args, out)
+	}
+
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	for _, tag := range tags {
+		git("tag", tag)
+	}
+
+	return dir
+}
+
+func TestHandleNestedModules(t *testing.T) {
+	repo := createTaggedRepo(t, "v0.9.0", "v1.0.0", "sub/v0.5.0", "sub/v1.0.0", "tools/v2.0.1")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mono/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.0.0"}`))
+		case "/example.com/mono/@v/v1.0.0.mod":
+			_, _ = w.Write([]byte("module example.com/mono\n\nreplace example.com/mono/gen => ./gen\n"))
+		case "/example.com/mono/sub/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.0.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+
+	result, _, err := handleNestedModules(context.Background(), proxy,
+		nestedModulesInput{Module: "example.com/mono", Repo: repo, Import: "example.com/mono/sub/pkg"})
+	mustf(t, err, "nested modules")
+
+	text := resultText(t, result)
+
+	for _, want := range []string{
+		"(root module example.com/mono): 4",
+		"example.com/mono           (root)      v1.0.0      v1.0.0        v0.9.0, v1.0.0 (2 tags)  tags",
+		"example.com/mono/gen       gen/        -           not found     -                        replace",
+		"example.com/mono/sub       sub/        v1.0.0      v1.0.0        v0.5.0, v1.0.0 (2 tags)  tags",
+		"example.com/mono/tools/v2  tools/      v2.0.1      not found     v2.0.1 (1 tag)           tags",
+		"1 of 3 nested modules are tagged v1.0.0 like the root module",
+		"only in that module's own archive",
+		"example.com/mono/sub/pkg is provided by module example.com/mono/sub: go get example.com/mono/sub@v1.0.0",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	if !strings.Contains(strings.SplitN(text, "\n", 4)[3], "example.com/mono  ") {
+		t.Errorf("root module is not listed first:\n%s", text)
+	}
+}
+
+func TestTagSeries(t *testing.T) {
+	for _, tc := range []struct {
+		versions []string
+		want     string
+	}{
+		{nil, "-"},
+		{[]string{"v1.2.3"}, "v1.2.3 (1 tag)"},
+		{[]string{"v1.10.0", "v0.1.0", "v1.2.0", "v0.3.0-rc.1"}, "v0.1.0..v0.3.0-rc.1, v1.2.0..v1.10.0 (4 tags)"},
+	} {
+		if got := tagSeries(tc.versions); got != tc.want {
+			t.Errorf("tagSeries(%v) = %q, want %q", tc.versions, got, tc.want)
+		}
+	}
+}
+
+func TestImportOwner(t *testing.T) {
+	mods := []*nestedModule{
+		{path: "example.com/mono"}, {path: "example.com/mono/sub"}, {path: "example.com/mono/sub/v2"},
+	}
+
+	for importPath, want := range map[string]string{
+		"example.com/mono/pkg":        "example.com/mono",
+		"example.com/mono/sub":        "example.com/mono/sub",
+		"example.com/mono/subtle":     "example.com/mono",
+		"example.com/mono/sub/v2/pkg": "example.com/mono/sub/v2",
+		"example.com/other":           "",
+	} {
+		got := ""
+		if m := importOwner(mods, importPath); m != nil {
+			got = m.path
+		}
+
+		if got != want {
+			t.Errorf("importOwner(%s) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
+func TestHandleNestedModules_Errors(t *testing.T) {
+	result, _, err := handleNestedModules(context.Background(), &ProxyClient{}, nestedModulesInput{})
+	mustf(t, err, "nested modules")
+
+	if !result.IsError {
+		t.Error("expected an error without module or repo")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	result, _, err = handleNestedModules(context.Background(), &ProxyClient{offline: true},
+		nestedModulesInput{Repo: filepath.Join(t.TempDir(), "missing")})
+	mustf(t, err, "nested modules")
+
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, "Cannot list tags") {
+		t.Errorf("expected a tag listing error: %s", text)
+	}
+}
+
+func TestTagVersions(t *testing.T) {
+	repo := createTaggedRepo(t, "v1.1.0", "v1.0.0", "sub/v1.2.3", "v2.0.0")
+	origin := `{"Version":"v1.0.0","Origin":{"VCS":"git","URL":"` + repo + `"}}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/example.com/mono/@latest" {
+			_, _ = w.Write([]byte(origin))
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+
+	versions, url, err := tagVersions(context.Background(), proxy, "example.com/mono")
+	mustf(t, err, "tag versions")
+
+	if url != repo || strings.Join(versions, ",") != "v1.0.0,v1.1.0" {
+		t.Errorf("tagVersions = %v from %s", versions, url)
+	}
+
+	proxy.govcs, err = NewVCSPolicy("example.com:off", "")
+	mustf(t, err, "new VCS policy")
+
+	if _, _, err := tagVersions(context.Background(), proxy, "example.com/mono"); !errors.Is(err, ErrVCSDisallowed) {
+		t.Errorf("GOVCS off: got %v, want ErrVCSDisallowed", err)
+	}
+
+	proxy.offline = true
+
+	if _, _, err := tagVersions(context.Background(), proxy, "example.com/mono"); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline: got %v, want ErrOffline", err)
+	}
+}
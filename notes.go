@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type notesKey struct{}
+
+// callNotes collects remarks about how a tool call was answered, such as a
+// version chosen by a fallback, that belong in its result whatever tool it
+// is. Handlers may fetch concurrently, so it is locked.
+type callNotes struct {
+	mu    sync.Mutex
+	notes []string
+}
+
+// noteResult adds a remark to the result of the tool call running in ctx.
+// Repeated remarks are added once. It does nothing outside a tool call,
+// as in background jobs.
+func noteResult(ctx context.Context, note string) {
+	n, ok := ctx.Value(notesKey{}).(*callNotes)
+	if !ok {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !slices.Contains(n.notes, note) {
+		n.notes = append(n.notes, note)
+	}
+}
+
+// notesMiddleware appends the remarks noted during a tool call to its
+// result as a separate text block, so that they never mix with content
+// such as a file's.
+func notesMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			n := &callNotes{}
+
+			result, err := next(context.WithValue(ctx, notesKey{}, n), method, req)
+			if res, ok := result.(*mcp.CallToolResult); ok && err == nil && len(n.notes) > 0 {
+				res.Content = append(res.Content, &mcp.TextContent{Text: "Note: " + strings.Join(n.notes, "\nNote: ")})
+			}
+
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNoteResult(t *testing.T) {
+	// Outside a tool call, notes are dropped.
+	noteResult(context.Background(), "ignored")
+
+	notes := &callNotes{}
+	ctx := context.WithValue(context.Background(), notesKey{}, notes)
+
+	noteResult(ctx, "first")
+	noteResult(ctx, "second")
+	noteResult(ctx, "first")
+
+	if len(notes.notes) != 2 || notes.notes[0] != "first" || notes.notes[1] != "second" {
+		t.Errorf("notes = %q, want first and second once each", notes.notes)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	defaultOSVURL = "https://api.osv.dev"
+	// osvBatchSize is the maximum number of queries per querybatch call.
+	osvBatchSize = 1000
+)
+
+// OSVClient queries the OSV vulnerability database.
+type OSVClient struct {
+	apiClient
+}
+
+func NewOSVClient() *OSVClient {
+	return &OSVClient{apiClient{baseURL: defaultOSVURL, client: http.DefaultClient}}
+}
+
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type osvQuery struct {
+	Package osvPackage `json:"package"`
+	Version string     `json:"version"`
+}
+
+// osvVuln is the subset of an OSV record used in reports.
+type osvVuln struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package osvPackage `json:"package"`
+		Ranges  []struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Introduced string `json:"introduced,omitempty"`
+				Fixed      string `json:"fixed,omitempty"`
+			} `json:"events"`
+		} `json:"ranges"`
+		EcosystemSpecific struct {
+			Imports []struct {
+				Path    string   `json:"path"`
+				Symbols []string `json:"symbols"`
+			} `json:"imports"`
+		} `json:"ecosystem_specific"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// osvVersion converts a Go module version to the OSV Go ecosystem form,
+// which has no "v" prefix.
+func osvVersion(v string) string {
+	return strings.TrimPrefix(v, "v")
+}
+
+// QueryBatch returns the IDs of vulnerabilities affecting each module
+// version, in input order.
+func (c *OSVClient) QueryBatch(ctx context.Context, versions []module.Version) ([][]string, error) {
+	result := make([][]string, 0, len(versions))
+
+	for start := 0; start < len(versions); start += osvBatchSize {
+		batch := versions[start:min(start+osvBatchSize, len(versions))]
+
+		req := struct {
+			Queries []osvQuery `json:"queries"`
+		}{Queries: make([]osvQuery, len(batch))}
+
+		for i, v := range batch {
+			req.Queries[i] = osvQuery{
+				Package: osvPackage{Name: v.Path, Ecosystem: "Go"},
+				Version: osvVersion(v.Version),
+			}
+		}
+
+		var resp struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+			} `json:"results"`
+		}
+
+		if err := c.postJSON(ctx, "/v1/querybatch", req, &resp); err != nil {
+			return nil, err
+		}
+
+		if len(resp.Results) != len(batch) {
+			return nil, fmt.Errorf("osv: got %d results for %d queries", len(resp.Results), len(batch))
+		}
+
+		for _, r := range resp.Results {
+			ids := make([]string, len(r.Vulns))
+			for i, v := range r.Vulns {
+				ids[i] = v.ID
+			}
+
+			result = append(result, ids)
+		}
+	}
+
+	return result, nil
+}
+
+// Vuln returns the full OSV record for id.
+func (c *OSVClient) Vuln(ctx context.Context, id string) (*osvVuln, error) {
+	var v osvVuln
+
+	if err := c.getJSON(ctx, "/v1/vulns/"+url.PathEscape(id), &v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// fixedVersions returns the versions fixing the vulnerability in mod, with
+// the "v" prefix restored.
+func (v *osvVuln) fixedVersions(mod string) []string {
+	var fixed []string
+
+	for _, a := range v.Affected {
+		if a.Package.Name != mod {
+			continue
+		}
+
+		for _, r := range a.Ranges {
+			for _, e := range r.Events {
+				if e.Fixed != "" {
+					fixed = append(fixed, "v"+strings.TrimPrefix(e.Fixed, "v"))
+				}
+			}
+		}
+	}
+
+	return fixed
+}
+
+// affectedSymbols returns the vulnerable functions and methods of mod the
+// Go vulnerability database names, as package.Symbol, or a package alone
+// when all of it is affected.
+func (v *osvVuln) affectedSymbols(mod string) []string {
+	var symbols []string
+
+	for _, a := range v.Affected {
+		if a.Package.Name != mod {
+			continue
+		}
+
+		for _, imp := range a.EcosystemSpecific.Imports {
+			if len(imp.Symbols) == 0 {
+				symbols = append(symbols, imp.Path)
+			}
+
+			for _, s := range imp.Symbols {
+				symbols = append(symbols, imp.Path+"."+s)
+			}
+		}
+	}
+
+	return symbols
+}
+
+// title returns the summary, or the first line of the details.
+func (v *osvVuln) title() string {
+	if v.Summary != "" {
+		return v.Summary
+	}
+
+	first, _, _ := strings.Cut(strings.TrimSpace(v.Details), "\n")
+
+	return first
+}
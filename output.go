@@ -0,0 +1,535 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Output formats of tool text, set for the server with -output-format
+// and per call with the format argument. json is per call only.
+const (
+	outputPlain    = "plain"    // text as the tools write it
+	outputMarkdown = "markdown" // file contents fenced, tables as Markdown tables
+	outputJSON     = "json"     // a JSON object with the structured content
+)
+
+// outputFormats are the accepted values of the format argument of
+// structuredTools.
+var outputFormats = []string{outputPlain, outputMarkdown, outputJSON}
+
+// structuredTools are the tools that return structured content, the
+// only ones offering the json format, mapped to the boolean argument
+// that turns it on, if any. Other tools write text alone, and the json
+// format is not inferred from it.
+var structuredTools = map[string]string{
+	"gomod_read_mod": "structured",
+}
+
+// fencedTools are the tools whose output is file content, fenced as a
+// whole in Markdown. The language of gomod_read_file follows the path.
+var fencedTools = map[string]string{
+	"gomod_read_file":    "",
+	"gomod_read_mod":     "gomod",
+	"gomod_compare_file": "diff",
+	"gomod_diff":         "diff",
+	"gomod_tags":         "text",
+	"gomod_sbom":         "json",
+}
+
+// fenceLanguages maps file extensions to Markdown code fence languages.
+var fenceLanguages = map[string]string{
+	".go":    "go",
+	".mod":   "gomod",
+	".s":     "asm",
+	".c":     "c",
+	".h":     "c",
+	".proto": "protobuf",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".md":    "markdown",
+	".sh":    "sh",
+}
+
+// checkOutputFormat returns an error naming the formats if format is not
+// one of them.
+func checkOutputFormat(format string, formats []string) error {
+	if slices.Contains(formats, format) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown output format %q: want %s", format, strings.Join(formats, ", "))
+}
+
+// outputFormatter renders tool results in the server's output format, or
+// the one a call asks for. Tools with a format argument of their own,
+// like gomod_tags, keep it and get the server's format.
+type outputFormatter struct {
+	format string
+
+	mu           sync.Mutex
+	ownsArg      map[string]bool // tools declaring a format argument
+	listed       bool
+	argument     *jsonschema.Schema // for structuredTools
+	textArgument *jsonschema.Schema // for the other tools
+}
+
+func newOutputFormatter(format string) *outputFormatter {
+	return &outputFormatter{
+		format:       format,
+		ownsArg:      make(map[string]bool),
+		argument:     formatArgument(outputFormats, format),
+		textArgument: formatArgument(textFormats, format),
+	}
+}
+
+// textFormats are the accepted values of -output-format and the output
+// formats of tools without structured content.
+var textFormats = []string{outputPlain, outputMarkdown}
+
+func formatArgument(formats []string, def string) *jsonschema.Schema {
+	enum := make([]any, len(formats))
+	for i, f := range formats {
+		enum[i] = f
+	}
+
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        enum,
+		Description: "Output format: '" + strings.Join(formats, "', '") + "' (default " + def + ")",
+	}
+}
+
+// Middleware adds the format argument to the listed tools, strips it from
+// calls before they are validated and renders their text results.
+func (o *outputFormatter) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch method {
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					o.addArgument(list)
+				}
+
+				return result, err
+			case "tools/call":
+				if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil {
+					return o.call(ctx, next, call)
+				}
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func (o *outputFormatter) call(
+	ctx context.Context, next mcp.MethodHandler, call *mcp.CallToolRequest,
+) (mcp.Result, error) {
+	owns, err := o.ownsFormat(ctx, next, call)
+	if err != nil {
+		return nil, err
+	}
+
+	tool := call.Params.Name
+	structuredArg, structured := structuredTools[tool]
+	format := o.format
+
+	var args map[string]any
+
+	err = rewriteArguments(call, func(a map[string]any) bool {
+		args = a
+
+		v, ok := a["format"]
+		if owns {
+			return false
+		}
+
+		if s, _ := v.(string); s != "" {
+			format = s
+		}
+
+		// json needs the tool's structured content.
+		if format == outputJSON && structuredArg != "" && a[structuredArg] != true {
+			a[structuredArg] = true
+			ok = true
+		}
+
+		delete(a, "format")
+
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if checkOutputFormat(format, outputFormats) != nil {
+		return errorResult(fmt.Sprintf("Unknown output format %q; use %s.", format, strings.Join(outputFormats, ", "))), nil
+	}
+
+	if format == outputJSON && !structured && !owns {
+		return errorResult(fmt.Sprintf("%s returns no structured content, so it has no json format; use %s.",
+			tool, strings.Join(textFormats, " or "))), nil
+	}
+
+	result, err := next(ctx, "tools/call", call)
+	if res, ok := result.(*mcp.CallToolResult); ok && err == nil {
+		renderResult(res, tool, args, format)
+	}
+
+	return result, err
+}
+
+// ownsFormat reports whether the called tool declares a format argument,
+// listing the server's tools the first time it is needed.
+func (o *outputFormatter) ownsFormat(
+	ctx context.Context, next mcp.MethodHandler, call *mcp.CallToolRequest,
+) (bool, error) {
+	o.mu.Lock()
+	listed := o.listed
+	o.mu.Unlock()
+
+	if !listed {
+		result, err := next(ctx, "tools/list", &mcp.ListToolsRequest{Session: call.Session, Params: &mcp.ListToolsParams{}})
+		if err != nil {
+			return false, fmt.Errorf("list tools: %w", err)
+		}
+
+		if list, ok := result.(*mcp.ListToolsResult); ok {
+			o.addArgument(list)
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.ownsArg[call.Params.Name], nil
+}
+
+// addArgument replaces each listed tool without a format argument by a
+// copy whose schema has one.
+func (o *outputFormatter) addArgument(list *mcp.ListToolsResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.listed = true
+
+	for i, t := range list.Tools {
+		schema, ok := t.InputSchema.(*jsonschema.Schema)
+		if !ok {
+			continue
+		}
+
+		if schema.Properties["format"] != nil {
+			o.ownsArg[t.Name] = true
+
+			continue
+		}
+
+		extended := *schema
+		extended.Properties = maps.Clone(schema.Properties)
+
+		if extended.Properties == nil {
+			extended.Properties = make(map[string]*jsonschema.Schema)
+		}
+
+		extended.Properties["format"] = o.textArgument
+		if _, ok := structuredTools[t.Name]; ok {
+			extended.Properties["format"] = o.argument
+		}
+
+		tool := *t
+		tool.InputSchema = &extended
+		list.Tools[i] = &tool
+	}
+}
+
+// renderResult rewrites the text content of a tool result in format.
+func renderResult(res *mcp.CallToolResult, tool string, args map[string]any, format string) {
+	if format == outputPlain {
+		return
+	}
+
+	if format == outputJSON {
+		if res.IsError || res.StructuredContent != nil {
+			res.Content = []mcp.Content{&mcp.TextContent{Text: renderJSON(tool, res)}}
+		}
+
+		return
+	}
+
+	for i, c := range res.Content {
+		text, ok := c.(*mcp.TextContent)
+		if !ok {
+			continue
+		}
+
+		res.Content[i] = &mcp.TextContent{Text: renderMarkdown(tool, args, text.Text, res.IsError)}
+	}
+}
+
+// jsonOutput is the JSON rendering of a tool result: its structured
+// content, or the text of an error.
+type jsonOutput struct {
+	Tool   string          `json:"tool"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+func renderJSON(tool string, res *mcp.CallToolResult) string {
+	out := jsonOutput{Tool: tool}
+
+	var text strings.Builder
+
+	for _, c := range res.Content {
+		if t, ok := c.(*mcp.TextContent); ok {
+			text.WriteString(t.Text)
+		}
+	}
+
+	if res.IsError {
+		out.Error = strings.TrimSpace(text.String())
+	} else {
+		result, err := json.Marshal(res.StructuredContent)
+		if err != nil {
+			return text.String()
+		}
+
+		out.Result = result
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return text.String()
+	}
+
+	return string(data)
+}
+
+func renderMarkdown(tool string, args map[string]any, text string, isError bool) string {
+	lang, fenced := fencedTools[tool]
+
+	if structured, _ := args["structured"].(bool); tool == "gomod_read_mod" && structured {
+		lang = "json"
+	}
+
+	if tool == "gomod_read_file" {
+		mode, _ := args["mode"].(string)
+		filePath, _ := args["path"].(string)
+
+		fenced = mode != readModeComments
+		lang = fenceLanguages[path.Ext(filePath)]
+	}
+
+	if fenced && !isError {
+		return fence(text, lang)
+	}
+
+	var sb strings.Builder
+
+	for _, b := range splitTextBlocks(text) {
+		// Tables need a blank line between them and other text.
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n\n") {
+			sb.WriteByte('\n')
+		}
+
+		if b.table != nil {
+			writeMarkdownTable(&sb, b.table)
+
+			continue
+		}
+
+		// Single newlines would join the lines into one paragraph; end
+		// them with a hard line break instead.
+		for j, line := range b.lines {
+			sb.WriteString(line)
+
+			if j < len(b.lines)-1 && line != "" && b.lines[j+1] != "" {
+				sb.WriteString("  ")
+			}
+
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
+
+var backtickRun = regexp.MustCompile("`+")
+
+// fence wraps text in a code fence longer than any backtick run in it.
+func fence(text, lang string) string {
+	longest := 0
+
+	for _, run := range backtickRun.FindAllString(text, -1) {
+		longest = max(longest, len(run))
+	}
+
+	marker := strings.Repeat("`", max(longest+1, 3))
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	return marker + lang + "\n" + text + marker + "\n"
+}
+
+func writeMarkdownTable(sb *strings.Builder, t *textTable) {
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+
+		for _, c := range cells {
+			sb.WriteString(" " + strings.ReplaceAll(c, "|", `\|`) + " |")
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	writeRow(t.columns)
+
+	sb.WriteString("|")
+	sb.WriteString(strings.Repeat(" --- |", len(t.columns)))
+	sb.WriteByte('\n')
+
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+}
+
+// textTable is a table written with text/tabwriter under a header of
+// upper-case column names.
+type textTable struct {
+	columns []string
+	rows    [][]string
+}
+
+// textBlock is either a table or a run of other lines of tool text.
+type textBlock struct {
+	table *textTable
+	lines []string
+}
+
+// headerCell matches the cells of a table header: words separated by
+// single spaces, as opposed to the column gaps of two or more.
+var headerCell = regexp.MustCompile(`\S+(?: \S+)*`)
+
+// splitTextBlocks splits tool text into tables and the lines between
+// them. A table starts at a line of at least two upper-case cells and
+// runs while the lines below it align with its columns.
+func splitTextBlocks(text string) []textBlock {
+	var (
+		blocks []textBlock
+		lines  []string
+	)
+
+	all := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	for i := 0; i < len(all); {
+		t, n := parseTable(all[i:])
+		if t == nil {
+			lines = append(lines, all[i])
+			i++
+
+			continue
+		}
+
+		if len(lines) > 0 {
+			blocks = append(blocks, textBlock{lines: lines})
+			lines = nil
+		}
+
+		blocks = append(blocks, textBlock{table: t})
+		i += n
+	}
+
+	if len(lines) > 0 {
+		blocks = append(blocks, textBlock{lines: lines})
+	}
+
+	return blocks
+}
+
+// parseTable parses the table at the start of lines and returns it with
+// the number of lines it spans, or nil if lines do not start with one.
+func parseTable(lines []string) (*textTable, int) {
+	matches := headerCell.FindAllStringIndex(lines[0], -1)
+	if len(matches) < 2 {
+		return nil, 0
+	}
+
+	t := &textTable{}
+
+	var starts []int
+
+	for _, m := range matches {
+		name := lines[0][m[0]:m[1]]
+		if strings.ToUpper(name) != name || !strings.ContainsAny(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+			return nil, 0
+		}
+
+		t.columns = append(t.columns, name)
+		starts = append(starts, len([]rune(lines[0][:m[0]])))
+	}
+
+	n := 1
+
+	for ; n < len(lines); n++ {
+		row, ok := splitRow([]rune(lines[n]), starts)
+		if !ok {
+			break
+		}
+
+		t.rows = append(t.rows, row)
+	}
+
+	if len(t.rows) == 0 {
+		return nil, 0
+	}
+
+	return t, n
+}
+
+// splitRow cuts a table line at the column starts. It fails for blank
+// lines and lines whose text crosses a column start.
+func splitRow(line []rune, starts []int) ([]string, bool) {
+	if strings.TrimSpace(string(line)) == "" {
+		return nil, false
+	}
+
+	cells := make([]string, len(starts))
+
+	for i, start := range starts {
+		if start > len(line) {
+			continue
+		}
+
+		if start > 0 && line[start-1] != ' ' {
+			return nil, false
+		}
+
+		if i == 0 && strings.TrimSpace(string(line[:start])) != "" {
+			return nil, false
+		}
+
+		end := len(line)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+
+		cells[i] = strings.TrimSpace(string(line[start:end]))
+	}
+
+	return cells, true
+}
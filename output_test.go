@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tableText = `Directory statistics for example.com/m@v1.0.0 (2 directories):
+
+DIRECTORY  FILES  GO FILES
+.          3      2
+internal   1      1
+`
+
+func TestSplitTextBlocks(t *testing.T) {
+	blocks := splitTextBlocks(tableText)
+	if len(blocks) != 2 || blocks[1].table == nil {
+		t.Fatalf("got %+v, want text then a table", blocks)
+	}
+
+	want := &textTable{
+		columns: []string{"DIRECTORY", "FILES", "GO FILES"},
+		rows:    [][]string{{".", "3", "2"}, {"internal", "1", "1"}},
+	}
+	if !reflect.DeepEqual(blocks[1].table, want) {
+		t.Errorf("table = %+v, want %+v", blocks[1].table, want)
+	}
+
+	for _, text := range []string{
+		"ALIAS  MODULE\n",            // header without rows
+		"Found 2 files:\nOK  done\n", // mixed case cells
+		"A  B\nx y z w\n",            // row text crossing a column
+	} {
+		for _, b := range splitTextBlocks(text) {
+			if b.table != nil {
+				t.Errorf("%q: unexpected table %+v", text, b.table)
+			}
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	got := renderMarkdown("gomod_dir_stats", nil, tableText, false)
+
+	want := "Directory statistics for example.com/m@v1.0.0 (2 directories):\n\n" +
+		"| DIRECTORY | FILES | GO FILES |\n| --- | --- | --- |\n| . | 3 | 2 |\n| internal | 1 | 1 |\n"
+	if got != want {
+		t.Errorf("table:\ngot  %q\nwant %q", got, want)
+	}
+
+	if got := renderMarkdown("gomod_stats", nil, "one\ntwo\n\nthree\n", false); got != "one  \ntwo\n\nthree\n" {
+		t.Errorf("lines = %q", got)
+	}
+
+	args := map[string]any{"path": "a/b.go"}
+	if got := renderMarkdown("gomod_read_file", args, "x := \"```\"\n", false); got != "````go\nx := \"```\"\n````\n" {
+		t.Errorf("fenced file = %q", got)
+	}
+
+	args["mode"] = readModeComments
+	if got := renderMarkdown("gomod_read_file", args, "a.go:1 func F\n  F does.\n", false); strings.Contains(got, "```") {
+		t.Errorf("comments mode fenced: %q", got)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	res := textResult("{\n  \"module\": \"example.com/m\"\n}\n")
+	res.StructuredContent = json.RawMessage(`{"module":"example.com/m"}`)
+
+	got := renderJSON("gomod_read_mod", res)
+	if got != `{"tool":"gomod_read_mod","result":{"module":"example.com/m"}}` {
+		t.Errorf("result = %s", got)
+	}
+
+	got = renderJSON("gomod_read_mod", errorResult("Module not found.\n"))
+	if got != `{"tool":"gomod_read_mod","error":"Module not found."}` {
+		t.Errorf("error = %s", got)
+	}
+}
+
+func TestCheckOutputFormat(t *testing.T) {
+	for _, f := range outputFormats {
+		if err := checkOutputFormat(f, outputFormats); err != nil {
+			t.Errorf("%s: %v", f, err)
+		}
+	}
+
+	err := checkOutputFormat("html", outputFormats)
+	if err == nil || !strings.Contains(err.Error(), "plain, markdown, json") {
+		t.Errorf("html: got %v", err)
+	}
+
+	// json is not a server-wide format, only gomod_read_mod has it.
+	err = checkOutputFormat(outputJSON, textFormats)
+	if err == nil || !strings.Contains(err.Error(), "plain, markdown") {
+		t.Errorf("json for the server: got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const maxPackageSynopsisWidth = 100
+
+// modulePackage is a package directory of a module.
+type modulePackage struct {
+	dir        string // "." for the module root
+	importPath string
+	name       string // from the package clause
+	synopsis   string
+}
+
+// findPackages lists the packages under prefix with their name and the
+// synopsis of their package comment. A directory is a package if it holds
+// a non-test Go source file; the comment of doc.go is preferred when
+// several files carry one.
+func findPackages(mf moduleFiles, mod, prefix string) ([]*modulePackage, error) {
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+
+	sort.Strings(paths)
+
+	byDir := make(map[string]*modulePackage)
+	fromDocGo := make(map[string]bool)
+
+	for _, p := range paths {
+		if !isGoSource(p) || strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+
+		dir := path.Dir(p)
+
+		pkg := byDir[dir]
+		if pkg == nil {
+			pkg = &modulePackage{dir: dir, importPath: mod}
+			if dir != "." {
+				pkg.importPath += "/" + dir
+			}
+
+			byDir[dir] = pkg
+		}
+
+		if fromDocGo[dir] {
+			continue
+		}
+
+		isDocGo := path.Base(p) == "doc.go"
+		if pkg.synopsis != "" && !isDocGo {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		name, synopsis := packageClause(p, src)
+		if pkg.name == "" {
+			pkg.name = name
+		}
+
+		if synopsis != "" {
+			pkg.synopsis = synopsis
+			fromDocGo[dir] = isDocGo
+		}
+	}
+
+	pkgs := make([]*modulePackage, 0, len(byDir))
+
+	for _, dir := range sortedKeys(byDir) {
+		pkgs = append(pkgs, byDir[dir])
+	}
+
+	return pkgs, nil
+}
+
+func formatPackages(module, version string, pkgs []*modulePackage) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Packages in %s@%s (%d):\n\n", module, version, len(pkgs))
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PACKAGE\tDIR\tNAME\tSYNOPSIS")
+
+	for _, p := range pkgs {
+		synopsis := p.synopsis
+		if synopsis == "" {
+			synopsis = "-"
+		} else if len(synopsis) > maxPackageSynopsisWidth {
+			synopsis = synopsis[:maxPackageSynopsisWidth-3] + "..."
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.importPath, p.dir, cmp.Or(p.name, "-"), synopsis)
+	}
+
+	_ = tw.Flush()
+
+	return sb.String()
+}
+
+type packagesInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional directory prefix filter"`
+}
+
+func handlePackages(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input packagesInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkgs, err := findPackages(mf, input.Module, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(pkgs) == 0 {
+		return errorResult(fmt.Sprintf("No Go packages under %q in %s@%s.", input.Path, input.Module, version)), nil, nil
+	}
+
+	return textResult(formatPackages(input.Module, version, pkgs)), nil, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestFindPackages(t *testing.T) {
+	mf := testModuleFiles(t, "v1.0.0", map[string]string{
+		"go.mod":               "module example.com/m\n",
+		"m.go":                 "// Package m does things.\npackage m\n",
+		"a/a.go":               "// Package a is described here.\npackage a\n",
+		"a/doc.go":             "// Package a is described in doc.go. More text.\npackage a\n",
+		"b/b.go":               "package b\n",
+		"b/b_test.go":          "// Package b_test tests b.\npackage b_test\n",
+		"c/c_test.go":          "package c\n",
+		"a/testdata/x/x.go":    "package x\n",
+		"cmd/tool/main.go":     "// Tool is a command.\npackage main\n",
+		"cmd/tool/README.md":   "# tool\n",
+		"internal/z/z.go":      "package z\n",
+		"internal/z/z_mock.go": "// Package z has a mock.\npackage z\n",
+	})
+
+	pkgs, err := findPackages(mf, "example.com/m", "")
+	mustf(t, err, "find packages")
+
+	want := "Packages in example.com/m@v1.0.0 (5):\n\n" +
+		"PACKAGE                   DIR         NAME  SYNOPSIS\n" +
+		"example.com/m             .           m     Package m does things.\n" +
+		"example.com/m/a           a           a     Package a is described in doc.go.\n" +
+		"example.com/m/b           b           b     -\n" +
+		"example.com/m/cmd/tool    cmd/tool    main  Tool is a command.\n" +
+		"example.com/m/internal/z  internal/z  z     Package z has a mock.\n"
+
+	if got := formatPackages("example.com/m", "v1.0.0", pkgs); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	pkgs, err = findPackages(mf, "example.com/m", "cmd/")
+	mustf(t, err, "find packages under cmd")
+
+	if len(pkgs) != 1 || pkgs[0].importPath != "example.com/m/cmd/tool" {
+		t.Errorf("under cmd/: got %+v", pkgs)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// perfSamples is the number of recent call durations kept per tool for
+// the latency percentiles.
+const perfSamples = 1000
+
+// cacheBackends are the backends that answer without the network. A call
+// that used only these was served from cache.
+var cacheBackends = map[string]bool{
+	backendModCache:  true,
+	backendZipCache:  true,
+	backendDiskCache: true,
+	backendMetaCache: true,
+}
+
+// toolPerf is what PerfStats keeps about one tool.
+type toolPerf struct {
+	calls   int
+	errors  int
+	cached  int // calls served from cache alone
+	fetched int // calls that used the network or a local command
+	total   time.Duration
+	max     time.Duration
+	recent  []time.Duration // ring of the last perfSamples durations
+	next    int
+}
+
+func (t *toolPerf) add(d time.Duration) {
+	t.calls++
+	t.total += d
+	t.max = max(t.max, d)
+
+	if len(t.recent) < perfSamples {
+		t.recent = append(t.recent, d)
+
+		return
+	}
+
+	t.recent[t.next] = d
+	t.next = (t.next + 1) % perfSamples
+}
+
+// percentile returns the duration below which a fraction p of the recent
+// calls fall, by the nearest-rank method.
+func (t *toolPerf) percentile(p float64) time.Duration {
+	if len(t.recent) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(t.recent)
+	slices.Sort(sorted)
+
+	rank := int(p*float64(len(sorted))+0.5) - 1
+
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// PerfStats counts tool calls, their latency and the backends that served
+// them, so that users can see where time goes and whether the caches help.
+type PerfStats struct {
+	mu       sync.Mutex
+	since    time.Time
+	tools    map[string]*toolPerf
+	backends map[string]int // calls using each backend
+	logged   int            // calls at the last log summary
+	now      func() time.Time
+}
+
+// NewPerfStats creates empty stats.
+func NewPerfStats() *PerfStats {
+	p := &PerfStats{now: time.Now}
+	p.Reset()
+
+	return p
+}
+
+// Reset forgets all calls recorded so far.
+func (p *PerfStats) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.since = p.now()
+	p.tools = make(map[string]*toolPerf)
+	p.backends = make(map[string]int)
+	p.logged = 0
+}
+
+// record adds a finished tool call.
+func (p *PerfStats) record(tool string, d time.Duration, failed bool, backends []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.tools[tool]
+	if t == nil {
+		t = &toolPerf{}
+		p.tools[tool] = t
+	}
+
+	t.add(d)
+
+	if failed {
+		t.errors++
+	}
+
+	network := false
+
+	for _, b := range backends {
+		p.backends[b]++
+		network = network || !cacheBackends[b]
+	}
+
+	switch {
+	case len(backends) == 0:
+	case network:
+		t.fetched++
+	default:
+		t.cached++
+	}
+}
+
+// Middleware times every tools/call request and records the backends the
+// call used.
+func (p *PerfStats) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			start := p.now()
+			ctx, backends := withCallBackends(ctx)
+
+			result, err := next(ctx, method, req)
+
+			res, _ := result.(*mcp.CallToolResult)
+			failed := err != nil || res == nil || res.IsError
+
+			p.record(call.Params.Name, p.now().Sub(start), failed, backends.list())
+
+			return result, err
+		}
+	}
+}
+
+// Schedule registers a periodic log summary on s. An interval of zero
+// disables it.
+func (p *PerfStats) Schedule(s *Scheduler, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	s.Every("perf-summary", interval, func(context.Context) error {
+		if line := p.summary(); line != "" {
+			log.Print(line)
+		}
+
+		return nil
+	})
+}
+
+// summary returns a one-line digest for the log, or "" when there were no
+// calls since the last one.
+func (p *PerfStats) summary() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	calls, failed, cached, fetched := 0, 0, 0, 0
+
+	for _, t := range p.tools {
+		calls += t.calls
+		failed += t.errors
+		cached += t.cached
+		fetched += t.fetched
+	}
+
+	if calls == p.logged {
+		return ""
+	}
+
+	p.logged = calls
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "perf: %d tool calls since %s (%d errors), cache hits %s",
+		calls, p.since.Format(time.RFC3339), failed, hitRate(cached, fetched))
+
+	var top []string
+
+	for _, name := range p.byTotalTime() {
+		if len(top) == 3 {
+			break
+		}
+
+		t := p.tools[name]
+		top = append(top, fmt.Sprintf("%s %s over %d calls (p95 %s)",
+			name, t.total.Round(time.Millisecond), t.calls, t.percentile(0.95).Round(time.Millisecond)))
+	}
+
+	sb.WriteString("; most time in " + strings.Join(top, ", "))
+
+	return sb.String()
+}
+
+// byTotalTime returns the tool names, the most time spent first. The
+// caller holds p.mu.
+func (p *PerfStats) byTotalTime() []string {
+	names := sortedKeys(p.tools)
+
+	slices.SortStableFunc(names, func(a, b string) int {
+		return cmp.Compare(p.tools[b].total, p.tools[a].total)
+	})
+
+	return names
+}
+
+// hitRate formats the share of calls served from cache.
+func hitRate(cached, fetched int) string {
+	if cached+fetched == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d/%d (%.0f%%)", cached, cached+fetched, 100*float64(cached)/float64(cached+fetched))
+}
+
+type perfStatsInput struct {
+	Reset bool `json:"reset,omitempty" jsonschema:"Clear the stats after reporting them"`
+}
+
+func handlePerfStats(perf *PerfStats, input perfStatsInput) (*mcp.CallToolResult, any, error) {
+	text := perf.format()
+
+	if input.Reset {
+		perf.Reset()
+
+		text += "\nStats reset.\n"
+	}
+
+	return textResult(text), nil, nil
+}
+
+func (p *PerfStats) format() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var sb strings.Builder
+
+	calls := 0
+
+	for _, t := range p.tools {
+		calls += t.calls
+	}
+
+	fmt.Fprintf(&sb, "Tool calls since %s: %d\n", p.since.Format(time.RFC3339), calls)
+
+	if calls == 0 {
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "\nLatency over the last %d calls of each tool, most total time first. "+
+		"Cache hits are calls served without the network.\n\n", perfSamples)
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TOOL\tCALLS\tERRORS\tP50\tP95\tMAX\tTOTAL\tCACHE HITS")
+
+	for _, name := range p.byTotalTime() {
+		t := p.tools[name]
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", name, t.calls, t.errors,
+			t.percentile(0.50).Round(time.Microsecond), t.percentile(0.95).Round(time.Microsecond),
+			t.max.Round(time.Microsecond), t.total.Round(time.Millisecond), hitRate(t.cached, t.fetched))
+	}
+
+	_ = tw.Flush()
+
+	if len(p.backends) > 0 {
+		fmt.Fprintf(&sb, "\nBackends (%d), by calls using each:\n", len(p.backends))
+
+		names := sortedKeys(p.backends)
+		slices.SortStableFunc(names, func(a, b string) int { return cmp.Compare(p.backends[b], p.backends[a]) })
+
+		for _, name := range names {
+			kind := "network"
+			if cacheBackends[name] {
+				kind = "cache"
+			}
+
+			fmt.Fprintf(&sb, "  %s: %d calls (%s)\n", name, p.backends[name], kind)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolPerf_Percentile(t *testing.T) {
+	tp := &toolPerf{}
+
+	for i := 1; i <= 100; i++ {
+		tp.add(time.Duration(i) * time.Millisecond)
+	}
+
+	if p50, p95 := tp.percentile(0.5), tp.percentile(0.95); p50 != 50*time.Millisecond || p95 != 95*time.Millisecond {
+		t.Errorf("p50 %s, p95 %s, want 50ms and 95ms", p50, p95)
+	}
+
+	for range perfSamples {
+		tp.add(time.Second)
+	}
+
+	if len(tp.recent) != perfSamples || tp.percentile(0.5) != time.Second || tp.max != time.Second {
+		t.Errorf("old samples should have been replaced: %d samples, p50 %s", len(tp.recent), tp.percentile(0.5))
+	}
+
+	if tp.calls != 100+perfSamples {
+		t.Errorf("calls = %d", tp.calls)
+	}
+}
+
+func TestPerfStats_Summary(t *testing.T) {
+	p := NewPerfStats()
+
+	if line := p.summary(); line != "" {
+		t.Errorf("summary without calls: %q", line)
+	}
+
+	p.record("gomod_read_file", 10*time.Millisecond, false, []string{backendModCache})
+	p.record("gomod_read_file", 20*time.Millisecond, false, []string{backendProxy, backendZipCache})
+	p.record("gomod_grep", time.Second, true, nil)
+
+	line := p.summary()
+
+	for _, want := range []string{"3 tool calls", "(1 errors)", "cache hits 1/2 (50%)",
+		"most time in gomod_grep 1s over 1 calls"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in summary: %s", want, line)
+		}
+	}
+
+	if line := p.summary(); line != "" {
+		t.Errorf("summary repeated without new calls: %q", line)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultPkgsiteURL = "https://pkg.go.dev"
+
+// PkgsiteClient searches pkg.go.dev. The site has no stable search API, so
+// results are scraped from the search page markup.
+type PkgsiteClient struct {
+	apiClient
+}
+
+func NewPkgsiteClient() *PkgsiteClient {
+	return &PkgsiteClient{apiClient{baseURL: defaultPkgsiteURL, client: http.DefaultClient}}
+}
+
+// searchResult is one package found by a pkg.go.dev search.
+type searchResult struct {
+	path       string
+	synopsis   string
+	importedBy int
+}
+
+var (
+	snippetTitleRe    = regexp.MustCompile(`<a href="/([^"?#]+)"[^>]*data-test-id="snippet-title"`)
+	snippetSynopsisRe = regexp.MustCompile(`(?s)<p[^>]*class="SearchSnippet-synopsis"[^>]*>(.*?)</p>`)
+	snippetImportedRe = regexp.MustCompile(`(?s)tab=importedby".*?<strong>([\d,]+)</strong>`)
+	htmlTagRe         = regexp.MustCompile(`<[^>]+>`)
+)
+
+// Search returns up to limit packages matching query, in pkg.go.dev's
+// ranking order.
+func (c *PkgsiteClient) Search(ctx context.Context, query string, limit int) ([]searchResult, error) {
+	body, err := c.get(ctx, "/search?limit="+strconv.Itoa(limit)+"&m=package&q="+url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSearchResults(string(body), limit), nil
+}
+
+// parseSearchResults extracts results from a pkg.go.dev search page.
+func parseSearchResults(page string, limit int) []searchResult {
+	chunks := strings.Split(page, `class="SearchSnippet"`)
+
+	var results []searchResult
+
+	for _, chunk := range chunks[1:] {
+		m := snippetTitleRe.FindStringSubmatch(chunk)
+		if m == nil {
+			continue
+		}
+
+		r := searchResult{path: m[1]}
+
+		if m := snippetSynopsisRe.FindStringSubmatch(chunk); m != nil {
+			r.synopsis = strings.Join(strings.Fields(html.UnescapeString(htmlTagRe.ReplaceAllString(m[1], ""))), " ")
+		}
+
+		if m := snippetImportedRe.FindStringSubmatch(chunk); m != nil {
+			r.importedBy, _ = strconv.Atoi(strings.ReplaceAll(m[1], ",", ""))
+		}
+
+		results = append(results, r)
+
+		if len(results) == limit {
+			break
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testSearchPage = `<html><body>
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer">
+    <h2>
+      <a href="/github.com/new/thing" data-gtmc="search result" data-test-id="snippet-title">
+        thing <span class="SearchSnippet-header-path">(github.com/new/thing)</span>
+      </a>
+    </h2>
+  </div>
+  <p class="SearchSnippet-synopsis" data-test-id="snippet-synopsis">
+    Package thing does &amp; things.
+  </p>
+  <div class="SearchSnippet-infoLabel">
+    <a href="/github.com/new/thing?tab=importedby" aria-label="Go to Imported By">
+      <span class="go-textSubtle">Imported by </span><strong>1,234</strong>
+    </a>
+  </div>
+</div>
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer">
+    <h2><a href="/example.com/other/thing/sub" data-test-id="snippet-title">sub</a></h2>
+  </div>
+</div>
+</body></html>`
+
+func TestParseSearchResults(t *testing.T) {
+	results := parseSearchResults(testSearchPage, 10)
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+
+	first := results[0]
+	if first.path != "github.com/new/thing" || first.synopsis != "Package thing does & things." ||
+		first.importedBy != 1234 {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+
+	if results[1].path != "example.com/other/thing/sub" || results[1].importedBy != 0 {
+		t.Errorf("unexpected second result: %+v", results[1])
+	}
+
+	if got := parseSearchResults(testSearchPage, 1); len(got) != 1 {
+		t.Errorf("limit not applied: %d results", len(got))
+	}
+}
+
+func TestPkgsiteClient_Search(t *testing.T) {
+	var query string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+
+		_, _ = w.Write([]byte(testSearchPage))
+	}))
+	defer ts.Close()
+
+	client := &PkgsiteClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+
+	results, err := client.Search(context.Background(), "thing finder", 5)
+	mustf(t, err, "search")
+
+	if query != "thing finder" || len(results) != 2 {
+		t.Errorf("query = %q, %d results", query, len(results))
+	}
+}
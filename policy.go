@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+)
+
+// ErrModuleDenied is returned for modules the module policy does not allow.
+var ErrModuleDenied = errors.New("module denied by policy")
+
+// ModulePolicy restricts which modules the server fetches and serves.
+// Patterns are comma-separated globs matched against module path
+// prefixes, like GOPRIVATE: "github.com/acme/*" matches
+// github.com/acme/tool and github.com/acme/tool/v2. A module is allowed
+// if it matches no deny pattern and, when allow patterns are set, at
+// least one of them. A nil policy allows every module.
+type ModulePolicy struct {
+	mu    sync.RWMutex
+	allow string
+	deny  string
+}
+
+// NewModulePolicy creates a policy from allow and deny pattern lists.
+// Empty lists impose no restriction.
+func NewModulePolicy(allow, deny string) (*ModulePolicy, error) {
+	allow, deny = normalizePatterns(allow), normalizePatterns(deny)
+
+	for _, glob := range strings.Split(allow+","+deny, ",") {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid module pattern %q: %w", glob, err)
+		}
+	}
+
+	return &ModulePolicy{allow: allow, deny: deny}, nil
+}
+
+// Update replaces the pattern lists, as on a config reload. Invalid
+// patterns leave the policy unchanged.
+func (p *ModulePolicy) Update(allow, deny string) error {
+	q, err := NewModulePolicy(allow, deny)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.allow, p.deny = q.allow, q.deny
+	p.mu.Unlock()
+
+	return nil
+}
+
+// normalizePatterns trims spaces and drops empty entries from a pattern
+// list.
+func normalizePatterns(list string) string {
+	var globs []string
+
+	for _, glob := range strings.Split(list, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+
+	return strings.Join(globs, ",")
+}
+
+// Check returns an error wrapping ErrModuleDenied if mod may not be
+// fetched or served.
+func (p *ModulePolicy) Check(mod string) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.deny != "" && module.MatchPrefixPatterns(p.deny, mod) {
+		return fmt.Errorf("%w: %s matches a denied module pattern", ErrModuleDenied, mod)
+	}
+
+	if p.allow != "" && !module.MatchPrefixPatterns(p.allow, mod) {
+		return fmt.Errorf("%w: %s matches no allowed module pattern", ErrModuleDenied, mod)
+	}
+
+	return nil
+}
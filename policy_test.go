@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestModulePolicy_Check(t *testing.T) {
+	policy, err := NewModulePolicy("github.com/acme/*, golang.org/x", "github.com/acme/banned")
+	mustf(t, err, "new policy")
+
+	for mod, allowed := range map[string]bool{
+		"github.com/acme/tool":          true,
+		"github.com/acme/tool/v2":       true,
+		"golang.org/x/mod":              true,
+		"github.com/acme/banned":        false,
+		"github.com/acme/banned/sub":    false,
+		"github.com/other/tool":         false,
+		"golang.org/xyz":                false,
+		"example.com/github.com/acme/x": false,
+	} {
+		if err := policy.Check(mod); (err == nil) != allowed || err != nil && !errors.Is(err, ErrModuleDenied) {
+			t.Errorf("Check(%s) = %v, want allowed %v", mod, err, allowed)
+		}
+	}
+}
+
+func TestModulePolicy_DenyOnly(t *testing.T) {
+	policy, err := NewModulePolicy("", "*.example.com")
+	mustf(t, err, "new policy")
+
+	if err := policy.Check("git.example.com/team/mod"); !errors.Is(err, ErrModuleDenied) {
+		t.Errorf("expected denial, got %v", err)
+	}
+
+	if err := policy.Check("github.com/acme/tool"); err != nil {
+		t.Errorf("expected no denial, got %v", err)
+	}
+
+	var none *ModulePolicy
+	if err := none.Check("git.example.com/team/mod"); err != nil {
+		t.Errorf("nil policy denied: %v", err)
+	}
+}
+
+func TestNewModulePolicy_InvalidPattern(t *testing.T) {
+	if _, err := NewModulePolicy("github.com/[acme", ""); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestModulePolicy_Enforced(t *testing.T) {
+	var hits int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+
+		_, _ = w.Write([]byte("v1.0.0\n"))
+	}))
+	defer ts.Close()
+
+	policy, err := NewModulePolicy("", "example.com/banned")
+	mustf(t, err, "new policy")
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client(), policy: policy}
+
+	if _, err := proxy.ListVersions(context.Background(), "example.com/banned"); !errors.Is(err, ErrModuleDenied) {
+		t.Errorf("expected denial from the proxy client, got %v", err)
+	}
+
+	if hits != 0 {
+		t.Errorf("denied module was fetched %d times", hits)
+	}
+
+	dir := t.TempDir()
+	populateModCache(t, dir, "example.com/banned", "v1.0.0", map[string]string{"go.mod": "module example.com/banned\n"})
+
+	modCache := NewModCache(dir)
+	modCache.policy = policy
+
+	if modCache.HasModule("example.com/banned", "v1.0.0") || len(modCache.CachedVersions("example.com/banned")) > 0 {
+		t.Error("denied module is visible in the module cache")
+	}
+
+	if _, err := modCache.ReadFile("example.com/banned", "v1.0.0", "go.mod"); !errors.Is(err, ErrModuleDenied) {
+		t.Errorf("expected denial from the module cache, got %v", err)
+	}
+
+	_, _, _, err = findNestedModules(context.Background(), proxy, "example.com/banned", "")
+	if !errors.Is(err, ErrModuleDenied) {
+		t.Errorf("expected denial of the repository's tags, got %v", err)
+	}
+
+	srv := httptest.NewServer(&goProxyHandler{proxy: proxy, cache: NewZipCache(), modCache: modCache})
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/example.com/banned/@v/list")
+	mustf(t, err, "GET list")
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("GOPROXY list of denied module: got %d, want 403", resp.StatusCode)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// prefetchList is the parsed -prefetch flag: module versions, "latest"
+// when none was given, and project directories whose requirements are
+// fetched.
+type prefetchList struct {
+	modules  []module.Version
+	projects []string
+}
+
+// parsePrefetchList parses a comma-separated list of module[@version]
+// entries and project paths. An entry is a project when it starts with
+// "." or "/", or names a go.mod file.
+func parsePrefetchList(list string) (*prefetchList, error) {
+	pl := &prefetchList{}
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, ".") || filepath.IsAbs(entry) || filepath.Base(entry) == "go.mod":
+			if filepath.Base(entry) == "go.mod" {
+				entry = filepath.Dir(entry)
+			}
+
+			pl.projects = append(pl.projects, entry)
+
+			continue
+		}
+
+		path, version, _ := strings.Cut(entry, "@")
+		if err := module.CheckPath(path); err != nil {
+			return nil, fmt.Errorf("prefetch %q: %w", entry, err)
+		}
+
+		pl.modules = append(pl.modules, module.Version{Path: path, Version: cmp.Or(version, "latest")})
+	}
+
+	return pl, nil
+}
+
+// resolve returns the module versions to fetch, the projects' requirements
+// after the listed modules.
+func (pl *prefetchList) resolve() ([]module.Version, error) {
+	mods := append([]module.Version(nil), pl.modules...)
+
+	for _, dir := range pl.projects {
+		p, err := loadProject(dir)
+		if err != nil {
+			return nil, fmt.Errorf("prefetch %s: %w", dir, err)
+		}
+
+		for _, dep := range p.dependencies() {
+			mods = append(mods, dep.Version)
+		}
+	}
+
+	return mods, nil
+}
+
+// prefetch downloads the listed modules into the caches, so the first tool
+// calls about them do not wait for the proxy. Failures are logged.
+func (svc *services) prefetch(ctx context.Context, pl *prefetchList) {
+	if svc.proxy.offline {
+		log.Printf("prefetch skipped in -offline mode")
+
+		return
+	}
+
+	mods, err := pl.resolve()
+	if err != nil {
+		log.Printf("warning: %v", err)
+
+		return
+	}
+
+	start := time.Now()
+
+	var fetched atomic.Int64
+
+	parallelEach(mods, func(_ int, mv module.Version) {
+		version, err := resolveVersion(ctx, svc.proxy, mv.Path, mv.Version)
+		if err == nil {
+			_, err = openModule(ctx, svc.proxy, svc.cache, svc.modCache, mv.Path, version)
+		}
+
+		if err != nil {
+			log.Printf("warning: prefetch %s@%s: %v", mv.Path, mv.Version, err)
+
+			return
+		}
+
+		fetched.Add(1)
+	})
+
+	log.Printf("prefetched %d of %d modules in %v", fetched.Load(), len(mods), time.Since(start).Round(time.Millisecond))
+}
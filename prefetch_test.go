@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestParsePrefetchList(t *testing.T) {
+	pl, err := parsePrefetchList("golang.org/x/mod@v0.33.0, github.com/acme/tool,./svc/go.mod,/src/app,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []module.Version{
+		{Path: "golang.org/x/mod", Version: "v0.33.0"},
+		{Path: "github.com/acme/tool", Version: "latest"},
+	}
+
+	if len(pl.modules) != len(want) || pl.modules[0] != want[0] || pl.modules[1] != want[1] {
+		t.Errorf("modules = %v, want %v", pl.modules, want)
+	}
+
+	if len(pl.projects) != 2 || pl.projects[0] != "svc" || pl.projects[1] != "/src/app" {
+		t.Errorf("projects = %v, want [svc /src/app]", pl.projects)
+	}
+
+	if _, err := parsePrefetchList("not a module"); err == nil {
+		t.Error("expected an error for an invalid module path")
+	}
+}
+
+func TestServicesPrefetch(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod": "module example.com/testmod\n",
+	})
+
+	proxy, ts := newTestProxy(fakeProxy(zipData))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	mustf(t, os.WriteFile(filepath.Join(dir, "go.mod"),
+		[]byte("module example.com/app\n\nrequire example.com/testmod v1.0.0\n"), 0o600), "write go.mod")
+
+	svc := &services{proxy: proxy, cache: NewZipCache(), modCache: NewModCache(t.TempDir())}
+
+	svc.prefetch(context.Background(), &prefetchList{
+		modules:  []module.Version{{Path: "example.com/testmod", Version: "latest"}},
+		projects: []string{dir},
+	})
+
+	if svc.cache.Get("example.com/testmod", "v1.0.0") == nil {
+		t.Error("example.com/testmod@v1.0.0 was not prefetched")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/semver"
+)
+
+type prereleaseKey struct{}
+
+// includesPrereleases reports whether "latest" may resolve to a
+// prerelease in ctx: as the tool call's include_prerelease argument asks,
+// or else as -include-prerelease does.
+func (p *ProxyClient) includesPrereleases(ctx context.Context) bool {
+	if include, ok := ctx.Value(prereleaseKey{}).(bool); ok {
+		return include
+	}
+
+	return p.includePrerelease
+}
+
+// latestRelease returns the highest release of a module that is not
+// retracted, or "" if it has none.
+func (p *ProxyClient) latestRelease(ctx context.Context, module string) string {
+	versions, err := p.ListVersions(ctx, module)
+	if err != nil {
+		return ""
+	}
+
+	var releases []string
+
+	for _, v := range versions {
+		if semver.Prerelease(v) == "" {
+			releases = append(releases, v)
+		}
+	}
+
+	if len(releases) == 0 {
+		return ""
+	}
+
+	return p.latestListed(ctx, module, releases)
+}
+
+// prereleaseMiddleware adds the include_prerelease argument to the listed
+// tools that take a version, and strips it from calls before they are
+// validated, passing it on in the context for ResolveLatest.
+func prereleaseMiddleware(include bool) mcp.Middleware {
+	argument := &jsonschema.Schema{
+		Type: "boolean",
+		Description: fmt.Sprintf("Let 'latest' resolve to a prerelease such as v2.0.0-rc.1 when @latest "+
+			"names one (default %t)", include),
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch method {
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					addPrereleaseArgument(list, argument)
+				}
+
+				return result, err
+			case "tools/call":
+				call, ok := req.(*mcp.CallToolRequest)
+				if !ok || call.Params == nil {
+					break
+				}
+
+				err := rewriteArguments(call, func(args map[string]any) bool {
+					v, ok := args["include_prerelease"]
+					if !ok {
+						return false
+					}
+
+					delete(args, "include_prerelease")
+
+					if include, ok := v.(bool); ok {
+						ctx = context.WithValue(ctx, prereleaseKey{}, include)
+					}
+
+					return true
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// addPrereleaseArgument replaces each listed tool taking a version, or
+// the from and to versions of a comparison, by a copy whose schema also
+// has the include_prerelease argument.
+func addPrereleaseArgument(list *mcp.ListToolsResult, argument *jsonschema.Schema) {
+	for i, t := range list.Tools {
+		schema, ok := t.InputSchema.(*jsonschema.Schema)
+		if !ok || schema.Properties["include_prerelease"] != nil ||
+			schema.Properties["version"] == nil && schema.Properties["from"] == nil {
+			continue
+		}
+
+		extended := *schema
+		extended.Properties = maps.Clone(schema.Properties)
+		extended.Properties["include_prerelease"] = argument
+
+		tool := *t
+		tool.InputSchema = &extended
+		list.Tools[i] = &tool
+	}
+}
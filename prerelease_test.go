@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProxyClient_ResolveLatest_Prerelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		flag     bool
+		arg      any // include_prerelease argument of the call; nil if absent
+		want     string
+		wantNote string
+	}{
+		{"release preferred", "v1.0.0\nv1.1.0\nv2.0.0-rc.1\n", false, nil, "v1.1.0", "is the prerelease v2.0.0-rc.1"},
+		{"flag", "v1.1.0\nv2.0.0-rc.1\n", true, nil, "v2.0.0-rc.1", ""},
+		{"argument", "v1.1.0\nv2.0.0-rc.1\n", false, true, "v2.0.0-rc.1", ""},
+		{"argument overrides flag", "v1.1.0\nv2.0.0-rc.1\n", true, false, "v1.1.0", "is the prerelease"},
+		{"no release", "v2.0.0-beta.1\nv2.0.0-rc.1\n", false, nil, "v2.0.0-rc.1", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/example.com/mod/@latest":
+					_, _ = w.Write([]byte(`{"Version":"v2.0.0-rc.1"}`))
+				case r.URL.Path == "/example.com/mod/@v/list":
+					_, _ = w.Write([]byte(tc.list))
+				case strings.HasSuffix(r.URL.Path, ".mod"):
+					_, _ = w.Write([]byte("module example.com/mod\n"))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+
+			proxy.includePrerelease = tc.flag
+
+			notes := &callNotes{}
+			ctx := context.WithValue(context.Background(), notesKey{}, notes)
+
+			if tc.arg != nil {
+				ctx = context.WithValue(ctx, prereleaseKey{}, tc.arg)
+			}
+
+			version, err := proxy.ResolveLatest(ctx, "example.com/mod")
+			mustf(t, err, "resolve latest")
+
+			if version != tc.want {
+				t.Errorf("got %q, want %q", version, tc.want)
+			}
+
+			note := strings.Join(notes.notes, "\n")
+			if tc.wantNote == "" && note != "" || !strings.Contains(note, tc.wantNote) {
+				t.Errorf("note = %q, want one containing %q", note, tc.wantNote)
+			}
+		})
+	}
+}
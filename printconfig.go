@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats of print-config.
+const (
+	configFormatJSON   = "json"
+	configFormatClaude = "claude"
+)
+
+// pathFlags are server flags holding paths, made absolute in the printed
+// configuration because the client starts the server in another directory.
+var pathFlags = map[string]bool{
+	"local-dir": true, "audit-log": true, "config": true, "cache-dir": true, "ca-bundle": true,
+}
+
+// mcpServerConfig is a server entry of the "mcpServers" object used by
+// Claude Desktop's claude_desktop_config.json and Claude Code's .mcp.json.
+type mcpServerConfig struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// runPrintConfig implements the print-config subcommand. It accepts the
+// server's flags and prints the client configuration that starts the
+// server with them.
+func runPrintConfig(args []string) error {
+	fs := flag.NewFlagSet("print-config", flag.ExitOnError)
+	name := fs.String("name", "gomod", "Server name in the client configuration")
+	format := fs.String("format", configFormatJSON,
+		"Output format: json (mcpServers snippet) or claude (claude mcp add command)")
+
+	registerServerFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	command, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate executable: %w", err)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(command); err == nil {
+		command = resolved
+	}
+
+	if strings.Contains(command, "go-build") {
+		fmt.Fprintln(os.Stderr, "warning: running from a temporary go run build; "+
+			"install the binary and run print-config from it")
+	}
+
+	cfg, err := serverConfig(fs, command, discoverModCache())
+	if err != nil {
+		return err
+	}
+
+	return writeServerConfig(os.Stdout, *format, *name, cfg)
+}
+
+// serverConfig builds the client entry for the server flags set in fs.
+// Flags left at their defaults are omitted, as are print-config's own.
+func serverConfig(fs *flag.FlagSet, command, modCache string) (*mcpServerConfig, error) {
+	cfg := &mcpServerConfig{Type: "stdio", Command: command, Args: []string{}}
+
+	var err error
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "name" || f.Name == "format" || err != nil {
+			return
+		}
+
+		value := f.Value.String()
+		if pathFlags[f.Name] && value != "" {
+			if value, err = filepath.Abs(value); err != nil {
+				err = fmt.Errorf("resolve -%s: %w", f.Name, err)
+
+				return
+			}
+		}
+
+		cfg.Args = append(cfg.Args, "-"+f.Name+"="+value)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// MCP clients often start servers without the user's PATH, where the go
+	// command is missing and the module cache cannot be discovered.
+	if modCache != "" {
+		cfg.Env = map[string]string{"GOMODCACHE": modCache}
+	}
+
+	return cfg, nil
+}
+
+func writeServerConfig(w io.Writer, format, name string, cfg *mcpServerConfig) error {
+	switch format {
+	case configFormatJSON:
+		out, err := json.MarshalIndent(map[string]any{
+			"mcpServers": map[string]*mcpServerConfig{name: cfg},
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode config: %w", err)
+		}
+
+		fmt.Fprintf(w, "%s\n", out)
+	case configFormatClaude:
+		parts := []string{"claude", "mcp", "add", "--scope", "user"}
+
+		for _, k := range sortedKeys(cfg.Env) {
+			parts = append(parts, "-e", shellQuote(k+"="+cfg.Env[k]))
+		}
+
+		parts = append(parts, shellQuote(name), "--", shellQuote(cfg.Command))
+
+		for _, arg := range cfg.Args {
+			parts = append(parts, shellQuote(arg))
+		}
+
+		fmt.Fprintln(w, strings.Join(parts, " "))
+	default:
+		return fmt.Errorf("unknown format %q (use %q or %q)", format, configFormatJSON, configFormatClaude)
+	}
+
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell when it contains anything but
+// safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=/.,:@+%") == "" {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServerConfig(t *testing.T) {
+	fs := flag.NewFlagSet("print-config", flag.ContinueOnError)
+	fs.String("name", "gomod", "")
+	registerServerFlags(fs)
+
+	mustf(t, fs.Parse([]string{"-name", "go", "-offline", "-local-dir", "src", "-deny-modules", "example.com/*"}),
+		"parse flags")
+
+	cfg, err := serverConfig(fs, "/usr/local/bin/claude-gomod", "/home/u/go/pkg/mod")
+	mustf(t, err, "server config")
+
+	localDir, err := filepath.Abs("src")
+	mustf(t, err, "abs")
+
+	want := []string{"-deny-modules=example.com/*", "-local-dir=" + localDir, "-offline=true"}
+	if strings.Join(cfg.Args, " ") != strings.Join(want, " ") {
+		t.Errorf("args = %q, want %q", cfg.Args, want)
+	}
+
+	if cfg.Env["GOMODCACHE"] != "/home/u/go/pkg/mod" || cfg.Type != "stdio" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestWriteServerConfig(t *testing.T) {
+	cfg := &mcpServerConfig{
+		Type:    "stdio",
+		Command: "/opt/claude gomod/bin",
+		Args:    []string{"-deny-modules=example.com/*"},
+		Env:     map[string]string{"GOMODCACHE": "/cache"},
+	}
+
+	var sb strings.Builder
+
+	mustf(t, writeServerConfig(&sb, configFormatJSON, "gomod", cfg), "write json")
+
+	var parsed struct {
+		MCPServers map[string]mcpServerConfig `json:"mcpServers"`
+	}
+
+	mustf(t, json.Unmarshal([]byte(sb.String()), &parsed), "decode %s", sb.String())
+
+	if got := parsed.MCPServers["gomod"]; got.Command != cfg.Command || len(got.Args) != 1 {
+		t.Errorf("unexpected json config: %s", sb.String())
+	}
+
+	sb.Reset()
+
+	mustf(t, writeServerConfig(&sb, configFormatClaude, "gomod", cfg), "write claude")
+
+	want := "claude mcp add --scope user -e GOMODCACHE=/cache gomod -- '/opt/claude gomod/bin' " +
+		"'-deny-modules=example.com/*'\n"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+
+	if err := writeServerConfig(&sb, "yaml", "gomod", cfg); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"-offline=true": "-offline=true",
+		"":              "''",
+		"it's":          `'it'\''s'`,
+		"a b":           "'a b'",
+	} {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const scanConcurrency = 8
+
+// project is a local Go module whose dependencies are being inspected: its
+// parsed go.mod and, when present, the go.sum next to it.
+type project struct {
+	dir     string
+	mod     *modfile.File
+	goSum   []byte
+	version string // set for a published module version read as a project
+}
+
+// loadProject reads go.mod and go.sum from dir. A missing go.sum is not an
+// error.
+func loadProject(dir string) (*project, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("read project go.mod: %w", err)
+	}
+
+	mf, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse project go.mod: %w", err)
+	}
+
+	goSum, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read project go.sum: %w", err)
+	}
+
+	return &project{dir: dir, mod: mf, goSum: goSum}, nil
+}
+
+// publishedProject reads the go.mod of a published module version as a
+// project, to see what depending on it pulls in. It is parsed leniently,
+// which leaves out the replace and exclude directives: they apply only in
+// the main module, so a consumer's build ignores them.
+func publishedProject(ctx context.Context, proxy *ProxyClient, mod, version string) (*project, error) {
+	text, err := proxy.ReadMod(ctx, mod, version)
+	if err != nil {
+		return nil, err
+	}
+
+	mf, err := modfile.ParseLax("go.mod", []byte(text), nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse go.mod of %s@%s: %w", mod, version, err)
+	}
+
+	return &project{mod: mf, version: version}, nil
+}
+
+// projectBinding remembers the project a session is working on, so
+// project-wide tools can be called without repeating its location. Each
+// session has its own, kept by sessionContexts.
+type projectBinding struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// Dir returns the bound project directory, or "" when nothing is bound
+// or b is nil.
+func (b *projectBinding) Dir() string {
+	if b == nil {
+		return ""
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.dir
+}
+
+// Set binds dir, or unbinds when dir is empty.
+func (b *projectBinding) Set(dir string) {
+	b.mu.Lock()
+	b.dir = dir
+	b.mu.Unlock()
+}
+
+// projectFromInput builds a project from a directory or from go.mod and
+// go.sum contents passed directly, in that order of preference. With no
+// input at all, the bound project is used.
+func projectFromInput(binding *projectBinding, dir, goMod, goSum string) (*project, error) {
+	if dir == "" && goMod == "" && goSum == "" {
+		dir = binding.Dir()
+	}
+
+	if dir != "" {
+		return loadProject(dir)
+	}
+
+	p := &project{goSum: []byte(goSum)}
+
+	if goMod != "" {
+		mf, err := modfile.Parse("go.mod", []byte(goMod), nil)
+		if err != nil {
+			return nil, fmt.Errorf("parse go.mod: %w", err)
+		}
+
+		p.mod = mf
+	} else if goSum == "" {
+		return nil, errors.New("a project directory, go.mod or go.sum is required (or bind one with gomod_bind_project)")
+	}
+
+	return p, nil
+}
+
+// modulePath returns the project's own module path, or "".
+func (p *project) modulePath() string {
+	if p.mod == nil || p.mod.Module == nil {
+		return ""
+	}
+
+	return p.mod.Module.Mod.Path
+}
+
+// dependency is a module version the project uses.
+type dependency struct {
+	module.Version
+	indirect bool
+}
+
+// dependencies returns the project's required module versions, with
+// replace directives applied. Requirements replaced by a local directory
+// are dropped. Without a go.mod, the highest version of each module in
+// go.sum is used.
+func (p *project) dependencies() []dependency {
+	if p.mod == nil {
+		return sumDependencies(p.goSum)
+	}
+
+	replaced := make(map[module.Version]module.Version)
+
+	for _, r := range p.mod.Replace {
+		replaced[r.Old] = r.New
+	}
+
+	var deps []dependency
+
+	for _, r := range p.mod.Require {
+		v, ok := replaced[r.Mod]
+		if !ok {
+			v, ok = replaced[module.Version{Path: r.Mod.Path}]
+		}
+
+		if !ok {
+			v = r.Mod
+		}
+
+		if v.Version == "" {
+			continue // replaced by a local directory
+		}
+
+		deps = append(deps, dependency{Version: v, indirect: r.Indirect})
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Path < deps[j].Path })
+
+	return deps
+}
+
+// scanVersions returns the module versions whose code the project may
+// build: those with a zip hash in go.sum, or the go.mod requirements
+// when there is no go.sum.
+func (p *project) scanVersions() []module.Version {
+	if len(p.goSum) > 0 {
+		return sumModules(p.goSum)
+	}
+
+	deps := p.dependencies()
+	result := make([]module.Version, len(deps))
+
+	for i, d := range deps {
+		result[i] = d.Version
+	}
+
+	return result
+}
+
+// sumModules returns the distinct module versions in go.sum data that have
+// a hash for their full source, skipping go.mod-only entries, which the go
+// command records for versions it only consulted during resolution.
+func sumModules(goSum []byte) []module.Version {
+	seen := make(map[module.Version]bool)
+
+	var result []module.Version
+
+	for _, line := range strings.Split(string(goSum), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+
+		v := module.Version{Path: fields[0], Version: fields[1]}
+		if !seen[v] {
+			seen[v] = true
+
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// sumDependencies returns the highest version of every module listed in
+// go.sum data.
+func sumDependencies(goSum []byte) []dependency {
+	highest := make(map[string]string)
+
+	for _, v := range parseGoSum(goSum) {
+		if cur, ok := highest[v.Path]; !ok || semver.Compare(v.Version, cur) > 0 {
+			highest[v.Path] = v.Version
+		}
+	}
+
+	deps := make([]dependency, 0, len(highest))
+
+	for _, path := range sortedKeys(highest) {
+		deps = append(deps, dependency{Version: module.Version{Path: path, Version: highest[path]}})
+	}
+
+	return deps
+}
+
+// parseGoSum returns the distinct module versions in go.sum data, in file
+// order. Entries for a version's go.mod alone ("v1.0.0/go.mod") are
+// reported under the bare version.
+func parseGoSum(data []byte) []module.Version {
+	seen := make(map[module.Version]bool)
+
+	var result []module.Version
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		v := module.Version{Path: fields[0], Version: strings.TrimSuffix(fields[1], "/go.mod")}
+		if !seen[v] {
+			seen[v] = true
+
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// parallelEach calls fn for every item using a bounded number of
+// goroutines, for per-module network lookups. fn must be safe for
+// concurrent use.
+func parallelEach[T any](items []T, fn func(i int, item T)) {
+	sem := make(chan struct{}, scanConcurrency)
+
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fn(i, item)
+		}()
+	}
+
+	wg.Wait()
+}
+
+type bindProjectInput struct {
+	Dir    string `json:"dir,omitempty" jsonschema:"Project directory containing go.mod"`
+	Unbind bool   `json:"unbind,omitempty" jsonschema:"Clear the bound project instead"`
+}
+
+func handleBindProject(binding *projectBinding, input bindProjectInput) (*mcp.CallToolResult, any, error) {
+	if input.Unbind {
+		binding.Set("")
+
+		return textResult("Project unbound."), nil, nil
+	}
+
+	if input.Dir == "" {
+		if dir := binding.Dir(); dir != "" {
+			return textResult("Bound project: " + dir), nil, nil
+		}
+
+		return errorResult("No project is bound; pass dir to bind one."), nil, nil
+	}
+
+	dir, err := filepath.Abs(input.Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve project directory: %w", err)
+	}
+
+	proj, err := loadProject(dir)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	binding.Set(dir)
+
+	return textResult(fmt.Sprintf(
+		"Bound project %s at %s (%d requirements, %d module versions in go.sum). "+
+			"Project-wide tools now default to it.",
+		proj.modulePath(), dir, len(proj.dependencies()), len(sumModules(proj.goSum)),
+	)), nil, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectDependencies(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.22
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.2.0 // indirect
+	example.com/local v0.1.0
+)
+
+replace example.com/a => example.com/a-fork v1.0.1
+
+replace example.com/local => ../local
+`,
+	})
+
+	proj, err := loadProject(dir)
+	mustf(t, err, "load project")
+
+	if got := proj.modulePath(); got != "example.com/app" {
+		t.Errorf("modulePath = %q", got)
+	}
+
+	deps := proj.dependencies()
+
+	want := []string{"example.com/a-fork@v1.0.1", "example.com/b@v1.2.0"}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d dependencies, want %d: %v", len(deps), len(want), deps)
+	}
+
+	for i, w := range want {
+		if deps[i].String() != w {
+			t.Errorf("deps[%d] = %s, want %s", i, deps[i], w)
+		}
+	}
+
+	if deps[0].indirect || !deps[1].indirect {
+		t.Errorf("unexpected indirect flags: %+v", deps)
+	}
+}
+
+func TestProjectFromInput_GoSum(t *testing.T) {
+	goSum := `example.com/a v1.0.0 h1:aaa=
+example.com/a v1.0.0/go.mod h1:bbb=
+example.com/a v1.1.0/go.mod h1:ccc=
+example.com/b v0.3.0 h1:ddd=
+`
+
+	proj, err := projectFromInput(nil, "", "", goSum)
+	mustf(t, err, "project from go.sum")
+
+	deps := proj.dependencies()
+	if len(deps) != 2 || deps[0].String() != "example.com/a@v1.1.0" || deps[1].String() != "example.com/b@v0.3.0" {
+		t.Errorf("unexpected dependencies: %v", deps)
+	}
+
+	if got := parseGoSum([]byte(goSum)); len(got) != 3 {
+		t.Errorf("parseGoSum returned %d versions, want 3: %v", len(got), got)
+	}
+}
+
+func TestProjectFromInput_Errors(t *testing.T) {
+	if _, err := projectFromInput(nil, "", "", ""); err == nil {
+		t.Error("expected error without input")
+	}
+
+	if _, err := projectFromInput(nil, filepath.Join(t.TempDir(), "missing"), "", ""); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestSumModules(t *testing.T) {
+	got := sumModules([]byte(`example.com/a v1.0.0 h1:aaa=
+example.com/a v1.0.0/go.mod h1:bbb=
+example.com/b v0.1.0/go.mod h1:ccc=
+example.com/a v1.0.0 h1:aaa=
+`))
+
+	if len(got) != 1 || got[0].String() != "example.com/a@v1.0.0" {
+		t.Errorf("sumModules = %v", got)
+	}
+}
+
+func TestProjectBinding(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{"go.mod": "module example.com/bound\n"})
+
+	binding := &projectBinding{}
+	binding.Set(dir)
+
+	proj, err := projectFromInput(binding, "", "", "")
+	mustf(t, err, "bound project")
+
+	if proj.modulePath() != "example.com/bound" {
+		t.Errorf("modulePath = %q", proj.modulePath())
+	}
+
+	proj, err = projectFromInput(binding, "", "module example.com/explicit\n", "")
+	mustf(t, err, "explicit go.mod")
+
+	if proj.modulePath() != "example.com/explicit" {
+		t.Errorf("explicit input should win over the binding, got %q", proj.modulePath())
+	}
+}
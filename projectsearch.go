@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+const (
+	// defaultProjectSearchMatches bounds the matches reported over all
+	// dependencies.
+	defaultProjectSearchMatches = 200
+	// maxProjectSearchPerModule bounds the matches reported per dependency,
+	// so that one module cannot fill the result.
+	maxProjectSearchPerModule = 20
+)
+
+// tagKindNames names the ctags kinds in search results.
+var tagKindNames = map[byte]string{
+	tagFunc:     "func",
+	tagMethod:   "method",
+	tagType:     "type",
+	tagConst:    "const",
+	tagVar:      "var",
+	tagField:    "field",
+	tagIfaceFun: "interface method",
+}
+
+// dependencyHits are the matches found in one module version of a project.
+type dependencyHits struct {
+	mod     module.Version
+	matches int
+	text    string // one line per match
+	capped  bool
+	err     error
+}
+
+// symbolMatcher returns a test for tags declaring symbol: a top-level name,
+// or Type.Name for a method, struct field or interface method.
+func symbolMatcher(symbol string) func(tag) bool {
+	if recv, name, ok := strings.Cut(symbol, "."); ok {
+		return func(t tag) bool { return t.receiver == recv && t.name == name }
+	}
+
+	return func(t tag) bool { return t.receiver == "" && t.kind != tagPackage && t.name == symbol }
+}
+
+// searchSymbol lists the declarations of a module that match.
+func searchSymbol(mf moduleFiles, match func(tag) bool) (*dependencyHits, error) {
+	fset, files, err := parseGoFiles(mf, "", parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := &dependencyHits{}
+
+	var sb strings.Builder
+
+	for _, t := range collectTags(fset, files) {
+		if !match(t) {
+			continue
+		}
+
+		if hits.matches == maxProjectSearchPerModule {
+			hits.capped = true
+
+			break
+		}
+
+		name := t.name
+		if t.receiver != "" {
+			name = t.receiver + "." + name
+		}
+
+		fmt.Fprintf(&sb, "%s:%d: %s %s\n", t.path, t.line, tagKindNames[t.kind], name)
+
+		hits.matches++
+	}
+
+	hits.text = sb.String()
+
+	return hits, nil
+}
+
+// searchText greps the files of a module.
+func searchText(mf moduleFiles, re *regexp.Regexp, goOnly bool) (*dependencyHits, error) {
+	res, err := grepModule(mf, "", re, grepOptions{maxMatches: maxProjectSearchPerModule, goOnly: goOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+
+	// Without context lines, the "--" between files only gets in the way.
+	for _, line := range strings.SplitAfter(res.out.String(), "\n") {
+		if line != "--\n" {
+			text.WriteString(line)
+		}
+	}
+
+	return &dependencyHits{matches: res.matches, text: text.String(), capped: res.truncated}, nil
+}
+
+type projectSearchInput struct {
+	Dir        string `json:"dir,omitempty" jsonschema:"Project directory with go.mod and go.sum (default: bound project)"`
+	GoSum      string `json:"go_sum,omitempty" jsonschema:"go.sum content, instead of dir"`
+	GoMod      string `json:"go_mod,omitempty" jsonschema:"go.mod content, searched when no go.sum is available"`
+	Symbol     string `json:"symbol,omitempty" jsonschema:"Declared name to find, or Type.Method / Type.Field"`
+	Pattern    string `json:"pattern,omitempty" jsonschema:"Regular expression to match lines against, instead of symbol"`
+	Fixed      bool   `json:"fixed,omitempty" jsonschema:"Match the pattern as a literal string, like grep -F"`
+	IgnoreCase bool   `json:"ignore_case,omitempty" jsonschema:"Match the pattern regardless of case, like grep -i"`
+	GoOnly     bool   `json:"go_only,omitempty" jsonschema:"Search only .go files with the pattern"`
+	MaxMatches int    `json:"max_matches,omitempty" jsonschema:"Maximum matches reported overall (default 200)"`
+}
+
+func handleProjectSearch(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache, modCache *ModCache,
+	binding *projectBinding, input projectSearchInput,
+) (*mcp.CallToolResult, any, error) {
+	if (input.Symbol == "") == (input.Pattern == "") {
+		return errorResult("Either symbol or pattern is required, not both."), nil, nil
+	}
+
+	var (
+		search func(moduleFiles) (*dependencyHits, error)
+		what   string
+	)
+
+	if input.Symbol != "" {
+		match := symbolMatcher(input.Symbol)
+		search = func(mf moduleFiles) (*dependencyHits, error) { return searchSymbol(mf, match) }
+		what = "symbol " + input.Symbol
+	} else {
+		re, err := compileGrepPattern(input.Pattern, input.Fixed, input.IgnoreCase)
+		if err != nil {
+			return errorResult(fmt.Sprintf("Invalid pattern: %v", err)), nil, nil
+		}
+
+		search = func(mf moduleFiles) (*dependencyHits, error) { return searchText(mf, re, input.GoOnly) }
+		what = fmt.Sprintf("%q", input.Pattern)
+	}
+
+	maxMatches := input.MaxMatches
+	if maxMatches <= 0 {
+		maxMatches = defaultProjectSearchMatches
+	}
+
+	proj, err := projectFromInput(binding, input.Dir, input.GoMod, input.GoSum)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	versions := proj.scanVersions()
+	results := make([]*dependencyHits, len(versions))
+
+	parallelEach(versions, func(i int, mv module.Version) {
+		mf, err := openModule(ctx, proxy, cache, modCache, mv.Path, mv.Version)
+		if err == nil {
+			results[i], err = search(mf)
+		}
+
+		if err != nil {
+			results[i] = &dependencyHits{err: err}
+		}
+
+		results[i].mod = mv
+	})
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(formatProjectSearch(ctx, proj, what, results, maxMatches)), nil, nil
+}
+
+func formatProjectSearch(
+	ctx context.Context, proj *project, what string, results []*dependencyHits, maxMatches int,
+) string {
+	var (
+		body    strings.Builder
+		failed  []string
+		total   int
+		modules int
+		shown   int
+		capped  bool
+	)
+
+	for _, r := range results {
+		if r.err != nil {
+			failed = append(failed, fmt.Sprintf("  %s: %v", r.mod, r.err))
+
+			continue
+		}
+
+		if r.matches == 0 {
+			continue
+		}
+
+		total += r.matches
+		modules++
+
+		if shown >= maxMatches {
+			continue
+		}
+
+		fmt.Fprintf(&body, "\n%s (%d):\n", r.mod, r.matches)
+
+		lines := strings.Split(strings.TrimSuffix(r.text, "\n"), "\n")
+		if len(lines) > maxMatches-shown {
+			lines = lines[:maxMatches-shown]
+		}
+
+		for _, line := range lines {
+			body.WriteString("  " + line + "\n")
+		}
+
+		shown += len(lines)
+
+		if r.capped {
+			capped = true
+
+			fmt.Fprintf(&body, "  ... stopped at %d matches in this module\n", maxProjectSearchPerModule)
+		}
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Searched %d module versions", len(results))
+
+	if name := proj.modulePath(); name != "" {
+		fmt.Fprintf(&sb, " of %s", name)
+	}
+
+	fmt.Fprintf(&sb, " for %s: %d matches in %d modules.\n", what, total, modules)
+	sb.WriteString(body.String())
+
+	if shown < total {
+		fmt.Fprintf(&sb, "\nShowing %d of %d matches; raise max_matches or narrow the search.\n", shown, total)
+	}
+
+	if shown < total || capped {
+		noteTruncated(ctx)
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(&sb, "\nNot searched (%d):\n%s\n", len(failed), strings.Join(failed, "\n"))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchSymbol(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod": "module example.com/testmod\n",
+		"client.go": "package testmod\n\ntype Client struct {\n\tTimeout int\n}\n\n" +
+			"func (c *Client) Do() {}\n\nfunc Do() {}\n",
+	})
+
+	entry, err := NewZipCache().Put("example.com/testmod", "v1.0.0", zipData)
+	mustf(t, err, "put zip")
+
+	for symbol, want := range map[string]string{
+		"Do":             "client.go:9: func Do\n",
+		"Client.Do":      "client.go:7: method Client.Do\n",
+		"Client.Timeout": "client.go:4: field Client.Timeout\n",
+		"testmod":        "",
+	} {
+		hits, err := searchSymbol(zipFiles{entry}, symbolMatcher(symbol))
+		mustf(t, err, "search %s", symbol)
+
+		if hits.text != want {
+			t.Errorf("search %s = %q, want %q", symbol, hits.text, want)
+		}
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"a.go": "package testmod\n\nconst userAgent = \"testmod/1.0\"\n",
+		"b.md": "Set the user agent.\n",
+	})
+
+	entry, err := NewZipCache().Put("example.com/testmod", "v1.0.0", zipData)
+	mustf(t, err, "put zip")
+
+	re, err := compileGrepPattern("user ?agent", false, true)
+	mustf(t, err, "compile")
+
+	hits, err := searchText(zipFiles{entry}, re, false)
+	mustf(t, err, "search")
+
+	if hits.matches != 2 || strings.Contains(hits.text, "--") {
+		t.Errorf("search = %d matches:\n%s", hits.matches, hits.text)
+	}
+
+	if hits, _ = searchText(zipFiles{entry}, re, true); hits.matches != 1 {
+		t.Errorf("go_only search = %d matches, want 1", hits.matches)
+	}
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// protoRPC is one method of a service definition.
+type protoRPC struct {
+	name          string
+	request       string
+	response      string
+	clientStreams bool
+	serverStreams bool
+}
+
+func (r protoRPC) String() string {
+	stream := func(streams bool) string {
+		if streams {
+			return "stream "
+		}
+
+		return ""
+	}
+
+	return fmt.Sprintf("%s(%s%s) returns (%s%s)",
+		r.name, stream(r.clientStreams), r.request, stream(r.serverStreams), r.response)
+}
+
+type protoService struct {
+	name string
+	rpcs []protoRPC
+}
+
+// protoFile is the outline of a .proto file.
+type protoFile struct {
+	path      string
+	pkg       string
+	goPackage string
+	messages  []string // nested messages as Outer.Inner
+	enums     []string
+	services  []protoService
+	generated []*pbGoFile
+}
+
+// pbGoFile is a Go file generated from a .proto file.
+type pbGoFile struct {
+	path      string
+	source    string // the .proto path recorded by the generator
+	generator string
+	types     []string
+}
+
+// tokenizeProto splits proto source into identifiers, string literals and
+// punctuation, dropping comments.
+func tokenizeProto(src string) []string {
+	var tokens []string
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			if j := strings.IndexByte(src[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			if j := strings.Index(src[i+2:], "*/"); j >= 0 {
+				i += j + 4
+			} else {
+				i = len(src)
+			}
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+
+				j++
+			}
+
+			tokens = append(tokens, src[i:min(j+1, len(src))])
+			i = j + 1
+		case isProtoIdentByte(c):
+			j := i
+			for j < len(src) && isProtoIdentByte(src[j]) {
+				j++
+			}
+
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+
+	return tokens
+}
+
+func isProtoIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseProto extracts the package, go_package option, messages, enums and
+// services of a .proto file. It is a structural scan, not a validating
+// parser.
+func parseProto(p, src string) *protoFile {
+	pf := &protoFile{path: p}
+	tokens := tokenizeProto(src)
+
+	// Each open brace pushes the message name, or "" for other blocks.
+	var (
+		scopes  []string
+		service *protoService
+	)
+
+	at := func(i int) string {
+		if i < len(tokens) {
+			return tokens[i]
+		}
+
+		return ""
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch tok := tokens[i]; {
+		case tok == "package" && len(scopes) == 0:
+			pf.pkg = at(i + 1)
+		case tok == "option" && at(i+1) == "go_package" && at(i+2) == "=":
+			pf.goPackage = strings.Trim(at(i+3), `"'`)
+		case (tok == "message" || tok == "enum" || tok == "service") && at(i+2) == "{":
+			var names []string
+
+			for _, s := range scopes {
+				if s != "" {
+					names = append(names, s)
+				}
+			}
+
+			name := strings.Join(append(names, at(i+1)), ".")
+
+			switch tok {
+			case "message":
+				pf.messages = append(pf.messages, name)
+				scopes = append(scopes, at(i+1))
+			case "enum":
+				pf.enums = append(pf.enums, name)
+				scopes = append(scopes, "")
+			case "service":
+				pf.services = append(pf.services, protoService{name: at(i + 1)})
+				service = &pf.services[len(pf.services)-1]
+				scopes = append(scopes, "")
+			}
+
+			i += 2
+		case tok == "rpc" && service != nil:
+			rpc, n := parseProtoRPC(tokens[i+1:])
+			if n > 0 {
+				service.rpcs = append(service.rpcs, rpc)
+				i += n
+			}
+		case tok == "{":
+			scopes = append(scopes, "")
+		case tok == "}":
+			if len(scopes) > 0 {
+				scopes = scopes[:len(scopes)-1]
+			}
+
+			if len(scopes) == 0 {
+				service = nil
+			}
+		}
+	}
+
+	return pf
+}
+
+// parseProtoRPC parses "Name ( [stream] Req ) returns ( [stream] Resp )"
+// and returns the number of tokens consumed, or 0 if tokens do not match.
+func parseProtoRPC(tokens []string) (protoRPC, int) {
+	var rpc protoRPC
+
+	i := 0
+	next := func() string {
+		if i < len(tokens) {
+			i++
+
+			return tokens[i-1]
+		}
+
+		return ""
+	}
+
+	msgType := func() (string, bool, bool) {
+		if next() != "(" {
+			return "", false, false
+		}
+
+		t := next()
+
+		streams := t == "stream"
+		if streams {
+			t = next()
+		}
+
+		return t, streams, next() == ")"
+	}
+
+	rpc.name = next()
+
+	req, clientStreams, ok := msgType()
+	if !ok || next() != "returns" {
+		return protoRPC{}, 0
+	}
+
+	resp, serverStreams, ok := msgType()
+	if !ok {
+		return protoRPC{}, 0
+	}
+
+	rpc.request, rpc.clientStreams = req, clientStreams
+	rpc.response, rpc.serverStreams = resp, serverStreams
+
+	return rpc, i
+}
+
+var protoSourceRe = regexp.MustCompile(`(?m)^// source: (\S+\.proto)$`)
+
+// parsePbGo inspects a generated .pb.go file: the .proto source it names
+// and its exported type declarations.
+func parsePbGo(p, src string) *pbGoFile {
+	generated, generator := generatedBy(p, src)
+	if !generated {
+		return nil
+	}
+
+	m := protoSourceRe.FindStringSubmatch(src)
+	if m == nil {
+		return nil
+	}
+
+	pb := &pbGoFile{path: p, source: m[1], generator: generator}
+
+	f, err := parser.ParseFile(token.NewFileSet(), p, src, parser.SkipObjectResolution)
+	if f == nil && err != nil {
+		return pb
+	}
+
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gd.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+				pb.types = append(pb.types, ts.Name.Name)
+			}
+		}
+	}
+
+	return pb
+}
+
+// matchesProtoSource reports whether a generated file's recorded source
+// refers to the .proto file at p. Generators record the path relative to
+// the protoc include root, which is often a subdirectory of the module.
+func matchesProtoSource(p, source string) bool {
+	return p == source || strings.HasSuffix(p, "/"+source)
+}
+
+// mapProtos parses the .proto files and generated .pb.go files in mf
+// under prefix and pairs them up. Generated files whose source is not in
+// the module are returned separately.
+func mapProtos(mf moduleFiles, prefix string) ([]*protoFile, []*pbGoFile, error) {
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(paths)
+
+	var (
+		protos []*protoFile
+		pbs    []*pbGoFile
+	)
+
+	for _, p := range paths {
+		isProto := strings.HasSuffix(p, ".proto")
+		if !isProto && !strings.HasSuffix(p, ".pb.go") && !strings.HasSuffix(p, ".pb.gw.go") {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		if isProto {
+			protos = append(protos, parseProto(p, src))
+		} else if pb := parsePbGo(p, src); pb != nil {
+			pbs = append(pbs, pb)
+		}
+	}
+
+	var orphans []*pbGoFile
+
+	for _, pb := range pbs {
+		var best *protoFile
+
+		for _, pf := range protos {
+			if !matchesProtoSource(pf.path, pb.source) {
+				continue
+			}
+
+			// Prefer the .proto in the same directory when names collide.
+			if best == nil || path.Dir(pf.path) == path.Dir(pb.path) {
+				best = pf
+			}
+		}
+
+		if best == nil {
+			orphans = append(orphans, pb)
+
+			continue
+		}
+
+		best.generated = append(best.generated, pb)
+	}
+
+	return protos, orphans, nil
+}
+
+func formatPbGo(sb *strings.Builder, indent string, pb *pbGoFile) {
+	fmt.Fprintf(sb, "%s%s", indent, pb.path)
+
+	if pb.generator != "" {
+		fmt.Fprintf(sb, " (%s)", pb.generator)
+	}
+
+	if len(pb.types) > 0 {
+		fmt.Fprintf(sb, ": %s", strings.Join(pb.types, ", "))
+	}
+
+	sb.WriteString("\n")
+}
+
+func formatProtoMap(module, version string, protos []*protoFile, orphans []*pbGoFile) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Proto files in %s@%s (%d):\n", module, version, len(protos))
+
+	if len(protos) == 0 && len(orphans) == 0 {
+		sb.WriteString("\nNo .proto files or protoc-generated Go files found.\n")
+
+		return sb.String()
+	}
+
+	for _, pf := range protos {
+		fmt.Fprintf(&sb, "\n%s\n", pf.path)
+
+		if pf.pkg != "" {
+			fmt.Fprintf(&sb, "  package: %s\n", pf.pkg)
+		}
+
+		if pf.goPackage != "" {
+			fmt.Fprintf(&sb, "  go_package: %s\n", pf.goPackage)
+		}
+
+		if len(pf.messages) > 0 {
+			fmt.Fprintf(&sb, "  messages: %s\n", strings.Join(pf.messages, ", "))
+		}
+
+		if len(pf.enums) > 0 {
+			fmt.Fprintf(&sb, "  enums: %s\n", strings.Join(pf.enums, ", "))
+		}
+
+		for _, s := range pf.services {
+			fmt.Fprintf(&sb, "  service %s:\n", s.name)
+
+			for _, rpc := range s.rpcs {
+				fmt.Fprintf(&sb, "    rpc %s\n", rpc)
+			}
+		}
+
+		if len(pf.generated) == 0 {
+			sb.WriteString("  generated: none in this module\n")
+
+			continue
+		}
+
+		sb.WriteString("  generated:\n")
+
+		for _, pb := range pf.generated {
+			formatPbGo(&sb, "    ", pb)
+		}
+	}
+
+	if len(orphans) > 0 {
+		sb.WriteString("\nGenerated files whose .proto source is not in the module:\n")
+
+		for _, pb := range orphans {
+			formatPbGo(&sb, "  ", pb)
+			fmt.Fprintf(&sb, "    source: %s\n", pb.source)
+		}
+	}
+
+	return sb.String()
+}
+
+type protoMapInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+}
+
+func handleProtoMap(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input protoMapInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	protos, orphans, err := mapProtos(mf, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(formatProtoMap(input.Module, version, protos, orphans)), nil, nil
+}
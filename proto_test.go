@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testProto = `syntax = "proto3";
+
+// Package greeter is an example.
+package acme.greeter.v1;
+
+option go_package = "example.com/testmod/gen/greeter/v1;greeterv1";
+
+/* A persisted greeting. */
+message Greeting {
+  string text = 1;
+  message Meta { string lang = 1; }
+  enum Kind { KIND_UNSPECIFIED = 0; }
+  oneof payload { string a = 2; int32 b = 3; }
+}
+
+message HelloRequest { string name = 1; }
+message HelloReply { Greeting greeting = 1; }
+
+service Greeter {
+  // SayHello greets.
+  rpc SayHello(HelloRequest) returns (HelloReply);
+  rpc Chat(stream HelloRequest) returns (stream HelloReply) {
+    option deprecated = true;
+  }
+}
+`
+
+func TestParseProto(t *testing.T) {
+	pf := parseProto("proto/greeter/v1/greeter.proto", testProto)
+
+	if pf.pkg != "acme.greeter.v1" || pf.goPackage != "example.com/testmod/gen/greeter/v1;greeterv1" {
+		t.Errorf("package = %q, go_package = %q", pf.pkg, pf.goPackage)
+	}
+
+	if got := strings.Join(pf.messages, ","); got != "Greeting,Greeting.Meta,HelloRequest,HelloReply" {
+		t.Errorf("messages = %s", got)
+	}
+
+	if got := strings.Join(pf.enums, ","); got != "Greeting.Kind" {
+		t.Errorf("enums = %s", got)
+	}
+
+	if len(pf.services) != 1 || len(pf.services[0].rpcs) != 2 {
+		t.Fatalf("services = %+v", pf.services)
+	}
+
+	if got := pf.services[0].rpcs[1].String(); got != "Chat(stream HelloRequest) returns (stream HelloReply)" {
+		t.Errorf("rpc = %s", got)
+	}
+}
+
+func TestMapProtos(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"proto/greeter/v1/greeter.proto": testProto,
+		"gen/greeter/v1/greeter.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n" +
+			"// source: greeter/v1/greeter.proto\n\npackage greeterv1\n\n" +
+			"type Greeting struct{}\n\ntype Greeting_Meta struct{}\n\ntype state struct{}\n",
+		"gen/greeter/v1/greeter_grpc.pb.go": "// Code generated by protoc-gen-go-grpc. DO NOT EDIT.\n" +
+			"// source: greeter/v1/greeter.proto\n\npackage greeterv1\n\n" +
+			"type GreeterClient interface{}\n\ntype GreeterServer interface{}\n",
+		"gen/status/status.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n" +
+			"// source: google/rpc/status.proto\n\npackage status\n\ntype Status struct{}\n",
+		"handwritten.pb.go": "package mod\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+	mustf(t, err, "put zip in cache")
+
+	protos, orphans, err := mapProtos(zipFiles{entry}, "")
+	mustf(t, err, "map protos")
+
+	if len(protos) != 1 || len(protos[0].generated) != 2 {
+		t.Fatalf("protos = %+v", protos)
+	}
+
+	if len(orphans) != 1 || orphans[0].source != "google/rpc/status.proto" {
+		t.Errorf("orphans = %+v", orphans)
+	}
+
+	out := formatProtoMap("mod", "v1.0.0", protos, orphans)
+	for _, want := range []string{
+		"    gen/greeter/v1/greeter.pb.go (protoc-gen-go): Greeting, Greeting_Meta\n",
+		"    gen/greeter/v1/greeter_grpc.pb.go (protoc-gen-go-grpc): GreeterClient, GreeterServer\n",
+		"    rpc SayHello(HelloRequest) returns (HelloReply)\n",
+		"    source: google/rpc/status.proto\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
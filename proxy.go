@@ -2,11 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"path"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 const (
@@ -17,10 +26,46 @@ const (
 // ErrModuleNotFound is returned when the proxy responds with 404 or 410.
 var ErrModuleNotFound = errors.New("module not found")
 
-// ProxyClient fetches module data from proxy.golang.org.
+// ErrOffline is returned for every request while the client is offline.
+var ErrOffline = errors.New("offline mode: network access disabled")
+
+// ProxyClient fetches module data from the module proxies of GOPROXY, by
+// default proxy.golang.org.
 type ProxyClient struct {
-	baseURL string
+	baseURL string     // the only proxy when proxies is empty
+	proxies []proxyHop // optional GOPROXY list walked instead of baseURL
 	client  *http.Client
+	meta    *MetadataCache  // optional cache for version lists and @latest
+	policy  *ModulePolicy   // optional restriction of the modules fetched
+	noproxy string          // GONOPROXY patterns, or else GOPRIVATE
+	vanity  *VanityResolver // optional go-import lookup for custom domains
+	health  *HealthTracker  // optional record of request outcomes
+	govcs   *VCSPolicy      // optional GOVCS limits on git commands
+	disk    *DiskCache      // optional persistent store of zips, go.mod and .info files
+	sums    *goSums         // optional go.sum hashes downloads must match
+	sumdb   *ChecksumDB     // optional checksum database downloads must match
+	offline bool
+
+	infoMu sync.Mutex
+	infos  map[string]*VersionInfo // .info of semantic versions, by module@version
+
+	includePrerelease bool // let "latest" be a prerelease that @latest names
+}
+
+// VersionInfo is the proxy's .info and @latest response.
+type VersionInfo struct {
+	Version string
+	Time    time.Time
+	Origin  *Origin `json:",omitempty"`
+}
+
+// Origin records where the proxy obtained a version, when it reports it.
+type Origin struct {
+	VCS    string `json:",omitempty"`
+	URL    string `json:",omitempty"`
+	Subdir string `json:",omitempty"`
+	Hash   string `json:",omitempty"`
+	Ref    string `json:",omitempty"`
 }
 
 func NewProxyClient() *ProxyClient {
@@ -30,11 +75,16 @@ func NewProxyClient() *ProxyClient {
 	}
 }
 
-// ListVersions returns the list of known versions for a module.
+// ListVersions returns the known versions of a module in semver order.
+// Lines of the proxy's list that are not valid semantic versions are
+// dropped.
 func (p *ProxyClient) ListVersions(ctx context.Context, module string) ([]string, error) {
-	url := fmt.Sprintf("%s/%s/@v/list", p.baseURL, encodePath(module))
+	urlPath, err := p.modulePath(module, "@v/list")
+	if err != nil {
+		return nil, err
+	}
 
-	body, err := p.get(ctx, url)
+	body, err := p.getMeta(ctx, urlPath)
 	if err != nil {
 		return nil, err
 	}
@@ -42,81 +92,498 @@ func (p *ProxyClient) ListVersions(ctx context.Context, module string) ([]string
 	var versions []string
 
 	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
-		if line = strings.TrimSpace(line); line != "" {
+		if line = strings.TrimSpace(line); semver.IsValid(line) {
 			versions = append(versions, line)
 		}
 	}
 
+	semver.Sort(versions)
+
 	return versions, nil
 }
 
-// Latest returns the JSON info for the latest version of a module.
-func (p *ProxyClient) Latest(ctx context.Context, module string) (string, error) {
-	url := fmt.Sprintf("%s/%s/@latest", p.baseURL, encodePath(module))
+// Latest returns the info for the latest version of a module.
+func (p *ProxyClient) Latest(ctx context.Context, module string) (*VersionInfo, error) {
+	urlPath, err := p.modulePath(module, "@latest")
+	if err != nil {
+		return nil, err
+	}
 
-	body, err := p.get(ctx, url)
+	body, err := p.getMeta(ctx, urlPath)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(body), nil
+	return parseVersionInfo(body)
 }
 
-// ResolveLatest resolves "latest" to a concrete version string.
-func (p *ProxyClient) ResolveLatest(ctx context.Context, module string) (string, error) {
-	url := fmt.Sprintf("%s/%s/@latest", p.baseURL, encodePath(module))
+// Info returns the info for a module version. Unlike @latest, the info of
+// a semantic version never changes, so once fetched it is kept for the
+// life of the client and in the disk cache. That of a branch or commit,
+// which the proxy resolves anew, is always fetched.
+func (p *ProxyClient) Info(ctx context.Context, module, version string) (*VersionInfo, error) {
+	urlPath, err := p.modulePath(module, "@v/"+version+".info")
+	if err != nil {
+		return nil, err
+	}
+
+	immutable := isCanonicalVersion(version)
+	key := module + "@" + version
+
+	if immutable {
+		if info := p.cachedInfo(key); info != nil {
+			noteBackend(ctx, backendMetaCache)
+
+			return info, nil
+		}
+
+		if data, ok := p.fromDisk(ctx, module, version, "info"); ok {
+			if info, err := parseVersionInfo(data); err == nil && info.Version == version {
+				p.cacheInfo(key, info)
+
+				return info, nil
+			}
+		}
+	}
 
-	body, err := p.get(ctx, url)
+	body, err := p.get(ctx, urlPath)
 	if err != nil {
+		return nil, err
+	}
+
+	info, err := parseVersionInfo(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if immutable && info.Version == version {
+		p.cacheInfo(key, info)
+		p.toDisk(module, version, "info", body)
+	}
+
+	return info, nil
+}
+
+func (p *ProxyClient) cachedInfo(key string) *VersionInfo {
+	p.infoMu.Lock()
+	defer p.infoMu.Unlock()
+
+	return p.infos[key]
+}
+
+func (p *ProxyClient) cacheInfo(key string, info *VersionInfo) {
+	p.infoMu.Lock()
+	defer p.infoMu.Unlock()
+
+	if p.infos == nil {
+		p.infos = make(map[string]*VersionInfo)
+	}
+
+	p.infos[key] = info
+}
+
+// isCanonicalVersion reports whether version is a semantic version as the
+// proxy lists it, rather than a query, branch or commit.
+func isCanonicalVersion(version string) bool {
+	return semver.IsValid(version) && module.CanonicalVersion(version) == version
+}
+
+// ResolveLatest resolves "latest" to a concrete version string. Some
+// proxies fail @latest or answer it with stale data, so it is checked
+// against @v/list: when @latest fails, or names a version older than the
+// highest listed one that is not retracted, that version is used instead
+// and a note saying so is added to the tool result. Unless prereleases
+// are included, a prerelease answer gives way to the highest release.
+func (p *ProxyClient) ResolveLatest(ctx context.Context, module string) (string, error) {
+	latest, err := p.resolveLatest(ctx, module)
+	if err != nil || semver.Prerelease(latest) == "" || p.includesPrereleases(ctx) {
+		return latest, err
+	}
+
+	release := p.latestRelease(ctx, module)
+	if release == "" {
+		return latest, nil
+	}
+
+	noteResult(ctx, fmt.Sprintf("%s@latest is the prerelease %s; using %s, the highest release that is "+
+		"not retracted. Pass include_prerelease to get the prerelease.", module, latest, release))
+
+	return release, nil
+}
+
+func (p *ProxyClient) resolveLatest(ctx context.Context, module string) (string, error) {
+	info, err := p.Latest(ctx, module)
+	if errors.Is(err, ErrOffline) || ctx.Err() != nil {
+		return "", err
+	}
+
+	versions, listErr := p.ListVersions(ctx, module)
+	if listErr != nil || len(versions) == 0 {
+		if err != nil {
+			return "", err
+		}
+
+		return info.Version, nil
+	}
+
+	if err == nil && !outranked(info.Version, versions) {
+		return info.Version, nil
+	}
+
+	listed := p.latestListed(ctx, module, versions)
+
+	switch {
+	case err != nil && listed == "":
 		return "", err
+	case err != nil:
+		noteResult(ctx, fmt.Sprintf("%s@latest failed (%v); using %s, the highest version in @v/list "+
+			"that is not retracted.", module, err, listed))
+
+		return listed, nil
+	case semver.Compare(listed, info.Version) > 0:
+		noteResult(ctx, fmt.Sprintf("%s@latest reported %s, behind @v/list; using %s, the highest listed "+
+			"version that is not retracted.", module, info.Version, listed))
+
+		return listed, nil
+	}
+
+	return info.Version, nil
+}
+
+// outranked reports whether versions has one the go command would prefer
+// to latest as the latest version: a higher release, or for a latest that
+// is a pre-release or pseudo-version, any higher version.
+func outranked(latest string, versions []string) bool {
+	for _, v := range versions {
+		if semver.Compare(v, latest) > 0 && (semver.Prerelease(v) == "" || semver.Prerelease(latest) != "") {
+			return true
+		}
 	}
 
-	// The response is JSON like {"Version":"v0.1.0","Time":"..."}
-	// Do a simple extraction to avoid importing encoding/json just for this.
-	s := string(body)
+	return false
+}
+
+// latestListed picks the version the go command would choose as latest
+// from a module's version list: the highest release, or else pre-release,
+// that the highest version's go.mod does not retract. +incompatible
+// versions only count if there are no others.
+func (p *ProxyClient) latestListed(ctx context.Context, module string, versions []string) string {
+	var compatible []string
 
-	const key = `"Version":"`
+	for _, v := range versions {
+		if !strings.HasSuffix(v, "+incompatible") {
+			compatible = append(compatible, v)
+		}
+	}
 
-	i := strings.Index(s, key)
-	if i < 0 {
-		return "", fmt.Errorf("cannot parse latest response: %s", s)
+	if len(compatible) > 0 {
+		versions = compatible
 	}
 
-	s = s[i+len(key):]
+	mf := &modfile.File{}
 
-	j := strings.Index(s, `"`)
-	if j < 0 {
-		return "", fmt.Errorf("cannot parse latest response version")
+	if data, err := p.ReadMod(ctx, module, versions[len(versions)-1]); err == nil {
+		if parsed, err := modfile.ParseLax("go.mod", []byte(data), nil); err == nil {
+			mf = parsed
+		}
 	}
 
-	return s[:j], nil
+	return latestAllowed(versions, mf)
+}
+
+func parseVersionInfo(body []byte) (*VersionInfo, error) {
+	var info VersionInfo
+
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("parse version info: %w", err)
+	}
+
+	if info.Version == "" {
+		return nil, fmt.Errorf("version info has no version: %s", body)
+	}
+
+	return &info, nil
 }
 
 // ReadMod returns the go.mod content for a module version.
 func (p *ProxyClient) ReadMod(ctx context.Context, module, version string) (string, error) {
-	url := fmt.Sprintf("%s/%s/@v/%s.mod", p.baseURL, encodePath(module), version)
+	urlPath, err := p.modulePath(module, "@v/"+version+".mod")
+	if err != nil {
+		return "", err
+	}
+
+	if data, ok := p.fromDisk(ctx, module, version, "mod"); ok {
+		if err := p.verifyCached(module, version, true, data); err != nil {
+			return "", err
+		}
 
-	body, err := p.get(ctx, url)
+		return string(data), nil
+	}
+
+	body, err := p.get(ctx, urlPath)
 	if err != nil {
 		return "", err
 	}
 
+	if err := p.verifyDownload(module, version, true, body); err != nil {
+		return "", err
+	}
+
+	p.toDisk(module, version, "mod", body)
+
 	return string(body), nil
 }
 
 // DownloadZip downloads the zip archive for a module version.
 func (p *ProxyClient) DownloadZip(ctx context.Context, module, version string) ([]byte, error) {
-	url := fmt.Sprintf("%s/%s/@v/%s.zip", p.baseURL, encodePath(module), version)
+	urlPath, err := p.modulePath(module, "@v/"+version+".zip")
+	if err != nil {
+		return nil, err
+	}
+
+	if data, ok := p.fromDisk(ctx, module, version, "zip"); ok {
+		if err := p.verifyCached(module, version, false, data); err != nil {
+			return nil, err
+		}
 
-	body, err := p.get(ctx, url)
+		return data, nil
+	}
+
+	body, err := p.get(ctx, urlPath)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := checkZip(module, version, body); err != nil {
+		return nil, err
+	}
+
+	if err := p.verifyDownload(module, version, false, body); err != nil {
+		return nil, err
+	}
+
+	p.toDisk(module, version, "zip", body)
+
 	return body, nil
 }
 
-func (p *ProxyClient) get(ctx context.Context, url string) ([]byte, error) {
+// verifySum checks a downloaded zip, or with goMod a go.mod file, against
+// the -verify-go-sum files, if they list the module version.
+func (p *ProxyClient) verifySum(module, version string, goMod bool, data []byte) error {
+	if !p.sums.has(module, version, goMod) {
+		return nil
+	}
+
+	hash, err := sumHash(module, version, goMod, data)
+	if err != nil {
+		return err
+	}
+
+	return p.sums.verify(module, version, goMod, hash)
+}
+
+// verifyDownload checks a zip or go.mod file fetched from the proxy against
+// the go.sum files and the checksum database, and records in the disk
+// cache that the database verified it, for verifyCached.
+func (p *ProxyClient) verifyDownload(module, version string, goMod bool, data []byte) error {
+	if err := p.verifySum(module, version, goMod, data); err != nil {
+		return err
+	}
+
+	if !p.sumdb.covers(module) {
+		return nil
+	}
+
+	hash, err := sumHash(module, version, goMod, data)
+	if err != nil {
+		return err
+	}
+
+	if err := p.sumdb.verify(module, version, goMod, hash); err != nil {
+		return err
+	}
+
+	p.toDisk(module, version, sumDBExt(goMod), []byte(p.sumdb.name))
+
+	return nil
+}
+
+// verifyCached checks a zip, or with goMod a go.mod file, read from the
+// disk cache, which may have been filled under other settings. A zip is
+// checked against the module zip format again, and both against the
+// -verify-go-sum files. The checksum database is asked unless the disk
+// cache records that the same database verified the file.
+func (p *ProxyClient) verifyCached(module, version string, goMod bool, data []byte) error {
+	if !goMod {
+		if err := checkZip(module, version, data); err != nil {
+			return err
+		}
+	}
+
+	if p.sumdb.covers(module) {
+		if name, ok := p.disk.Get(module, version, sumDBExt(goMod)); !ok || string(name) != p.sumdb.name {
+			return p.verifyDownload(module, version, goMod, data)
+		}
+	}
+
+	return p.verifySum(module, version, goMod, data)
+}
+
+// sumDBExt is the disk cache extension recording the checksum database
+// that verified a zip, or with goMod a go.mod file.
+func sumDBExt(goMod bool) string {
+	if goMod {
+		return "mod.sumdb"
+	}
+
+	return "zip.sumdb"
+}
+
+// sumHash returns the h1: hash of a zip, or with goMod of a go.mod file.
+func sumHash(module, version string, goMod bool, data []byte) (string, error) {
+	hash, err := goModHash(string(data))
+	if !goMod {
+		hash, err = zipHash(data)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("verify %s@%s: %w", module, version, err)
+	}
+
+	return hash, nil
+}
+
+// fromDisk returns a file of a module version from the disk cache, which
+// also answers in offline mode.
+func (p *ProxyClient) fromDisk(ctx context.Context, module, version, ext string) ([]byte, bool) {
+	if p.disk == nil {
+		return nil, false
+	}
+
+	data, ok := p.disk.Get(module, version, ext)
+	if ok {
+		noteBackend(ctx, backendDiskCache)
+	}
+
+	return data, ok
+}
+
+func (p *ProxyClient) toDisk(module, version, ext string, data []byte) {
+	if p.disk == nil {
+		return
+	}
+
+	if err := p.disk.Put(module, version, ext, data); err != nil {
+		log.Printf("warning: could not cache %s@%s.%s on disk: %v", module, version, ext, err)
+	}
+}
+
+// Fetch returns the raw response for a path below the module's proxy
+// root, e.g. "@v/list" or "@v/v1.0.0.info".
+func (p *ProxyClient) Fetch(ctx context.Context, module, path string) ([]byte, error) {
+	urlPath, err := p.modulePath(module, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.get(ctx, urlPath)
+}
+
+// modulePath returns the path below a proxy's root of a file below a
+// module's root, such as "@v/list" or "@v/v1.0.0.mod", with the module
+// path and version escaped as the GOPROXY protocol requires. Modules the
+// policy denies have no path, and neither do those matching GONOPROXY,
+// which the go command fetches directly rather than from a proxy; they
+// are not found, so local directories are suggested instead.
+func (p *ProxyClient) modulePath(mod, file string) (string, error) {
+	if err := p.policy.Check(mod); err != nil {
+		return "", err
+	}
+
+	if p.noproxy != "" && module.MatchPrefixPatterns(p.noproxy, mod) {
+		return "", fmt.Errorf("%w: %s matches GONOPROXY or GOPRIVATE, so it is not fetched from a module proxy",
+			ErrModuleNotFound, mod)
+	}
+
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return "", fmt.Errorf("invalid module path: %w", err)
+	}
+
+	if name, ok := strings.CutPrefix(file, "@v/"); ok {
+		if name, err = escapeVersionFile(name); err != nil {
+			return "", err
+		}
+
+		file = "@v/" + name
+	}
+
+	return "/" + escaped + "/" + file, nil
+}
+
+// escapeVersionFile escapes the version in a "<version>.<ext>" file name of
+// a module's @v directory. "list" is returned unchanged.
+func escapeVersionFile(name string) (string, error) {
+	if name == "list" {
+		return name, nil
+	}
+
+	ext := path.Ext(name)
+
+	version, err := module.EscapeVersion(strings.TrimSuffix(name, ext))
+	if err != nil {
+		return "", fmt.Errorf("invalid version: %w", err)
+	}
+
+	return version + ext, nil
+}
+
+// getMeta is get for mutable metadata, served from the metadata cache
+// when one is configured.
+func (p *ProxyClient) getMeta(ctx context.Context, urlPath string) ([]byte, error) {
+	if p.meta == nil {
+		return p.get(ctx, urlPath)
+	}
+
+	if body, ok := p.meta.Get(urlPath); ok {
+		noteBackend(ctx, backendMetaCache)
+
+		return body, nil
+	}
+
+	body, err := p.get(ctx, urlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p.meta.Put(urlPath, body)
+
+	return body, nil
+}
+
+// Probe fetches @latest of a well-known module from one proxy of the
+// GOPROXY list, past the metadata cache, and returns its version, to
+// check that the proxy answers.
+func (p *ProxyClient) Probe(ctx context.Context, baseURL string) (string, error) {
+	urlPath, err := p.modulePath(doctorProbeModule, "@latest")
+	if err != nil {
+		return "", err
+	}
+
+	body, err := p.getFrom(ctx, baseURL, urlPath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := parseVersionInfo(body)
+	if err != nil {
+		return "", err
+	}
+
+	return info.Version, nil
+}
+
+func (p *ProxyClient) fetch(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -147,21 +614,3 @@ func (p *ProxyClient) get(ctx context.Context, url string) ([]byte, error) {
 
 	return body, nil
 }
-
-// encodePath encodes a module path for use in proxy URLs.
-// Uppercase letters are replaced with !lowercase per the
-// Go module proxy protocol.
-func encodePath(path string) string {
-	var b strings.Builder
-
-	for _, r := range path {
-		if 'A' <= r && r <= 'Z' {
-			b.WriteByte('!')
-			b.WriteRune(r + ('a' - 'A'))
-		} else {
-			b.WriteRune(r)
-		}
-	}
-
-	return b.String()
-}
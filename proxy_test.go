@@ -5,24 +5,34 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestEncodePath(t *testing.T) {
+func TestProxyClient_ModulePath(t *testing.T) {
+	proxy := &ProxyClient{}
+
 	tests := []struct {
-		input, want string
+		module, file, want string
 	}{
-		{"golang.org/x/tools", "golang.org/x/tools"},
-		{"github.com/Azure/go-sdk", "github.com/!azure/go-sdk"},
-		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
-		{"nochange", "nochange"},
-		{"ALL", "!a!l!l"},
+		{"golang.org/x/tools", "@v/list", "/golang.org/x/tools/@v/list"},
+		{"github.com/Azure/go-sdk", "@latest", "/github.com/!azure/go-sdk/@latest"},
+		{"github.com/BurntSushi/toml", "@v/v1.0.0.mod", "/github.com/!burnt!sushi/toml/@v/v1.0.0.mod"},
+		{"example.com/mod", "@v/v1.0.0-RC1.zip", "/example.com/mod/@v/v1.0.0-!r!c1.zip"},
 	}
 
 	for _, tt := range tests {
-		got := encodePath(tt.input)
-		if got != tt.want {
-			t.Errorf("encodePath(%q) = %q, want %q", tt.input, got, tt.want)
+		got, err := proxy.modulePath(tt.module, tt.file)
+		if err != nil || got != tt.want {
+			t.Errorf("modulePath(%q, %q) = %q, %v, want %q", tt.module, tt.file, got, err, tt.want)
+		}
+	}
+
+	for _, mod := range []string{"", "example.com/a b", "example.com/../x"} {
+		if _, err := proxy.modulePath(mod, "@v/list"); err == nil {
+			t.Errorf("modulePath(%q): expected error for invalid module path", mod)
 		}
 	}
 }
@@ -41,7 +51,8 @@ func TestProxyClient_ListVersions(t *testing.T) {
 			return
 		}
 
-		if _, err := w.Write([]byte("v0.1.0\nv0.2.0\nv1.0.0\n")); err != nil {
+		// The list is unordered; string order would put v0.10.0 first.
+		if _, err := w.Write([]byte("v0.10.0\nv1.0.0\nv0.2.0\nv1.0.0-rc.1\nnot-a-version\nv0.9.1\n")); err != nil {
 			t.Errorf("write response: %v", err)
 		}
 	}))
@@ -52,12 +63,9 @@ func TestProxyClient_ListVersions(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(versions) != 3 {
-		t.Fatalf("got %d versions, want 3", len(versions))
-	}
-
-	if versions[2] != "v1.0.0" {
-		t.Errorf("versions[2] = %q, want %q", versions[2], "v1.0.0")
+	want := "v0.2.0 v0.9.1 v0.10.0 v1.0.0-rc.1 v1.0.0"
+	if got := strings.Join(versions, " "); got != want {
+		t.Errorf("versions = %s, want %s", got, want)
 	}
 }
 
@@ -109,6 +117,103 @@ func TestProxyClient_ResolveLatest(t *testing.T) {
 	}
 }
 
+func TestProxyClient_ResolveLatest_Fallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		latest   string // @latest response; "" for a server error
+		list     string
+		retract  string // retract directive in the highest version's go.mod
+		want     string
+		wantNote string
+	}{
+		{"latest fails", "", "v1.0.0\nv1.1.0\n", "", "v1.1.0", "@latest failed"},
+		{"latest behind", "v1.0.0", "v1.0.0\nv1.2.0\n", "", "v1.2.0", "@latest reported v1.0.0, behind @v/list"},
+		{"retracted", "v1.1.0", "v1.1.0\nv1.2.0\n", "retract v1.2.0", "v1.1.0", ""},
+		{"newer pre-release", "v1.1.0", "v1.1.0\nv1.2.0-rc.1\n", "", "v1.1.0", ""},
+		{"pseudo-version behind", "v0.0.0-20240101000000-abcdefabcdef", "v0.1.0\n", "", "v0.1.0", "behind"},
+		{"incompatible", "v1.1.0", "v1.1.0\nv2.0.0+incompatible\n", "", "v1.1.0", ""},
+		{"empty list", "v0.0.0-20240101000000-abcdefabcdef", "", "", "v0.0.0-20240101000000-abcdefabcdef", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/example.com/mod/@latest" && tc.latest == "":
+					http.Error(w, "boom", http.StatusInternalServerError)
+				case r.URL.Path == "/example.com/mod/@latest":
+					_, _ = w.Write([]byte(`{"Version":"` + tc.latest + `"}`))
+				case r.URL.Path == "/example.com/mod/@v/list":
+					_, _ = w.Write([]byte(tc.list))
+				case strings.HasSuffix(r.URL.Path, ".mod"):
+					_, _ = w.Write([]byte("module example.com/mod\n\n" + tc.retract + "\n"))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+
+			notes := &callNotes{}
+			ctx := context.WithValue(context.Background(), notesKey{}, notes)
+
+			version, err := proxy.ResolveLatest(ctx, "example.com/mod")
+			mustf(t, err, "resolve latest")
+
+			if version != tc.want {
+				t.Errorf("got %q, want %q", version, tc.want)
+			}
+
+			note := strings.Join(notes.notes, "\n")
+			if tc.wantNote == "" && note != "" || !strings.Contains(note, tc.wantNote) {
+				t.Errorf("note = %q, want one containing %q", note, tc.wantNote)
+			}
+		})
+	}
+}
+
+func TestProxyClient_Latest(t *testing.T) {
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mod/@latest":
+			// Field order and escaping differ from the usual proxy output.
+			_, _ = w.Write([]byte(`{
+  "Time": "2025-01-01T00:00:00Z",
+  "Origin": {"VCS": "git", "URL": "https:\/\/example.com\/mod", "Ref": "refs/tags/v1.2.3", "Hash": "abc123"},
+  "Version": "v1.2.3"
+}`))
+		case "/example.com/mod/@v/v1.0.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.0.0","Time":"2024-06-01T12:00:00Z"}`))
+		case "/example.com/bad/@latest":
+			_, _ = w.Write([]byte(`{"Time":"2025-01-01T00:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	info, err := proxy.Latest(context.Background(), "example.com/mod")
+	mustf(t, err, "latest")
+
+	if info.Version != "v1.2.3" || info.Time != time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	if info.Origin == nil || info.Origin.URL != "https://example.com/mod" || info.Origin.Hash != "abc123" {
+		t.Errorf("unexpected origin: %+v", info.Origin)
+	}
+
+	info, err = proxy.Info(context.Background(), "example.com/mod", "v1.0.0")
+	mustf(t, err, "info")
+
+	if info.Version != "v1.0.0" || info.Origin != nil {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	if _, err := proxy.ResolveLatest(context.Background(), "example.com/bad"); err == nil {
+		t.Error("expected error for info without a version")
+	}
+}
+
 func TestProxyClient_ReadMod(t *testing.T) {
 	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/example.com/mod/@v/v1.0.0.mod" {
@@ -198,3 +303,99 @@ func TestProxyClient_ServerError(t *testing.T) {
 		t.Fatal("500 should not be ErrModuleNotFound")
 	}
 }
+
+func TestProxyClient_MetadataCache(t *testing.T) {
+	var requests int
+
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		_, _ = w.Write([]byte("v1.0.0\n"))
+	}))
+	defer ts.Close()
+
+	proxy.meta = NewMetadataCache(time.Minute)
+
+	for range 3 {
+		if _, err := proxy.ListVersions(context.Background(), "example.com/mod"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 with metadata cache", requests)
+	}
+}
+
+func TestProxyClient_Offline(t *testing.T) {
+	proxy, ts := newTestProxy(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected request in offline mode")
+	}))
+	defer ts.Close()
+
+	proxy.offline = true
+
+	_, err := proxy.ListVersions(context.Background(), "example.com/mod")
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("got err=%v, want ErrOffline", err)
+	}
+}
+
+func TestProxyClient_InfoCache(t *testing.T) {
+	var requests int
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		version := strings.TrimSuffix(path.Base(r.URL.Path), ".info")
+		if version == "master" {
+			version = "v0.0.0-20240101000000-abcdefabcdef"
+		}
+
+		_, _ = w.Write([]byte(`{"Version":"` + version + `","Time":"2024-01-01T00:00:00Z"}`))
+	})
+
+	proxy, ts := newTestProxy(handler)
+	defer ts.Close()
+
+	disk, err := OpenDiskCache(t.TempDir())
+	mustf(t, err, "open disk cache")
+
+	proxy.disk = disk
+
+	for range 3 {
+		info, err := proxy.Info(context.Background(), "example.com/mod", "v1.0.0")
+		if err != nil || info.Version != "v1.0.0" {
+			t.Fatalf("Info = %+v, %v", info, err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 for a version's .info", requests)
+	}
+
+	// A branch resolves anew every time.
+	for range 2 {
+		if _, err := proxy.Info(context.Background(), "example.com/mod", "master"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests != 3 {
+		t.Errorf("requests = %d, want the branch fetched twice", requests)
+	}
+
+	// Another client shares the disk cache.
+	other, ts2 := newTestProxy(handler)
+	defer ts2.Close()
+
+	other.disk = disk
+
+	if info, err := other.Info(context.Background(), "example.com/mod", "v1.0.0"); err != nil || info.Version != "v1.0.0" {
+		t.Errorf("Info from disk = %+v, %v", info, err)
+	}
+
+	if requests != 3 {
+		t.Errorf("requests = %d, want the .info read from disk", requests)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultGOPROXY is the go command's GOPROXY when none is set.
+const defaultGOPROXY = defaultProxyURL + ",direct"
+
+// ErrProxyOff is returned for requests that reach "off" in GOPROXY.
+var ErrProxyOff = errors.New("module lookup disabled by GOPROXY=off")
+
+// ErrProxyDirect is returned for a GOPROXY list with "direct" but no
+// proxy. Modules are read from proxies, never from version control, so
+// "direct" is skipped and such a list leaves nothing to fetch from.
+var ErrProxyDirect = errors.New("GOPROXY lists no module proxy, only direct")
+
+// proxyHop is one entry of a GOPROXY list.
+type proxyHop struct {
+	url string // a proxy URL, or "direct" or "off"
+
+	// fallbackOnError is set for an entry followed by "|": the next entry
+	// is tried after any error, not only after not found.
+	fallbackOnError bool
+}
+
+// parseGOPROXY parses a GOPROXY value as the go command does: URLs and
+// the keywords "direct" and "off", separated by "," to fall back when a
+// proxy answers not found, or by "|" to fall back after any error. An
+// entry without a scheme is an https URL. An empty value is the default,
+// proxy.golang.org followed by direct. A list with "direct" but no proxy
+// is an ErrProxyDirect error.
+func parseGOPROXY(value string) ([]proxyHop, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = defaultGOPROXY
+	}
+
+	setting := value
+
+	var hops []proxyHop
+
+	for value != "" {
+		entry, sep := value, byte(0)
+		if i := strings.IndexAny(value, ",|"); i >= 0 {
+			entry, sep, value = value[:i], value[i], value[i+1:]
+		} else {
+			value = ""
+		}
+
+		entry = strings.TrimSpace(entry)
+
+		switch entry {
+		case "":
+			continue
+		case "direct", "off":
+		default:
+			if !strings.Contains(entry, ":/") {
+				entry = "https://" + entry
+			}
+
+			u, err := neturl.Parse(entry)
+			if err != nil || u.Host == "" || u.Scheme != "http" && u.Scheme != "https" {
+				return nil, fmt.Errorf("GOPROXY entry %q: want an http or https URL, direct or off", entry)
+			}
+
+			entry = strings.TrimSuffix(entry, "/")
+		}
+
+		hops = append(hops, proxyHop{url: entry, fallbackOnError: sep == '|'})
+	}
+
+	if len(hops) == 0 {
+		return nil, errors.New("GOPROXY lists no proxy")
+	}
+
+	if !hasProxy(hops) && slices.ContainsFunc(hops, func(h proxyHop) bool { return h.url == "direct" }) {
+		return nil, fmt.Errorf("%w: GOPROXY=%s, but modules are only read from a module proxy, never from "+
+			"version control; list one, as in GOPROXY=https://proxy.golang.org,direct", ErrProxyDirect, setting)
+	}
+
+	return hops, nil
+}
+
+// hasProxy reports whether a GOPROXY list has a proxy URL.
+func hasProxy(hops []proxyHop) bool {
+	return slices.ContainsFunc(hops, func(h proxyHop) bool { return h.url != "direct" && h.url != "off" })
+}
+
+// withoutAddr drops the proxies of a GOPROXY list that are the GOPROXY
+// endpoint served on addr, so that a GOPROXY exported for the go command
+// does not send this server's requests back to itself. A list left
+// without a proxy makes the client fall back to baseURL, with a warning.
+func withoutAddr(hops []proxyHop, addr string) []proxyHop {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return hops
+	}
+
+	var kept []proxyHop
+
+	for _, hop := range hops {
+		u, err := neturl.Parse(hop.url)
+		if err == nil && u.Port() == port && (u.Hostname() == host || isLoopback(u.Hostname()) && isLoopback(host)) {
+			log.Printf("warning: skipping GOPROXY entry %s, the GOPROXY endpoint this server serves", hop.url)
+
+			continue
+		}
+
+		kept = append(kept, hop)
+	}
+
+	if len(kept) < len(hops) && !hasProxy(kept) {
+		log.Printf("warning: GOPROXY lists no other proxy; using %s", defaultProxyURL)
+
+		return nil
+	}
+
+	return kept
+}
+
+func isLoopback(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// proxyList returns the GOPROXY list the client walks, which is baseURL
+// alone unless proxies is set.
+func (p *ProxyClient) proxyList() []proxyHop {
+	if len(p.proxies) > 0 {
+		return p.proxies
+	}
+
+	return []proxyHop{{url: p.baseURL}}
+}
+
+// Endpoints returns the URLs of the proxies in the GOPROXY list, in order.
+func (p *ProxyClient) Endpoints() []string {
+	var urls []string
+
+	for _, hop := range p.proxyList() {
+		if hop.url != "direct" && hop.url != "off" {
+			urls = append(urls, hop.url)
+		}
+	}
+
+	return urls
+}
+
+// get fetches a path below the proxy root, such as
+// "/example.com/m/@v/list", walking the GOPROXY list as the go command
+// does. After not found from a proxy the next entry is tried; after any
+// other error only when the proxy is followed by "|". "direct" is
+// skipped and "off" fails. When no entry answers, the most telling error
+// is returned: one other than not found, if any entry gave one.
+// Modules matching GONOPROXY have no path, so they never get here.
+func (p *ProxyClient) get(ctx context.Context, path string) ([]byte, error) {
+	if p.offline {
+		return nil, ErrOffline
+	}
+
+	var best error
+
+	for _, hop := range p.proxyList() {
+		var err error
+
+		switch hop.url {
+		case "direct":
+			continue
+		case "off":
+			err = ErrProxyOff
+		default:
+			var body []byte
+			if body, err = p.getFrom(ctx, hop.url, path); err == nil {
+				return body, nil
+			}
+		}
+
+		notFound := errors.Is(err, ErrModuleNotFound)
+		if best == nil || !notFound && errors.Is(best, ErrModuleNotFound) {
+			best = err
+		}
+
+		if !notFound && !hop.fallbackOnError || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if best == nil {
+		best = ErrProxyDirect
+	}
+
+	return nil, best
+}
+
+// getFrom fetches a path from one proxy, recording the outcome in the
+// health tracker.
+func (p *ProxyClient) getFrom(ctx context.Context, baseURL, path string) ([]byte, error) {
+	noteBackend(ctx, backendProxy)
+
+	start := time.Now()
+
+	body, err := p.fetch(ctx, baseURL+path)
+
+	// Not found is an answer; requests the caller gave up on say nothing
+	// about the proxy.
+	if ctx.Err() == nil {
+		failure := err
+		if errors.Is(err, ErrModuleNotFound) {
+			failure = nil
+		}
+
+		p.health.Record(baseURL, time.Since(start), failure)
+	}
+
+	return body, err
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGOPROXY(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  []proxyHop
+	}{
+		{"", []proxyHop{{url: "https://proxy.golang.org"}, {url: "direct"}}},
+		{"off", []proxyHop{{url: "off"}}},
+		{"https://athens.corp.example/, direct", []proxyHop{{url: "https://athens.corp.example"}, {url: "direct"}}},
+		{"goproxy.corp.example|http://mirror.corp.example:8080/go,off", []proxyHop{
+			{url: "https://goproxy.corp.example", fallbackOnError: true},
+			{url: "http://mirror.corp.example:8080/go"},
+			{url: "off"},
+		}},
+		{"https://a.example,,https://b.example|", []proxyHop{
+			{url: "https://a.example"},
+			{url: "https://b.example", fallbackOnError: true},
+		}},
+	} {
+		got, err := parseGOPROXY(tt.value)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGOPROXY(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"file:///srv/goproxy", "ftp://proxy.example", "https://", " , | "} {
+		if _, err := parseGOPROXY(value); err == nil {
+			t.Errorf("parseGOPROXY(%q): expected an error", value)
+		}
+	}
+
+	// Modules are never fetched directly, so direct alone leaves nothing.
+	for _, value := range []string{"direct", "direct,off", "direct|direct"} {
+		if _, err := parseGOPROXY(value); !errors.Is(err, ErrProxyDirect) {
+			t.Errorf("parseGOPROXY(%q) = %v, want ErrProxyDirect", value, err)
+		}
+	}
+}
+
+func TestWithoutAddr(t *testing.T) {
+	hops := []proxyHop{
+		{url: "http://localhost:7070"},
+		{url: "http://127.0.0.1:7070"},
+		{url: "http://localhost:8080"},
+		{url: "https://proxy.golang.org"},
+		{url: "direct"},
+	}
+
+	want := []proxyHop{{url: "http://localhost:8080"}, {url: "https://proxy.golang.org"}, {url: "direct"}}
+
+	for _, addr := range []string{"localhost:7070", ":7070", "127.0.0.1:7070"} {
+		if got := withoutAddr(hops, addr); !reflect.DeepEqual(got, want) {
+			t.Errorf("withoutAddr(%q) = %+v, want %+v", addr, got, want)
+		}
+	}
+
+	// Without another proxy the client falls back to its base URL.
+	if got := withoutAddr([]proxyHop{{url: "http://localhost:7070"}, {url: "direct"}}, ":7070"); got != nil {
+		t.Errorf("withoutAddr leaving only direct = %+v, want none", got)
+	}
+}
+
+func TestProxyClient_GOPROXYFallback(t *testing.T) {
+	servers := map[string]*httptest.Server{}
+
+	for name, code := range map[string]int{
+		"OK": http.StatusOK, "MISSING": http.StatusNotFound, "GONE": http.StatusGone, "FAILING": http.StatusBadGateway,
+	} {
+		servers[name] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if code != http.StatusOK {
+				http.Error(w, http.StatusText(code), code)
+
+				return
+			}
+
+			_, _ = w.Write([]byte("v1.0.0\n"))
+		}))
+		defer servers[name].Close()
+	}
+
+	var names []string
+	for name, ts := range servers {
+		names = append(names, name, ts.URL)
+	}
+
+	urls := strings.NewReplacer(names...)
+
+	for _, tt := range []struct {
+		goproxy string
+		want    string // "" when the list is fetched
+	}{
+		{"OK", ""},
+		{"MISSING,GONE,OK", ""},
+		{"MISSING,direct,OK", ""},
+		{"direct,OK", ""},
+		{"FAILING|OK", ""},
+		{"FAILING,OK", "unexpected status 502"},
+		{"MISSING|FAILING,OK", "unexpected status 502"},
+		{"FAILING|MISSING", "unexpected status 502"},
+		{"MISSING,GONE", ErrModuleNotFound.Error()},
+		{"MISSING,direct", ErrModuleNotFound.Error()},
+		{"MISSING,off,OK", ErrProxyOff.Error()},
+		{"off", ErrProxyOff.Error()},
+	} {
+		hops, err := parseGOPROXY(urls.Replace(tt.goproxy))
+		mustf(t, err, "parse %s", tt.goproxy)
+
+		proxy := &ProxyClient{proxies: hops, client: http.DefaultClient}
+
+		versions, err := proxy.ListVersions(context.Background(), "example.com/m")
+		if tt.want == "" && (err != nil || len(versions) != 1) {
+			t.Errorf("GOPROXY=%s: got %v, %v; want the version list", tt.goproxy, versions, err)
+		}
+
+		if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("GOPROXY=%s: got %v, want %q", tt.goproxy, err, tt.want)
+		}
+	}
+}
+
+func TestProxyClient_Endpoints(t *testing.T) {
+	proxy := &ProxyClient{baseURL: "https://proxy.example"}
+	if got := proxy.Endpoints(); !reflect.DeepEqual(got, []string{"https://proxy.example"}) {
+		t.Errorf("Endpoints without a list = %q", got)
+	}
+
+	proxy.proxies = []proxyHop{{url: "https://a.example"}, {url: "direct"}, {url: "https://b.example"}, {url: "off"}}
+	if got := proxy.Endpoints(); !reflect.DeepEqual(got, []string{"https://a.example", "https://b.example"}) {
+		t.Errorf("Endpoints = %q", got)
+	}
+}
+
+func TestProxyClient_GONOPROXY(t *testing.T) {
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/git.corp.example/") {
+			t.Errorf("GONOPROXY module requested: %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte("v1.0.0\n"))
+	}))
+	defer ts.Close()
+
+	proxy.noproxy = "git.corp.example,*.internal.example"
+
+	for _, mod := range []string{"git.corp.example/team/lib", "go.internal.example/x"} {
+		_, err := proxy.ListVersions(context.Background(), mod)
+		if !errors.Is(err, ErrModuleNotFound) || !strings.Contains(err.Error(), "GONOPROXY") {
+			t.Errorf("ListVersions(%s) = %v, want not found through GONOPROXY", mod, err)
+		}
+	}
+
+	if _, err := proxy.ListVersions(context.Background(), "example.com/public"); err != nil {
+		t.Errorf("ListVersions of a public module: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxHealthSamples is how many recent requests are kept per endpoint.
+const maxHealthSamples = 100
+
+// healthSample is the outcome of one request to an endpoint.
+type healthSample struct {
+	at      time.Time
+	latency time.Duration
+	err     error
+}
+
+// HealthTracker keeps the outcomes of the most recent requests to each
+// proxy and checksum database endpoint, so their error rates can be
+// reported. Responses that are answers, like 404 for an unknown module,
+// count as successes.
+type HealthTracker struct {
+	mu      sync.Mutex
+	samples map[string][]healthSample
+}
+
+// NewHealthTracker creates an empty tracker.
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{samples: make(map[string][]healthSample)}
+}
+
+// Record adds the outcome of a request to endpoint, dropping the oldest
+// sample once maxHealthSamples are kept. A nil tracker records nothing.
+func (h *HealthTracker) Record(endpoint string, latency time.Duration, err error) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := append(h.samples[endpoint], healthSample{at: time.Now(), latency: latency, err: err})
+	if len(s) > maxHealthSamples {
+		s = s[len(s)-maxHealthSamples:]
+	}
+
+	h.samples[endpoint] = s
+}
+
+// endpointHealth summarizes the recent requests to an endpoint.
+type endpointHealth struct {
+	requests   int
+	errors     int
+	avgLatency time.Duration
+	lastErr    error
+	lastErrAt  time.Time
+}
+
+// Summary returns the summary of the requests kept for endpoint.
+func (h *HealthTracker) Summary(endpoint string) endpointHealth {
+	var sum endpointHealth
+
+	if h == nil {
+		return sum
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var total time.Duration
+
+	for _, s := range h.samples[endpoint] {
+		sum.requests++
+		total += s.latency
+
+		if s.err != nil {
+			sum.errors++
+			sum.lastErr, sum.lastErrAt = s.err, s.at
+		}
+	}
+
+	if sum.requests > 0 {
+		sum.avgLatency = total / time.Duration(sum.requests)
+	}
+
+	return sum
+}
+
+// probeSumDB fetches the checksum database's latest signed tree head.
+func probeSumDB(ctx context.Context, client *http.Client, sumDBURL string) error {
+	url := sumDBURL + "/latest"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// endpointStatus is one row of the gomod_proxy_status report.
+type endpointStatus struct {
+	kind     string
+	endpoint string
+	probe    time.Duration
+	probeErr error
+	recent   endpointHealth
+}
+
+type proxyStatusInput struct{}
+
+func handleProxyStatus(ctx context.Context, proxy *ProxyClient, sumDBURL string) (*mcp.CallToolResult, any, error) {
+	if proxy.offline {
+		return textResult("Offline mode: the module proxy and checksum database are not contacted."), nil, nil
+	}
+
+	var rows []endpointStatus
+
+	for _, endpoint := range proxy.Endpoints() {
+		rows = append(rows, endpointStatus{kind: "proxy", endpoint: endpoint})
+	}
+
+	rows = append(rows, endpointStatus{kind: "sumdb", endpoint: sumDBURL})
+
+	parallelEach(rows, func(i int, row endpointStatus) {
+		start := time.Now()
+
+		if row.kind == "sumdb" {
+			rows[i].probeErr = probeSumDB(ctx, proxy.client, sumDBURL)
+			rows[i].probe = time.Since(start)
+
+			proxy.health.Record(sumDBURL, rows[i].probe, rows[i].probeErr)
+
+			return
+		}
+
+		_, rows[i].probeErr = proxy.Probe(ctx, row.endpoint)
+		rows[i].probe = time.Since(start)
+	})
+
+	for i := range rows {
+		rows[i].recent = proxy.health.Summary(rows[i].endpoint)
+	}
+
+	return textResult(formatProxyStatus(rows, time.Now())), nil, nil
+}
+
+func formatProxyStatus(rows []endpointStatus, now time.Time) string {
+	var sb strings.Builder
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "KIND\tENDPOINT\tPROBE\tLATENCY\tRECENT REQUESTS\tERRORS\tAVG LATENCY")
+
+	var problems []string
+
+	for _, r := range rows {
+		probe := "ok"
+		if r.probeErr != nil {
+			probe = "FAIL"
+
+			problems = append(problems, fmt.Sprintf("%s probe failed: %v", r.endpoint, r.probeErr))
+		}
+
+		errRate := "-"
+		if r.recent.requests > 0 {
+			errRate = fmt.Sprintf("%d (%.0f%%)", r.recent.errors, 100*float64(r.recent.errors)/float64(r.recent.requests))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", r.kind, r.endpoint, probe,
+			r.probe.Round(time.Millisecond), r.recent.requests, errRate, r.recent.avgLatency.Round(time.Millisecond))
+
+		if r.recent.lastErr != nil && !errors.Is(r.recent.lastErr, r.probeErr) {
+			problems = append(problems, fmt.Sprintf("%s last error %s ago: %v",
+				r.endpoint, now.Sub(r.recent.lastErrAt).Round(time.Second), r.recent.lastErr))
+		}
+	}
+
+	_ = tw.Flush()
+
+	if len(problems) > 0 {
+		sb.WriteString("\n")
+
+		for _, p := range problems {
+			sb.WriteString(p + "\n")
+		}
+	}
+
+	fmt.Fprintf(&sb, "\nRecent requests are the last %d to each endpoint, including the probes.\n", maxHealthSamples)
+
+	return sb.String()
+}
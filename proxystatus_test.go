@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthTracker(t *testing.T) {
+	h := NewHealthTracker()
+
+	for i := range maxHealthSamples + 10 {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("boom")
+		}
+
+		h.Record("https://proxy.example", time.Duration(i%4)*time.Millisecond, err)
+	}
+
+	sum := h.Summary("https://proxy.example")
+	if sum.requests != maxHealthSamples || sum.errors != maxHealthSamples/10 || sum.lastErr == nil {
+		t.Errorf("got %+v, want the last %d requests with every tenth failed", sum, maxHealthSamples)
+	}
+
+	if got := h.Summary("https://other.example"); got.requests != 0 {
+		t.Errorf("unknown endpoint = %+v", got)
+	}
+
+	var nilTracker *HealthTracker
+
+	nilTracker.Record("x", 0, nil)
+
+	if got := nilTracker.Summary("x"); got.requests != 0 {
+		t.Errorf("nil tracker = %+v", got)
+	}
+}
+
+func TestProxyClient_RecordsHealth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/example.com/broken/@v/list" {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client(), health: NewHealthTracker()}
+
+	_, _ = proxy.ListVersions(context.Background(), "example.com/missing")
+	_, _ = proxy.ListVersions(context.Background(), "example.com/broken")
+
+	if sum := proxy.health.Summary(ts.URL); sum.requests != 2 || sum.errors != 1 {
+		t.Errorf("got %+v, want 2 requests with 1 error (404 is an answer)", sum)
+	}
+}
+
+func TestFormatProxyStatus(t *testing.T) {
+	now := time.Now()
+
+	text := formatProxyStatus([]endpointStatus{
+		{
+			kind: "proxy", endpoint: "https://proxy.example", probe: 12 * time.Millisecond,
+			recent: endpointHealth{
+				requests: 4, errors: 1, avgLatency: 30 * time.Millisecond,
+				lastErr: errors.New("unexpected status 502"), lastErrAt: now.Add(-time.Minute),
+			},
+		},
+		{kind: "sumdb", endpoint: "https://sum.example", probeErr: errors.New("status 503")},
+	}, now)
+
+	for _, want := range []string{
+		"proxy  https://proxy.example  ok     12ms     4                1 (25%)  30ms",
+		"sumdb  https://sum.example    FAIL   0s       0                -        0s",
+		"https://sum.example probe failed: status 503",
+		"https://proxy.example last error 1m0s ago: unexpected status 502",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
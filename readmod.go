@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+)
+
+// goModInfo is the structured form of a go.mod file returned by
+// gomod_read_mod with structured set.
+type goModInfo struct {
+	Module     string         `json:"module"`
+	Deprecated string         `json:"deprecated,omitempty"`
+	Go         string         `json:"go,omitempty"`
+	Toolchain  string         `json:"toolchain,omitempty"`
+	Godebug    []goModGodebug `json:"godebug,omitempty"`
+	Require    []goModRequire `json:"require,omitempty"`
+	Exclude    []goModVersion `json:"exclude,omitempty"`
+	Replace    []goModReplace `json:"replace,omitempty"`
+	Retract    []goModRetract `json:"retract,omitempty"`
+	Tool       []string       `json:"tool,omitempty"`
+	Ignore     []string       `json:"ignore,omitempty"`
+}
+
+type goModGodebug struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type goModRequire struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitempty"`
+}
+
+// goModVersion is a module path with a version, which the new side of a
+// replace with a directory and the old side of one for every version
+// leave out.
+type goModVersion struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+}
+
+type goModReplace struct {
+	Old goModVersion `json:"old"`
+	New goModVersion `json:"new"`
+}
+
+type goModRetract struct {
+	Low       string `json:"low"`
+	High      string `json:"high"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// parseGoModInfo parses a go.mod file with every directive, although the
+// go command only applies replace, exclude and the like in the main
+// module. A file with directives too new for x/mod is parsed leniently,
+// which leaves those and the main-module directives out.
+func parseGoModInfo(content string) (*goModInfo, error) {
+	f, err := modfile.Parse("go.mod", []byte(content), nil)
+	if err != nil {
+		var lerr error
+		if f, lerr = modfile.ParseLax("go.mod", []byte(content), nil); lerr != nil {
+			return nil, fmt.Errorf("parse go.mod: %w", err)
+		}
+	}
+
+	info := &goModInfo{}
+
+	if f.Module != nil {
+		info.Module, info.Deprecated = f.Module.Mod.Path, f.Module.Deprecated
+	}
+
+	if f.Go != nil {
+		info.Go = f.Go.Version
+	}
+
+	if f.Toolchain != nil {
+		info.Toolchain = f.Toolchain.Name
+	}
+
+	for _, g := range f.Godebug {
+		info.Godebug = append(info.Godebug, goModGodebug{Key: g.Key, Value: g.Value})
+	}
+
+	for _, r := range f.Require {
+		info.Require = append(info.Require, goModRequire{Path: r.Mod.Path, Version: r.Mod.Version, Indirect: r.Indirect})
+	}
+
+	for _, x := range f.Exclude {
+		info.Exclude = append(info.Exclude, goModVersion{Path: x.Mod.Path, Version: x.Mod.Version})
+	}
+
+	for _, r := range f.Replace {
+		info.Replace = append(info.Replace, goModReplace{
+			Old: goModVersion{Path: r.Old.Path, Version: r.Old.Version},
+			New: goModVersion{Path: r.New.Path, Version: r.New.Version},
+		})
+	}
+
+	for _, r := range f.Retract {
+		info.Retract = append(info.Retract, goModRetract{Low: r.Low, High: r.High, Rationale: r.Rationale})
+	}
+
+	for _, t := range f.Tool {
+		info.Tool = append(info.Tool, t.Path)
+	}
+
+	for _, i := range f.Ignore {
+		info.Ignore = append(info.Ignore, i.Path)
+	}
+
+	return info, nil
+}
+
+// structuredGoMod returns the result of gomod_read_mod with structured
+// set: the directives as structured content, and as indented JSON text
+// for clients that only read text.
+func structuredGoMod(content string) (*mcp.CallToolResult, any, error) {
+	info, err := parseGoModInfo(content)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	text, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode go.mod: %w", err)
+	}
+
+	return textResult(string(text) + "\n"), info, nil
+}
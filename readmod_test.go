@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGoModInfo(t *testing.T) {
+	info, err := parseGoModInfo(`// Deprecated: use example.com/new.
+module example.com/old
+
+go 1.22.0
+
+toolchain go1.23.4
+
+godebug default=go1.21
+
+require (
+	example.com/a v1.2.0
+	example.com/b v0.3.1 // indirect
+)
+
+exclude example.com/a v1.1.0
+
+replace (
+	example.com/a => example.com/fork v1.2.1
+	example.com/b v0.3.1 => ../b
+)
+
+retract (
+	v1.0.1 // Published by mistake.
+	[v0.9.0, v0.9.5]
+)
+
+tool example.com/a/cmd/gen
+`)
+	mustf(t, err, "parse go.mod")
+
+	want := &goModInfo{
+		Module:     "example.com/old",
+		Deprecated: "use example.com/new.",
+		Go:         "1.22.0",
+		Toolchain:  "go1.23.4",
+		Godebug:    []goModGodebug{{Key: "default", Value: "go1.21"}},
+		Require: []goModRequire{
+			{Path: "example.com/a", Version: "v1.2.0"},
+			{Path: "example.com/b", Version: "v0.3.1", Indirect: true},
+		},
+		Exclude: []goModVersion{{Path: "example.com/a", Version: "v1.1.0"}},
+		Replace: []goModReplace{
+			{Old: goModVersion{Path: "example.com/a"}, New: goModVersion{Path: "example.com/fork", Version: "v1.2.1"}},
+			{Old: goModVersion{Path: "example.com/b", Version: "v0.3.1"}, New: goModVersion{Path: "../b"}},
+		},
+		Retract: []goModRetract{
+			{Low: "v1.0.1", High: "v1.0.1", Rationale: "Published by mistake."},
+			{Low: "v0.9.0", High: "v0.9.5"},
+		},
+		Tool: []string{"example.com/a/cmd/gen"},
+	}
+
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("got %+v\nwant %+v", info, want)
+	}
+
+	// Unknown directives fall back to the lenient parse.
+	info, err = parseGoModInfo("module example.com/x\n\nfuture directive\n\nreplace example.com/a => ../a\n")
+	mustf(t, err, "parse go.mod with an unknown directive")
+
+	if info.Module != "example.com/x" || info.Replace != nil {
+		t.Errorf("lenient parse: %+v", info)
+	}
+
+	if _, err := parseGoModInfo("module example.com/x\nrequire (\n"); err == nil {
+		t.Error("expected an error for a truncated go.mod")
+	}
+}
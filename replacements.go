@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+const (
+	defaultReplacementLimit = 5
+	replacementSearchLimit  = 20
+	// maxModuleWalk bounds how many path elements are trimmed when mapping
+	// a package path from search results to its module.
+	maxModuleWalk = 3
+)
+
+// Where a replacement candidate came from, in order of preference.
+const (
+	sourceDeprecation = "named in deprecation notice"
+	sourceNextMajor   = "newer major version"
+	sourceSearch      = "pkg.go.dev search"
+)
+
+var candidateRank = map[string]int{sourceDeprecation: 0, sourceNextMajor: 1, sourceSearch: 2}
+
+// repoInfo is deps.dev data about a module's source repository.
+type repoInfo struct {
+	id         string
+	stars      int
+	maintained int // OpenSSF Scorecard Maintained score, -1 if unknown
+	licenses   []string
+}
+
+// moduleCandidate is a possible fork or successor of a module.
+type moduleCandidate struct {
+	module     string
+	source     string
+	latest     *VersionInfo
+	deprecated string
+	repo       *repoInfo
+	synopsis   string
+	importedBy int
+}
+
+// lookupRepo fetches license and repository data from deps.dev. Missing
+// data is not an error; nil is returned instead.
+func lookupRepo(ctx context.Context, depsDev *DepsDevClient, mod, version string) *repoInfo {
+	v, err := depsDev.Version(ctx, mod, version)
+	if err != nil {
+		return nil
+	}
+
+	info := &repoInfo{id: v.sourceRepo(), maintained: -1, licenses: v.Licenses}
+
+	if info.id == "" {
+		return info
+	}
+
+	if p, err := depsDev.Project(ctx, info.id); err == nil {
+		info.stars = int(p.StarsCount)
+		info.maintained = p.maintainedScore()
+	}
+
+	return info
+}
+
+// resolveCandidateModule maps an import path to the module containing it
+// by trimming path elements until the proxy knows a module.
+func resolveCandidateModule(ctx context.Context, proxy *ProxyClient, importPath string) (string, *VersionInfo, bool) {
+	p := importPath
+
+	for range maxModuleWalk + 1 {
+		if info, err := proxy.Latest(ctx, p); err == nil {
+			return p, info, true
+		}
+
+		parent := path.Dir(p)
+		if parent == "." || parent == p || !strings.Contains(parent, "/") {
+			break
+		}
+
+		p = parent
+	}
+
+	return "", nil, false
+}
+
+// findReplacements collects successors named in the deprecation notice,
+// the next major version, and similarly named modules from pkg.go.dev,
+// enriched with their latest release and deps.dev repository data.
+func findReplacements(
+	ctx context.Context, proxy *ProxyClient, pkgsite *PkgsiteClient, depsDev *DepsDevClient,
+	mod, deprecation string,
+) []*moduleCandidate {
+	candidates := make(map[string]*moduleCandidate)
+
+	name := repoName(mod)
+
+	add := func(importPath, source string, sr *searchResult) {
+		resolved, info, ok := resolveCandidateModule(ctx, proxy, importPath)
+		if !ok || resolved == mod {
+			return
+		}
+
+		// Search hits must look like a fork: a different module with the
+		// same repository name.
+		if source == sourceSearch && (repoName(resolved) != name || strings.HasPrefix(resolved, mod+"/")) {
+			return
+		}
+
+		if c, ok := candidates[resolved]; ok {
+			if candidateRank[source] < candidateRank[c.source] {
+				c.source = source
+			}
+
+			return
+		}
+
+		c := &moduleCandidate{module: resolved, source: source, latest: info}
+		if sr != nil {
+			c.synopsis, c.importedBy = sr.synopsis, sr.importedBy
+		}
+
+		candidates[resolved] = c
+	}
+
+	if successor := successorFromDeprecation(deprecation, mod); successor != "" {
+		add(successor, sourceDeprecation, nil)
+	}
+
+	if next := nextMajorPath(mod); next != "" {
+		add(next, sourceNextMajor, nil)
+	}
+
+	if results, err := pkgsite.Search(ctx, name, replacementSearchLimit); err == nil {
+		for i := range results {
+			if slices.Contains(strings.Split(results[i].path, "/"), name) {
+				add(results[i].path, sourceSearch, &results[i])
+			}
+		}
+	}
+
+	result := make([]*moduleCandidate, 0, len(candidates))
+
+	for _, c := range candidates {
+		if _, mf, err := latestModFile(ctx, proxy, c.module); err == nil {
+			c.deprecated = moduleDeprecation(mf)
+		}
+
+		c.repo = lookupRepo(ctx, depsDev, c.module, c.latest.Version)
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+
+		if (a.deprecated == "") != (b.deprecated == "") {
+			return a.deprecated == ""
+		}
+
+		if candidateRank[a.source] != candidateRank[b.source] {
+			return candidateRank[a.source] < candidateRank[b.source]
+		}
+
+		return a.latest.Time.After(b.latest.Time)
+	})
+
+	return result
+}
+
+// repoName returns the last path element of a module path, ignoring a /vN
+// major version suffix.
+func repoName(mod string) string {
+	if prefix, _, ok := module.SplitPathVersion(mod); ok {
+		mod = prefix
+	}
+
+	return path.Base(mod)
+}
+
+func formatRepoInfo(r *repoInfo) string {
+	if r == nil {
+		return ""
+	}
+
+	var parts []string
+
+	if r.id != "" {
+		parts = append(parts, fmt.Sprintf("%s, %d stars", r.id, r.stars))
+	}
+
+	if r.maintained >= 0 {
+		parts = append(parts, fmt.Sprintf("maintained %d/10", r.maintained))
+	}
+
+	if len(r.licenses) > 0 {
+		parts = append(parts, strings.Join(r.licenses, ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func formatReleaseDate(t time.Time) string {
+	if t.IsZero() {
+		return "release date unknown"
+	}
+
+	return "released " + t.Format(time.DateOnly)
+}
+
+type replacementsInput struct {
+	Module string `json:"module" jsonschema:"Deprecated or abandoned Go module path"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of suggestions (default 5)"`
+}
+
+func handleReplacements(
+	ctx context.Context, proxy *ProxyClient, pkgsite *PkgsiteClient,
+	depsDev *DepsDevClient, local *LocalReader, input replacementsInput,
+) (*mcp.CallToolResult, any, error) {
+	info, err := proxy.Latest(ctx, input.Module)
+	if err != nil {
+		if errors.Is(err, ErrModuleNotFound) {
+			return notFoundResult(input.Module, local), nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	_, mf, err := latestModFile(ctx, proxy, input.Module)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deprecation := moduleDeprecation(mf)
+	repo := lookupRepo(ctx, depsDev, input.Module, info.Version)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s@%s (latest, %s)\n", input.Module, info.Version, formatReleaseDate(info.Time))
+
+	switch {
+	case deprecation != "":
+		fmt.Fprintf(&sb, "Deprecated: %s\n", deprecation)
+	case repo != nil && repo.maintained == 0:
+		sb.WriteString("Not deprecated, but its repository scores 0/10 for maintenance.\n")
+	default:
+		sb.WriteString("Not deprecated; it may not need replacing.\n")
+	}
+
+	if s := formatRepoInfo(repo); s != "" {
+		fmt.Fprintf(&sb, "Repository: %s\n", s)
+	}
+
+	candidates := findReplacements(ctx, proxy, pkgsite, depsDev, input.Module, deprecation)
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultReplacementLimit
+	}
+
+	if len(candidates) == 0 {
+		sb.WriteString("\nNo forks or successors found.\n")
+
+		return textResult(sb.String()), nil, nil
+	}
+
+	fmt.Fprintf(&sb, "\nSuggested replacements (showing %d of %d):\n", min(limit, len(candidates)), len(candidates))
+
+	for i, c := range candidates {
+		if i == limit {
+			break
+		}
+
+		fmt.Fprintf(&sb, "\n%d. %s@%s (%s) — %s\n",
+			i+1, c.module, c.latest.Version, formatReleaseDate(c.latest.Time), c.source)
+
+		if c.deprecated != "" {
+			fmt.Fprintf(&sb, "   Also deprecated: %s\n", c.deprecated)
+		}
+
+		if s := formatRepoInfo(c.repo); s != "" {
+			fmt.Fprintf(&sb, "   %s\n", s)
+		}
+
+		if c.importedBy > 0 {
+			fmt.Fprintf(&sb, "   imported by %d packages\n", c.importedBy)
+		}
+
+		if c.synopsis != "" {
+			fmt.Fprintf(&sb, "   %s\n", c.synopsis)
+		}
+	}
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// replacementsServer fakes the proxy, pkg.go.dev and deps.dev for a
+// deprecated module with a named successor, a v2 and a fork.
+func replacementsServer() *httptest.Server {
+	files := map[string]string{
+		"/example.com/old/@latest":           `{"Version":"v1.4.0","Time":"2019-03-01T00:00:00Z"}`,
+		"/example.com/old/@v/v1.4.0.mod":     "// Deprecated: use example.com/new instead.\nmodule example.com/old\n",
+		"/example.com/old/v2/@latest":        `{"Version":"v2.0.1","Time":"2020-05-01T00:00:00Z"}`,
+		"/example.com/old/v2/@v/v2.0.1.mod":  "// Deprecated: frozen.\nmodule example.com/old/v2\n",
+		"/example.com/new/@latest":           `{"Version":"v1.0.0","Time":"2024-01-01T00:00:00Z"}`,
+		"/example.com/new/@v/v1.0.0.mod":     "module example.com/new\n",
+		"/github.com/fork/old/@latest":       `{"Version":"v1.6.0","Time":"2025-02-01T00:00:00Z"}`,
+		"/github.com/fork/old/@v/v1.6.0.mod": "module github.com/fork/old\n",
+		"/example.com/unrelated/@latest":     `{"Version":"v0.1.0"}`,
+		"/search": `<div class="SearchSnippet"><a href="/github.com/fork/old/sub" data-test-id="snippet-title">x</a>
+<p class="SearchSnippet-synopsis">Maintained fork.</p></div>
+<div class="SearchSnippet"><a href="/example.com/unrelated/old" data-test-id="snippet-title">y</a></div>`,
+		"/v3/systems/go/packages/github.com%2Ffork%2Fold/versions/v1.6.0": `{"licenses":["MIT"],
+"relatedProjects":[{"projectKey":{"id":"github.com/fork/old"},"relationType":"SOURCE_REPO"}]}`,
+		"/v3/projects/github.com%2Ffork%2Fold": `{"starsCount":42,"scorecard":{"checks":[{"name":"Maintained","score":10}]}}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestHandleReplacements(t *testing.T) {
+	ts := replacementsServer()
+	defer ts.Close()
+
+	proxy := &ProxyClient{baseURL: ts.URL, client: ts.Client()}
+	pkgsite := &PkgsiteClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+	depsDev := &DepsDevClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+
+	result, _, err := handleReplacements(context.Background(), proxy, pkgsite, depsDev,
+		NewLocalReader(t.TempDir()), replacementsInput{Module: "example.com/old"})
+	mustf(t, err, "replacements")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	for _, want := range []string{
+		"example.com/old@v1.4.0 (latest, released 2019-03-01)",
+		"Deprecated: use example.com/new instead.",
+		"1. example.com/new@v1.0.0 (released 2024-01-01) — named in deprecation notice",
+		"2. github.com/fork/old@v1.6.0 (released 2025-02-01) — pkg.go.dev search",
+		"github.com/fork/old, 42 stars; maintained 10/10; MIT",
+		"Maintained fork.",
+		"3. example.com/old/v2@v2.0.1 (released 2020-05-01) — newer major version",
+		"Also deprecated: frozen.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "unrelated") {
+		t.Errorf("unrelated search result suggested:\n%s", text)
+	}
+}
+
+func TestRepoName(t *testing.T) {
+	for mod, want := range map[string]string{
+		"github.com/owner/repo":    "repo",
+		"github.com/owner/repo/v3": "repo",
+		"gopkg.in/yaml.v3":         "yaml",
+	} {
+		if got := repoName(mod); got != want {
+			t.Errorf("repoName(%q) = %q, want %q", mod, got, want)
+		}
+	}
+}
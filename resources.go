@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxPublishedModules bounds the module versions whose files are listed as
+// resources. The oldest are removed first.
+const maxPublishedModules = 50
+
+// moduleResources publishes the README and go.mod of every module version
+// a tool opens as MCP resources, so clients can pin them into context.
+// Contents are read when a resource is read, not when it is published.
+type moduleResources struct {
+	server *mcp.Server
+	open   func(ctx context.Context, mod, version string) (moduleFiles, error)
+
+	mu        sync.Mutex
+	published map[string][]string // module@version -> resource URIs
+	order     []string
+}
+
+func newModuleResources(server *mcp.Server, svc *services) *moduleResources {
+	return &moduleResources{
+		server: server,
+		open: func(ctx context.Context, mod, version string) (moduleFiles, error) {
+			return openModule(ctx, svc.proxy, svc.cache, svc.modCache, mod, version)
+		},
+		published: make(map[string][]string),
+	}
+}
+
+type resourcesKey struct{}
+
+// Middleware makes the publisher available to tool handlers, which reach
+// it through publishModule.
+func (r *moduleResources) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/call" {
+				ctx = context.WithValue(ctx, resourcesKey{}, r)
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// publishModule publishes the resources of a module version opened by the
+// tool call running in ctx. It does nothing outside a tool call.
+func publishModule(ctx context.Context, mod, version string, mf moduleFiles) {
+	if r, ok := ctx.Value(resourcesKey{}).(*moduleResources); ok {
+		r.publish(mod, version, mf)
+	}
+}
+
+// moduleResourceURI returns the URI of a file of a module version.
+func moduleResourceURI(mod, version, file string) string {
+	return "gomod://" + mod + "@" + version + "/" + file
+}
+
+func (r *moduleResources) publish(mod, version string, mf moduleFiles) {
+	key := mod + "@" + version
+
+	r.mu.Lock()
+
+	if _, ok := r.published[key]; ok {
+		r.mu.Unlock()
+
+		return
+	}
+
+	// Reserve the key; the server is changed outside the lock because it
+	// notifies every session.
+	r.published[key] = nil
+	r.order = append(r.order, key)
+
+	var evicted []string
+
+	if len(r.order) > maxPublishedModules {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+		evicted = r.published[oldest]
+
+		delete(r.published, oldest)
+	}
+
+	r.mu.Unlock()
+
+	if len(evicted) > 0 {
+		r.server.RemoveResources(evicted...)
+	}
+
+	uris := make([]string, 0, 2)
+
+	for _, file := range moduleResourceFiles(mf) {
+		uri := moduleResourceURI(mod, version, file)
+		uris = append(uris, uri)
+
+		mimeType := "text/plain"
+		if ext := strings.ToLower(path.Ext(file)); ext == ".md" || ext == ".markdown" {
+			mimeType = "text/markdown"
+		}
+
+		r.server.AddResource(&mcp.Resource{
+			URI:         uri,
+			Name:        key + "/" + file,
+			Description: file + " of " + key,
+			MIMEType:    mimeType,
+		}, r.handler(mod, version, file, mimeType))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.published[key]; ok {
+		r.published[key] = uris
+	}
+}
+
+func (r *moduleResources) handler(mod, version, file, mimeType string) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		mf, err := r.open(ctx, mod, version)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := mf.ReadFile(file)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: req.Params.URI, MIMEType: mimeType, Text: text}},
+		}, nil
+	}
+}
+
+// moduleResourceFiles returns the files of a module published as
+// resources: go.mod and the README at the module root, preferring a
+// markdown README when there are several.
+func moduleResourceFiles(mf moduleFiles) []string {
+	paths, err := mf.ListFiles("")
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+
+	if slices.Contains(paths, "go.mod") {
+		files = append(files, "go.mod")
+	}
+
+	if readme := findReadme(paths, "."); readme != "" {
+		files = append(files, readme)
+	}
+
+	return files
+}
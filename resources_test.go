@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type resourcesTestInput struct {
+	Version string `json:"version"`
+}
+
+// testModuleFiles returns the files of an in-memory module version.
+func testModuleFiles(t *testing.T, version string, files map[string]string) moduleFiles {
+	t.Helper()
+
+	entry, err := NewZipCache().Put("example.com/m", version, createTestZip(t, "example.com/m@"+version+"/", files))
+	mustf(t, err, "put zip in cache")
+
+	return zipFiles{entry}
+}
+
+func TestModuleResourceFiles(t *testing.T) {
+	mf := testModuleFiles(t, "v1.0.0", map[string]string{
+		"go.mod":        "module example.com/m\n",
+		"README":        "plain",
+		"README.md":     "# m",
+		"sub/README.md": "# sub",
+	})
+
+	if got := strings.Join(moduleResourceFiles(mf), ","); got != "go.mod,README.md" {
+		t.Errorf("moduleResourceFiles = %s", got)
+	}
+}
+
+func TestModuleResources_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "resources-test", Version: "0.0.1"},
+		&mcp.ServerOptions{HasResources: true})
+
+	opened := func(version string) moduleFiles {
+		return testModuleFiles(t, version, map[string]string{
+			"go.mod":    "module example.com/m\n",
+			"README.md": "# m " + version,
+		})
+	}
+
+	resources := &moduleResources{
+		server: server,
+		open: func(_ context.Context, _, version string) (moduleFiles, error) {
+			return opened(version), nil
+		},
+		published: make(map[string][]string),
+	}
+	server.AddReceivingMiddleware(resources.Middleware())
+
+	mcp.AddTool(server, &mcp.Tool{Name: "open"}, func(
+		ctx context.Context, _ *mcp.CallToolRequest, input resourcesTestInput,
+	) (*mcp.CallToolResult, any, error) {
+		publishModule(ctx, "example.com/m", input.Version, opened(input.Version))
+
+		return textResult("ok"), nil, nil
+	})
+
+	changed := make(chan struct{}, 2*maxPublishedModules+4)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+
+	t1, t2 := mcp.NewInMemoryTransports()
+
+	_, err := server.Connect(ctx, t1, nil)
+	mustf(t, err, "connect server")
+
+	session, err := client.Connect(ctx, t2, nil)
+	mustf(t, err, "connect client")
+
+	defer session.Close()
+
+	for range 2 {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "open", Arguments: map[string]any{"version": "v1.0.0"}})
+		mustf(t, err, "call open")
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no resource list change notification")
+	}
+
+	list, err := session.ListResources(ctx, nil)
+	mustf(t, err, "list resources")
+
+	if len(list.Resources) != 2 {
+		t.Fatalf("got %d resources, want 2 (published once)", len(list.Resources))
+	}
+
+	uri := moduleResourceURI("example.com/m", "v1.0.0", "README.md")
+
+	read, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	mustf(t, err, "read resource")
+
+	if c := read.Contents[0]; c.Text != "# m v1.0.0" || c.MIMEType != "text/markdown" {
+		t.Errorf("unexpected contents: %+v", c)
+	}
+
+	for i := range maxPublishedModules {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name: "open", Arguments: map[string]any{"version": fmt.Sprintf("v1.1.%d", i)},
+		})
+		mustf(t, err, "call open")
+	}
+
+	list, err = session.ListResources(ctx, nil)
+	mustf(t, err, "list resources")
+
+	for _, r := range list.Resources {
+		if strings.Contains(r.URI, "@v1.0.0/") {
+			t.Errorf("oldest module version was not evicted: %s", r.URI)
+		}
+	}
+
+	if len(list.Resources) != 2*maxPublishedModules {
+		t.Errorf("got %d resources, want %d", len(list.Resources), 2*maxPublishedModules)
+	}
+}
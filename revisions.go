@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+var (
+	revisionHashRe = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	revisionNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._/-]*$`)
+	versionLikeRe  = regexp.MustCompile(`^v\d`)
+)
+
+// isRevision reports whether version names a revision rather than a
+// version: a commit hash, or a branch or tag name that does not start
+// like a version does.
+func isRevision(version string) bool {
+	if revisionHashRe.MatchString(version) {
+		return true
+	}
+
+	return revisionNameRe.MatchString(version) && !versionLikeRe.MatchString(version) &&
+		!strings.Contains(version, "..") && !strings.HasSuffix(version, "/")
+}
+
+// resolveRevision resolves a branch, tag or commit hash to the version
+// the go command would use for it, usually a pseudo-version. The proxy
+// is asked first, as go get does. When it does not know the revision, or
+// cannot be asked because the name does not fit in a URL, the repository
+// is asked for the commit of the branch or tag and the proxy for that.
+func resolveRevision(ctx context.Context, proxy *ProxyClient, mod, rev string) (string, error) {
+	var proxyErr error
+
+	if _, err := module.EscapeVersion(rev); err == nil {
+		info, err := proxy.Info(ctx, mod, rev)
+		if err == nil {
+			noteResult(ctx, fmt.Sprintf("Revision %q of %s is %s.", rev, mod, info.Version))
+
+			return info.Version, nil
+		}
+
+		if !errors.Is(err, ErrModuleNotFound) || revisionHashRe.MatchString(rev) {
+			return "", fmt.Errorf("resolve revision %q: %w", rev, err)
+		}
+
+		proxyErr = err
+	}
+
+	vcsErr := func(err error) error {
+		if proxyErr != nil {
+			return fmt.Errorf("resolve revision %q: not on the proxy (%v), and %w", rev, proxyErr, err)
+		}
+
+		return fmt.Errorf("resolve revision %q: %w", rev, err)
+	}
+
+	if proxy.offline {
+		return "", vcsErr(ErrOffline)
+	}
+
+	root, url := repoRoot(ctx, proxy, mod)
+
+	if err := proxy.govcs.Check(root, "git"); err != nil {
+		return "", vcsErr(err)
+	}
+
+	hash, err := lookupRef(ctx, url, rev)
+	if err != nil {
+		return "", vcsErr(err)
+	}
+
+	info, err := proxy.Info(ctx, mod, hash)
+	if err != nil {
+		return "", fmt.Errorf("resolve revision %q, commit %s in %s: %w", rev, hash, url, err)
+	}
+
+	noteResult(ctx, fmt.Sprintf("Revision %q of %s is commit %s in %s, version %s.", rev, mod, hash[:12], url,
+		info.Version))
+
+	return info.Version, nil
+}
+
+// lookupRef returns the commit a branch or tag of a repository points
+// at, preferring a branch when both have the name.
+func lookupRef(ctx context.Context, url, ref string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	noteBackend(ctx, "git")
+
+	//nolint:gosec // The URL is passed after "--", so it cannot add options.
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--", url,
+		"refs/heads/"+ref, "refs/tags/"+ref, "refs/tags/"+ref+"^{}")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git ls-remote %s: %w: %s", url, err, strings.TrimSpace(stderr.String()))
+	}
+
+	refs := make(map[string]string)
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if hash, name, ok := strings.Cut(line, "\t"); ok {
+			refs[name] = hash
+		}
+	}
+
+	// An annotated tag's own object is not a commit; ^{} is the commit.
+	for _, name := range []string{"refs/heads/" + ref, "refs/tags/" + ref + "^{}", "refs/tags/" + ref} {
+		if hash, ok := refs[name]; ok {
+			return hash, nil
+		}
+	}
+
+	return "", fmt.Errorf("no branch or tag %s in %s", ref, url)
+}
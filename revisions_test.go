@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestIsRevision(t *testing.T) {
+	for version, want := range map[string]bool{
+		"master":       true,
+		"feature/auth": true,
+		"release-1.2":  true,
+		"4f3a2b1":      true,
+		"0123456789abcdef0123456789abcdef01234567": true,
+		"v1.2.x":   false,
+		"1.2.3":    false,
+		"feature/": false,
+		"a..b":     false,
+		"-rf":      false,
+		"<v2.0.0":  false,
+	} {
+		if got := isRevision(version); got != want {
+			t.Errorf("isRevision(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestResolveRevision(t *testing.T) {
+	repo := createTaggedRepo(t)
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+
+		out, err := cmd.CombinedOutput()
+		mustf(t, err, "git %v: %s", args, out)
+
+		return strings.TrimSpace(string(out))
+	}
+
+	git("branch", "feature/auth")
+	git("tag", "-a", "snapshot", "-m", "snapshot")
+
+	hash := git("rev-parse", "HEAD")
+	pseudo := "v0.0.0-20250101000000-" + hash[:12]
+
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mono/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.0.0","Origin":{"VCS":"git","URL":"` + repo + `"}}`))
+		case "/example.com/mono/@v/master.info", "/example.com/mono/@v/" + hash + ".info":
+			_, _ = w.Write([]byte(`{"Version":"` + pseudo + `"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	notes := &callNotes{}
+	ctx := context.WithValue(context.Background(), notesKey{}, notes)
+
+	version, err := resolveVersion(ctx, proxy, "example.com/mono", "master")
+	note := strings.Join(notes.notes, "\n")
+	if err != nil || version != pseudo || !strings.Contains(note, `"master" of example.com/mono is`) {
+		t.Errorf("master = %q, %v; notes %q", version, err, notes.notes)
+	}
+
+	// The proxy cannot be asked for a branch with a slash; git can.
+	version, err = resolveVersion(ctx, proxy, "example.com/mono", "feature/auth")
+	if err != nil || version != pseudo {
+		t.Errorf("feature/auth = %q, %v", version, err)
+	}
+
+	// An annotated tag resolves to its commit, not the tag object.
+	version, err = resolveVersion(ctx, proxy, "example.com/mono", "snapshot")
+	if err != nil || version != pseudo {
+		t.Errorf("snapshot = %q, %v", version, err)
+	}
+
+	_, err = resolveVersion(ctx, proxy, "example.com/mono", "missing")
+	if err == nil ||
+		!strings.Contains(err.Error(), "not on the proxy") || !strings.Contains(err.Error(), "no branch or tag missing") {
+		t.Errorf("missing branch: %v", err)
+	}
+
+	// Unknown commits are not looked up in the repository.
+	_, err = resolveVersion(ctx, proxy, "example.com/mono", "abcdef1")
+	if err == nil || strings.Contains(err.Error(), "git") {
+		t.Errorf("unknown commit: %v", err)
+	}
+}
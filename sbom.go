@@ -0,0 +1,310 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+// SBOM formats of gomod_sbom.
+const (
+	sbomCycloneDX = "cyclonedx"
+	sbomSPDX      = "spdx"
+)
+
+// sbomTool names this server as the tool that wrote an SBOM.
+const sbomTool = "claude-gomod"
+
+// sbomModule is a module version in an SBOM, with what the SBOM says about
+// it.
+type sbomModule struct {
+	module.Version
+	license  string // SPDX expression, or "" when unknown
+	requires []module.Version
+}
+
+// purl returns the package URL of the module version, as the purl
+// specification writes Go modules.
+func (m sbomModule) purl() string {
+	purl := "pkg:golang/" + m.Path
+	if m.Version.Version != "" {
+		purl += "@" + m.Version.Version
+	}
+
+	return purl
+}
+
+// sbomModules returns the main module and the build list of the graph,
+// with the selected requirements of each. Unless skipLicenses is set, the
+// license of each module version is detected from its files.
+func sbomModules(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache, modCache *ModCache,
+	proj *project, g *moduleGraph, skipLicenses bool,
+) []sbomModule {
+	versions := append([]module.Version{g.main}, g.buildList()...)
+	mods := make([]sbomModule, len(versions))
+
+	parallelEach(versions, func(i int, v module.Version) {
+		mods[i] = sbomModule{Version: v, requires: sortedVersions(g.resolvedRequirements(v))}
+
+		if skipLicenses {
+			return
+		}
+
+		var (
+			mf  moduleFiles
+			err error
+		)
+
+		switch {
+		case v.Version != "":
+			mf, err = openModule(ctx, proxy, cache, modCache, v.Path, v.Version)
+		case proj.dir != "":
+			mf = dirFiles{root: proj.dir}
+		default:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+
+		if _, root, err := findLicenses(mf); err == nil {
+			mods[i].license = moduleLicense(root)
+		}
+	})
+
+	return mods
+}
+
+// cycloneDXBOM is a CycloneDX 1.5 document, with the fields gomod_sbom
+// fills in.
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type     string             `json:"type"`
+	BOMRef   string             `json:"bom-ref,omitempty"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+	Licenses []cycloneDXLicense `json:"licenses,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cycloneDX(mods []sbomModule, serial string, now time.Time) *cycloneDXBOM {
+	component := func(typ string, m sbomModule) cycloneDXComponent {
+		c := cycloneDXComponent{Type: typ, BOMRef: m.purl(), Name: m.Path, Version: m.Version.Version, PURL: m.purl()}
+		if m.license != "" {
+			c.Licenses = []cycloneDXLicense{{Expression: m.license}}
+		}
+
+		return c
+	}
+
+	bom := &cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools:     cycloneDXTools{Components: []cycloneDXComponent{{Type: "application", Name: sbomTool}}},
+			Component: component("application", mods[0]),
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	for i, m := range mods {
+		if i > 0 {
+			bom.Components = append(bom.Components, component("library", m))
+		}
+
+		dep := cycloneDXDependency{Ref: m.purl(), DependsOn: []string{}}
+		for _, r := range m.requires {
+			dep.DependsOn = append(dep.DependsOn, sbomModule{Version: r}.purl())
+		}
+
+		bom.Dependencies = append(bom.Dependencies, dep)
+	}
+
+	return bom
+}
+
+// spdxDocument is an SPDX 2.3 document, with the fields gomod_sbom fills
+// in.
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdx(mods []sbomModule, serial string, now time.Time) *spdxDocument {
+	ids := make(map[module.Version]string, len(mods))
+	for i, m := range mods {
+		ids[m.Version] = fmt.Sprintf("SPDXRef-Package-%d", i)
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              mods[0].String(),
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + sbomTool + "/" + serial,
+		CreationInfo: spdxCreationInfo{
+			Created:  now.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + sbomTool},
+		},
+		Relationships: []spdxRelationship{{"SPDXRef-DOCUMENT", "DESCRIBES", ids[mods[0].Version]}},
+	}
+
+	for _, m := range mods {
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:             m.Path,
+			SPDXID:           ids[m.Version],
+			VersionInfo:      m.Version.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  cmp.Or(m.license, "NOASSERTION"),
+			ExternalRefs:     []spdxExternalRef{{"PACKAGE-MANAGER", "purl", m.purl()}},
+		})
+
+		for _, r := range m.requires {
+			if id, ok := ids[r]; ok {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{ids[m.Version], "DEPENDS_ON", id})
+			}
+		}
+	}
+
+	return doc
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type sbomInput struct {
+	Dir          string `json:"dir,omitempty" jsonschema:"Project directory with go.mod (default: bound project)"`
+	GoMod        string `json:"go_mod,omitempty" jsonschema:"go.mod content, instead of dir"`
+	Root         string `json:"root,omitempty" jsonschema:"Describe this module@version, instead of a project"`
+	Format       string `json:"format,omitempty" jsonschema:"cyclonedx (default) or spdx"`
+	SkipLicenses bool   `json:"skip_licenses,omitempty" jsonschema:"Leave licenses out and skip downloading every module"`
+}
+
+func handleSBOM(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache, modCache *ModCache,
+	binding *projectBinding, graphs *moduleGraphs, input sbomInput,
+) (*mcp.CallToolResult, any, error) {
+	format := cmp.Or(strings.ToLower(input.Format), sbomCycloneDX)
+	if !slices.Contains([]string{sbomCycloneDX, sbomSPDX}, format) {
+		return errorResult(fmt.Sprintf("Unknown format %q; use %s or %s.", input.Format, sbomCycloneDX, sbomSPDX)),
+			nil, nil
+	}
+
+	graphInput := projectGraphInput{Dir: input.Dir, GoMod: input.GoMod, Root: input.Root}
+
+	proj, err := graphProject(ctx, proxy, binding, graphInput)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	g, _, err := graphs.get(ctx, proxy, proj)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	if g.truncated {
+		return errorResult(fmt.Sprintf("The module graph stopped at %d module versions, so the SBOM would be "+
+			"incomplete.", maxGraphModules)), nil, nil
+	}
+
+	mods := sbomModules(ctx, proxy, cache, modCache, proj, g, input.SkipLicenses)
+	if mods[0].Path == "" {
+		mods[0].Path = "project"
+	}
+
+	var bom any = cycloneDX(mods, newUUID(), time.Now())
+	if format == sbomSPDX {
+		bom = spdx(mods, newUUID(), time.Now())
+	}
+
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode SBOM: %w", err)
+	}
+
+	return textResult(string(data) + "\n"), nil, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+func testSBOMModules() []sbomModule {
+	a := module.Version{Path: "example.com/a", Version: "v1.0.0"}
+	b := module.Version{Path: "example.com/b", Version: "v1.2.0"}
+
+	return []sbomModule{
+		{Version: module.Version{Path: "example.com/app"}, license: "MIT", requires: []module.Version{a}},
+		{Version: a, license: "Apache-2.0 OR MIT", requires: []module.Version{b}},
+		{Version: b},
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	bom := cycloneDX(testSBOMModules(), "0-1", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	if bom.SerialNumber != "urn:uuid:0-1" || bom.Metadata.Timestamp != "2026-01-02T03:04:05Z" {
+		t.Errorf("serial %s, timestamp %s", bom.SerialNumber, bom.Metadata.Timestamp)
+	}
+
+	if c := bom.Metadata.Component; c.PURL != "pkg:golang/example.com/app" || c.Licenses[0].Expression != "MIT" {
+		t.Errorf("main component = %+v", c)
+	}
+
+	if len(bom.Components) != 2 || bom.Components[0].PURL != "pkg:golang/example.com/a@v1.0.0" ||
+		bom.Components[1].Licenses != nil {
+		t.Errorf("components = %+v", bom.Components)
+	}
+
+	if d := bom.Dependencies[1]; d.Ref != "pkg:golang/example.com/a@v1.0.0" ||
+		strings.Join(d.DependsOn, ",") != "pkg:golang/example.com/b@v1.2.0" {
+		t.Errorf("dependency = %+v", d)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	doc := spdx(testSBOMModules(), "0-1", time.Now())
+
+	if len(doc.Packages) != 3 || doc.Packages[2].LicenseDeclared != "NOASSERTION" ||
+		doc.Packages[1].ExternalRefs[0].ReferenceLocator != "pkg:golang/example.com/a@v1.0.0" {
+		t.Errorf("packages = %+v", doc.Packages)
+	}
+
+	var rels []string
+	for _, r := range doc.Relationships {
+		rels = append(rels, r.SPDXElementID+" "+r.RelationshipType+" "+r.RelatedSPDXElement)
+	}
+
+	want := "SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-0, SPDXRef-Package-0 DEPENDS_ON SPDXRef-Package-1, " +
+		"SPDXRef-Package-1 DEPENDS_ON SPDXRef-Package-2"
+	if got := strings.Join(rels, ", "); got != want {
+		t.Errorf("relationships = %s", got)
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	id := newUUID()
+	if len(id) != 36 || id[14] != '4' || id == newUUID() {
+		t.Errorf("newUUID() = %s", id)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollJitter = 0.1
+	schedulerTick     = time.Second
+)
+
+// scheduledJob is a periodic task run by the Scheduler.
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+
+	nextRun  time.Time
+	lastRun  time.Time
+	lastErr  error
+	runs     int
+	duration time.Duration
+}
+
+// Scheduler runs all periodic background work (watch polling, metadata
+// cache expiry) from a single loop, so that intervals, jitter and
+// pausing are configured in one place.
+type Scheduler struct {
+	jitter float64 // fraction of the interval, e.g. 0.1 for ±10%
+
+	mu     sync.Mutex
+	jobs   map[string]*scheduledJob
+	paused bool
+}
+
+// NewScheduler creates a Scheduler that randomises each job's interval by
+// up to ±jitter (a fraction of the interval).
+func NewScheduler(jitter float64) *Scheduler {
+	return &Scheduler{
+		jitter: jitter,
+		jobs:   make(map[string]*scheduledJob),
+	}
+}
+
+// Every registers run to be called every interval, replacing any job with
+// the same name. The first run happens one interval from now.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[name] = &scheduledJob{
+		name:     name,
+		interval: interval,
+		run:      run,
+		nextRun:  time.Now().Add(s.jittered(interval)),
+	}
+}
+
+// SetPaused pauses or resumes all jobs. Jobs that fall due while paused
+// run once the scheduler is resumed.
+func (s *Scheduler) SetPaused(paused bool) {
+	s.mu.Lock()
+	s.paused = paused
+	s.mu.Unlock()
+}
+
+// Paused reports whether the scheduler is paused.
+func (s *Scheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.paused
+}
+
+// Run executes due jobs until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.runDue(ctx, now)
+		}
+	}
+}
+
+// runDue runs every job whose next run time is at or before now. Jobs run
+// sequentially, outside the lock.
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+
+	if s.paused {
+		s.mu.Unlock()
+
+		return
+	}
+
+	var due []*scheduledJob
+
+	for _, job := range s.jobs {
+		if !job.nextRun.After(now) {
+			due = append(due, job)
+		}
+	}
+
+	s.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].name < due[j].name })
+
+	for _, job := range due {
+		start := time.Now()
+		err := job.run(ctx)
+
+		if err != nil {
+			log.Printf("warning: scheduled job %s failed: %v", job.name, err)
+		}
+
+		s.mu.Lock()
+		job.lastRun = start
+		job.lastErr = err
+		job.runs++
+		job.duration = time.Since(start)
+		job.nextRun = start.Add(s.jittered(job.interval))
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) jittered(interval time.Duration) time.Duration {
+	if s.jitter <= 0 {
+		return interval
+	}
+
+	//nolint:gosec // Jitter does not need a secure random source.
+	offset := (rand.Float64()*2 - 1) * s.jitter * float64(interval)
+
+	return interval + time.Duration(offset)
+}
+
+// jobStatus is a snapshot of a scheduled job's state.
+type jobStatus struct {
+	name     string
+	interval time.Duration
+	nextRun  time.Time
+	lastRun  time.Time
+	lastErr  error
+	runs     int
+	duration time.Duration
+}
+
+// Status returns the state of every job, sorted by name.
+func (s *Scheduler) Status() []jobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]jobStatus, 0, len(s.jobs))
+
+	for _, job := range s.jobs {
+		result = append(result, jobStatus{
+			name:     job.name,
+			interval: job.interval,
+			nextRun:  job.nextRun,
+			lastRun:  job.lastRun,
+			lastErr:  job.lastErr,
+			runs:     job.runs,
+			duration: job.duration,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+
+	return result
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRunDue(t *testing.T) {
+	s := NewScheduler(0)
+
+	var runs int
+
+	s.Every("job", time.Minute, func(context.Context) error {
+		runs++
+
+		return nil
+	})
+
+	ctx := context.Background()
+
+	s.runDue(ctx, time.Now())
+
+	if runs != 0 {
+		t.Fatalf("job ran before its interval elapsed")
+	}
+
+	s.runDue(ctx, time.Now().Add(time.Minute))
+
+	if runs != 1 {
+		t.Fatalf("runs = %d, want 1", runs)
+	}
+
+	status := s.Status()
+	if len(status) != 1 || status[0].runs != 1 || status[0].lastRun.IsZero() {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if !status[0].nextRun.After(status[0].lastRun) {
+		t.Errorf("next run %v not after last run %v", status[0].nextRun, status[0].lastRun)
+	}
+}
+
+func TestSchedulerPaused(t *testing.T) {
+	s := NewScheduler(0)
+
+	var runs int
+
+	s.Every("job", time.Minute, func(context.Context) error {
+		runs++
+
+		return errors.New("boom")
+	})
+
+	s.SetPaused(true)
+	s.runDue(context.Background(), time.Now().Add(time.Hour))
+
+	if runs != 0 {
+		t.Fatalf("job ran while paused")
+	}
+
+	s.SetPaused(false)
+	s.runDue(context.Background(), time.Now().Add(time.Hour))
+
+	if runs != 1 {
+		t.Fatalf("runs = %d after resume, want 1", runs)
+	}
+
+	if err := s.Status()[0].lastErr; err == nil || err.Error() != "boom" {
+		t.Errorf("lastErr = %v, want boom", err)
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	s := NewScheduler(0.1)
+
+	for range 100 {
+		d := s.jittered(time.Minute)
+		if d < 54*time.Second || d > 66*time.Second {
+			t.Fatalf("jittered interval %s outside ±10%%", d)
+		}
+	}
+}
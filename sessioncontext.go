@@ -0,0 +1,492 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// contextArgs are the tool arguments that default to the session context,
+// in the order they are reported.
+var contextArgs = []string{"module", "version", "package"}
+
+// moduleContext is the default module, version and package of a session.
+type moduleContext struct {
+	Module  string
+	Version string
+	Package string
+}
+
+func (c moduleContext) args() map[string]string {
+	return map[string]string{"module": c.Module, "version": c.Version, "package": c.Package}
+}
+
+func (c moduleContext) arg(name string) string {
+	switch name {
+	case "module":
+		return c.Module
+	case "version":
+		return c.Version
+	case "package":
+		return c.Package
+	}
+
+	return ""
+}
+
+// fileRef identifies a file of a module version.
+type fileRef struct {
+	module  string
+	version string
+	path    string
+}
+
+// sessionState is what the server remembers about one session.
+type sessionState struct {
+	context  moduleContext
+	handles  []fileRef // handle n refers to handles[n-1]
+	handleOf map[fileRef]int
+	project  *projectBinding
+}
+
+// sessionContexts keeps the context set by gomod_set_context, the file
+// handles and the project bound by gomod_bind_project of each session,
+// and fills the first two into tool calls that omit those arguments.
+type sessionContexts struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*sessionState
+	schemas  map[string]*jsonschema.Schema // tool input schemas by name
+}
+
+func newSessionContexts() *sessionContexts {
+	return &sessionContexts{
+		sessions: make(map[*mcp.ServerSession]*sessionState),
+		schemas:  make(map[string]*jsonschema.Schema),
+	}
+}
+
+// state returns the state of a session, or nil if it has none. The
+// caller holds s.mu.
+func (s *sessionContexts) state(session *mcp.ServerSession) *sessionState {
+	return s.sessions[session]
+}
+
+// create returns the state of a session, creating it if needed. State is
+// only created when something is stored, and it is dropped when the
+// session's connection closes, so sessions that come and go under
+// -http-addr are not remembered forever. The caller holds s.mu.
+func (s *sessionContexts) create(session *mcp.ServerSession) *sessionState {
+	st := s.sessions[session]
+	if st == nil {
+		st = &sessionState{handleOf: make(map[fileRef]int), project: &projectBinding{}}
+		s.sessions[session] = st
+
+		go s.forgetOnClose(session)
+	}
+
+	return st
+}
+
+// forgetOnClose drops the state of a session once its connection closes.
+func (s *sessionContexts) forgetOnClose(session *mcp.ServerSession) {
+	_ = session.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, session)
+}
+
+// Get returns the context of a session; ok is false when none is set.
+func (s *sessionContexts) Get(session *mcp.ServerSession) (moduleContext, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.state(session)
+	if st == nil {
+		return moduleContext{}, false
+	}
+
+	return st.context, st.context != (moduleContext{})
+}
+
+// Set replaces the context of a session, or clears it when c is empty.
+func (s *sessionContexts) Set(session *mcp.ServerSession, c moduleContext) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st := s.state(session); c == (moduleContext{}) {
+		if st != nil {
+			st.context = c
+		}
+
+		return
+	}
+
+	s.create(session).context = c
+}
+
+// Handle returns the session's handle for a file, assigning the next
+// number the first time the file is seen.
+func (s *sessionContexts) Handle(session *mcp.ServerSession, ref fileRef) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.create(session)
+
+	if n, ok := st.handleOf[ref]; ok {
+		return n
+	}
+
+	st.handles = append(st.handles, ref)
+	st.handleOf[ref] = len(st.handles)
+
+	return len(st.handles)
+}
+
+// lookupHandle returns the file a session's handle refers to.
+func (s *sessionContexts) lookupHandle(session *mcp.ServerSession, n int) (fileRef, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.state(session)
+	if st == nil || n < 1 || n > len(st.handles) {
+		return fileRef{}, false
+	}
+
+	return st.handles[n-1], true
+}
+
+// Project returns the project binding of a session, or nil, which has no
+// project, if the session has stored nothing.
+func (s *sessionContexts) Project(session *mcp.ServerSession) *projectBinding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st := s.state(session); st != nil {
+		return st.project
+	}
+
+	return nil
+}
+
+// BindProject returns the project binding of a session for
+// gomod_bind_project to change, creating it if needed.
+func (s *sessionContexts) BindProject(session *mcp.ServerSession) *projectBinding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.create(session).project
+}
+
+// Middleware fills omitted context arguments into tool calls, expands
+// file handles and lists the arguments they supply as optional. Calls
+// that still lack a required argument fail schema validation as before.
+func (s *sessionContexts) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch method {
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					s.relaxTools(list)
+				}
+
+				return result, err
+			case "tools/call":
+				if call, ok := req.(*mcp.CallToolRequest); ok {
+					problem, err := s.fill(ctx, next, call)
+					if err != nil {
+						return nil, err
+					}
+
+					if problem != "" {
+						return errorResult(problem), nil
+					}
+				}
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// fill adds the arguments of a file handle and the session's context
+// values for the arguments a tool accepts but the call leaves out, in
+// that order of preference. A problem with the call is returned as text
+// for an error result.
+func (s *sessionContexts) fill(
+	ctx context.Context, next mcp.MethodHandler, call *mcp.CallToolRequest,
+) (string, error) {
+	if call.Params == nil || isContextTool(call.Params.Name) {
+		return "", nil
+	}
+
+	c, hasContext := s.Get(call.Session)
+
+	var (
+		problem   string
+		schemaErr error
+	)
+
+	err := rewriteArguments(call, func(args map[string]any) bool {
+		handle, hasHandle := args["handle"]
+		if !hasContext && !hasHandle {
+			return false
+		}
+
+		schema, err := s.inputSchema(ctx, next, call)
+		if err != nil || schema == nil {
+			schemaErr = err
+
+			return false
+		}
+
+		changed := false
+
+		if hasHandle && schema.Properties["handle"] != nil {
+			n, _ := handle.(float64)
+
+			ref, ok := s.lookupHandle(call.Session, int(n))
+			if !ok {
+				problem = fmt.Sprintf("Unknown file handle %v; list files with handles: true to get handles.", handle)
+
+				return false
+			}
+
+			changed = fillArgs(args, schema, map[string]string{
+				"module": ref.module, handleVersionArg(schema): ref.version, "path": ref.path,
+			})
+		}
+
+		if hasContext {
+			changed = fillArgs(args, schema, c.args()) || changed
+		}
+
+		return changed
+	})
+
+	if schemaErr != nil {
+		return "", schemaErr
+	}
+
+	return problem, err
+}
+
+// fillArgs sets the values for the empty arguments that a tool accepts
+// and reports whether it set any. The version, package and path belong
+// to the module in values, so they are left alone when the call names a
+// different module.
+func fillArgs(args map[string]any, schema *jsonschema.Schema, values map[string]string) bool {
+	changed := false
+
+	if mod := values["module"]; mod != "" && schema.Properties["module"] != nil {
+		given, _ := args["module"].(string)
+
+		switch {
+		case isEmptyArg(args["module"]):
+			args["module"] = mod
+			changed = true
+		case given != mod:
+			return false
+		}
+	}
+
+	for name, v := range values {
+		if name == "module" {
+			continue
+		}
+
+		if v != "" && schema.Properties[name] != nil && isEmptyArg(args[name]) {
+			args[name] = v
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// handleVersionArg is the argument a handle's version goes to: version,
+// or from for tools that compare two versions.
+func handleVersionArg(schema *jsonschema.Schema) string {
+	if schema.Properties["version"] == nil && schema.Properties["from"] != nil {
+		return "from"
+	}
+
+	return "version"
+}
+
+// rewriteArguments lets change edit the arguments of a tool call and
+// re-encodes them if it reports a change. Arguments that are not a JSON
+// object are left to the tool's own validation.
+func rewriteArguments(call *mcp.CallToolRequest, change func(args map[string]any) bool) error {
+	if call.Params == nil {
+		return nil
+	}
+
+	args := make(map[string]any)
+
+	if len(call.Params.Arguments) > 0 && json.Unmarshal(call.Params.Arguments, &args) != nil {
+		return nil
+	}
+
+	if !change(args) {
+		return nil
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("encode arguments: %w", err)
+	}
+
+	call.Params.Arguments = data
+
+	return nil
+}
+
+func isEmptyArg(v any) bool {
+	s, ok := v.(string)
+
+	return v == nil || (ok && s == "")
+}
+
+// inputSchema returns the input schema of the called tool, listing the
+// server's tools the first time it is needed.
+func (s *sessionContexts) inputSchema(
+	ctx context.Context, next mcp.MethodHandler, call *mcp.CallToolRequest,
+) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	schema, ok := s.schemas[call.Params.Name]
+	s.mu.Unlock()
+
+	if ok {
+		return schema, nil
+	}
+
+	result, err := next(ctx, "tools/list", &mcp.ListToolsRequest{Session: call.Session, Params: &mcp.ListToolsParams{}})
+	if err != nil {
+		return nil, fmt.Errorf("list tools: %w", err)
+	}
+
+	if list, ok := result.(*mcp.ListToolsResult); ok {
+		s.relaxTools(list)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.schemas[call.Params.Name], nil
+}
+
+// relaxTools records the listed input schemas and replaces each tool with
+// a copy whose schema no longer requires the arguments the context or a
+// file handle can supply.
+func (s *sessionContexts) relaxTools(list *mcp.ListToolsResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, t := range list.Tools {
+		schema, ok := t.InputSchema.(*jsonschema.Schema)
+		if !ok {
+			continue
+		}
+
+		s.schemas[t.Name] = schema
+
+		if isContextTool(t.Name) {
+			continue
+		}
+
+		optional := contextArgs
+		if schema.Properties["handle"] != nil {
+			optional = append([]string{"path", "from"}, contextArgs...)
+		}
+
+		required := slices.DeleteFunc(slices.Clone(schema.Required), func(name string) bool {
+			return slices.Contains(optional, name)
+		})
+
+		if len(required) == len(schema.Required) {
+			continue
+		}
+
+		relaxed := *schema
+		relaxed.Required = required
+
+		tool := *t
+		tool.InputSchema = &relaxed
+		list.Tools[i] = &tool
+	}
+}
+
+func isContextTool(name string) bool {
+	return name == "gomod_set_context" || name == "gomod_get_context"
+}
+
+type setContextInput struct {
+	Module  string `json:"module,omitempty" jsonschema:"Default Go module path; omit to clear the context"`
+	Version string `json:"version,omitempty" jsonschema:"Default version; 'latest' is resolved once, now"`
+	Package string `json:"package,omitempty" jsonschema:"Default package (import path or directory) for tools taking one"`
+}
+
+type getContextInput struct{}
+
+func handleSetContext(
+	ctx context.Context, proxy *ProxyClient, contexts *sessionContexts,
+	session *mcp.ServerSession, input setContextInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.Module == "" {
+		if input.Version != "" || input.Package != "" {
+			return errorResult("A version or package needs a module; pass module too."), nil, nil
+		}
+
+		contexts.Set(session, moduleContext{})
+
+		return textResult("Context cleared; tools need module and version again."), nil, nil
+	}
+
+	c := moduleContext{Module: input.Module, Package: input.Package}
+
+	if input.Version != "" {
+		version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+		if err != nil {
+			return errorResult(err.Error()), nil, nil
+		}
+
+		c.Version = version
+	}
+
+	contexts.Set(session, c)
+
+	return textResult("Context set:\n" + formatModuleContext(c)), nil, nil
+}
+
+func handleGetContext(contexts *sessionContexts, session *mcp.ServerSession) (*mcp.CallToolResult, any, error) {
+	c, ok := contexts.Get(session)
+	if !ok {
+		return textResult("No context is set; use gomod_set_context to set a default module and version."), nil, nil
+	}
+
+	return textResult(formatModuleContext(c)), nil, nil
+}
+
+func formatModuleContext(c moduleContext) string {
+	var sb strings.Builder
+
+	for _, name := range contextArgs {
+		v := c.arg(name)
+		if v == "" {
+			v = "(not set)"
+		}
+
+		fmt.Fprintf(&sb, "  %-8s %s\n", name+":", v)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSessionContexts_RelaxTools(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type:     "object",
+		Required: []string{"module", "version", "path"},
+		Properties: map[string]*jsonschema.Schema{
+			"module": {Type: "string"}, "version": {Type: "string"}, "path": {Type: "string"},
+		},
+	}
+	tool := &mcp.Tool{Name: "gomod_read_file", InputSchema: schema}
+	setTool := &mcp.Tool{Name: "gomod_set_context", InputSchema: &jsonschema.Schema{
+		Type: "object", Required: []string{"module"},
+	}}
+	list := &mcp.ListToolsResult{Tools: []*mcp.Tool{tool, setTool}}
+
+	contexts := newSessionContexts()
+	contexts.relaxTools(list)
+
+	relaxed, _ := list.Tools[0].InputSchema.(*jsonschema.Schema)
+	if len(relaxed.Required) != 1 || relaxed.Required[0] != "path" {
+		t.Errorf("relaxed required = %v, want [path]", relaxed.Required)
+	}
+
+	if len(schema.Required) != 3 || tool.InputSchema != schema {
+		t.Errorf("registered tool was modified: required = %v", schema.Required)
+	}
+
+	if list.Tools[1] != setTool {
+		t.Error("gomod_set_context should be listed unchanged")
+	}
+
+	if contexts.schemas["gomod_read_file"] != schema {
+		t.Error("the original schema should be recorded for filling arguments")
+	}
+}
+
+func TestSessionContexts_SetClears(t *testing.T) {
+	contexts := newSessionContexts()
+	session, _ := connectTestSession(t)
+
+	contexts.Set(session, moduleContext{Module: "example.com/m"})
+
+	if c, ok := contexts.Get(session); !ok || c.Module != "example.com/m" {
+		t.Fatalf("Get = %+v, %v", c, ok)
+	}
+
+	contexts.Set(session, moduleContext{})
+
+	if _, ok := contexts.Get(session); ok {
+		t.Error("an empty context should clear the session's entry")
+	}
+}
+
+func TestSessionContexts_Handles(t *testing.T) {
+	contexts := newSessionContexts()
+	a, _ := connectTestSession(t)
+	b, _ := connectTestSession(t)
+	ref := fileRef{"example.com/m", "v1.0.0", "m.go"}
+
+	if n := contexts.Handle(a, ref); n != 1 {
+		t.Errorf("first handle = %d, want 1", n)
+	}
+
+	if n := contexts.Handle(a, fileRef{"example.com/m", "v1.0.0", "go.mod"}); n != 2 {
+		t.Errorf("second handle = %d, want 2", n)
+	}
+
+	if n := contexts.Handle(a, ref); n != 1 {
+		t.Errorf("repeated file got handle %d, want 1", n)
+	}
+
+	if got, ok := contexts.lookupHandle(a, 1); !ok || got != ref {
+		t.Errorf("lookup 1 = %+v, %v", got, ok)
+	}
+
+	if _, ok := contexts.lookupHandle(b, 1); ok {
+		t.Error("handles should be per session")
+	}
+}
+
+func TestSessionContexts_ForgetsClosedSessions(t *testing.T) {
+	contexts := newSessionContexts()
+	session, client := connectTestSession(t)
+
+	if _, ok := contexts.Get(session); ok || len(contexts.sessions) != 0 {
+		t.Fatal("Get should not create state")
+	}
+
+	contexts.Set(session, moduleContext{Module: "example.com/m"})
+	contexts.Handle(session, fileRef{"example.com/m", "v1.0.0", "m.go"})
+
+	mustf(t, client.Close(), "close client session")
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		contexts.mu.Lock()
+		n := len(contexts.sessions)
+		contexts.mu.Unlock()
+
+		if n == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the closed session's state was kept")
+		}
+	}
+}
+
+// connectTestSession connects a client to an empty server in memory and
+// returns both ends; the client is closed when the test ends.
+func connectTestSession(t *testing.T) (*mcp.ServerSession, *mcp.ClientSession) {
+	t.Helper()
+
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "0.0.1"}, nil)
+	t1, t2 := mcp.NewInMemoryTransports()
+
+	session, err := server.Connect(ctx, t1, nil)
+	mustf(t, err, "connect server")
+
+	client, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil).Connect(ctx, t2, nil)
+	mustf(t, err, "connect client")
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	return session, client
+}
+
+func TestFillArgs_OtherModule(t *testing.T) {
+	schema := &jsonschema.Schema{Properties: map[string]*jsonschema.Schema{
+		"module": {Type: "string"}, "version": {Type: "string"}, "package": {Type: "string"},
+	}}
+	values := moduleContext{Module: "github.com/foo/bar", Version: "v1.2.3", Package: "baz"}.args()
+
+	args := map[string]any{"module": "golang.org/x/text"}
+	if fillArgs(args, schema, values) || len(args) != 1 {
+		t.Errorf("another module's call got the context's arguments: %v", args)
+	}
+
+	args = map[string]any{"module": "github.com/foo/bar", "version": "v1.0.0"}
+	if !fillArgs(args, schema, values) || args["version"] != "v1.0.0" || args["package"] != "baz" {
+		t.Errorf("the context module's call = %v", args)
+	}
+
+	args = map[string]any{}
+	if !fillArgs(args, schema, values) || args["module"] != "github.com/foo/bar" || args["version"] != "v1.2.3" {
+		t.Errorf("an empty call = %v", args)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sizeMetaKey is the key of the size estimate in a tool result's _meta.
+const sizeMetaKey = "size"
+
+// resultSize is the size estimate attached to every tool result: what was
+// returned and, when a tool cut or filtered its content, the whole of it.
+// Tokens are estimated at four bytes each.
+type resultSize struct {
+	Bytes      int  `json:"bytes"`
+	Tokens     int  `json:"tokens"`
+	FullBytes  int  `json:"full_bytes,omitempty"`
+	FullTokens int  `json:"full_tokens,omitempty"`
+	Truncated  bool `json:"truncated,omitempty"`
+}
+
+// estimateTokens approximates the number of tokens n bytes of code or
+// text take.
+func estimateTokens(n int) int {
+	return (n + 3) / 4
+}
+
+type sizeKey struct{}
+
+// callSize collects what handlers report about the content behind a tool
+// call's result. Handlers may fetch concurrently, so it is locked.
+type callSize struct {
+	mu        sync.Mutex
+	full      int
+	truncated bool
+}
+
+// noteFullSize records that the result of the tool call running in ctx
+// was filtered from n bytes of content, such as a whole file. Sizes noted
+// by one call add up. It does nothing outside a tool call.
+func noteFullSize(ctx context.Context, n int) {
+	if s, ok := ctx.Value(sizeKey{}).(*callSize); ok {
+		s.mu.Lock()
+		s.full += n
+		s.mu.Unlock()
+	}
+}
+
+// noteTruncated records that the result of the tool call running in ctx
+// stopped at a limit, so that more can be asked for. It does nothing
+// outside a tool call.
+func noteTruncated(ctx context.Context) {
+	if s, ok := ctx.Value(sizeKey{}).(*callSize); ok {
+		s.mu.Lock()
+		s.truncated = true
+		s.mu.Unlock()
+	}
+}
+
+// sizeMiddleware adds the size estimate to tool results under
+// _meta.size, measured as returned to the client.
+func sizeMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			s := &callSize{}
+
+			result, err := next(context.WithValue(ctx, sizeKey{}, s), method, req)
+
+			res, ok := result.(*mcp.CallToolResult)
+			if !ok || res == nil || err != nil || res.IsError {
+				return result, err
+			}
+
+			n := resultBytes(res)
+			size := resultSize{Bytes: n, Tokens: estimateTokens(n), Truncated: s.truncated}
+
+			if s.full > n {
+				size.FullBytes, size.FullTokens = s.full, estimateTokens(s.full)
+			}
+
+			if res.Meta == nil {
+				res.Meta = mcp.Meta{}
+			}
+
+			res.Meta[sizeMetaKey] = size
+
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	for n, want := range map[int]int{0: 0, 1: 1, 4: 1, 5: 2, 4000: 1000} {
+		if got := estimateTokens(n); got != want {
+			t.Errorf("estimateTokens(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestNoteFullSize(t *testing.T) {
+	// Outside a tool call, sizes are dropped.
+	noteFullSize(context.Background(), 10)
+	noteTruncated(context.Background())
+
+	s := &callSize{}
+	ctx := context.WithValue(context.Background(), sizeKey{}, s)
+
+	noteFullSize(ctx, 100)
+	noteFullSize(ctx, 20)
+	noteTruncated(ctx)
+
+	if s.full != 120 || !s.truncated {
+		t.Errorf("size = %d, truncated %v; want 120, true", s.full, s.truncated)
+	}
+}
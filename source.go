@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// moduleFiles gives read access to the files of a single module version,
+// whether they come from the local module cache or a downloaded zip.
+type moduleFiles interface {
+	// ListFiles returns file paths relative to the module root that
+	// start with prefix. An empty prefix matches every file.
+	ListFiles(prefix string) ([]string, error)
+
+	// ReadFile returns the text content of a file. Binary files are
+	// rejected with an error.
+	ReadFile(path string) (string, error)
+
+	// Open returns a reader for the raw content of a file, text or not.
+	Open(path string) (io.ReadCloser, error)
+
+	// Size returns the uncompressed size of a file in bytes. For a zip
+	// it comes from the central directory, so nothing is read.
+	Size(path string) (int64, error)
+}
+
+// modCacheFiles adapts a module version in ModCache to moduleFiles.
+type modCacheFiles struct {
+	modCache *ModCache
+	module   string
+	version  string
+}
+
+func (f *modCacheFiles) ListFiles(prefix string) ([]string, error) {
+	return f.modCache.ListFiles(f.module, f.version, prefix)
+}
+
+func (f *modCacheFiles) ReadFile(path string) (string, error) {
+	return f.modCache.ReadFile(f.module, f.version, path)
+}
+
+func (f *modCacheFiles) Open(path string) (io.ReadCloser, error) {
+	return f.modCache.Open(f.module, f.version, path)
+}
+
+func (f *modCacheFiles) Size(path string) (int64, error) {
+	return f.modCache.Size(f.module, f.version, path)
+}
+
+// zipFiles adapts a ZipEntry to moduleFiles.
+type zipFiles struct {
+	*ZipEntry
+}
+
+func (f zipFiles) ListFiles(prefix string) ([]string, error) {
+	return f.ZipEntry.ListFiles(prefix), nil
+}
+
+// dirFiles exposes a local directory, such as a checkout of a module, as
+// moduleFiles. Like module zips, it leaves out VCS metadata, vendor
+// directories and nested modules (subdirectories with their own go.mod).
+type dirFiles struct {
+	root string
+}
+
+func (d dirFiles) ListFiles(prefix string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(d.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return fmt.Errorf("compute relative path: %w", err)
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if rel != "." && skipLocalDir(path, entry.Name()) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.Type().IsRegular() && (prefix == "" || strings.HasPrefix(rel, prefix)) {
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk local dir: %w", err)
+	}
+
+	return files, nil
+}
+
+func (d dirFiles) ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(d.root, filepath.FromSlash(path)))
+	if err != nil {
+		return "", fmt.Errorf("read local file: %w", err)
+	}
+
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("file appears to be binary: %s", path)
+	}
+
+	return string(data), nil
+}
+
+func (d dirFiles) Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(d.root, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, fmt.Errorf("open local file: %w", err)
+	}
+
+	return f, nil
+}
+
+func (d dirFiles) Size(path string) (int64, error) {
+	info, err := os.Stat(filepath.Join(d.root, filepath.FromSlash(path)))
+	if err != nil {
+		return 0, fmt.Errorf("stat local file: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// skipLocalDir reports whether a directory of a local checkout falls
+// outside the module's zip contents.
+func skipLocalDir(path, name string) bool {
+	switch name {
+	case ".git", ".hg", ".svn", ".bzr", "vendor":
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(path, "go.mod"))
+
+	return err == nil
+}
+
+// openModule returns the files of a module version, reading from the
+// local module cache when possible and otherwise downloading the zip
+// archive through the proxy (or reusing a cached copy). Inside a tool call
+// the version's README and go.mod are published as resources.
+func openModule(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, module, version string,
+) (moduleFiles, error) {
+	var mf moduleFiles
+
+	if modCache.HasModule(module, version) {
+		noteBackend(ctx, backendModCache)
+
+		mf = &modCacheFiles{modCache: modCache, module: module, version: version}
+	} else {
+		entry, err := getOrDownload(ctx, proxy, cache, module, version)
+		if err != nil {
+			return nil, err
+		}
+
+		storeDownload(ctx, proxy, modCache, module, version, entry)
+
+		mf = zipFiles{entry}
+	}
+
+	publishModule(ctx, module, version, mf)
+
+	return mf, nil
+}
@@ -0,0 +1,583 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Read modes of gomod_read_file.
+const (
+	readModeFull     = "full"     // the file as published
+	readModeCode     = "code"     // Go source without comments
+	readModeComments = "comments" // doc comments and top-level comment blocks only
+	readModeOutline  = "outline"  // package clause, imports and declarations without bodies
+)
+
+// stripComments re-prints Go source without its comments. Comments that
+// tools act on are kept: //go: and //line directives, build constraints,
+// cgo //export lines and the cgo preamble. With dropBlank, blank lines
+// outside raw string literals are removed as well.
+func stripComments(name, src string, dropBlank bool) (string, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	preamble := cgoPreamble(f)
+
+	var kept []*ast.CommentGroup
+
+	for _, g := range f.Comments {
+		if g == preamble {
+			kept = append(kept, g)
+
+			continue
+		}
+
+		var directives []*ast.Comment
+
+		for _, c := range g.List {
+			if isDirectiveComment(c.Text) {
+				directives = append(directives, c)
+			}
+		}
+
+		if len(directives) > 0 {
+			kept = append(kept, &ast.CommentGroup{List: directives})
+		}
+	}
+
+	f.Comments = kept
+
+	clearDocComments(f, preamble)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return "", fmt.Errorf("print %s: %w", name, err)
+	}
+
+	if !dropBlank {
+		return buf.String(), nil
+	}
+
+	return dropBlankLines(buf.String()), nil
+}
+
+// outlineSource prints the package clause, imports and top-level
+// declarations of Go source, without comments or function bodies. Each
+// declaration is headed by the lines it spans in the file, so that it can
+// be read in full with symbol.
+func outlineSource(name, src string) (string, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	var sb strings.Builder
+
+	for _, g := range f.Comments {
+		if g.Pos() > f.Package {
+			break
+		}
+
+		for _, c := range g.List {
+			if strings.HasPrefix(c.Text, "//go:build ") {
+				sb.WriteString(c.Text + "\n\n")
+			}
+		}
+	}
+
+	fmt.Fprintf(&sb, "package %s\n", f.Name.Name)
+
+	clearDocComments(f, nil)
+
+	for _, decl := range f.Decls {
+		start, end := fset.Position(decl.Pos()).Line, fset.Position(decl.End()).Line
+
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			fn.Body = nil
+		}
+
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, decl); err != nil {
+			return "", fmt.Errorf("print %s: %w", name, err)
+		}
+
+		if start == end {
+			fmt.Fprintf(&sb, "\n// line %d\n%s\n", start, buf.String())
+		} else {
+			fmt.Fprintf(&sb, "\n// lines %d-%d\n%s\n", start, end, buf.String())
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// isDirectiveComment reports whether a comment is read by the toolchain
+// rather than by people.
+func isDirectiveComment(text string) bool {
+	for _, prefix := range []string{"//go:", "//line ", "/*line ", "// +build", "//export "} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clearDocComments unlinks doc and line comments from the syntax tree,
+// except keep. The printer falls back to them when a file has no other
+// comments.
+func clearDocComments(f *ast.File, keep *ast.CommentGroup) {
+	unlink := func(g **ast.CommentGroup) {
+		if *g != keep {
+			*g = nil
+		}
+	}
+
+	unlink(&f.Doc)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GenDecl:
+			unlink(&n.Doc)
+		case *ast.FuncDecl:
+			unlink(&n.Doc)
+		case *ast.Field:
+			unlink(&n.Doc)
+			unlink(&n.Comment)
+		case *ast.ImportSpec:
+			unlink(&n.Doc)
+			unlink(&n.Comment)
+		case *ast.ValueSpec:
+			unlink(&n.Doc)
+			unlink(&n.Comment)
+		case *ast.TypeSpec:
+			unlink(&n.Doc)
+			unlink(&n.Comment)
+		}
+
+		return true
+	})
+}
+
+// cgoPreamble returns the comment above import "C", which cgo compiles as
+// C source.
+func cgoPreamble(f *ast.File) *ast.CommentGroup {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			if imp, ok := spec.(*ast.ImportSpec); ok && imp.Path.Value == `"C"` {
+				if imp.Doc != nil {
+					return imp.Doc
+				}
+
+				return gen.Doc
+			}
+		}
+	}
+
+	return nil
+}
+
+// dropBlankLines removes empty lines from gofmt-ed source, except inside
+// raw string literals, where they are part of the value.
+func dropBlankLines(src string) string {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return src
+	}
+
+	inRaw := make(map[int]bool)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && strings.HasPrefix(lit.Value, "`") {
+			for line := fset.Position(lit.Pos()).Line + 1; line <= fset.Position(lit.End()).Line; line++ {
+				inRaw[line] = true
+			}
+		}
+
+		return true
+	})
+
+	var sb strings.Builder
+
+	for i, line := range strings.SplitAfter(src, "\n") {
+		if strings.TrimSpace(line) == "" && !inRaw[i+1] {
+			continue
+		}
+
+		sb.WriteString(line)
+	}
+
+	return sb.String()
+}
+
+// extractComments returns the narrative comments of a Go file: doc
+// comments and comment blocks outside function bodies, each headed by its
+// position and what it documents. Directives are left out.
+func extractComments(name, src string) (string, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	labels := commentLabels(f)
+
+	var bodies []ast.Node
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				bodies = append(bodies, n.Body)
+			}
+		case *ast.FuncLit:
+			bodies = append(bodies, n.Body)
+
+			return false
+		}
+
+		return true
+	})
+
+	var sb strings.Builder
+
+	for _, g := range f.Comments {
+		text := strings.TrimSpace(g.Text())
+		if text == "" || insideAny(g, bodies) {
+			continue
+		}
+
+		label := labels[g]
+		if label == "" {
+			label = "comment"
+		}
+
+		fmt.Fprintf(&sb, "%s:%d %s\n", name, fset.Position(g.Pos()).Line, label)
+
+		for _, line := range strings.Split(text, "\n") {
+			sb.WriteString(strings.TrimRight("  "+line, " "))
+			sb.WriteByte('\n')
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String(), nil
+}
+
+func insideAny(n ast.Node, ranges []ast.Node) bool {
+	for _, r := range ranges {
+		if n.Pos() >= r.Pos() && n.End() <= r.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commentLabels names the declaration each doc comment of f documents,
+// such as "func F", "method T.M" or "field T.A".
+func commentLabels(f *ast.File) map[*ast.CommentGroup]string {
+	labels := make(map[*ast.CommentGroup]string)
+
+	set := func(g *ast.CommentGroup, label string) {
+		if g != nil {
+			labels[g] = label
+		}
+	}
+
+	set(f.Doc, "package "+f.Name.Name)
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if recv := receiverName(d); recv != "" {
+				set(d.Doc, "method "+recv+"."+d.Name.Name)
+			} else {
+				set(d.Doc, "func "+d.Name.Name)
+			}
+		case *ast.GenDecl:
+			set(d.Doc, d.Tok.String())
+
+			for _, spec := range d.Specs {
+				labelSpec(spec, d, set)
+			}
+		}
+	}
+
+	return labels
+}
+
+func labelSpec(spec ast.Spec, d *ast.GenDecl, set func(*ast.CommentGroup, string)) {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		label := "type " + spec.Name.Name
+
+		set(spec.Doc, label)
+		set(spec.Comment, label)
+
+		if len(d.Specs) == 1 {
+			set(d.Doc, label)
+		}
+
+		var (
+			fields *ast.FieldList
+			kind   string
+		)
+
+		switch t := spec.Type.(type) {
+		case *ast.StructType:
+			fields, kind = t.Fields, "field "
+		case *ast.InterfaceType:
+			fields, kind = t.Methods, "method "
+		default:
+			return
+		}
+
+		for _, field := range fields.List {
+			label := kind + spec.Name.Name + "." + fieldName(field)
+
+			set(field.Doc, label)
+			set(field.Comment, label)
+		}
+	case *ast.ValueSpec:
+		label := d.Tok.String() + " " + identNames(spec.Names)
+
+		set(spec.Doc, label)
+		set(spec.Comment, label)
+
+		if len(d.Specs) == 1 {
+			set(d.Doc, label)
+		}
+	}
+}
+
+// fieldName returns the names of a field, or its type if it is embedded.
+func fieldName(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return types.ExprString(field.Type)
+	}
+
+	return identNames(field.Names)
+}
+
+func identNames(idents []*ast.Ident) string {
+	names := make([]string, len(idents))
+	for i, n := range idents {
+		names[i] = n.Name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// readComments extracts the comments of a Go file, or of every non-test
+// Go file of the package in directory p.
+func readComments(mf moduleFiles, p string) (*mcp.CallToolResult, any, error) {
+	files := []string{p}
+
+	if path.Ext(p) != ".go" {
+		dir := strings.Trim(p, "/")
+
+		prefix := dir + "/"
+		if dir == "" || dir == "." {
+			dir, prefix = ".", ""
+		}
+
+		paths, err := mf.ListFiles(prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files = files[:0]
+
+		for _, f := range paths {
+			if path.Dir(f) == dir && strings.HasSuffix(f, ".go") && !strings.HasSuffix(f, "_test.go") {
+				files = append(files, f)
+			}
+		}
+
+		if len(files) == 0 {
+			return errorResult(fmt.Sprintf("%s is neither a .go file nor a package directory.", p)), nil, nil
+		}
+
+		sort.Strings(files)
+	}
+
+	var sb strings.Builder
+
+	for _, f := range files {
+		src, err := mf.ReadFile(f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		comments, err := extractComments(f, src)
+		if err != nil {
+			return errorResult(err.Error()), nil, nil
+		}
+
+		sb.WriteString(comments)
+	}
+
+	if sb.Len() == 0 {
+		return textResult(fmt.Sprintf("No comments in %s.", p)), nil, nil
+	}
+
+	return textResult(sb.String()), nil, nil
+}
+
+// symbolSource returns the declaration of symbol in a Go file with its
+// doc comment, headed by a comment giving its line range. symbol names a
+// function, a method as Type.Method, a type, a constant or a variable; a
+// bare name also finds a method if no other declaration has it and the
+// method name is unique.
+func symbolSource(name, src, symbol string) (string, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	recv, sym, isMethod := strings.Cut(strings.NewReplacer("(", "", ")", "", "*", "").Replace(symbol), ".")
+	if !isMethod {
+		recv, sym = "", recv
+	}
+
+	var (
+		found    ast.Node
+		doc      *ast.CommentGroup
+		methods  []*ast.FuncDecl
+		declared []string
+	)
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			r := receiverName(d)
+			if r != "" {
+				declared = append(declared, r+"."+d.Name.Name)
+			} else {
+				declared = append(declared, d.Name.Name)
+			}
+
+			if d.Name.Name != sym {
+				continue
+			}
+
+			if r == recv {
+				found, doc = d, d.Doc
+			} else if r != "" && !isMethod {
+				methods = append(methods, d)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				names := specNames(spec)
+				declared = append(declared, names...)
+
+				if isMethod || !slices.Contains(names, sym) {
+					continue
+				}
+
+				// A spec of a grouped declaration stands alone; a
+				// single spec is shown with its keyword.
+				found, doc = spec, specDoc(spec)
+				if len(d.Specs) == 1 {
+					found, doc = d, d.Doc
+				}
+			}
+		}
+	}
+
+	if found == nil && len(methods) == 1 {
+		found, doc = methods[0], methods[0].Doc
+	}
+
+	if found == nil {
+		if len(methods) > 1 {
+			return "", fmt.Errorf("%s is a method of several types in %s; use Type.%s", sym, name, sym)
+		}
+
+		return "", fmt.Errorf("no declaration of %s in %s; it declares %s", symbol, name, declNameList(declared))
+	}
+
+	start := found.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+
+	tf := fset.File(start)
+
+	return fmt.Sprintf("// %s:%d-%d\n%s\n", name, fset.Position(start).Line, fset.Position(found.End()).Line,
+		src[tf.Offset(start):tf.Offset(found.End())]), nil
+}
+
+func specNames(spec ast.Spec) []string {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return []string{s.Name.Name}
+	case *ast.ValueSpec:
+		names := make([]string, 0, len(s.Names))
+		for _, n := range s.Names {
+			if n.Name != "_" {
+				names = append(names, n.Name)
+			}
+		}
+
+		return names
+	}
+
+	return nil
+}
+
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+
+	return nil
+}
+
+// declNameList lists declared names for an error message, shortened when
+// a file declares many.
+func declNameList(names []string) string {
+	const maxNames = 20
+
+	if len(names) == 0 {
+		return "nothing"
+	}
+
+	if len(names) > maxNames {
+		return strings.Join(names[:maxNames], ", ") + fmt.Sprintf(" and %d more", len(names)-maxNames)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	src := `//go:build linux
+
+// Package p does things.
+package p
+
+/*
+#include <stdio.h>
+*/
+import "C"
+
+// T is a type.
+type T struct {
+	A int // trailing
+}
+
+// F does something.
+//
+//go:noinline
+func F() string {
+	// explain
+
+	return ` + "`a\n\nb`" + `
+}
+`
+
+	got, err := stripComments("p.go", src, false)
+	mustf(t, err, "strip comments")
+
+	want := "//go:build linux\n\npackage p\n\n/*\n#include <stdio.h>\n*/\nimport \"C\"\n\n" +
+		"type T struct {\n\tA int\n}\n\n//go:noinline\nfunc F() string {\n\n\treturn `a\n\nb`\n}\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = stripComments("p.go", src, true)
+	mustf(t, err, "strip comments and blank lines")
+
+	want = "//go:build linux\npackage p\n/*\n#include <stdio.h>\n*/\nimport \"C\"\n" +
+		"type T struct {\n\tA int\n}\n//go:noinline\nfunc F() string {\n\treturn `a\n\nb`\n}\n"
+	if got != want {
+		t.Errorf("without blank lines, got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := stripComments("bad.go", "package p\nfunc {", false); err == nil {
+		t.Error("invalid source should fail to parse")
+	}
+}
+
+func TestOutlineSource(t *testing.T) {
+	src := `//go:build linux
+
+// Package p does things.
+package p
+
+import (
+	"fmt"
+	"os"
+)
+
+// T is a type.
+type T struct {
+	A int // trailing
+}
+
+const N = 1
+
+// F does something.
+func (t *T) F(n int) (string, error) {
+	fmt.Println(n)
+
+	return os.Getenv("X"), nil
+}
+`
+
+	got, err := outlineSource("p.go", src)
+	mustf(t, err, "outline")
+
+	want := "//go:build linux\n\npackage p\n\n// lines 6-9\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\n" +
+		"// lines 12-14\ntype T struct {\n\tA int\n}\n\n// line 16\nconst N = 1\n\n" +
+		"// lines 19-23\nfunc (t *T) F(n int) (string, error)\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := outlineSource("bad.go", "package p\nfunc {"); err == nil {
+		t.Error("invalid source should fail to parse")
+	}
+}
+
+func TestExtractComments(t *testing.T) {
+	src := `// Copyright 2025 The Authors.
+
+// Package p does things.
+package p
+
+// Kinds of things.
+const (
+	A = 1 // the first
+	B = 2
+)
+
+// T is a type.
+type T struct {
+	// N counts.
+	N int
+	io.Reader // embedded
+}
+
+// I is implemented by T.
+type I interface {
+	// M does it.
+	M()
+}
+
+// F does something.
+//
+//go:noinline
+func (t *T) F() {
+	// not narrative
+}
+
+/* trailing block */
+`
+
+	got, err := extractComments("p.go", src)
+	mustf(t, err, "extract comments")
+
+	want := `p.go:1 comment
+  Copyright 2025 The Authors.
+
+p.go:3 package p
+  Package p does things.
+
+p.go:6 const
+  Kinds of things.
+
+p.go:8 const A
+  the first
+
+p.go:12 type T
+  T is a type.
+
+p.go:14 field T.N
+  N counts.
+
+p.go:16 field T.io.Reader
+  embedded
+
+p.go:19 type I
+  I is implemented by T.
+
+p.go:21 method I.M
+  M does it.
+
+p.go:25 method T.F
+  F does something.
+
+p.go:32 comment
+  trailing block
+
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSymbolSource(t *testing.T) {
+	const src = `package p
+
+// Limit bounds things.
+const Limit = 3
+
+const (
+	// A is first.
+	A = iota
+	B
+)
+
+// T is a type.
+type T struct{}
+
+// F does something.
+func (t *T) F() {}
+
+// U is another type.
+type U struct{}
+
+// Close closes.
+func (u U) Close() {}
+
+func (t *T) Close() {}
+
+// New returns a T.
+func New() *T { return &T{} }
+`
+
+	for symbol, want := range map[string]string{
+		"New":     "// p.go:26-27\n// New returns a T.\nfunc New() *T { return &T{} }\n",
+		"T.F":     "// p.go:15-16\n// F does something.\nfunc (t *T) F() {}\n",
+		"(*T).F":  "// p.go:15-16\n// F does something.\nfunc (t *T) F() {}\n",
+		"F":       "// p.go:15-16\n// F does something.\nfunc (t *T) F() {}\n",
+		"T":       "// p.go:12-13\n// T is a type.\ntype T struct{}\n",
+		"Limit":   "// p.go:3-4\n// Limit bounds things.\nconst Limit = 3\n",
+		"A":       "// p.go:7-8\n// A is first.\n\tA = iota\n",
+		"U.Close": "// p.go:21-22\n// Close closes.\nfunc (u U) Close() {}\n",
+	} {
+		got, err := symbolSource("p.go", src, symbol)
+		if err != nil || got != want {
+			t.Errorf("%s: got %q, %v; want %q", symbol, got, err, want)
+		}
+	}
+
+	if _, err := symbolSource("p.go", src, "Close"); err == nil || !strings.Contains(err.Error(), "use Type.Close") {
+		t.Errorf("ambiguous method: got %v", err)
+	}
+
+	if _, err := symbolSource("p.go", src, "Missing"); err == nil || !strings.Contains(err.Error(), "Limit, A, B, T") {
+		t.Errorf("missing symbol: got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of machine-readable spec artifacts, in report order.
+const (
+	specOpenAPI      = "OpenAPI"
+	specSwagger      = "Swagger"
+	specJSONSchema   = "JSON Schema"
+	specGraphQL      = "GraphQL schema"
+	specSQLMigration = "SQL migration"
+	specSQL          = "SQL"
+)
+
+var specOrder = []string{specOpenAPI, specSwagger, specJSONSchema, specGraphQL, specSQLMigration, specSQL}
+
+var (
+	openAPIRe       = regexp.MustCompile(`(?m)^\s*["']?openapi["']?\s*:\s*["']?(3[0-9.]*)`)
+	swaggerRe       = regexp.MustCompile(`(?m)^\s*["']?swagger["']?\s*:\s*["']?(2[0-9.]*)`)
+	specTitleRe     = regexp.MustCompile(`(?m)(?:^|[\s{,])["']?title["']?\s*:\s*["']?([^"'\n,}]+)`)
+	jsonSchemaRe    = regexp.MustCompile(`["']?\$schema["']?\s*:\s*["']?[^"'\n]*json-schema\.org`)
+	schemaIDRe      = regexp.MustCompile(`["']?\$id["']?\s*:\s*["']([^"']+)`)
+	graphQLTypeRe   = regexp.MustCompile(`(?m)^\s*(?:extend\s+)?(?:type|input|interface|enum|union|scalar)\s+\w+`)
+	createTableRe   = regexp.MustCompile("(?i)create\\s+table\\s+(?:if\\s+not\\s+exists\\s+)?[\"`]?([\\w.]+)")
+	migrationNameRe = regexp.MustCompile(`^\d+[_.-]|\.(up|down)\.sql$`)
+)
+
+// specFile is a spec artifact found in a module.
+type specFile struct {
+	path   string
+	kind   string
+	bytes  int
+	detail string
+}
+
+// specCandidate reports whether the extension of p can hold a spec, so
+// that only those files are read.
+func specCandidate(p string) bool {
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		if elem == "testdata" || elem == "vendor" || elem == "node_modules" {
+			return false
+		}
+	}
+
+	switch strings.ToLower(path.Ext(p)) {
+	case ".yaml", ".yml", ".json", ".sql", ".graphql", ".graphqls", ".gql":
+		return true
+	default:
+		return false
+	}
+}
+
+// detectSpec classifies the file at p and describes it. It returns an
+// empty kind for files that are not spec artifacts.
+func detectSpec(p, src string) (string, string) {
+	head := src[:min(len(src), 4096)]
+
+	switch ext := strings.ToLower(path.Ext(p)); ext {
+	case ".yaml", ".yml", ".json":
+		if m := openAPIRe.FindStringSubmatch(head); m != nil {
+			return specOpenAPI, joinDetail(m[1], specTitle(src))
+		}
+
+		if m := swaggerRe.FindStringSubmatch(head); m != nil {
+			return specSwagger, joinDetail(m[1], specTitle(src))
+		}
+
+		if jsonSchemaRe.MatchString(head) || strings.HasSuffix(strings.ToLower(p), ".schema.json") {
+			detail := specTitle(src)
+			if m := schemaIDRe.FindStringSubmatch(head); m != nil {
+				detail = joinDetail(detail, m[1])
+			}
+
+			return specJSONSchema, detail
+		}
+	case ".graphql", ".graphqls", ".gql":
+		return specGraphQL, fmt.Sprintf("%d type definitions", len(graphQLTypeRe.FindAllString(src, -1)))
+	case ".sql":
+		kind := specSQL
+		if isMigration(p) {
+			kind = specSQLMigration
+		}
+
+		var tables []string
+		for _, m := range createTableRe.FindAllStringSubmatch(src, -1) {
+			tables = append(tables, m[1])
+		}
+
+		if len(tables) == 0 {
+			return kind, ""
+		}
+
+		return kind, "creates " + strings.Join(tables, ", ")
+	}
+
+	return "", ""
+}
+
+// isMigration reports whether a SQL file is a schema migration: it lives in
+// a migrations directory or has a sequence-numbered or up/down name.
+func isMigration(p string) bool {
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		switch strings.ToLower(elem) {
+		case "migrations", "migration", "migrate", "schema":
+			return true
+		}
+	}
+
+	return migrationNameRe.MatchString(path.Base(p))
+}
+
+func specTitle(src string) string {
+	if m := specTitleRe.FindStringSubmatch(src); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+
+	return ""
+}
+
+func joinDetail(parts ...string) string {
+	var nonEmpty []string
+
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+
+	return strings.Join(nonEmpty, " — ")
+}
+
+// findSpecs returns the spec artifacts under prefix, grouped in specOrder
+// and sorted by path within each kind.
+func findSpecs(mf moduleFiles, prefix string) ([]*specFile, error) {
+	paths, err := mf.ListFiles(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+
+	var specs []*specFile
+
+	for _, p := range paths {
+		if !specCandidate(p) {
+			continue
+		}
+
+		src, err := mf.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		if kind, detail := detectSpec(p, src); kind != "" {
+			specs = append(specs, &specFile{path: p, kind: kind, bytes: len(src), detail: detail})
+		}
+	}
+
+	rank := make(map[string]int, len(specOrder))
+	for i, k := range specOrder {
+		rank[k] = i
+	}
+
+	sort.SliceStable(specs, func(i, j int) bool { return rank[specs[i].kind] < rank[specs[j].kind] })
+
+	return specs, nil
+}
+
+func formatSpecs(module, version string, specs []*specFile) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Spec artifacts in %s@%s (%d files):\n", module, version, len(specs))
+
+	if len(specs) == 0 {
+		sb.WriteString("\nNo OpenAPI, JSON Schema, GraphQL or SQL files found.\n")
+
+		return sb.String()
+	}
+
+	sb.WriteString("\n")
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "KIND\tFILE\tBYTES\tDETAIL")
+
+	for _, s := range specs {
+		detail := s.detail
+		if detail == "" {
+			detail = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", s.kind, s.path, s.bytes, detail)
+	}
+
+	_ = tw.Flush()
+
+	return sb.String()
+}
+
+type specsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+}
+
+func handleSpecs(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input specsInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specs, err := findSpecs(mf, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(formatSpecs(input.Module, version, specs)), nil, nil
+}
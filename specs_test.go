@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindSpecs(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"api/openapi.yaml": "openapi: 3.1.0\ninfo:\n  title: Pet Store\n  version: 1.0.0\n",
+		"api/swagger.json": "{\n  \"swagger\": \"2.0\",\n  \"info\": {\"title\": \"Legacy\"}\n}\n",
+		"schema/config.json": "{\"$schema\": \"https://json-schema.org/draft/2020-12/schema\", " +
+			"\"$id\": \"https://example.com/config\", \"title\": \"Config\"}",
+		"db/migrations/0001_init.up.sql":   "CREATE TABLE users (id int);\nCREATE TABLE IF NOT EXISTS `orders` (id int);\n",
+		"db/migrations/0001_init.down.sql": "DROP TABLE users;\n",
+		"queries/users.sql":                "SELECT * FROM users;\n",
+		"graph/schema.graphql":             "type Query { user: User }\n\ntype User { id: ID! }\n",
+		"config/app.yaml":                  "name: app\n",
+		"testdata/openapi.yaml":            "openapi: 3.0.0\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+	mustf(t, err, "put zip in cache")
+
+	specs, err := findSpecs(zipFiles{entry}, "")
+	mustf(t, err, "find specs")
+
+	var got []string
+	for _, s := range specs {
+		got = append(got, s.kind+": "+s.path+": "+s.detail)
+	}
+
+	want := []string{
+		"OpenAPI: api/openapi.yaml: 3.1.0 — Pet Store",
+		"Swagger: api/swagger.json: 2.0 — Legacy",
+		"JSON Schema: schema/config.json: Config — https://example.com/config",
+		"GraphQL schema: graph/schema.graphql: 2 type definitions",
+		"SQL migration: db/migrations/0001_init.down.sql: ",
+		"SQL migration: db/migrations/0001_init.up.sql: creates users, orders",
+		"SQL: queries/users.sql: ",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("specs:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	out := formatSpecs("mod", "v1.0.0", specs)
+	if !strings.Contains(out, "(7 files)") || !strings.Contains(out, "SQL             queries/users.sql") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type statsInput struct{}
+
+func handleStats(
+	proxy *ProxyClient, cache *ZipCache, watcher *Watcher, scheduler *Scheduler,
+) (*mcp.CallToolResult, any, error) {
+	var sb strings.Builder
+
+	sb.WriteString("Caches:\n")
+	fmt.Fprintf(&sb, "  zip archives: %d\n", cache.Len())
+
+	if proxy.meta != nil {
+		fmt.Fprintf(&sb, "  metadata entries: %d (TTL %s)\n", proxy.meta.Len(), proxy.meta.ttl)
+	} else {
+		sb.WriteString("  metadata entries: disabled\n")
+	}
+
+	if proxy.disk != nil {
+		if u, err := proxy.disk.Usage(); err != nil {
+			fmt.Fprintf(&sb, "  disk cache: %s (%v)\n", proxy.disk.dir, err)
+		} else {
+			fmt.Fprintf(&sb, "  disk cache: %d files, %d bytes compressed, in %s\n", u.objects, u.bytes, proxy.disk.dir)
+		}
+	}
+
+	fmt.Fprintf(&sb, "\nWatched modules: %d\n", len(watcher.Modules()))
+
+	state := "running"
+
+	switch {
+	case proxy.offline:
+		state = "paused (offline mode)"
+	case scheduler.Paused():
+		state = "paused"
+	}
+
+	fmt.Fprintf(&sb, "\nScheduler: %s, jitter ±%.0f%%\n", state, scheduler.jitter*100)
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "  JOB\tINTERVAL\tRUNS\tLAST RUN\tNEXT RUN\tLAST ERROR")
+
+	for _, job := range scheduler.Status() {
+		lastErr := "-"
+		if job.lastErr != nil {
+			lastErr = job.lastErr.Error()
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%s\t%s\t%s\n",
+			job.name, job.interval, job.runs, formatJobTime(job.lastRun), formatJobTime(job.nextRun), lastErr)
+	}
+
+	_ = tw.Flush()
+
+	return textResult(sb.String()), nil, nil
+}
+
+func formatJobTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb"
+)
+
+// sumGolangOrgKey is the verifier key of sum.golang.org, which the go
+// command also has built in.
+const sumGolangOrgKey = "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ze6cgDbZlJ2jzPUrL"
+
+// checksumDBTimeout bounds each request to the checksum database, which
+// sumdb.Client makes without a context.
+const checksumDBTimeout = 30 * time.Second
+
+// ErrChecksumDBUnavailable is returned for a download the checksum
+// database could not be asked about. Like the go command, the server does
+// not use it unverified.
+var ErrChecksumDBUnavailable = errors.New("checksum database unavailable")
+
+// ChecksumDB verifies module hashes against a checksum database, as the
+// go command does for modules not in GONOSUMDB or GOPRIVATE. The signed
+// tree and the tiles read are kept in memory.
+type ChecksumDB struct {
+	name    string
+	url     string
+	nosumdb string // GONOSUMDB patterns, or else GOPRIVATE
+	client  *sumdb.Client
+}
+
+// NewChecksumDB configures a checksum database client from the GOSUMDB
+// setting: "" for sum.golang.org, a verifier key with an optional URL,
+// or "off" for none, in which case it returns nil.
+func NewChecksumDB(gosumdb, nosumdb string, client *http.Client) (*ChecksumDB, error) {
+	gosumdb = strings.TrimSpace(gosumdb)
+
+	key, url, _ := strings.Cut(gosumdb, " ")
+
+	switch key {
+	case "off":
+		return nil, nil
+	case "", "sum.golang.org":
+		key = sumGolangOrgKey
+	case "sum.golang.google.cn":
+		key, url = sumGolangOrgKey, cmp.Or(url, "https://sum.golang.google.cn")
+	}
+
+	name, _, ok := strings.Cut(key, "+")
+	if !ok {
+		return nil, fmt.Errorf("GOSUMDB %q: want a name known to the go command or a verifier key", gosumdb)
+	}
+
+	url = strings.TrimSuffix(cmp.Or(strings.TrimSpace(url), "https://"+name), "/")
+
+	return &ChecksumDB{
+		name:    name,
+		url:     url,
+		nosumdb: nosumdb,
+		client: sumdb.NewClient(&checksumDBOps{
+			key:     key,
+			url:     url,
+			client:  client,
+			timeout: checksumDBTimeout,
+			config:  make(map[string][]byte),
+			cache:   make(map[string][]byte),
+		}),
+	}, nil
+}
+
+// covers reports whether the database is asked about a module.
+func (db *ChecksumDB) covers(mod string) bool {
+	return db != nil && !module.MatchPrefixPatterns(db.nosumdb, mod)
+}
+
+// verify checks a module zip, or with goMod a go.mod file, against the
+// database. A hash that differs is an ErrChecksumMismatch error, and one
+// the database cannot be asked about an ErrChecksumDBUnavailable error.
+func (db *ChecksumDB) verify(mod, version string, goMod bool, hash string) error {
+	vers := version
+	if goMod {
+		vers += "/go.mod"
+	}
+
+	lines, err := db.client.Lookup(mod, vers)
+	if err != nil {
+		return fmt.Errorf("verify %s@%s: %w: %s: %w (GONOSUMDB or GOSUMDB=off skips it)",
+			mod, vers, ErrChecksumDBUnavailable, db.name, err)
+	}
+
+	prefix := mod + " " + vers + " "
+
+	for _, line := range lines {
+		if want, ok := strings.CutPrefix(line, prefix); ok && strings.HasPrefix(want, "h1:") && want != hash {
+			return fmt.Errorf("verify %s@%s: %w: downloaded %s, %s has %s",
+				mod, vers, ErrChecksumMismatch, hash, db.name, want)
+		}
+	}
+
+	return nil
+}
+
+// checksumDBOps are the sumdb.ClientOps of a ChecksumDB, reading from the
+// database over HTTP and keeping its state in memory.
+type checksumDBOps struct {
+	key     string
+	url     string
+	client  *http.Client
+	timeout time.Duration
+
+	mu     sync.Mutex
+	config map[string][]byte
+	cache  map[string][]byte
+}
+
+func (o *checksumDBOps) ReadRemote(path string) ([]byte, error) {
+	// sumdb.ClientOps passes no context, so the request gets its own
+	// deadline: the HTTP client has none.
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.url+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("checksum database: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxZipSize))
+	if err != nil {
+		return nil, fmt.Errorf("checksum database %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("checksum database %s: status %d: %s", path, resp.StatusCode,
+			clipLine(string(bytes.TrimSpace(body))))
+	}
+
+	return body, nil
+}
+
+func (o *checksumDBOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.config[file], nil
+}
+
+func (o *checksumDBOps) WriteConfig(file string, old, data []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !bytes.Equal(o.config[file], old) {
+		return sumdb.ErrWriteConflict
+	}
+
+	o.config[file] = data
+
+	return nil
+}
+
+func (o *checksumDBOps) ReadCache(file string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data, ok := o.cache[file]
+	if !ok {
+		return nil, errors.New("not cached")
+	}
+
+	return data, nil
+}
+
+func (o *checksumDBOps) WriteCache(file string, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.cache[file] = data
+}
+
+func (o *checksumDBOps) Log(msg string) {
+	log.Print(msg)
+}
+
+func (o *checksumDBOps) SecurityError(msg string) {
+	log.Printf("SECURITY ERROR: %s", msg)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// testChecksumDB serves a checksum database whose records are the go.sum
+// lines of sums, keyed by "path@version". Other modules are not found.
+func testChecksumDB(t *testing.T, nosumdb string, sums map[string]string) *ChecksumDB {
+	t.Helper()
+
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sum.example.com")
+	mustf(t, err, "generate key")
+
+	ts := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		lines, ok := sums[path+"@"+vers]
+		if !ok {
+			return nil, fmt.Errorf("%s@%s: not found", path, vers)
+		}
+
+		return []byte(lines), nil
+	})))
+	t.Cleanup(ts.Close)
+
+	db, err := NewChecksumDB(vkey+" "+ts.URL, nosumdb, ts.Client())
+	mustf(t, err, "new checksum database")
+
+	return db
+}
+
+func TestNewChecksumDB(t *testing.T) {
+	for _, tt := range []struct {
+		gosumdb   string
+		name, url string
+	}{
+		{"", "sum.golang.org", "https://sum.golang.org"},
+		{"sum.golang.org", "sum.golang.org", "https://sum.golang.org"},
+		{"sum.golang.google.cn", "sum.golang.org", "https://sum.golang.google.cn"},
+		{sumGolangOrgKey + " https://sumdb.example.com/", "sum.golang.org", "https://sumdb.example.com"},
+	} {
+		db, err := NewChecksumDB(tt.gosumdb, "", http.DefaultClient)
+		if err != nil || db.name != tt.name || db.url != tt.url {
+			t.Errorf("NewChecksumDB(%q) = %+v, %v; want %s at %s", tt.gosumdb, db, err, tt.name, tt.url)
+		}
+	}
+
+	if db, err := NewChecksumDB("off", "", http.DefaultClient); db != nil || err != nil {
+		t.Errorf("NewChecksumDB(off) = %+v, %v; want nil", db, err)
+	}
+
+	if _, err := NewChecksumDB("sum.example.com", "", http.DefaultClient); err == nil {
+		t.Error("expected an error for a checksum database without a key")
+	}
+}
+
+func TestChecksumDB_Covers(t *testing.T) {
+	db, err := NewChecksumDB("", "example.com/private,*.corp.example", http.DefaultClient)
+	mustf(t, err, "new checksum database")
+
+	for mod, want := range map[string]bool{
+		"example.com/public":        true,
+		"example.com/private":       false,
+		"example.com/private/sub":   false,
+		"git.corp.example/team/mod": false,
+	} {
+		if got := db.covers(mod); got != want {
+			t.Errorf("covers(%q) = %v, want %v", mod, got, want)
+		}
+	}
+
+	if (*ChecksumDB)(nil).covers("example.com/public") {
+		t.Error("a nil checksum database covers no module")
+	}
+}
+
+func TestChecksumDB_Verify(t *testing.T) {
+	db := testChecksumDB(t, "", map[string]string{
+		"example.com/m@v1.0.0": "example.com/m v1.0.0 h1:zip=\nexample.com/m v1.0.0/go.mod h1:mod=\n",
+	})
+
+	for _, tt := range []struct {
+		goMod bool
+		hash  string
+		ok    bool
+	}{
+		{false, "h1:zip=", true},
+		{true, "h1:mod=", true},
+		{false, "h1:tampered=", false},
+		{true, "h1:zip=", false},
+	} {
+		err := db.verify("example.com/m", "v1.0.0", tt.goMod, tt.hash)
+		if (err == nil) != tt.ok || err != nil && !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("verify go.mod %v %s: %v", tt.goMod, tt.hash, err)
+		}
+	}
+
+	err := db.verify("example.com/m", "v1.0.0", true, "h1:bad=")
+	if err == nil || !strings.Contains(err.Error(), "v1.0.0/go.mod: checksum mismatch: downloaded h1:bad=, "+
+		"sum.example.com has h1:mod=") {
+		t.Errorf("mismatch error = %v", err)
+	}
+
+	// A module the database cannot answer for is not used.
+	if err := db.verify("example.com/unknown", "v1.0.0", false, "h1:any="); !errors.Is(err, ErrChecksumDBUnavailable) {
+		t.Errorf("verify unknown module = %v, want ErrChecksumDBUnavailable", err)
+	}
+}
+
+func TestChecksumDBOps_ReadRemoteTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-done
+	}))
+	t.Cleanup(ts.Close)
+	t.Cleanup(func() { close(done) })
+
+	ops := &checksumDBOps{url: ts.URL, client: ts.Client(), timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+
+	_, err := ops.ReadRemote("/lookup/example.com/m@v1.0.0")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadRemote from a server that never responds = %v, want a deadline error", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ReadRemote took %v", elapsed)
+	}
+}
+
+func TestProxyClient_VerifyDownload(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"go.mod": "module example.com/testmod\n"})
+
+	modSum, err := goModHash("module example.com/testmod\n\ngo 1.21\n")
+	mustf(t, err, "go.mod hash")
+
+	sums := map[string]string{
+		"example.com/testmod@v1.0.0": "example.com/testmod v1.0.0 h1:tampered=\n" +
+			"example.com/testmod v1.0.0/go.mod " + modSum + "\n",
+	}
+
+	proxy, ts := newTestProxy(fakeProxy(zipData))
+	defer ts.Close()
+
+	proxy.sumdb = testChecksumDB(t, "", sums)
+
+	if _, err := proxy.ReadMod(context.Background(), "example.com/testmod", "v1.0.0"); err != nil {
+		t.Errorf("ReadMod: %v", err)
+	}
+
+	if _, err := proxy.DownloadZip(context.Background(), "example.com/testmod", "v1.0.0"); !errors.Is(err,
+		ErrChecksumMismatch) {
+		t.Errorf("DownloadZip = %v, want a checksum mismatch", err)
+	}
+
+	// GONOSUMDB modules are not looked up.
+	proxy.sumdb = testChecksumDB(t, "example.com/testmod", sums)
+
+	if _, err := proxy.DownloadZip(context.Background(), "example.com/testmod", "v1.0.0"); err != nil {
+		t.Errorf("DownloadZip of a GONOSUMDB module: %v", err)
+	}
+}
+
+func TestProxyClient_VerifyCached(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"go.mod": "module example.com/testmod\n"})
+
+	zipSum, err := zipHash(zipData)
+	mustf(t, err, "zip hash")
+
+	disk, err := OpenDiskCache(t.TempDir())
+	mustf(t, err, "open disk cache")
+
+	proxy, ts := newTestProxy(fakeProxy(zipData))
+	defer ts.Close()
+
+	proxy.disk = disk
+	proxy.sumdb = testChecksumDB(t, "", map[string]string{
+		"example.com/testmod@v1.0.0": "example.com/testmod v1.0.0 " + zipSum + "\n",
+	})
+
+	_, err = proxy.DownloadZip(context.Background(), "example.com/testmod", "v1.0.0")
+	mustf(t, err, "download zip")
+
+	// A client reading the disk cache trusts the recorded verification
+	// and does not ask its database, which has no record of the module.
+	cached, ts2 := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %s", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer ts2.Close()
+
+	cached.disk = disk
+	cached.sumdb = testChecksumDB(t, "", nil)
+
+	if _, err := cached.DownloadZip(context.Background(), "example.com/testmod", "v1.0.0"); err != nil {
+		t.Errorf("DownloadZip from disk: %v", err)
+	}
+
+	// A zip cached without a verification is checked like a download.
+	mustf(t, disk.Put("example.com/other", "v1.0.0", "zip", zipData), "cache zip")
+
+	if _, err := cached.DownloadZip(context.Background(), "example.com/other", "v1.0.0"); !errors.Is(err,
+		ErrInvalidZip) {
+		t.Errorf("DownloadZip of a zip for another module = %v, want an invalid zip", err)
+	}
+
+	otherZip := createTestZip(t, "example.com/other@v1.0.0/", map[string]string{"go.mod": "module example.com/other\n"})
+	mustf(t, disk.Put("example.com/other", "v1.0.0", "zip", otherZip), "cache zip")
+
+	if _, err := cached.DownloadZip(context.Background(), "example.com/other", "v1.0.0"); !errors.Is(err,
+		ErrChecksumDBUnavailable) {
+		t.Errorf("DownloadZip of an unverified cached zip = %v, want the database asked", err)
+	}
+}
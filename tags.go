@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// tag is a single named declaration in a Go source file.
+type tag struct {
+	name     string
+	path     string
+	line     int
+	offset   int
+	kind     byte   // ctags kind letter
+	receiver string // receiver type for methods
+}
+
+// Kind letters follow the universal-ctags Go parser.
+const (
+	tagPackage  = 'p'
+	tagFunc     = 'f'
+	tagMethod   = 'm'
+	tagType     = 't'
+	tagConst    = 'c'
+	tagVar      = 'v'
+	tagField    = 'w'
+	tagIfaceFun = 'n'
+)
+
+// collectTags returns the top-level declarations, methods, struct fields
+// and interface methods of the given files.
+func collectTags(fset *token.FileSet, files []*goFile) []tag {
+	var tags []tag
+
+	for _, f := range files {
+		add := func(ident *ast.Ident, kind byte, receiver string) {
+			if ident == nil || ident.Name == "_" {
+				return
+			}
+
+			pos := fset.Position(ident.Pos())
+			tags = append(tags, tag{
+				name:     ident.Name,
+				path:     f.path,
+				line:     pos.Line,
+				offset:   pos.Offset,
+				kind:     kind,
+				receiver: receiver,
+			})
+		}
+
+		add(f.ast.Name, tagPackage, "")
+
+		for _, decl := range f.ast.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if recv := receiverName(d); recv != "" {
+					add(d.Name, tagMethod, recv)
+				} else {
+					add(d.Name, tagFunc, "")
+				}
+			case *ast.GenDecl:
+				collectGenDeclTags(d, add)
+			}
+		}
+	}
+
+	return tags
+}
+
+func collectGenDeclTags(d *ast.GenDecl, add func(*ast.Ident, byte, string)) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			add(s.Name, tagType, "")
+
+			switch t := s.Type.(type) {
+			case *ast.StructType:
+				for _, field := range t.Fields.List {
+					for _, name := range field.Names {
+						add(name, tagField, s.Name.Name)
+					}
+				}
+			case *ast.InterfaceType:
+				for _, method := range t.Methods.List {
+					for _, name := range method.Names {
+						add(name, tagIfaceFun, s.Name.Name)
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			kind := byte(tagVar)
+			if d.Tok == token.CONST {
+				kind = tagConst
+			}
+
+			for _, name := range s.Names {
+				add(name, kind, "")
+			}
+		}
+	}
+}
+
+// formatCtags renders tags in the extended ctags format with line number
+// addresses, sorted by tag name as required by readers that bisect.
+func formatCtags(tags []tag) string {
+	sorted := make([]tag, len(tags))
+	copy(sorted, tags)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].name != sorted[j].name {
+			return sorted[i].name < sorted[j].name
+		}
+
+		if sorted[i].path != sorted[j].path {
+			return sorted[i].path < sorted[j].path
+		}
+
+		return sorted[i].line < sorted[j].line
+	})
+
+	var sb strings.Builder
+
+	sb.WriteString("!_TAG_FILE_FORMAT\t2\t/extended format/\n")
+	sb.WriteString("!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+
+	for _, t := range sorted {
+		fmt.Fprintf(&sb, "%s\t%s\t%d;\"\t%c\tline:%d", t.name, t.path, t.line, t.kind, t.line)
+
+		if t.receiver != "" {
+			fmt.Fprintf(&sb, "\ttype:%s", t.receiver)
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// formatEtags renders tags in the Emacs etags format. Each file gets its
+// own section with the source line up to the tag as the search pattern.
+func formatEtags(files []*goFile, tags []tag) string {
+	byPath := make(map[string][]tag)
+
+	for _, t := range tags {
+		byPath[t.path] = append(byPath[t.path], t)
+	}
+
+	var sb strings.Builder
+
+	for _, f := range files {
+		fileTags := byPath[f.path]
+		if len(fileTags) == 0 {
+			continue
+		}
+
+		sort.SliceStable(fileTags, func(i, j int) bool {
+			return fileTags[i].offset < fileTags[j].offset
+		})
+
+		var section strings.Builder
+
+		for _, t := range fileTags {
+			fmt.Fprintf(&section, "%s\x7f%s\x01%d,%d\n", etagsPattern(f.src, t), t.name, t.line, t.offset)
+		}
+
+		fmt.Fprintf(&sb, "\x0c\n%s,%d\n%s", f.path, section.Len(), section.String())
+	}
+
+	return sb.String()
+}
+
+// etagsPattern returns the text from the start of the tag's line through
+// the end of the tag name.
+func etagsPattern(src string, t tag) string {
+	start := strings.LastIndexByte(src[:t.offset], '\n') + 1
+	end := t.offset + len(t.name)
+
+	if end > len(src) {
+		end = len(src)
+	}
+
+	return src[start:end]
+}
+
+type tagsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Format  string `json:"format,omitempty" jsonschema:"Output format: 'ctags' (default) or 'etags'"`
+}
+
+func handleTags(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input tagsInput,
+) (*mcp.CallToolResult, any, error) {
+	format := strings.ToLower(input.Format)
+	if format != "" && format != "ctags" && format != "etags" {
+		return errorResult(fmt.Sprintf("Unknown tags format %q, expected 'ctags' or 'etags'.", input.Format)), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset, files, err := parseGoFiles(mf, input.Path, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags := collectTags(fset, files)
+
+	if format == "etags" {
+		return textResult(formatEtags(files, tags)), nil, nil
+	}
+
+	return textResult(formatCtags(tags)), nil, nil
+}
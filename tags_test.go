@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const tagsTestSource = `package lib
+
+const Answer = 42
+
+var debug bool
+
+type Client struct {
+	Name string
+}
+
+type Doer interface {
+	Do() error
+}
+
+func New() *Client { return &Client{} }
+
+func (c *Client) Do() error { return nil }
+`
+
+func parseTagsTestModule(t *testing.T) ([]*goFile, []tag) {
+	t.Helper()
+
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"lib/lib.go":          tagsTestSource,
+		"lib/testdata/x.go":   "package broken",
+		"README.md":           "# mod\n",
+		"lib/internal/doc.go": "package internal\n",
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+
+	fset, files, err := parseGoFiles(zipFiles{entry}, "", 0)
+
+	mustf(t, err, "parse go files")
+
+	return files, collectTags(fset, files)
+}
+
+func TestCollectTags(t *testing.T) {
+	files, tags := parseTagsTestModule(t)
+
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2 (testdata and non-Go files skipped)", len(files))
+	}
+
+	want := map[string]byte{
+		"Answer": tagConst,
+		"debug":  tagVar,
+		"Client": tagType,
+		"Name":   tagField,
+		"Doer":   tagType,
+		"New":    tagFunc,
+		"lib":    tagPackage,
+	}
+
+	got := make(map[string]byte)
+
+	for _, tg := range tags {
+		if tg.path == "lib/lib.go" {
+			got[tg.name] = tg.kind
+		}
+	}
+
+	for name, kind := range want {
+		if got[name] != kind {
+			t.Errorf("tag %s: kind %c, want %c", name, got[name], kind)
+		}
+	}
+}
+
+func TestFormatCtags(t *testing.T) {
+	_, tags := parseTagsTestModule(t)
+
+	out := formatCtags(tags)
+
+	if !strings.HasPrefix(out, "!_TAG_FILE_FORMAT\t2\t") {
+		t.Errorf("missing format header: %q", out)
+	}
+
+	if !strings.Contains(out, "New\tlib/lib.go\t15;\"\tf\tline:15\n") {
+		t.Errorf("missing New function tag:\n%s", out)
+	}
+
+	if !strings.Contains(out, "Do\tlib/lib.go\t17;\"\tm\tline:17\ttype:Client\n") {
+		t.Errorf("missing Do method tag with receiver:\n%s", out)
+	}
+
+	var names []string
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if !strings.HasPrefix(line, "!_") {
+			names = append(names, strings.SplitN(line, "\t", 2)[0])
+		}
+	}
+
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Fatalf("tags not sorted: %q before %q", names[i-1], names[i])
+		}
+	}
+}
+
+func TestFormatEtags(t *testing.T) {
+	files, tags := parseTagsTestModule(t)
+
+	out := formatEtags(files, tags)
+
+	if !strings.HasPrefix(out, "\x0c\nlib/internal/doc.go,") {
+		t.Errorf("expected first section for lib/internal/doc.go: %q", out)
+	}
+
+	if !strings.Contains(out, "func New\x7fNew\x0115,") {
+		t.Errorf("missing New entry: %q", out)
+	}
+
+	if !strings.Contains(out, "type Client\x7fClient\x017,") {
+		t.Errorf("missing Client entry: %q", out)
+	}
+}
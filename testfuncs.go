@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of test functions recognized by the go test command.
+const (
+	kindTest      = "test"
+	kindBenchmark = "benchmark"
+	kindFuzz      = "fuzz"
+)
+
+// testFunc is a Test, Benchmark or Fuzz function in a _test.go file.
+type testFunc struct {
+	name string
+	kind string
+	file *goFile
+	line int
+	decl *ast.FuncDecl
+}
+
+// collectTestFuncs returns the test functions declared in the _test.go
+// files among files, in file and declaration order.
+func collectTestFuncs(fset *token.FileSet, files []*goFile) []testFunc {
+	var result []testFunc
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.path, "_test.go") {
+			continue
+		}
+
+		for _, decl := range f.ast.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+
+			if kind := testFuncKind(fn); kind != "" {
+				result = append(result, testFunc{
+					name: fn.Name.Name,
+					kind: kind,
+					file: f,
+					line: fset.Position(fn.Pos()).Line,
+					decl: fn,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// testFuncKind classifies fn using the same rules as go test: a
+// top-level function named Test/Benchmark/Fuzz followed by a non-lowercase
+// character, taking a single *testing.T, *testing.B or *testing.F.
+func testFuncKind(fn *ast.FuncDecl) string {
+	if fn.Recv != nil || fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
+		return ""
+	}
+
+	param := fn.Type.Params.List[0]
+	if len(param.Names) > 1 {
+		return ""
+	}
+
+	star, ok := param.Type.(*ast.StarExpr)
+	if !ok {
+		return ""
+	}
+
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	for _, k := range []struct{ prefix, typ, kind string }{
+		{"Test", "T", kindTest},
+		{"Benchmark", "B", kindBenchmark},
+		{"Fuzz", "F", kindFuzz},
+	} {
+		if sel.Sel.Name == k.typ && hasTestPrefix(fn.Name.Name, k.prefix) {
+			return k.kind
+		}
+	}
+
+	return ""
+}
+
+// hasTestPrefix reports whether name is prefix, or prefix followed by a
+// character that is not a lowercase letter.
+func hasTestPrefix(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	if len(name) == len(prefix) {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+
+	return !unicode.IsLower(r)
+}
+
+type listTestsInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Kind    string `json:"kind,omitempty" jsonschema:"Optional kind filter: 'test', 'benchmark' or 'fuzz'"`
+}
+
+func handleListTests(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input listTestsInput,
+) (*mcp.CallToolResult, any, error) {
+	kind := strings.ToLower(input.Kind)
+	if kind != "" && kind != kindTest && kind != kindBenchmark && kind != kindFuzz {
+		return errorResult(fmt.Sprintf(
+			"Unknown kind %q, expected 'test', 'benchmark' or 'fuzz'.", input.Kind,
+		)), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset, files, err := parseGoFiles(mf, input.Path, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var funcs []testFunc
+
+	for _, tf := range collectTestFuncs(fset, files) {
+		if kind == "" || tf.kind == kind {
+			funcs = append(funcs, tf)
+		}
+	}
+
+	title := fmt.Sprintf("Test functions in %s@%s", input.Module, version)
+
+	return textResult(formatTestFuncs(title, funcs)), nil, nil
+}
+
+// formatTestFuncs lists test functions grouped by package directory.
+func formatTestFuncs(title string, funcs []testFunc) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s (%d functions):\n", title, len(funcs))
+
+	lastDir := ""
+
+	for i, tf := range funcs {
+		if dir := path.Dir(tf.file.path); i == 0 || dir != lastDir {
+			fmt.Fprintf(&sb, "\n%s:\n", dir)
+
+			lastDir = dir
+		}
+
+		fmt.Fprintf(&sb, "  %s\t%s:%d\n", tf.name, tf.file.path, tf.line)
+	}
+
+	return sb.String()
+}
+
+type listBenchmarksInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Name    string `json:"name,omitempty" jsonschema:"Optional benchmark name whose source should be returned"`
+}
+
+func handleListBenchmarks(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input listBenchmarksInput,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset, files, err := parseGoFiles(mf, input.Path, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var benchmarks []testFunc
+
+	for _, tf := range collectTestFuncs(fset, files) {
+		if tf.kind == kindBenchmark && (input.Name == "" || tf.name == input.Name) {
+			benchmarks = append(benchmarks, tf)
+		}
+	}
+
+	if input.Name == "" {
+		title := fmt.Sprintf("Benchmarks in %s@%s", input.Module, version)
+
+		return textResult(formatTestFuncs(title, benchmarks)), nil, nil
+	}
+
+	if len(benchmarks) == 0 {
+		return errorResult(fmt.Sprintf(
+			"No benchmark %q found in %s@%s.", input.Name, input.Module, version,
+		)), nil, nil
+	}
+
+	var sb strings.Builder
+
+	for i, tf := range benchmarks {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+
+		fmt.Fprintf(&sb, "// %s:%d\n%s\n", tf.file.path, tf.line, declSource(fset, tf.file, tf.decl, tf.decl.Doc))
+	}
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testFuncsSource = `package mod
+
+import "testing"
+
+func TestParse(t *testing.T) {}
+
+func TestparseLower(t *testing.T) {}
+
+func Test(t *testing.T) {}
+
+func BenchmarkParse(b *testing.B) {}
+
+func FuzzParse(f *testing.F) {}
+
+func TestWrongParam(b *testing.B) {}
+
+func helper(t *testing.T) {}
+`
+
+func TestCollectTestFuncs(t *testing.T) {
+	data := createTestZip(t, "mod@v1.0.0/", map[string]string{
+		"parse.go":      "package mod\n\nfunc TestNotInTestFile(t *testing.T) {}\n",
+		"parse_test.go": testFuncsSource,
+	})
+
+	entry, err := NewZipCache().Put("mod", "v1.0.0", data)
+
+	mustf(t, err, "put zip in cache")
+
+	fset, files, err := parseGoFiles(zipFiles{entry}, "", 0)
+
+	mustf(t, err, "parse go files")
+
+	funcs := collectTestFuncs(fset, files)
+
+	got := make(map[string]string)
+
+	for _, tf := range funcs {
+		got[tf.name] = tf.kind
+	}
+
+	want := map[string]string{
+		"TestParse":      kindTest,
+		"Test":           kindTest,
+		"BenchmarkParse": kindBenchmark,
+		"FuzzParse":      kindFuzz,
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for name, kind := range want {
+		if got[name] != kind {
+			t.Errorf("%s: kind %q, want %q", name, got[name], kind)
+		}
+	}
+
+	out := formatTestFuncs("Test functions in mod@v1.0.0", funcs)
+	if !strings.Contains(out, "TestParse\tparse_test.go:5") {
+		t.Errorf("expected file:line location in output:\n%s", out)
+	}
+}
@@ -4,52 +4,122 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
+// defaultVersionsLimit is how many of the newest versions
+// gomod_list_versions lists, with their publish dates, by default.
+const defaultVersionsLimit = 100
+
 type listVersionsInput struct {
-	Module string `json:"module" jsonschema:"Go module path, e.g. golang.org/x/tools"`
+	Module  string `json:"module" jsonschema:"Go module path, e.g. golang.org/x/tools"`
+	GitTags bool   `json:"git_tags,omitempty" jsonschema:"Also list semver tags from the origin repository"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"List only this many of the newest versions (default 100)"`
 }
 
 type readModInput struct {
-	Module  string `json:"module" jsonschema:"Go module path"`
-	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Module     string `json:"module" jsonschema:"Go module path"`
+	Version    string `json:"version" jsonschema:"Module version or 'latest'"`
+	Structured bool   `json:"structured,omitempty" jsonschema:"Return the parsed directives as JSON instead of the text"`
 }
 
 type listFilesInput struct {
-	Module  string `json:"module" jsonschema:"Go module path"`
-	Version string `json:"version" jsonschema:"Module version or 'latest'"`
-	Path    string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Module    string `json:"module" jsonschema:"Go module path"`
+	Version   string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path      string `json:"path,omitempty" jsonschema:"Optional path prefix filter"`
+	Generated string `json:"generated,omitempty" jsonschema:"Generated files: include (default), exclude or only"`
+	Handles   bool   `json:"handles,omitempty" jsonschema:"Number the files; pass a number as handle to other tools"`
 }
 
 type readFileInput struct {
-	Module  string `json:"module" jsonschema:"Go module path"`
-	Version string `json:"version" jsonschema:"Module version or 'latest'"`
-	Path    string `json:"path" jsonschema:"File path within the module"`
+	Module       string `json:"module" jsonschema:"Go module path"`
+	Version      string `json:"version" jsonschema:"Module version or 'latest'"`
+	Path         string `json:"path" jsonschema:"File path within the module, or a package directory in comments mode"`
+	Mode         string `json:"mode,omitempty" jsonschema:"full (default), code (no comments), comments (only) or outline"`
+	NoBlankLines bool   `json:"no_blank_lines,omitempty" jsonschema:"In code mode, also drop blank lines"`
+	Symbol       string `json:"symbol,omitempty" jsonschema:"Only this func, Type.Method, type, const or var"`
+	Handle       int    `json:"handle,omitempty" jsonschema:"Handle from gomod_list_files for module, version and path"`
+}
+
+// services bundles the clients, caches and background components that
+// tool handlers depend on.
+type services struct {
+	proxy     *ProxyClient
+	cache     *ZipCache
+	local     *LocalReader
+	modCache  *ModCache
+	watcher   *Watcher
+	scheduler *Scheduler
+	pkgsite   *PkgsiteClient
+	depsDev   *DepsDevClient
+	osv       *OSVClient
+	aliases   ModuleAliases
+	output    *outputFormatter
+	perf      *PerfStats
+	sumDBURL  string
+
+	govulncheck string // govulncheck binary name or path
 }
 
-func registerTools(
-	server *mcp.Server, proxy *ProxyClient, cache *ZipCache,
-	local *LocalReader, modCache *ModCache,
-) {
+func registerTools(server *mcp.Server, svc *services) {
+	proxy, cache, local, modCache := svc.proxy, svc.cache, svc.local, svc.modCache
+	watcher, scheduler := svc.watcher, svc.scheduler
+	pkgsite, depsDev, osv := svc.pkgsite, svc.depsDev, svc.osv
+
+	contexts := newSessionContexts()
+	graphs := newModuleGraphs()
+
+	server.AddReceivingMiddleware(
+		svc.perf.Middleware(),
+		sizeMiddleware(),
+		newModuleResources(server, svc).Middleware(),
+		svc.aliases.Middleware(),
+		contexts.Middleware(),
+		prereleaseMiddleware(proxy.includePrerelease),
+		moduleRootMiddleware(proxy),
+		notesMiddleware(),
+		svc.output.Middleware(),
+	)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "gomod_list_versions",
 		Description: "List available versions of a Go module from the Go module proxy. " +
-			"Returns version list and latest version info.",
+			"Returns versions in semantic version order with their publish dates, the newest 100 unless limit says " +
+			"otherwise, and the latest version info. " +
+			"With git_tags, semver tags in the origin repository that the proxy has not seen yet are included.",
 	}, func(
 		ctx context.Context, _ *mcp.CallToolRequest,
 		input listVersionsInput,
 	) (*mcp.CallToolResult, any, error) {
-		return handleListVersions(ctx, proxy, local, input)
+		return handleListVersions(ctx, proxy, local, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_major_versions",
+		Description: "Find the major versions of a Go module on the proxy: probes the /v2, /v3, ... module paths " +
+			"(.v1, .v2, ... for gopkg.in) and lists each one found with its latest release. " +
+			"Use it to check whether a newer major version than the one imported exists.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input majorVersionsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleMajorVersions(ctx, proxy, input)
 	})
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "gomod_read_mod",
 		Description: "Read the go.mod file of a Go module at a specific version. " +
-			"Use version 'latest' to auto-resolve.",
+			"Use version 'latest' to auto-resolve. With structured, the file is parsed and its module, go, " +
+			"toolchain, godebug, require, exclude, replace, retract, tool and ignore directives are returned " +
+			"as JSON, also as structured content.",
 	}, func(
 		ctx context.Context, _ *mcp.CallToolRequest,
 		input readModInput,
@@ -58,53 +128,767 @@ func registerTools(
 	})
 
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "gomod_list_files",
-		Description: "List files in a Go module's source archive. Optionally filter by path prefix.",
+		Name: "gomod_list_files",
+		Description: "List files in a Go module's source archive with their uncompressed sizes " +
+			"and the total. Optionally filter by path prefix. Generated Go files " +
+			"(\"Code generated ... DO NOT EDIT\") are tagged with their generator " +
+			"and can be excluded or listed alone. With handles, each file gets a number that " +
+			"gomod_read_file and gomod_compare_file accept as handle instead of module, version and path.",
 	}, func(
-		ctx context.Context, _ *mcp.CallToolRequest,
+		ctx context.Context, req *mcp.CallToolRequest,
 		input listFilesInput,
 	) (*mcp.CallToolResult, any, error) {
-		return handleListFiles(ctx, proxy, cache, modCache, input)
+		handle := func(ref fileRef) int { return contexts.Handle(req.Session, ref) }
+
+		return handleListFiles(ctx, proxy, cache, modCache, handle, input)
 	})
 
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "gomod_read_file",
-		Description: "Read a source file from a Go module's archive. Rejects binary files.",
+		Name: "gomod_read_file",
+		Description: "Read a source file from a Go module's archive. Rejects binary files. " +
+			"Mode code returns Go source without comments, which saves tokens when only the logic matters; " +
+			"mode comments returns only the doc comments and comment blocks of a file or package directory. " +
+			"Mode outline returns only the package clause, imports and top-level declarations without " +
+			"function bodies or comments, each with its line range, for large files. " +
+			"With symbol, only that declaration is returned, including its doc comment.",
 	}, func(
 		ctx context.Context, _ *mcp.CallToolRequest,
 		input readFileInput,
 	) (*mcp.CallToolResult, any, error) {
 		return handleReadFile(ctx, proxy, cache, modCache, input)
 	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_grep",
+		Description: "Search the text files of a Go module for a regular expression or, with fixed, " +
+			"a literal string, optionally ignoring case or limited to .go files, like grep -n. " +
+			"Matches are reported as file:line:text with optional context lines (file-line-text), " +
+			"bounded per file and overall (100 matches by default). " +
+			"Use it to find where a symbol or string is used inside a dependency; " +
+			"gomod_project_search searches every dependency of a project at once.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input grepInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleGrep(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_tags",
+		Description: "Generate a ctags or etags tags list for the Go declarations in a module. " +
+			"Optionally filter by path prefix.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input tagsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleTags(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_find_symbol",
+		Description: "Find where a symbol is declared in a Go module: a func, type, const or var by name, or a " +
+			"method, struct field or interface method as Type.Name. Returns the file, line range and full " +
+			"declaration source with its doc comment, for every package that declares it. Test files are " +
+			"skipped unless tests is set. Cheaper than grepping the module.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input findSymbolInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleFindSymbol(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_callers",
+		Description: "Find all callers of a function or method (Type.Method) inside a Go module " +
+			"using SSA call graph analysis, including calls through interfaces. " +
+			"Slower than text search; loads and type-checks the whole module. Dependencies are read from the " +
+			"local module cache only; packages using missing ones count as load errors.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input callersInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleCallers(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_metrics",
+		Description: "Report per-package metrics for a Go module: file counts and sizes, function counts, " +
+			"and average/max cyclomatic complexity. Optionally filter by path prefix and exclude generated files.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input metricsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleMetrics(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_dir_stats",
+		Description: "Count files, Go lines and test lines per directory of a module, " +
+			"as a structural map before deciding where to look. Breaks down the module root, or path, to depth levels.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input dirStatsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleDirStats(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_list_tests",
+		Description: "List Test, Benchmark and Fuzz functions in a Go module, grouped by package, " +
+			"with file and line locations. Optionally filter by path prefix and kind.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input listTestsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleListTests(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_list_benchmarks",
+		Description: "List Benchmark functions in a Go module grouped by package. " +
+			"Pass a benchmark name to get its full source instead.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input listBenchmarksInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleListBenchmarks(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_list_fuzz",
+		Description: "List fuzz targets in a Go module with their f.Add seed counts and " +
+			"seed corpus files under testdata/fuzz. Optionally filter by path prefix.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input listFuzzInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleListFuzz(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_usage_examples",
+		Description: "Find real usages of an exported symbol (Name or Type.Method) within a Go module's own " +
+			"examples, tests and code, returning representative snippets with context.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input usageExamplesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleUsageExamples(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_compare_local",
+		Description: "Compare a local directory (e.g. a fork or replace target) against a published module version, " +
+			"reporting modified, added and removed files.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input compareLocalInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleCompareLocal(ctx, proxy, cache, modCache, local, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_compare_versions",
+		Description: "Compare two versions of a module file by file, by content hash, reporting modified, added " +
+			"and removed files. The first look at how much an upgrade changes; follow up with gomod_compare_file.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input compareVersionsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleCompareVersions(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_compare_file",
+		Description: "Show a unified diff of one file between two versions of a module. " +
+			"Cheaper than comparing whole modules when tracking down a specific behavior change.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input compareFileInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleCompareFile(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_diff",
+		Description: "Show a unified diff of one file between versionA and versionB of a module, " +
+			"as gomod_compare_file does. Use it to review what a dependency upgrade changed in a file.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input diffInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleDiff(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_apidiff",
+		Description: "Report the exported API changes between two versions of a module, split into breaking and " +
+			"compatible ones as apidiff does: removed or changed declarations and methods added to interfaces " +
+			"break, other additions do not. Signatures are compared without type checking.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input apiDiffInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleAPIDiff(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_changelog",
+		Description: "Find a module's changelog (CHANGELOG, CHANGES, HISTORY, release notes) and return the entry " +
+			"for a version, or with from, the entries for every version after it up to that one. " +
+			"Saves listing files and reading a whole changelog for the part that matters.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input changelogInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleChangelog(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_upgrade_report",
+		Description: "Report what upgrading a module from one version to another changes: the go directive, " +
+			"dependencies, exported API, license, retracted versions taken in, vulnerabilities fixed or introduced, " +
+			"and the changelog entries in between. One answer to \"should I take this upgrade?\"",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input upgradeReportInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleUpgradeReport(ctx, proxy, cache, modCache, osv, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_hash",
+		Description: "Get the h1: module hash and go.mod hash of a module version, as recorded in go.sum, " +
+			"and the SHA-256 digest of each file.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input hashInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleHash(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_verify_local",
+		Description: "Check whether a local directory is identical to a published module version " +
+			"by comparing h1: dirhash module hashes. Answers yes or no.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input verifyLocalInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleVerifyLocal(ctx, proxy, cache, modCache, local, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_extract",
+		Description: "Extract a Go module version to a directory (a new temp dir by default) and return its path, " +
+			"so file-based tools like grep or editors can work on the full source tree.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input extractInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleExtract(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_watch",
+		Description: "Watch a Go module for new versions, retractions and deprecations during the session. " +
+			"The server polls the proxy periodically and sends log notifications on changes; " +
+			"use gomod_watch_events to query them. Set remove to stop watching.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input watchInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleWatch(ctx, watcher, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_watch_events",
+		Description: "List watched modules and the changes recorded for them: " +
+			"new versions, retractions and deprecations. " +
+			"Pass since to only get events after a given sequence number.",
+	}, func(
+		_ context.Context, _ *mcp.CallToolRequest,
+		input watchEventsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleWatchEvents(watcher, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_stats",
+		Description: "Show server state: cache sizes, watched modules and the background scheduler's jobs " +
+			"(interval, runs, last and next run, last error).",
+	}, func(
+		_ context.Context, _ *mcp.CallToolRequest,
+		_ statsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleStats(proxy, cache, watcher, scheduler)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_perf_stats",
+		Description: "Show per-tool call counts, p50/p95/max latency and cache hit ratios, and how many calls " +
+			"each backend (modcache, zip cache, disk cache, proxy, APIs) served, to see where time goes.",
+	}, func(
+		_ context.Context, _ *mcp.CallToolRequest,
+		input perfStatsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handlePerfStats(svc.perf, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_proxy_status",
+		Description: "Probe the module proxy and the checksum database and report their latency, " +
+			"together with the error rate and average latency of the server's recent requests to them. " +
+			"Use it to explain slow or failing tool calls.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		_ proxyStatusInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleProxyStatus(ctx, proxy, svc.sumDBURL)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_hygiene",
+		Description: "Check every dependency of a project (directory, go.mod or go.sum) for retracted versions " +
+			"and deprecated modules, with suggested replacement versions.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input hygieneInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleHygiene(ctx, proxy, contexts.Project(req.Session), input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_replacements",
+		Description: "Suggest actively maintained forks or successors for a deprecated or abandoned module, " +
+			"using its Deprecated notice, newer major versions, pkg.go.dev search and deps.dev repository data. " +
+			"Each suggestion shows its latest version and release date.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input replacementsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleReplacements(ctx, proxy, pkgsite, depsDev, local, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_resolve_import",
+		Description: "Resolve a package import path, such as a vanity URL, to the module that provides it and its " +
+			"repository: the proxy is asked for the path and its parents, longest first, as go get does, and the " +
+			"path's ?go-get=1 page for its go-import meta tag.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input resolveImportInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleResolveImport(ctx, proxy, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_alternatives",
+		Description: "Find comparable alternative modules for a module path or a capability description " +
+			"(e.g. \"yaml parser\"), showing latest release, license, stars, importer and dependent counts " +
+			"side by side. Results are ranked by a score combining pkg.go.dev relevance with those signals.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input alternativesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleAlternatives(ctx, proxy, pkgsite, depsDev, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_dependents",
+		Description: "Report who uses a Go module version and how its repository is kept: the dependent package " +
+			"counts of deps.dev (direct and indirect), the imported-by count of pkg.go.dev, the source " +
+			"repository's stars, forks and open issues, and its OpenSSF Scorecard with every check. A " +
+			"popularity and maintenance signal when choosing between libraries.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input dependentsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleDependents(ctx, proxy, pkgsite, depsDev, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_bind_project",
+		Description: "Bind the session to a local project directory (containing go.mod/go.sum). " +
+			"Project-wide tools such as gomod_hygiene and gomod_osv_scan use it when no project is given. " +
+			"Call without dir to show the binding, or with unbind to clear it.",
+	}, func(
+		_ context.Context, req *mcp.CallToolRequest,
+		input bindProjectInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleBindProject(contexts.BindProject(req.Session), input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_set_context",
+		Description: "Set the session's default module, version and package. " +
+			"Later calls may omit those arguments and get these values; 'latest' is resolved once, when set. " +
+			"Call without module to clear the context.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input setContextInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleSetContext(ctx, proxy, contexts, req.Session, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "gomod_get_context",
+		Description: "Show the session's default module, version and package set by gomod_set_context.",
+	}, func(
+		_ context.Context, req *mcp.CallToolRequest,
+		_ getContextInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleGetContext(contexts, req.Session)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_aliases",
+		Description: "List the configured module aliases. " +
+			"Any tool's module argument accepts an alias, alone or as the first element of a longer path.",
+	}, func(
+		_ context.Context, _ *mcp.CallToolRequest,
+		_ listAliasesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleListAliases(svc.aliases)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_project_graph",
+		Description: "Load a project's full module graph, as go mod graph prints it, and query it: 'summary'; " +
+			"'chain', the shortest require chain to a module or module@version, like go mod why -m; " +
+			"'requirers', every module version requiring it; 'majors', modules selected at several major " +
+			"versions; 'forces', the requirements that set a module's selected version; 'conflicts', duplicate " +
+			"major versions and replace or exclude directives with surprising effects, each with the require " +
+			"chains involved; 'edges', every requirement, optionally resolved to the selected versions; " +
+			"'build_list', the versions minimal version selection picks. " +
+			"Set root to a module@version to graph what depending on it pulls in instead of a project. " +
+			"The graph is kept between calls until go.mod changes.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input projectGraphInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleProjectGraph(ctx, proxy, contexts.Project(req.Session), graphs, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_project_search",
+		Description: "Find which of a project's dependencies declare a symbol (Name or Type.Method) or contain " +
+			"text matching a pattern, searching every module version in its go.sum (or go.mod, or the bound " +
+			"project) instead of one module at a time. Matches are grouped by module version.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input projectSearchInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleProjectSearch(ctx, proxy, cache, modCache, contexts.Project(req.Session), input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_osv_scan",
+		Description: "Scan every module version in a project's go.sum (or go.mod, or the bound project) " +
+			"against the OSV vulnerability database in one batched query, or with module, one module version. " +
+			"Returns a consolidated report grouped by severity, with fixed versions and the affected symbols; " +
+			"for one module, also the lowest version fixing them all.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input osvScanInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleOSVScan(ctx, proxy, osv, contexts.Project(req.Session), input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_govulncheck",
+		Description: "Run govulncheck on a local project (or the bound project) and report only vulnerabilities " +
+			"whose affected functions are reachable from its code, with call traces. " +
+			"Requires the govulncheck binary; set all to include imported-but-unused findings.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input govulncheckInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleGovulncheck(ctx, svc.govulncheck, proxy.offline, contexts.Project(req.Session), input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_licenses",
+		Description: "Detect the licenses of a Go module version. Every LICENSE/COPYING file is matched against " +
+			"known license texts; returns an SPDX expression for the module (e.g. \"Apache-2.0 OR MIT\" for dual " +
+			"licensing) plus per-file coverage and confidence percentages, and with text, the license texts.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input licensesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleLicenses(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_sbom",
+		Description: "Write a CycloneDX 1.5 or SPDX 2.3 JSON SBOM for a project, or with root for a module@version: " +
+			"the build list from the module graph, as minimal version selection resolves it, with package URLs, " +
+			"the requirements between modules and each module's detected license.",
+	}, func(
+		ctx context.Context, req *mcp.CallToolRequest,
+		input sbomInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleSBOM(ctx, proxy, cache, modCache, contexts.Project(req.Session), graphs, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_proto_map",
+		Description: "Map a Go module's .proto files to their generated Go code: for each .proto, its package, go_package, " +
+			"messages, enums and gRPC services, paired with the generated .pb.go/_grpc.pb.go files and the exported " +
+			"Go types they declare. Optionally filter by path prefix.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input protoMapInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleProtoMap(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_readme",
+		Description: "Return the README of a module version, or of a directory in it, whatever its name: " +
+			"README.md is preferred over README.rst, README and the like. Set max_bytes to read only the start.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input readmeInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleReadme(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_docs",
+		Description: "Index the documentation shipped in a Go module beyond the README: markdown, " +
+			"reStructuredText and AsciiDoc files anywhere in the tree, text files under docs/, and doc.go " +
+			"package comments, each with its title or first heading. Optionally filter by path prefix.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input docsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleDocs(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_packages",
+		Description: "List every package of a Go module with its directory, package name and the one-line synopsis " +
+			"of its package comment: a compact table of contents, even for very large modules. " +
+			"Optionally filter by directory prefix.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input packagesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handlePackages(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_imports",
+		Description: "List the imports of a package (or one .go file) in a Go module, grouped into the standard " +
+			"library, the module's own packages and other modules, each external import with the go.mod " +
+			"requirement that provides it. Answers what a package depends on without reading its files. " +
+			"Pass tests to add the imports only its _test.go files use.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input importsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleImports(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_doc",
+		Description: "Render the documentation of a package in a Go module like go doc -all: package comment, " +
+			"then exported constants, variables, functions and types with their methods, each with its doc " +
+			"comment, and the names of the examples (gomod_examples returns their code). Pass symbol " +
+			"(Name or Type.Method) for one declaration. Far cheaper than reading the package's files.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input docInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleDoc(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_examples",
+		Description: "Return the Example functions of a package in a Go module, from its _test.go files: the " +
+			"code of each with its doc comment and expected // Output:, and its file and line. Pass symbol " +
+			"(Name or Type.Method) for the examples of one declaration; a type includes its constructors and " +
+			"methods. Examples are the package's own usage documentation.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input examplesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleExamples(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_api",
+		Description: "List the exported API surface of a Go module's packages, or of one package: every constant, " +
+			"variable, function signature and type, with each type's fields, embedded types and method set " +
+			"indented under it, one declaration per line. Internal packages and commands are left out.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input apiInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleAPI(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_specs",
+		Description: "List machine-readable spec artifacts in a Go module: OpenAPI/Swagger documents, " +
+			"JSON Schemas, GraphQL schemas and SQL files (migrations separately), with sizes, versions, titles " +
+			"and created tables. Optionally filter by path prefix.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input specsInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleSpecs(ctx, proxy, cache, modCache, input)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "gomod_nested_modules",
+		Description: "Discover the modules of a multi-module repository from its repo-root module or VCS URL: " +
+			"nested modules found from tag prefixes (e.g. sub/v1.2.3) and local replace directives, with their " +
+			"latest tag, tag series and whether the proxy serves them. Use it to request files from the right " +
+			"module of a monorepo, or pass import to find the module that provides a package.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest,
+		input nestedModulesInput,
+	) (*mcp.CallToolResult, any, error) {
+		return handleNestedModules(ctx, proxy, input)
+	})
 }
 
 func handleListVersions(
-	ctx context.Context, proxy *ProxyClient,
-	local *LocalReader, input listVersionsInput,
+	ctx context.Context, proxy *ProxyClient, local *LocalReader,
+	modCache *ModCache, input listVersionsInput,
 ) (*mcp.CallToolResult, any, error) {
 	versions, err := proxy.ListVersions(ctx, input.Module)
 	if err != nil {
 		if errors.Is(err, ErrModuleNotFound) {
+			if input.GitTags {
+				if tagged, url, err := tagVersions(ctx, proxy, input.Module); err == nil && len(tagged) > 0 {
+					return textResult(fmt.Sprintf("%s is not on the Go module proxy. Versions tagged in %s:\n%s\n",
+						input.Module, url, strings.Join(tagged, "\n"))), nil, nil
+				}
+			}
+
 			return notFoundResult(input.Module, local), nil, nil
 		}
 
+		if cached := modCache.CachedVersions(input.Module); errors.Is(err, ErrOffline) && len(cached) > 0 {
+			return textResult(fmt.Sprintf("Versions of %s in the local module cache (offline):\n%s\n",
+				input.Module, strings.Join(cached, "\n"))), nil, nil
+		}
+
 		return nil, nil, err
 	}
 
-	latest, _ := proxy.Latest(ctx, input.Module)
+	latest, latestErr := proxy.Latest(ctx, input.Module)
 
 	var sb strings.Builder
 
 	fmt.Fprintf(&sb, "Versions of %s:\n", input.Module)
 
+	onProxy := make(map[string]bool, len(versions))
 	for _, v := range versions {
-		sb.WriteString(v)
+		onProxy[v] = true
+	}
+
+	var tagNote string
+
+	if input.GitTags {
+		tagged, url, err := tagVersions(ctx, proxy, input.Module)
+		if err != nil {
+			tagNote = fmt.Sprintf("Git tags unavailable: %v\n", err)
+		} else {
+			added := 0
+
+			for _, v := range tagged {
+				if !onProxy[v] {
+					versions = append(versions, v)
+					added++
+				}
+			}
+
+			semver.Sort(versions)
+
+			tagNote = fmt.Sprintf("Git tags: %d versions in %s, %d not on the proxy\n", len(tagged), url, added)
+		}
+	}
+
+	limit := defaultVersionsLimit
+	if input.Limit > 0 {
+		limit = input.Limit
+	}
+
+	if older := len(versions) - limit; older > 0 {
+		fmt.Fprintf(&sb, "[... %d older versions; raise limit to list them]\n", older)
+		noteTruncated(ctx)
+
+		versions = versions[older:]
+	}
+
+	published := make([]time.Time, len(versions))
+
+	width := 0
+
+	// Info caches the .info of each version, so only the first listing of
+	// a version asks the proxy for its publish time.
+	parallelEach(versions, func(i int, v string) {
+		if !onProxy[v] {
+			return
+		}
+
+		if info, err := proxy.Info(ctx, input.Module, v); err == nil {
+			published[i] = info.Time
+		}
+	})
+
+	for _, v := range versions {
+		width = max(width, len(v))
+	}
+
+	for i, v := range versions {
+		line := v
+
+		switch {
+		case !onProxy[v]:
+			line = fmt.Sprintf("%-*s  (git tag only, not on the proxy yet)", width, v)
+		case !published[i].IsZero():
+			line = fmt.Sprintf("%-*s  %s", width, v, published[i].Format(time.DateOnly))
+		}
+
+		sb.WriteString(line + "\n")
+	}
+
+	if latestErr == nil {
+		fmt.Fprintf(&sb, "\nLatest: %s", latest.Version)
+
+		if !latest.Time.IsZero() {
+			fmt.Fprintf(&sb, " (%s)", latest.Time.Format(time.RFC3339))
+		}
+
 		sb.WriteByte('\n')
+
+		if o := latest.Origin; o != nil {
+			fmt.Fprintf(&sb, "Origin: %s %s", o.VCS, o.URL)
+
+			if o.Subdir != "" {
+				fmt.Fprintf(&sb, " (subdir %s)", o.Subdir)
+			}
+
+			if o.Ref != "" {
+				fmt.Fprintf(&sb, " %s", o.Ref)
+			}
+
+			if o.Hash != "" {
+				fmt.Fprintf(&sb, " %s", o.Hash)
+			}
+
+			sb.WriteByte('\n')
+		}
 	}
 
-	if latest != "" {
-		sb.WriteString("\nLatest info:\n")
-		sb.WriteString(latest)
+	if tagNote != "" {
+		sb.WriteByte('\n')
+		sb.WriteString(tagNote)
 	}
 
 	return textResult(sb.String()), nil, nil
@@ -119,48 +903,104 @@ func handleReadMod(
 		return nil, nil, err
 	}
 
-	if modCache.HasModule(input.Module, version) {
-		content, err := modCache.ReadFile(input.Module, version, "go.mod")
-		if err == nil {
-			return textResult(content), nil, nil
-		}
-	}
-
-	content, err := proxy.ReadMod(ctx, input.Module, version)
+	content, err := readGoMod(ctx, proxy, modCache, input.Module, version)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if input.Structured {
+		return structuredGoMod(content)
+	}
+
 	return textResult(content), nil, nil
 }
 
+// readGoMod returns the go.mod file of a module version from the module
+// cache or else the proxy.
+func readGoMod(ctx context.Context, proxy *ProxyClient, modCache *ModCache, mod, version string) (string, error) {
+	if modCache.HasModule(mod, version) {
+		content, err := modCache.ReadFile(mod, version, "go.mod")
+		if err == nil {
+			noteBackend(ctx, backendModCache)
+
+			return content, nil
+		}
+	}
+
+	return proxy.ReadMod(ctx, mod, version)
+}
+
 func handleListFiles(
 	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
-	modCache *ModCache, input listFilesInput,
+	modCache *ModCache, handle func(fileRef) int, input listFilesInput,
 ) (*mcp.CallToolResult, any, error) {
+	if err := checkGeneratedFilter(input.Generated); err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
 	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var files []string
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if modCache.HasModule(input.Module, version) {
-		files, err = modCache.ListFiles(input.Module, version, input.Path)
-		if err != nil {
-			return nil, nil, err
-		}
-	} else {
-		entry, err := getOrDownload(ctx, proxy, cache, input.Module, version)
-		if err != nil {
-			return nil, nil, err
-		}
+	files, err := mf.ListFiles(input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		files = entry.ListFiles(input.Path)
+	if len(files) == 0 && input.Path != "" {
+		dir := strings.TrimSuffix(input.Path, "/") + "/"
+		if hint := nestedModuleHint(ctx, proxy, input.Module, version, dir); hint != "" {
+			return errorResult(hint), nil, nil
+		}
 	}
 
 	sort.Strings(files)
 
+	var (
+		lines []string
+		total int64
+	)
+
+	for _, f := range files {
+		var (
+			generated bool
+			generator string
+		)
+
+		if strings.HasSuffix(f, ".go") {
+			if header, err := readGoHeader(mf, f); err == nil {
+				generated, generator = generatedBy(f, header)
+			}
+		}
+
+		if !keepGenerated(input.Generated, generated) {
+			continue
+		}
+
+		line := f
+
+		if input.Handles {
+			line = fmt.Sprintf("[%d] %s", handle(fileRef{input.Module, version, f}), f)
+		}
+
+		if size, err := mf.Size(f); err == nil {
+			line += fmt.Sprintf(" (%d bytes)", size)
+			total += size
+		}
+
+		if generated {
+			line += generatedTag(generator)
+		}
+
+		lines = append(lines, line)
+	}
+
 	var sb strings.Builder
 
 	fmt.Fprintf(&sb, "Files in %s@%s", input.Module, version)
@@ -169,10 +1009,10 @@ func handleListFiles(
 		fmt.Fprintf(&sb, " (prefix: %s)", input.Path)
 	}
 
-	fmt.Fprintf(&sb, " (%d files):\n", len(files))
+	fmt.Fprintf(&sb, " (%d files, %d bytes uncompressed):\n", len(lines), total)
 
-	for _, f := range files {
-		sb.WriteString(f)
+	for _, line := range lines {
+		sb.WriteString(line)
 		sb.WriteByte('\n')
 	}
 
@@ -188,33 +1028,76 @@ func handleReadFile(
 		return nil, nil, err
 	}
 
-	if modCache.HasModule(input.Module, version) {
-		content, err := modCache.ReadFile(input.Module, version, input.Path)
-		if err != nil {
-			return nil, nil, err
-		}
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		return textResult(content), nil, nil
+	if input.Mode == readModeComments {
+		return readComments(mf, input.Path)
 	}
 
-	entry, err := getOrDownload(ctx, proxy, cache, input.Module, version)
+	content, err := mf.ReadFile(input.Path)
 	if err != nil {
+		if files, _ := mf.ListFiles(input.Path); !slices.Contains(files, input.Path) {
+			if hint := nestedModuleHint(ctx, proxy, input.Module, version, input.Path); hint != "" {
+				return errorResult(hint), nil, nil
+			}
+		}
+
 		return nil, nil, err
 	}
 
-	content, err := entry.ReadFile(input.Path)
-	if err != nil {
-		return nil, nil, err
+	noteFullSize(ctx, len(content))
+
+	if input.Symbol != "" {
+		if input.Mode != "" && input.Mode != readModeFull {
+			return errorResult(fmt.Sprintf("Symbol cannot be combined with mode %q.", input.Mode)), nil, nil
+		}
+
+		if path.Ext(input.Path) != ".go" {
+			return errorResult("Symbol needs a .go file."), nil, nil
+		}
+
+		decl, err := symbolSource(input.Path, content, input.Symbol)
+		if err != nil {
+			return errorResult(err.Error()), nil, nil
+		}
+
+		return textResult(decl), nil, nil
+	}
+
+	switch input.Mode {
+	case "", readModeFull:
+	case readModeCode:
+		if path.Ext(input.Path) != ".go" {
+			return errorResult(fmt.Sprintf("Mode %q needs a .go file.", input.Mode)), nil, nil
+		}
+
+		if content, err = stripComments(input.Path, content, input.NoBlankLines); err != nil {
+			return errorResult(err.Error()), nil, nil
+		}
+	case readModeOutline:
+		if path.Ext(input.Path) != ".go" {
+			return errorResult(fmt.Sprintf("Mode %q needs a .go file.", input.Mode)), nil, nil
+		}
+
+		if content, err = outlineSource(input.Path, content); err != nil {
+			return errorResult(err.Error()), nil, nil
+		}
+	default:
+		return errorResult(fmt.Sprintf("Unknown mode %q; use %s, %s, %s or %s.",
+			input.Mode, readModeFull, readModeCode, readModeComments, readModeOutline)), nil, nil
 	}
 
 	return textResult(content), nil, nil
 }
 
 func resolveVersion(
-	ctx context.Context, proxy *ProxyClient, module, version string,
+	ctx context.Context, proxy *ProxyClient, mod, version string,
 ) (string, error) {
 	if strings.EqualFold(version, "latest") {
-		resolved, err := proxy.ResolveLatest(ctx, module)
+		resolved, err := proxy.ResolveLatest(ctx, mod)
 		if err != nil {
 			return "", fmt.Errorf("resolve latest version: %w", err)
 		}
@@ -222,6 +1105,25 @@ func resolveVersion(
 		return resolved, nil
 	}
 
+	if q, ok := parseVersionQuery(version); ok {
+		return resolveVersionQuery(ctx, proxy, mod, version, q)
+	}
+
+	if !semver.IsValid(version) && isRevision(version) {
+		return resolveRevision(ctx, proxy, mod, version)
+	}
+
+	if !semver.IsValid(version) {
+		return "", fmt.Errorf("invalid version %q: want a semantic version such as v1.2.3, a query such as "+
+			"v1.2 or <v2.0.0, a branch or commit, or \"latest\"", version)
+	}
+
+	// The proxy only serves canonical versions: v1.2 is v1.2.0, and build
+	// metadata other than +incompatible is not part of a module version.
+	if canonical := module.CanonicalVersion(version); canonical != version {
+		return "", fmt.Errorf("version %q is not canonical; use %q", version, canonical)
+	}
+
 	return version, nil
 }
 
@@ -230,6 +1132,8 @@ func getOrDownload(
 	cache *ZipCache, module, version string,
 ) (*ZipEntry, error) {
 	if entry := cache.Get(module, version); entry != nil {
+		noteBackend(ctx, backendZipCache)
+
 		return entry, nil
 	}
 
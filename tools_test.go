@@ -4,13 +4,17 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -18,6 +22,7 @@ import (
 // testEnv sets up a full MCP server with a fake proxy and returns a connected
 // client session for calling tools.
 type testEnv struct {
+	server      *mcp.Server
 	session     *mcp.ClientSession
 	proxyHTTP   *httptest.Server
 	localDir    string
@@ -29,7 +34,9 @@ func (e *testEnv) close() {
 	e.proxyHTTP.Close()
 }
 
-func setupTestEnv(t *testing.T, handler http.Handler) *testEnv {
+// setupTestEnv starts a server backed by handler as the proxy and the
+// metadata APIs. Options adjust the services before tools are registered.
+func setupTestEnv(t *testing.T, handler http.Handler, opts ...func(*services)) *testEnv {
 	t.Helper()
 
 	ts := httptest.NewServer(handler)
@@ -43,9 +50,32 @@ func setupTestEnv(t *testing.T, handler http.Handler) *testEnv {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "claude-gomod-test",
 		Version: "0.0.1",
-	}, nil)
+	}, &mcp.ServerOptions{HasResources: true})
+
+	watcher := NewWatcher(proxy, time.Hour)
+	scheduler := NewScheduler(0)
+
+	watcher.Schedule(scheduler)
+
+	svc := &services{
+		proxy:     proxy,
+		cache:     cache,
+		local:     local,
+		modCache:  modCache,
+		watcher:   watcher,
+		scheduler: scheduler,
+		pkgsite:   &PkgsiteClient{apiClient{baseURL: ts.URL, client: ts.Client()}},
+		depsDev:   &DepsDevClient{apiClient{baseURL: ts.URL, client: ts.Client()}},
+		osv:       &OSVClient{apiClient{baseURL: ts.URL, client: ts.Client()}},
+		output:    newOutputFormatter(outputPlain),
+		perf:      NewPerfStats(),
+	}
+
+	for _, opt := range opts {
+		opt(svc)
+	}
 
-	registerTools(server, proxy, cache, local, modCache)
+	registerTools(server, svc)
 
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "test-client",
@@ -68,6 +98,7 @@ func setupTestEnv(t *testing.T, handler http.Handler) *testEnv {
 	}
 
 	return &testEnv{
+		server:      server,
 		session:     session,
 		proxyHTTP:   ts,
 		localDir:    localDir,
@@ -75,6 +106,28 @@ func setupTestEnv(t *testing.T, handler http.Handler) *testEnv {
 	}
 }
 
+// otherSession connects another client to the server and returns the
+// environment seeing it.
+func (e *testEnv) otherSession(t *testing.T) *testEnv {
+	t.Helper()
+
+	ctx := context.Background()
+	t1, t2 := mcp.NewInMemoryTransports()
+
+	_, err := e.server.Connect(ctx, t1, nil)
+	mustf(t, err, "connect server")
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil).Connect(ctx, t2, nil)
+	mustf(t, err, "connect client")
+
+	t.Cleanup(func() { _ = session.Close() })
+
+	other := *e
+	other.session = session
+
+	return &other
+}
+
 func callTool(
 	t *testing.T, env *testEnv, name string, args map[string]any,
 ) *mcp.CallToolResult {
@@ -112,7 +165,7 @@ func fakeProxy(zipData []byte) http.Handler {
 		switch r.URL.Path {
 		case "/example.com/testmod/@v/list":
 			_, _ = w.Write([]byte("v0.1.0\nv0.2.0\nv1.0.0\n"))
-		case "/example.com/testmod/@latest":
+		case "/example.com/testmod/@latest", "/example.com/testmod/@v/v1.0.0.info":
 			_, _ = w.Write([]byte(`{"Version":"v1.0.0","Time":"2025-06-01T00:00:00Z"}`))
 		case "/example.com/testmod/@v/v1.0.0.mod":
 			_, _ = w.Write([]byte("module example.com/testmod\n\ngo 1.21\n"))
@@ -125,7 +178,17 @@ func fakeProxy(zipData []byte) http.Handler {
 }
 
 func TestToolsListVersions(t *testing.T) {
-	env := setupTestEnv(t, fakeProxy(nil))
+	var infos atomic.Int32
+
+	proxy := fakeProxy(nil)
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/example.com/testmod/@v/v1.0.0.info" {
+			infos.Add(1)
+		}
+
+		proxy.ServeHTTP(w, r)
+	}))
 	defer env.close()
 
 	result := callTool(t, env, "gomod_list_versions", map[string]any{
@@ -134,16 +197,24 @@ func TestToolsListVersions(t *testing.T) {
 
 	text := resultText(t, result)
 
-	if !strings.Contains(text, "v0.1.0") {
-		t.Error("expected v0.1.0 in output")
+	// Only v1.0.0 has a .info, so only it has a publish date.
+	if !strings.Contains(text, "Versions of example.com/testmod:\nv0.1.0\nv0.2.0\nv1.0.0  2025-06-01\n") {
+		t.Errorf("expected versions with dates: %s", text)
 	}
 
-	if !strings.Contains(text, "v1.0.0") {
-		t.Error("expected v1.0.0 in output")
+	if !strings.Contains(text, "Latest: v1.0.0 (2025-06-01T00:00:00Z)") {
+		t.Errorf("expected latest info in output: %s", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_list_versions", map[string]any{
+		"module": "example.com/testmod", "limit": 2,
+	}))
+	if !strings.Contains(text, "[... 1 older versions; raise limit to list them]\nv0.2.0\nv1.0.0  2025-06-01\n") {
+		t.Errorf("expected the 2 newest versions: %s", text)
 	}
 
-	if !strings.Contains(text, "Latest") {
-		t.Error("expected latest info in output")
+	if n := infos.Load(); n != 1 {
+		t.Errorf("v1.0.0.info requested %d times, want once across listings", n)
 	}
 }
 
@@ -191,6 +262,197 @@ func TestToolsListVersions_NotFound_WithLocalFallback(t *testing.T) {
 	}
 }
 
+func TestToolsListVersions_OfflineModCache(t *testing.T) {
+	dir := t.TempDir()
+	populateModCache(t, dir, "example.com/Mod", "v1.0.0", map[string]string{"go.mod": "module example.com/Mod\n"})
+
+	proxy := &ProxyClient{offline: true}
+
+	result, _, err := handleListVersions(context.Background(), proxy, NewLocalReader(t.TempDir()), NewModCache(dir),
+		listVersionsInput{Module: "example.com/Mod"})
+	mustf(t, err, "list versions")
+
+	if text := resultText(t, result); !strings.Contains(text, "local module cache (offline):\nv1.0.0\n") {
+		t.Errorf("expected cached versions: %s", text)
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	for version, wantErr := range map[string]bool{
+		"v1.2.3":                             false,
+		"v2.0.0+incompatible":                false,
+		"v0.0.0-20240101000000-abcdefabcdef": false,
+		"v1.2.3-rc.1":                        false,
+		"1.2.3":                              true,
+		"v1.2.x":                             true,
+		"v1.2.3+build.5":                     true,
+		"v1.2..3":                            true,
+	} {
+		_, err := resolveVersion(context.Background(), nil, "example.com/mod", version)
+		if (err != nil) != wantErr {
+			t.Errorf("resolveVersion(%q) error = %v, want error %v", version, err, wantErr)
+		}
+	}
+}
+
+func TestToolsVersionQuery(t *testing.T) {
+	proxy := fakeProxy(nil)
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v, ok := strings.CutPrefix(r.URL.Path, "/example.com/testmod/@v/"); ok && strings.HasSuffix(v, ".mod") {
+			_, _ = w.Write([]byte("module example.com/testmod // " + strings.TrimSuffix(v, ".mod") + "\n"))
+
+			return
+		}
+
+		proxy.ServeHTTP(w, r)
+	}))
+	defer env.close()
+
+	for query, want := range map[string]string{"v0": "v0.2.0", "<v0.2.0": "v0.1.0", ">v0.1.0": "v0.2.0"} {
+		text := resultText(t, callTool(t, env, "gomod_read_mod", map[string]any{
+			"module": "example.com/testmod", "version": query,
+		}))
+		if text != "module example.com/testmod // "+want+"\n" {
+			t.Errorf("version %q: got %q, want %s", query, text, want)
+		}
+	}
+
+	result := callTool(t, env, "gomod_read_mod", map[string]any{"module": "example.com/testmod", "version": "v3"})
+	text := resultText(t, result)
+	if !result.IsError || !strings.Contains(text, `no version of example.com/testmod matches "v3"`) {
+		t.Errorf("expected no match for v3: %s", text)
+	}
+}
+
+func TestToolsIncludePrerelease(t *testing.T) {
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch v, _ := strings.CutPrefix(r.URL.Path, "/example.com/testmod/"); {
+		case v == "@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.1.0-rc.1"}`))
+		case v == "@v/list":
+			_, _ = w.Write([]byte("v1.0.0\nv1.1.0-rc.1\n"))
+		case strings.HasSuffix(v, ".mod"):
+			_, _ = w.Write([]byte("module example.com/testmod // " + strings.TrimSuffix(v[len("@v/"):], ".mod") + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "latest"}
+
+	result := callTool(t, env, "gomod_read_mod", args)
+	if text := resultText(t, result); text != "module example.com/testmod // v1.0.0\n" || len(result.Content) != 2 {
+		t.Errorf("latest = %q, want v1.0.0 with a note", text)
+	}
+
+	args["include_prerelease"] = true
+	text := resultText(t, callTool(t, env, "gomod_read_mod", args))
+	if text != "module example.com/testmod // v1.1.0-rc.1\n" {
+		t.Errorf("latest with prereleases = %q", text)
+	}
+
+	tools, err := env.session.ListTools(context.Background(), nil)
+	mustf(t, err, "list tools")
+
+	for _, tool := range tools.Tools {
+		schema, _ := tool.InputSchema.(map[string]any)
+		props, _ := schema["properties"].(map[string]any)
+
+		if (props["version"] != nil) != (props["include_prerelease"] != nil) && props["from"] == nil {
+			t.Errorf("%s: include_prerelease listed %v, version %v", tool.Name, props["include_prerelease"] != nil,
+				props["version"] != nil)
+		}
+	}
+}
+
+func TestToolsMajorVersions(t *testing.T) {
+	env := setupTestEnv(t, majorsProxy(map[string]string{
+		"example.com/mod":    "v1.0.0\nv2.0.0+incompatible\nv1.1.0\n",
+		"example.com/mod/v3": "v3.0.0\nv3.1.0\n",
+	}))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_major_versions", map[string]any{"module": "example.com/mod"}))
+
+	for _, want := range []string{
+		"Major versions of example.com/mod on the proxy (2 found, probed through v8):\n",
+		"  example.com/mod     v1.1.0  2025-03-01  3 versions\n",
+		"  example.com/mod/v3  v3.1.0  2025-03-01  2 versions\n",
+		"example.com/mod also has +incompatible versions of v2",
+		"The newest major version is example.com/mod/v3 (latest v3.1.0)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	result := callTool(t, env, "gomod_major_versions", map[string]any{"module": "example.com/other"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "No major version of example.com/other") {
+		t.Errorf("expected no major versions: %s", resultText(t, result))
+	}
+}
+
+func TestToolsVerifyGoSum(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"main.go": "package main\n"})
+	goSum := filepath.Join(t.TempDir(), "go.sum")
+	mustf(t, os.WriteFile(goSum, []byte("example.com/testmod v1.0.0 h1:tampered=\n"), 0o600), "write go.sum")
+
+	env := setupTestEnv(t, fakeProxy(zipData), func(svc *services) {
+		var err error
+
+		svc.proxy.sums, err = loadGoSums(goSum)
+		mustf(t, err, "load go.sum")
+	})
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0"}
+
+	result := callTool(t, env, "gomod_list_files", args)
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, "zip: checksum mismatch") {
+		t.Errorf("expected a checksum mismatch: %s", text)
+	}
+
+	// The go.mod file has no go.sum line and is not checked.
+	if result := callTool(t, env, "gomod_read_mod", args); result.IsError {
+		t.Errorf("read_mod: %s", resultText(t, result))
+	}
+}
+
+func TestToolsInvalidZip(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"../escape.go": "package main\n"})
+
+	var cache *ZipCache
+
+	env := setupTestEnv(t, fakeProxy(zipData), func(svc *services) { cache = svc.cache })
+	defer env.close()
+
+	result := callTool(t, env, "gomod_list_files", map[string]any{"module": "example.com/testmod", "version": "v1.0.0"})
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, "invalid module zip") {
+		t.Errorf("expected an invalid zip error: %s", text)
+	}
+
+	if cache.Len() != 0 {
+		t.Error("the invalid zip was cached")
+	}
+}
+
+func TestToolsChecksumDB(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"main.go": "package main\n"})
+
+	// The database has no record of the module, so downloads are refused.
+	env := setupTestEnv(t, fakeProxy(zipData), func(svc *services) {
+		svc.proxy.sumdb = testChecksumDB(t, "", nil)
+	})
+	defer env.close()
+
+	result := callTool(t, env, "gomod_read_mod", map[string]any{"module": "example.com/testmod", "version": "v1.0.0"})
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, ErrChecksumDBUnavailable.Error()) {
+		t.Errorf("read_mod = %q, want the checksum database unavailable", text)
+	}
+}
+
 func TestToolsReadMod(t *testing.T) {
 	env := setupTestEnv(t, fakeProxy(nil))
 	defer env.close()
@@ -206,6 +468,26 @@ func TestToolsReadMod(t *testing.T) {
 	}
 }
 
+func TestToolsReadMod_Structured(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_read_mod", map[string]any{
+		"module":     "example.com/testmod",
+		"version":    "v1.0.0",
+		"structured": true,
+	})
+
+	structured, ok := result.StructuredContent.(map[string]any)
+	if !ok || structured["module"] != "example.com/testmod" {
+		t.Errorf("unexpected structured content: %#v", result.StructuredContent)
+	}
+
+	if text := resultText(t, result); !strings.Contains(text, `"module": "example.com/testmod"`) {
+		t.Errorf("expected the JSON as text too: %s", text)
+	}
+}
+
 func TestToolsReadMod_Latest(t *testing.T) {
 	env := setupTestEnv(t, fakeProxy(nil))
 	defer env.close()
@@ -239,8 +521,12 @@ func TestToolsListFiles(t *testing.T) {
 
 	text := resultText(t, result)
 
-	if !strings.Contains(text, "4 files") {
-		t.Errorf("expected '4 files' in output: %s", text)
+	if !strings.Contains(text, "(4 files, 64 bytes uncompressed)") {
+		t.Errorf("expected file count and total size in output: %s", text)
+	}
+
+	if !strings.Contains(text, "\nmain.go (13 bytes)\n") {
+		t.Errorf("expected main.go with its size in output: %s", text)
 	}
 
 	if !strings.Contains(text, "main.go") {
@@ -252,6 +538,27 @@ func TestToolsListFiles(t *testing.T) {
 	}
 }
 
+func TestToolsListFiles_PublishesResources(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":    "module example.com/testmod\n",
+		"README.md": "# testmod\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	callTool(t, env, "gomod_list_files", map[string]any{"module": "example.com/testmod", "version": "v1.0.0"})
+
+	read, err := env.session.ReadResource(context.Background(), &mcp.ReadResourceParams{
+		URI: "gomod://example.com/testmod@v1.0.0/README.md",
+	})
+	mustf(t, err, "read README resource")
+
+	if text := read.Contents[0].Text; text != "# testmod\n" {
+		t.Errorf("README resource = %q", text)
+	}
+}
+
 func TestToolsListFiles_WithPrefix(t *testing.T) {
 	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
 		"go.mod":      "module example.com/testmod\n",
@@ -285,6 +592,52 @@ func TestToolsListFiles_WithPrefix(t *testing.T) {
 	}
 }
 
+func TestToolsListFiles_Generated(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"main.go":         "package main\n",
+		"api/api.pb.go":   "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n",
+		"api/api.proto":   "syntax = \"proto3\";\n",
+		"kind_string.go":  "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage main\n",
+		"internal/gen.go": "// Code generated DO NOT EDIT.\n\npackage internal\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0"}
+
+	text := resultText(t, callTool(t, env, "gomod_list_files", args))
+	for _, want := range []string{
+		"api/api.pb.go (62 bytes) [generated: protoc-gen-go]\n", "api/api.proto (19 bytes)\n",
+		"internal/gen.go (49 bytes) [generated]\n", "kind_string.go (71 bytes) [generated: stringer]\n",
+		"main.go (13 bytes)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in listing:\n%s", want, text)
+		}
+	}
+
+	args["generated"] = "exclude"
+
+	text = resultText(t, callTool(t, env, "gomod_list_files", args))
+	if strings.Contains(text, "generated") || !strings.Contains(text, "(2 files, 32 bytes uncompressed)") {
+		t.Errorf("expected generated files excluded:\n%s", text)
+	}
+
+	args["generated"] = "only"
+
+	text = resultText(t, callTool(t, env, "gomod_list_files", args))
+	if strings.Contains(text, "main.go") || !strings.Contains(text, "(3 files, 182 bytes uncompressed)") {
+		t.Errorf("expected only generated files:\n%s", text)
+	}
+
+	args["generated"] = "hide"
+
+	if !callTool(t, env, "gomod_list_files", args).IsError {
+		t.Error("expected IsError for unknown generated filter")
+	}
+}
+
 func TestToolsListFiles_LatestResolution(t *testing.T) {
 	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
 		"go.mod": "module example.com/testmod\n",
@@ -330,134 +683,575 @@ func TestToolsReadFile(t *testing.T) {
 	}
 }
 
-func TestToolsReadFile_NotInArchive(t *testing.T) {
+func TestToolsReadFile_CodeMode(t *testing.T) {
 	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
-		"main.go": "package main\n",
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "// Package main is the entry point.\npackage main\n\n// main runs.\nfunc main() {}\n",
 	})
 
 	env := setupTestEnv(t, fakeProxy(zipData))
 	defer env.close()
 
-	result := callTool(t, env, "gomod_read_file", map[string]any{
-		"module":  "example.com/testmod",
-		"version": "v1.0.0",
-		"path":    "nonexistent.go",
-	})
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "main.go", "mode": "code"}
 
-	if !result.IsError {
-		t.Fatal("expected IsError for missing file")
+	if text := resultText(t, callTool(t, env, "gomod_read_file", args)); text != "package main\n\nfunc main() {}\n" {
+		t.Errorf("code mode = %q", text)
 	}
 
-	text := resultText(t, result)
-	if !strings.Contains(text, "not found") {
-		t.Errorf("expected 'not found' in error text: %s", text)
+	args["path"] = "go.mod"
+
+	if result := callTool(t, env, "gomod_read_file", args); !result.IsError {
+		t.Errorf("code mode on go.mod should fail, got %q", resultText(t, result))
 	}
 }
 
-func TestToolsReadFile_Binary(t *testing.T) {
-	zipData := createTestZipWithBinary(t, "example.com/testmod@v1.0.0/", "image.png",
-		[]byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0xff, 0xfe})
+func TestToolsReadFile_OutlineMode(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n\n// main runs.\nfunc main() {\n\tprintln(1)\n}\n",
+	})
 
 	env := setupTestEnv(t, fakeProxy(zipData))
 	defer env.close()
 
-	result := callTool(t, env, "gomod_read_file", map[string]any{
-		"module":  "example.com/testmod",
-		"version": "v1.0.0",
-		"path":    "image.png",
-	})
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "main.go", "mode": "outline"}
 
-	if !result.IsError {
-		t.Fatal("expected IsError for binary file")
+	text := resultText(t, callTool(t, env, "gomod_read_file", args))
+	if text != "package main\n\n// lines 4-6\nfunc main()\n" {
+		t.Errorf("outline mode = %q", text)
 	}
 
-	text := resultText(t, result)
-	if !strings.Contains(text, "binary") {
-		t.Errorf("expected 'binary' in error text: %s", text)
+	args["path"] = "go.mod"
+
+	if result := callTool(t, env, "gomod_read_file", args); !result.IsError {
+		t.Errorf("outline mode on go.mod should fail, got %q", resultText(t, result))
 	}
 }
 
-func TestToolsZipCaching(t *testing.T) {
-	// Verify that the zip is only downloaded once for multiple tool calls.
-	downloadCount := 0
+func TestToolsHash(t *testing.T) {
 	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
-		"a.go": "package a\n",
-		"b.go": "package b\n",
-	})
-
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.URL.Path {
-		case "/example.com/testmod/@v/v1.0.0.zip":
-			downloadCount++
-			_, _ = w.Write(zipData)
-		default:
-			http.NotFound(w, r)
-		}
+		"go.mod":      "module example.com/testmod\n",
+		"main.go":     "package main\n",
+		"lib/util.go": "package lib\n",
 	})
 
-	env := setupTestEnv(t, handler)
+	env := setupTestEnv(t, fakeProxy(zipData))
 	defer env.close()
 
-	// First call downloads the zip.
-	callTool(t, env, "gomod_list_files", map[string]any{
-		"module": "example.com/testmod", "version": "v1.0.0",
-	})
+	text := resultText(t, callTool(t, env, "gomod_hash", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "path": "lib/",
+	}))
 
-	if downloadCount != 1 {
-		t.Fatalf("expected 1 download, got %d", downloadCount)
-	}
+	mf, err := NewZipCache().Put("example.com/testmod", "v1.0.0", zipData)
+	mustf(t, err, "put zip in cache")
 
-	// Second call should use cache.
-	callTool(t, env, "gomod_read_file", map[string]any{
-		"module": "example.com/testmod", "version": "v1.0.0", "path": "b.go",
-	})
+	modHash, err := moduleHash(zipFiles{mf}, "example.com/testmod", "v1.0.0")
+	mustf(t, err, "module hash")
 
-	if downloadCount != 1 {
-		t.Fatalf("expected still 1 download after cache hit, got %d", downloadCount)
+	goModSum, err := goModHash("module example.com/testmod\n\ngo 1.21\n")
+	mustf(t, err, "go.mod hash")
+
+	for _, want := range []string{
+		"example.com/testmod v1.0.0 " + modHash + "\n",
+		"example.com/testmod v1.0.0/go.mod " + goModSum + "\n",
+		"(prefix: lib/) (1 files)",
+		// SHA-256 of "package lib\n".
+		"lib/util.go  6d5de2f89b37d300a3aea78915deab1ef26054b0edd6324ecfc6b168abd8a0ae",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "main.go") {
+		t.Errorf("path filter not applied:\n%s", text)
 	}
 }
 
-func TestToolsListReturnsAllFourTools(t *testing.T) {
-	env := setupTestEnv(t, fakeProxy(nil))
-	defer env.close()
+func TestToolsReadFile_Symbol(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n\n// Run runs.\nfunc Run() {}\n\nfunc main() { Run() }\n",
+	})
 
-	result, err := env.session.ListTools(context.Background(), nil)
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
 
-	mustf(t, err, "list tools")
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "main.go", "symbol": "Run"}
 
-	names := make(map[string]bool)
+	text := resultText(t, callTool(t, env, "gomod_read_file", args))
+	if text != "// main.go:3-4\n// Run runs.\nfunc Run() {}\n" {
+		t.Errorf("symbol Run = %q", text)
+	}
 
-	for _, tool := range result.Tools {
-		names[tool.Name] = true
+	args["symbol"] = "Stop"
+	if result := callTool(t, env, "gomod_read_file", args); !result.IsError ||
+		!strings.Contains(resultText(t, result), "it declares Run, main") {
+		t.Errorf("unknown symbol = %q", resultText(t, result))
 	}
 
-	for _, want := range []string{
-		"gomod_list_versions",
-		"gomod_read_mod",
-		"gomod_list_files",
-		"gomod_read_file",
-	} {
-		if !names[want] {
-			t.Errorf("missing tool %q in tools/list response", want)
-		}
+	args["mode"] = "code"
+	if result := callTool(t, env, "gomod_read_file", args); !result.IsError {
+		t.Errorf("symbol with code mode should fail, got %q", resultText(t, result))
 	}
 }
 
-// createTestZipWithBinary creates a zip containing a single binary file.
-func createTestZipWithBinary(t *testing.T, prefix, name string, data []byte) []byte {
-	t.Helper()
+func TestToolsResultSize(t *testing.T) {
+	src := "package main\n\n// Run runs.\nfunc Run() {}\n\nfunc main() { Run() }\n"
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": src,
+	})
 
-	var buf bytes.Buffer
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
 
-	w := zip.NewWriter(&buf)
+	size := func(args map[string]any) map[string]any {
+		t.Helper()
 
-	f, err := w.Create(prefix + name)
+		result := callTool(t, env, "gomod_read_file", args)
 
-	mustf(t, err, "create %s in zip", name)
+		size, ok := result.Meta[sizeMetaKey].(map[string]any)
+		if !ok {
+			t.Fatalf("no size in _meta: %v", result.Meta)
+		}
 
-	_, err = f.Write(data)
+		if size["bytes"] != float64(len(resultText(t, result))) {
+			t.Errorf("bytes = %v, want %d", size["bytes"], len(resultText(t, result)))
+		}
 
-	mustf(t, err, "write binary data to zip")
+		return size
+	}
+
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "main.go"}
+
+	whole := size(args)
+	if _, ok := whole["full_bytes"]; ok {
+		t.Errorf("whole file has full_bytes: %v", whole)
+	}
+
+	args["symbol"] = "Run"
+
+	symbol := size(args)
+	if symbol["full_bytes"] != float64(len(src)) || symbol["full_tokens"] != float64(estimateTokens(len(src))) {
+		t.Errorf("symbol size = %v, want full_bytes %d", symbol, len(src))
+	}
+}
+
+func TestToolsReadFile_NestedModule(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	proxy := fakeProxy(zipData)
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/example.com/testmod/examples/@latest" {
+			_, _ = w.Write([]byte(`{"Version":"v0.2.0"}`))
+
+			return
+		}
+
+		proxy.ServeHTTP(w, r)
+	}))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_read_file", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "path": "examples/demo/main.go",
+	})
+
+	want := `examples/demo/main.go is not part of example.com/testmod@v1.0.0: it belongs to the nested module ` +
+		`example.com/testmod/examples. Use module "example.com/testmod/examples", version "v0.2.0" ` +
+		`and path "demo/main.go" instead.`
+	if text := resultText(t, result); !result.IsError || text != want {
+		t.Errorf("nested file = %q", text)
+	}
+
+	result = callTool(t, env, "gomod_list_files", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "path": "examples",
+	})
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, `version "v0.2.0" instead.`) {
+		t.Errorf("nested prefix = %q", text)
+	}
+
+	result = callTool(t, env, "gomod_read_file", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "path": "missing.go",
+	})
+	if !result.IsError || strings.Contains(resultText(t, result), "nested module") {
+		t.Errorf("missing file = %q", resultText(t, result))
+	}
+}
+
+func TestToolsPackagePathAsModule(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":           "module example.com/testmod\n",
+		"main.go":          "package main\n",
+		"pkg/util/util.go": "// Package util helps.\npackage util\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_read_file", map[string]any{
+		"module": "example.com/testmod/pkg/util", "version": "v1.0.0", "path": "util.go",
+	}))
+	if text != "// Package util helps.\npackage util\n" {
+		t.Errorf("read_file via package path = %q", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_list_files", map[string]any{
+		"module": "example.com/testmod/pkg", "version": "latest",
+	}))
+	if !strings.Contains(text, "Files in example.com/testmod@v1.0.0 (prefix: pkg/) (1 files, 36 bytes uncompressed)") {
+		t.Errorf("list_files via package path = %q", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_doc", map[string]any{
+		"module": "example.com/testmod/pkg/util", "version": "v1.0.0",
+	}))
+	if !strings.HasPrefix(text, "package util // import \"example.com/testmod/pkg/util\"\n") {
+		t.Errorf("doc via package path = %q", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_doc", map[string]any{
+		"module": "example.com/testmod/pkg", "version": "v1.0.0", "package": "example.com/testmod/pkg/util",
+	}))
+	if !strings.HasPrefix(text, "package util // import \"example.com/testmod/pkg/util\"\n") {
+		t.Errorf("doc via package path with an import path = %q", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_list_versions", map[string]any{"module": "example.com/testmod/pkg/util"}))
+	if !strings.Contains(text, "Latest: v1.0.0") {
+		t.Errorf("list_versions via package path = %q", text)
+	}
+}
+
+func TestToolsLatestFallbackNote(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	proxy := fakeProxy(zipData)
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/testmod/@latest":
+			http.Error(w, "boom", http.StatusBadGateway)
+		case "/example.com/testmod/@v/list":
+			_, _ = w.Write([]byte("v0.1.0\nv1.0.0\n"))
+		default:
+			proxy.ServeHTTP(w, r)
+		}
+	}))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_list_files", map[string]any{"module": "example.com/testmod", "version": "latest"})
+
+	if text := resultText(t, result); !strings.Contains(text, "Files in example.com/testmod@v1.0.0") {
+		t.Errorf("list_files = %q", text)
+	}
+
+	if len(result.Content) != 2 {
+		t.Fatalf("got %d content blocks, want the file list and a note", len(result.Content))
+	}
+
+	note, _ := result.Content[1].(*mcp.TextContent)
+	if note == nil || !strings.HasPrefix(note.Text, "Note: example.com/testmod@latest failed") ||
+		!strings.Contains(note.Text, "using v1.0.0") {
+		t.Errorf("note = %+v", result.Content[1])
+	}
+}
+
+func TestToolsReadFile_CommentsMode(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":           "module example.com/testmod\n",
+		"pkg/a.go":         "// Package pkg is small.\npackage pkg\n",
+		"pkg/b.go":         "package pkg\n\n// B is b.\nfunc B() {}\n",
+		"pkg/b_test.go":    "package pkg\n\n// TestB tests.\nfunc TestB() {}\n",
+		"pkg/sub/c.go":     "// Package sub.\npackage sub\n",
+		"pkg/testdata/x":   "x",
+		"other/empty.go":   "package other\n",
+		"other/README.txt": "readme",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	read := func(p string) *mcp.CallToolResult {
+		return callTool(t, env, "gomod_read_file", map[string]any{
+			"module": "example.com/testmod", "version": "v1.0.0", "path": p, "mode": "comments",
+		})
+	}
+
+	want := "pkg/a.go:1 package pkg\n  Package pkg is small.\n\npkg/b.go:3 func B\n  B is b.\n\n"
+	if text := resultText(t, read("pkg")); text != want {
+		t.Errorf("package comments:\n%s", text)
+	}
+
+	if text := resultText(t, read("pkg/b.go")); !strings.HasPrefix(text, "pkg/b.go:3 func B") {
+		t.Errorf("file comments:\n%s", text)
+	}
+
+	if text := resultText(t, read("other")); text != "No comments in other." {
+		t.Errorf("no comments: %q", text)
+	}
+
+	if result := read("missing"); !result.IsError {
+		t.Errorf("missing package should fail, got %q", resultText(t, result))
+	}
+}
+
+func TestToolsReadFile_NotInArchive(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"main.go": "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_read_file", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"path":    "nonexistent.go",
+	})
+
+	if !result.IsError {
+		t.Fatal("expected IsError for missing file")
+	}
+
+	text := resultText(t, result)
+	if !strings.Contains(text, "not found") {
+		t.Errorf("expected 'not found' in error text: %s", text)
+	}
+}
+
+func TestToolsReadFile_Binary(t *testing.T) {
+	zipData := createTestZipWithBinary(t, "example.com/testmod@v1.0.0/", "image.png",
+		[]byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0xff, 0xfe})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_read_file", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"path":    "image.png",
+	})
+
+	if !result.IsError {
+		t.Fatal("expected IsError for binary file")
+	}
+
+	text := resultText(t, result)
+	if !strings.Contains(text, "binary") {
+		t.Errorf("expected 'binary' in error text: %s", text)
+	}
+}
+
+func TestToolsZipCaching(t *testing.T) {
+	// Verify that the zip is only downloaded once for multiple tool calls.
+	downloadCount := 0
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package b\n",
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/testmod/@v/v1.0.0.zip":
+			downloadCount++
+			_, _ = w.Write(zipData)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	env := setupTestEnv(t, handler)
+	defer env.close()
+
+	// First call downloads the zip.
+	callTool(t, env, "gomod_list_files", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0",
+	})
+
+	if downloadCount != 1 {
+		t.Fatalf("expected 1 download, got %d", downloadCount)
+	}
+
+	// Second call should use cache.
+	callTool(t, env, "gomod_read_file", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "path": "b.go",
+	})
+
+	if downloadCount != 1 {
+		t.Fatalf("expected still 1 download after cache hit, got %d", downloadCount)
+	}
+}
+
+func TestToolsListReturnsAllTools(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	result, err := env.session.ListTools(context.Background(), nil)
+
+	mustf(t, err, "list tools")
+
+	names := make(map[string]bool)
+
+	for _, tool := range result.Tools {
+		names[tool.Name] = true
+	}
+
+	for _, want := range []string{
+		"gomod_list_versions",
+		"gomod_major_versions",
+		"gomod_read_mod",
+		"gomod_list_files",
+		"gomod_read_file",
+		"gomod_tags",
+		"gomod_find_symbol",
+		"gomod_callers",
+		"gomod_metrics",
+		"gomod_list_tests",
+		"gomod_list_benchmarks",
+		"gomod_list_fuzz",
+		"gomod_usage_examples",
+		"gomod_compare_local",
+		"gomod_compare_versions",
+		"gomod_verify_local",
+		"gomod_extract",
+		"gomod_watch",
+		"gomod_watch_events",
+		"gomod_stats",
+		"gomod_perf_stats",
+		"gomod_hygiene",
+		"gomod_replacements",
+		"gomod_resolve_import",
+		"gomod_alternatives",
+		"gomod_dependents",
+		"gomod_bind_project",
+		"gomod_osv_scan",
+		"gomod_govulncheck",
+		"gomod_licenses",
+		"gomod_sbom",
+		"gomod_proto_map",
+		"gomod_readme",
+		"gomod_docs",
+		"gomod_specs",
+		"gomod_nested_modules",
+		"gomod_set_context",
+		"gomod_get_context",
+		"gomod_aliases",
+		"gomod_compare_file",
+		"gomod_dir_stats",
+		"gomod_proxy_status",
+		"gomod_packages",
+		"gomod_imports",
+		"gomod_doc",
+		"gomod_examples",
+		"gomod_api",
+		"gomod_apidiff",
+		"gomod_grep",
+		"gomod_hash",
+		"gomod_changelog",
+		"gomod_upgrade_report",
+		"gomod_project_graph",
+		"gomod_project_search",
+	} {
+		if !names[want] {
+			t.Errorf("missing tool %q in tools/list response", want)
+		}
+	}
+}
+
+func TestToolsTags(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n\ntype Server struct{}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_tags", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+	})
+
+	text := resultText(t, result)
+
+	if !strings.Contains(text, "Server\tmain.go\t5;\"\tt") {
+		t.Errorf("expected Server type tag in output: %s", text)
+	}
+
+	result = callTool(t, env, "gomod_tags", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"format":  "vim",
+	})
+
+	if !result.IsError {
+		t.Error("expected IsError for unknown format")
+	}
+}
+
+func TestToolsFindSymbol(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"a/a.go":      "package a\n\n// Server serves.\ntype Server struct{}\n\nfunc (s *Server) Run() {}\n",
+		"b/b.go":      "package b\n\ntype Server int\n",
+		"b/b_test.go": "package b\n\nfunc helper() {}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "latest", "symbol": "Server"}
+
+	text := resultText(t, callTool(t, env, "gomod_find_symbol", args))
+	if !strings.HasPrefix(text, "Declarations of Server in example.com/testmod@v1.0.0 (2):\n\n"+
+		"// a/a.go:3-4\n// Server serves.\ntype Server struct{}\n\n// b/b.go:3-3\ntype Server int\n") {
+		t.Errorf("unexpected declarations: %s", text)
+	}
+
+	args["symbol"] = "(*Server).Run"
+	if text := resultText(t, callTool(t, env, "gomod_find_symbol", args)); !strings.Contains(text, "// a/a.go:6-6\n") {
+		t.Errorf("expected the method: %s", text)
+	}
+
+	args["symbol"] = "Run"
+
+	result := callTool(t, env, "gomod_find_symbol", args)
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, "it is declared as Server.Run.") {
+		t.Errorf("expected an error pointing at the method: %s", text)
+	}
+
+	args["symbol"] = "helper"
+	if result := callTool(t, env, "gomod_find_symbol", args); !result.IsError {
+		t.Errorf("expected test files to be skipped: %s", resultText(t, result))
+	}
+
+	args["tests"] = true
+	if text := resultText(t, callTool(t, env, "gomod_find_symbol", args)); !strings.Contains(text, "b/b_test.go:3-3") {
+		t.Errorf("expected the test helper: %s", text)
+	}
+}
+
+// createTestZipWithBinary creates a zip containing a single binary file.
+func createTestZipWithBinary(t *testing.T, prefix, name string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	f, err := w.Create(prefix + name)
+
+	mustf(t, err, "create %s in zip", name)
+
+	_, err = f.Write(data)
+
+	mustf(t, err, "write binary data to zip")
 
 	w.Close()
 
@@ -468,7 +1262,7 @@ func createTestZipWithBinary(t *testing.T, prefix, name string, data []byte) []b
 func populateModCache(t *testing.T, dir, module, version string, files map[string]string) {
 	t.Helper()
 
-	modDir := filepath.Join(dir, encodePath(module)+"@"+version)
+	modDir := NewModCache(dir).ModDir(module, version)
 
 	for name, content := range files {
 		full := filepath.Join(modDir, filepath.FromSlash(name))
@@ -609,3 +1403,1390 @@ func TestToolsReadFile_FallsBackToProxy(t *testing.T) {
 		t.Errorf("expected source from proxy fallback: %s", text)
 	}
 }
+
+func TestToolsListBenchmarks(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"parse_test.go": "package testmod\n\nimport \"testing\"\n\n" +
+			"func TestParse(t *testing.T) {}\n\n" +
+			"// BenchmarkParse measures parsing.\nfunc BenchmarkParse(b *testing.B) {\n\tfor b.Loop() {\n\t}\n}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_list_benchmarks", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+	}))
+
+	if !strings.Contains(text, "(1 functions)") || strings.Contains(text, "TestParse") {
+		t.Errorf("expected only the benchmark in listing: %s", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_list_benchmarks", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"name":    "BenchmarkParse",
+	}))
+
+	if !strings.Contains(text, "// BenchmarkParse measures parsing.\nfunc BenchmarkParse") {
+		t.Errorf("expected benchmark source with doc comment: %s", text)
+	}
+
+	if !strings.Contains(text, "b.Loop()") {
+		t.Errorf("expected benchmark body: %s", text)
+	}
+
+	result := callTool(t, env, "gomod_list_benchmarks", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"name":    "BenchmarkMissing",
+	})
+
+	if !result.IsError {
+		t.Error("expected IsError for unknown benchmark")
+	}
+}
+
+func TestToolsCompareLocal(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	writeTree(t, filepath.Join(env.localDir, "testmod"), map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n\n// local patch\n",
+	})
+
+	text := resultText(t, callTool(t, env, "gomod_compare_local", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+	}))
+
+	if !strings.Contains(text, "M main.go") {
+		t.Errorf("expected main.go to be reported as modified: %s", text)
+	}
+
+	if !strings.Contains(text, "Unchanged: 1 files") {
+		t.Errorf("expected go.mod to be unchanged: %s", text)
+	}
+}
+
+func TestToolsVerifyLocal(t *testing.T) {
+	files := map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	}
+
+	env := setupTestEnv(t, fakeProxy(createTestZip(t, "example.com/testmod@v1.0.0/", files)))
+	defer env.close()
+
+	dir := filepath.Join(env.localDir, "testmod")
+
+	writeTree(t, dir, files)
+
+	text := resultText(t, callTool(t, env, "gomod_verify_local", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+	}))
+
+	if !strings.HasPrefix(text, "YES:") {
+		t.Errorf("expected identical result: %s", text)
+	}
+
+	writeTree(t, dir, map[string]string{"extra.go": "package main\n"})
+
+	text = resultText(t, callTool(t, env, "gomod_verify_local", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"dir":     dir,
+	}))
+
+	if !strings.HasPrefix(text, "NO:") {
+		t.Errorf("expected mismatch after adding a file: %s", text)
+	}
+}
+
+func TestToolsExtract(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	dir := filepath.Join(t.TempDir(), "out")
+
+	text := resultText(t, callTool(t, env, "gomod_extract", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"dir":     dir,
+	}))
+
+	if !strings.Contains(text, "Extracted 2 files") || !strings.Contains(text, dir) {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("expected main.go to be extracted: %v", err)
+	}
+
+	result := callTool(t, env, "gomod_extract", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"dir":     dir,
+	})
+
+	if !result.IsError {
+		t.Error("expected IsError when extracting into a non-empty directory")
+	}
+}
+
+func TestToolsWatch(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_watch", map[string]any{
+		"module": "example.com/testmod",
+	}))
+
+	if !strings.Contains(text, "Watching example.com/testmod") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_watch_events", map[string]any{}))
+
+	if !strings.Contains(text, "example.com/testmod (3 versions") || !strings.Contains(text, "Events (0)") {
+		t.Errorf("unexpected events output: %s", text)
+	}
+
+	callTool(t, env, "gomod_watch", map[string]any{"module": "example.com/testmod", "remove": true})
+
+	result := callTool(t, env, "gomod_watch", map[string]any{"module": "example.com/testmod", "remove": true})
+	if !result.IsError {
+		t.Errorf("expected error removing an unwatched module: %s", resultText(t, result))
+	}
+}
+
+func TestToolsStats(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	callTool(t, env, "gomod_watch", map[string]any{"module": "example.com/testmod"})
+
+	text := resultText(t, callTool(t, env, "gomod_stats", map[string]any{}))
+
+	for _, want := range []string{"zip archives: 0", "Watched modules: 1", "Scheduler: running", "watch"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output: %s", want, text)
+		}
+	}
+}
+
+func TestToolsPerfStats(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"go.mod": "module example.com/testmod\n"})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	for range 2 {
+		callTool(t, env, "gomod_read_file",
+			map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "go.mod"})
+	}
+
+	text := resultText(t, callTool(t, env, "gomod_perf_stats", map[string]any{"reset": true}))
+
+	for _, want := range []string{"Tool calls since", "CACHE HITS", "gomod_read_file  2 ", "zip-cache: 1 calls (cache)",
+		"calls (network)", "Stats reset."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output: %s", want, text)
+		}
+	}
+
+	// Only the call reporting the stats is counted after the reset.
+	text = resultText(t, callTool(t, env, "gomod_perf_stats", map[string]any{}))
+	if !strings.Contains(text, ": 1\n") || strings.Contains(text, "gomod_read_file") {
+		t.Errorf("expected the read_file calls to be gone after the reset: %s", text)
+	}
+}
+
+func TestToolsDoc(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":     "module example.com/testmod\n",
+		"util/u.go":  "// Package util helps.\npackage util\n\n// Add adds.\nfunc Add(a, b int) int { return a + b }\n",
+		"util/u2.go": "package util\n\nfunc hidden() {}\n",
+		"README.md":  "# testmod\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "package": "example.com/testmod/util"}
+
+	text := resultText(t, callTool(t, env, "gomod_doc", args))
+	if !strings.HasPrefix(text, "package util // import \"example.com/testmod/util\"\n") ||
+		!strings.Contains(text, "func Add(a, b int) int\n    Add adds.\n") || strings.Contains(text, "hidden") {
+		t.Errorf("unexpected doc: %s", text)
+	}
+
+	args["symbol"] = "Missing"
+	if result := callTool(t, env, "gomod_doc", args); !result.IsError {
+		t.Errorf("expected an error for a missing symbol: %s", resultText(t, result))
+	}
+
+	args = map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "package": "nope"}
+	if result := callTool(t, env, "gomod_doc", args); !result.IsError {
+		t.Errorf("expected an error for a missing package: %s", resultText(t, result))
+	}
+}
+
+func TestToolsImports(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod": "module example.com/testmod\n\nrequire (\n\tgolang.org/x/mod v0.20.0\n" +
+			"\tgolang.org/x/mod/sub v0.1.0\n)\n",
+		"util/u.go": "package util\n\nimport (\n\t\"fmt\"\n\t\"example.com/testmod/internal/x\"\n" +
+			"\t\"golang.org/x/mod/semver\"\n\t\"golang.org/x/mod/sub/y\"\n\t\"github.com/a/b\"\n)\n",
+		"util/v.go":      "package util\n\nimport \"fmt\"\n",
+		"util/u_test.go": "package util\n\nimport (\n\t\"fmt\"\n\t\"testing\"\n)\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "example.com/testmod/util"}
+
+	text := resultText(t, callTool(t, env, "gomod_imports", args))
+	want := "Imports of util in example.com/testmod@v1.0.0: 5, from 2 files.\n\n" +
+		"Imports, standard library (1):\n  fmt\n\n" +
+		"Imports, module example.com/testmod (1):\n  example.com/testmod/internal/x\n\n" +
+		"Imports, other modules (3):\n  github.com/a/b  (no requirement in go.mod)\n" +
+		"  golang.org/x/mod/semver  (golang.org/x/mod v0.20.0)\n  golang.org/x/mod/sub/y  (golang.org/x/mod/sub v0.1.0)\n"
+	if text != want {
+		t.Errorf("imports:\n%s\nwant:\n%s", text, want)
+	}
+
+	args["tests"] = true
+	if text := resultText(t, callTool(t, env, "gomod_imports", args)); !strings.Contains(text, ": 6, from 3 files.\n") ||
+		!strings.HasSuffix(text, "\nTest-only imports, standard library (1):\n  testing\n") {
+		t.Errorf("expected the test-only imports: %s", text)
+	}
+
+	args = map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "util/v.go"}
+	if text := resultText(t, callTool(t, env, "gomod_imports", args)); !strings.HasPrefix(text,
+		"Imports of util/v.go in example.com/testmod@v1.0.0: 1, from 1 files.\n") {
+		t.Errorf("unexpected imports of one file: %s", text)
+	}
+
+	args["path"] = "nope"
+	if result := callTool(t, env, "gomod_imports", args); !result.IsError {
+		t.Errorf("expected an error for a directory without Go files: %s", resultText(t, result))
+	}
+}
+
+func TestToolsExamples(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":    "module example.com/testmod\n",
+		"util/u.go": "package util\n\n// Add adds.\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub() {}\n",
+		"util/u_test.go": "package util_test\n\nimport \"fmt\"\n\nfunc TestAdd() {}\n\n" +
+			"func ExampleAdd() {\n\tfmt.Println(util.Add(1, 2))\n\t// Output: 3\n}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "latest", "package": "util", "symbol": "Add"}
+
+	text := resultText(t, callTool(t, env, "gomod_examples", args))
+	if !strings.HasPrefix(text, "Examples of Add in example.com/testmod/util at v1.0.0 (1):\n\n// util/u_test.go:7\n") ||
+		!strings.Contains(text, "\t// Output: 3\n}\n") {
+		t.Errorf("unexpected examples: %s", text)
+	}
+
+	args["symbol"] = "Sub"
+
+	result := callTool(t, env, "gomod_examples", args)
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, "the package has ExampleAdd.") {
+		t.Errorf("expected an error naming the examples: %s", text)
+	}
+
+	args = map[string]any{"module": "example.com/testmod", "version": "v1.0.0"}
+	if result := callTool(t, env, "gomod_examples", args); !result.IsError {
+		t.Errorf("expected an error for a package without Go files: %s", resultText(t, result))
+	}
+}
+
+func TestToolsAPI(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":          "module example.com/testmod\n",
+		"root.go":         "package testmod\n\nfunc Root() {}\n",
+		"util/u.go":       "package util\n\ntype T struct{ N int }\n\nfunc (T) Get() int { return 0 }\n",
+		"internal/x/x.go": "package x\n\nfunc Hidden() {}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	args := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "package": "util"}
+
+	text := resultText(t, callTool(t, env, "gomod_api", args))
+	if !strings.Contains(text, "(1 packages, 3 declarations)") ||
+		!strings.Contains(text, "  type T struct\n    field N int\n    func (T) Get() int\n") ||
+		strings.Contains(text, "Root") {
+		t.Errorf("unexpected API: %s", text)
+	}
+
+	args["package"] = "internal/x"
+	if result := callTool(t, env, "gomod_api", args); !result.IsError {
+		t.Errorf("expected an error for an internal package: %s", resultText(t, result))
+	}
+}
+
+func TestToolsAPIDiff(t *testing.T) {
+	zips := map[string][]byte{
+		"v1.0.0": createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n",
+			"m.go":   "package testmod\n\ntype Doer interface{ Do() }\n\nfunc Old() {}\n",
+		}),
+		"v1.1.0": createTestZip(t, "example.com/testmod@v1.1.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n",
+			"m.go":   "package testmod\n\ntype Doer interface{ Do(); Undo() }\n\nfunc New() {}\n",
+		}),
+	}
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := strings.TrimSuffix(path.Base(r.URL.Path), ".zip")
+		if data, ok := zips[version]; ok {
+			_, _ = w.Write(data)
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_apidiff", map[string]any{
+		"module": "example.com/testmod", "from": "v1.0.0", "to": "v1.1.0",
+	}))
+
+	for _, want := range []string{
+		"API changes from example.com/testmod@v1.0.0 to v1.1.0: 2 breaking, 1 compatible.\n",
+		"Both versions are v1: semantic versioning keeps breaking changes for a new major version.\n",
+		"\nBreaking (2):\n  example.com/testmod:\n    + method Undo()\n" +
+			"      (added to an existing interface, which its implementations must now provide)\n    - func Old()\n",
+		"\nCompatible (1):\n  example.com/testmod:\n    + func New()\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestToolsChangelog(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":       "module example.com/testmod\n",
+		"CHANGELOG.md": "# Changelog\n\n## v1.0.0\n\n- stable\n\n## v0.2.0\n\n- second\n\n## v0.1.0\n\n- first\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	changelog := func(args map[string]any) string {
+		args["module"] = "example.com/testmod"
+
+		return resultText(t, callTool(t, env, "gomod_changelog", args))
+	}
+
+	if text := changelog(map[string]any{"version": "latest"}); text !=
+		"CHANGELOG.md in example.com/testmod@v1.0.0, 1 entries for v1.0.0:\n\n## v1.0.0\n\n- stable\n" {
+		t.Errorf("unexpected entry:\n%s", text)
+	}
+
+	text := changelog(map[string]any{"version": "v1.0.0", "from": "v0.1.0"})
+	if !strings.Contains(text, "2 entries for versions after v0.1.0 up to v1.0.0:\n\n## v1.0.0\n\n- stable\n\n"+
+		"## v0.2.0\n\n- second\n") || strings.Contains(text, "first") {
+		t.Errorf("unexpected range:\n%s", text)
+	}
+
+	text = changelog(map[string]any{"version": "v1.0.0", "from": "v1.0.0"})
+	if !strings.Contains(text, "has no entry for versions after v1.0.0 up to v1.0.0.\n") ||
+		!strings.Contains(text, "Versions with entries (3): v1.0.0, v0.2.0, v0.1.0\n") {
+		t.Errorf("unexpected empty range:\n%s", text)
+	}
+}
+
+func TestToolsSBOM(t *testing.T) {
+	mit, err := licenseTexts.ReadFile("licenses/MIT.txt")
+	mustf(t, err, "read MIT reference")
+
+	env := setupTestEnv(t, graphProxy())
+	defer env.close()
+
+	dir := filepath.Join(env.localDir, "app")
+	writeTree(t, dir, map[string]string{"go.mod": graphGoMod, "LICENSE": string(mit)})
+
+	text := resultText(t, callTool(t, env, "gomod_sbom", map[string]any{"dir": dir}))
+
+	var bom cycloneDXBOM
+	mustf(t, json.Unmarshal([]byte(text), &bom), "decode CycloneDX")
+
+	if bom.BOMFormat != "CycloneDX" || len(bom.Components) != 6 ||
+		bom.Metadata.Component.PURL != "pkg:golang/example.com/app" ||
+		len(bom.Metadata.Component.Licenses) != 1 || bom.Metadata.Component.Licenses[0].Expression != "MIT" {
+		t.Errorf("unexpected CycloneDX SBOM:\n%s", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_sbom", map[string]any{
+		"root": "example.com/lib@v1.0.0", "format": "spdx", "skip_licenses": true,
+	}))
+
+	var doc spdxDocument
+	mustf(t, json.Unmarshal([]byte(text), &doc), "decode SPDX")
+
+	if doc.Name != "example.com/lib@v1.0.0" || len(doc.Packages) != 5 {
+		t.Errorf("unexpected SPDX SBOM:\n%s", text)
+	}
+
+	if result := callTool(t, env, "gomod_sbom", map[string]any{"dir": dir, "format": "xml"}); !result.IsError {
+		t.Errorf("expected an error for an unknown format: %s", resultText(t, result))
+	}
+}
+
+func TestToolsReadme(t *testing.T) {
+	zip := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":         "module example.com/testmod\n",
+		"README":         "plain\n",
+		"README.md":      "# testmod\n\nDoes things.\n\n## Usage\n\nCall it.\n",
+		"sub/README.rst": "sub\n===\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zip))
+	defer env.close()
+
+	readme := func(args map[string]any) *mcp.CallToolResult {
+		args["module"], args["version"] = "example.com/testmod", "v1.0.0"
+
+		return callTool(t, env, "gomod_readme", args)
+	}
+
+	if text := resultText(t, readme(map[string]any{})); text != "# testmod\n\nDoes things.\n\n## Usage\n\nCall it.\n" {
+		t.Errorf("unexpected README:\n%s", text)
+	}
+
+	if text := resultText(t, readme(map[string]any{"max_bytes": 26})); text !=
+		"# testmod\n\nDoes things.\n\n[... 19 more bytes of README.md; raise max_bytes or read it with gomod_read_file]\n" {
+		t.Errorf("unexpected cut README:\n%s", text)
+	}
+
+	if text := resultText(t, readme(map[string]any{"dir": "sub"})); text != "sub\n===\n" {
+		t.Errorf("unexpected sub README:\n%s", text)
+	}
+
+	if result := readme(map[string]any{"dir": "missing"}); !result.IsError {
+		t.Errorf("expected an error for a directory without a README: %s", resultText(t, result))
+	}
+}
+
+func TestToolsHygiene(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	dir := filepath.Join(env.localDir, "app")
+
+	writeTree(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n\nrequire example.com/testmod v1.0.0\n",
+	})
+
+	text := resultText(t, callTool(t, env, "gomod_hygiene", map[string]any{"dir": dir}))
+
+	if !strings.Contains(text, "Checked 1 dependencies of example.com/app") ||
+		!strings.Contains(text, "No retracted or deprecated dependencies found.") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	result := callTool(t, env, "gomod_hygiene", map[string]any{})
+	if !result.IsError {
+		t.Errorf("expected error without a project: %s", resultText(t, result))
+	}
+}
+
+func TestToolsReplacements(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_replacements", map[string]any{
+		"module": "example.com/testmod",
+	}))
+
+	if !strings.Contains(text, "example.com/testmod@v1.0.0 (latest, released 2025-06-01)") ||
+		!strings.Contains(text, "Not deprecated") {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
+func TestToolsResolveImport(t *testing.T) {
+	proxy := fakeProxy(nil)
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/testmod", "/example.com/testmod/sub/pkg":
+			_, _ = w.Write([]byte(`<meta name="go-import" content="example.com/testmod git https://git.example/testmod">`))
+		default:
+			proxy.ServeHTTP(w, r)
+		}
+	}), func(svc *services) {
+		svc.proxy.vanity = NewVanityResolver()
+		svc.proxy.vanity.baseURL, svc.proxy.vanity.client = svc.proxy.baseURL, svc.proxy.client
+	})
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_resolve_import", map[string]any{
+		"import_path": "example.com/testmod/sub/pkg",
+	}))
+	if want := "Import path: example.com/testmod/sub/pkg\nModule: example.com/testmod (latest v1.0.0)\n" +
+		"Package directory: sub/pkg\ngo-import: example.com/testmod git https://git.example/testmod\n"; text != want {
+		t.Errorf("got:\n%s\nwant:\n%s", text, want)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_resolve_import", map[string]any{"import_path": "example.com/other/x"}))
+	if !strings.Contains(text, "Module: none of the path and its parents is a module on the proxy\n") ||
+		!strings.Contains(text, "go-import: ") {
+		t.Errorf("unexpected result for an unknown path:\n%s", text)
+	}
+
+	if result := callTool(t, env, "gomod_resolve_import", map[string]any{"import_path": "bad path"}); !result.IsError {
+		t.Errorf("expected an error for an invalid path: %s", resultText(t, result))
+	}
+}
+
+func TestToolsAlternatives(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_alternatives", map[string]any{"query": "  "})
+	if !result.IsError {
+		t.Errorf("expected error for empty query: %s", resultText(t, result))
+	}
+
+	result = callTool(t, env, "gomod_alternatives", map[string]any{"query": "yaml parser"})
+	if !result.IsError {
+		t.Errorf("expected error when pkg.go.dev search fails: %s", resultText(t, result))
+	}
+}
+
+func TestToolsDependents(t *testing.T) {
+	proxy := fakeProxy(nil)
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3alpha/systems/go/packages/example.com%2Ftestmod/versions/v1.0.0:dependents":
+			_, _ = w.Write([]byte(`{"dependentCount": 42, "directDependentCount": 5, "indirectDependentCount": 37}`))
+		case "/v3/systems/go/packages/example.com%2Ftestmod/versions/v1.0.0":
+			_, _ = w.Write([]byte(`{"relatedProjects": [{"projectKey": {"id": "github.com/o/testmod"}, ` +
+				`"relationType": "SOURCE_REPO"}]}`))
+		case "/v3/projects/github.com%2Fo%2Ftestmod":
+			_, _ = w.Write([]byte(`{"starsCount": 120, "forksCount": "8", "openIssuesCount": 3, "scorecard": {` +
+				`"date": "2025-06-01T00:00:00Z", "overallScore": 6.4, "checks": [` +
+				`{"name": "Maintained", "score": 10, "reason": "30 commits in the last 90 days"}, ` +
+				`{"name": "Packaging", "score": -1, "reason": "no packaging workflow"}]}}`))
+		case "/search":
+			_, _ = w.Write([]byte(`<div class="SearchSnippet"><a href="/example.com/testmod" ` +
+				`data-test-id="snippet-title">x</a><a href="/example.com/testmod?tab=importedby">` +
+				`Imported by <strong>1,234</strong></a></div>`))
+		default:
+			proxy.ServeHTTP(w, r)
+		}
+	}))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_dependents", map[string]any{"module": "example.com/testmod"}))
+	for _, want := range []string{
+		"Dependents of example.com/testmod@v1.0.0:\n",
+		"  deps.dev: 42 packages depend on this version (5 directly, 37 indirectly)\n",
+		"  pkg.go.dev: example.com/testmod is imported by 1234 packages\n",
+		"\nSource repository github.com/o/testmod: 120 stars, 8 forks, 3 open issues\n",
+		"\nOpenSSF Scorecard: 6.4/10, from 2025-06-01 (2 checks):\n",
+		"  Maintained  10   30 commits in the last 90 days\n",
+		"  Packaging   n/a  no packaging workflow\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_dependents", map[string]any{
+		"module": "example.com/testmod", "version": "v0.1.0",
+	}))
+	if !strings.Contains(text, "  deps.dev: dependents unknown\n") ||
+		!strings.HasSuffix(text, "\nNo source repository known to deps.dev, so no Scorecard.\n") {
+		t.Errorf("unexpected report without deps.dev data:\n%s", text)
+	}
+}
+
+func TestToolsBindProject(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_bind_project", map[string]any{})
+	if !result.IsError {
+		t.Errorf("expected error with nothing bound: %s", resultText(t, result))
+	}
+
+	dir := filepath.Join(env.localDir, "app")
+
+	writeTree(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n\nrequire example.com/testmod v1.0.0\n",
+		"go.sum": "example.com/testmod v1.0.0 h1:aaa=\nexample.com/testmod v1.0.0/go.mod h1:bbb=\n",
+	})
+
+	text := resultText(t, callTool(t, env, "gomod_bind_project", map[string]any{"dir": dir}))
+	if !strings.Contains(text, "Bound project example.com/app") || !strings.Contains(text, "1 requirements") {
+		t.Errorf("unexpected bind result: %s", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_hygiene", map[string]any{}))
+	if !strings.Contains(text, "Checked 1 dependencies of example.com/app") {
+		t.Errorf("hygiene did not use the bound project: %s", text)
+	}
+
+	callTool(t, env, "gomod_bind_project", map[string]any{"unbind": true})
+
+	result = callTool(t, env, "gomod_osv_scan", map[string]any{})
+	if !result.IsError {
+		t.Errorf("expected error after unbinding: %s", resultText(t, result))
+	}
+}
+
+func TestToolsBindProject_PerSession(t *testing.T) {
+	env := setupTestEnv(t, fakeProxy(nil))
+	defer env.close()
+
+	other := env.otherSession(t)
+
+	for name, mod := range map[string]string{"a": "example.com/a", "b": "example.com/b"} {
+		writeTree(t, filepath.Join(env.localDir, name), map[string]string{"go.mod": "module " + mod + "\n"})
+	}
+
+	callTool(t, env, "gomod_bind_project", map[string]any{"dir": filepath.Join(env.localDir, "a")})
+
+	if result := callTool(t, other, "gomod_hygiene", map[string]any{}); !result.IsError {
+		t.Errorf("another session used the bound project: %s", resultText(t, result))
+	}
+
+	callTool(t, other, "gomod_bind_project", map[string]any{"dir": filepath.Join(env.localDir, "b")})
+
+	if text := resultText(t, callTool(t, env, "gomod_bind_project", map[string]any{})); !strings.HasSuffix(text, "a") {
+		t.Errorf("first session's binding = %q", text)
+	}
+
+	if text := resultText(t, callTool(t, other, "gomod_bind_project", map[string]any{})); !strings.HasSuffix(text, "b") {
+		t.Errorf("second session's binding = %q", text)
+	}
+}
+
+func TestToolsProjectGraph(t *testing.T) {
+	env := setupTestEnv(t, graphProxy())
+	defer env.close()
+
+	dir := filepath.Join(env.localDir, "app")
+	writeTree(t, dir, map[string]string{"go.mod": graphGoMod})
+
+	query := func(args map[string]any) string {
+		args["dir"] = dir
+
+		return resultText(t, callTool(t, env, "gomod_project_graph", args))
+	}
+
+	text := query(map[string]any{})
+	for _, want := range []string{
+		"Module graph of example.com/app: 7 module versions, 7 requirements, 6 modules selected.\n",
+		"The go.mod of 1 module versions could not be read",
+		"Direct requirements: 3\n",
+		"Modules selected at several major versions: 1",
+		"  example.com/d@v0.1.0: ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in summary:\n%s", want, text)
+		}
+	}
+
+	text = query(map[string]any{"query": "forces", "module": "example.com/c"})
+	if !strings.Contains(text, "(cached)") ||
+		!strings.Contains(text, "example.com/c is selected at v1.2.0, the highest version required by:\n"+
+			"  example.com/b@v1.0.0, via example.com/app -> example.com/b@v1.0.0\n") {
+		t.Errorf("unexpected forces answer:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "requirers", "module": "example.com/c"})
+	if !strings.Contains(text, "  example.com/a@v1.0.0 requires v1.1.0\n  example.com/b@v1.0.0 requires v1.2.0\n") {
+		t.Errorf("unexpected requirers answer:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "majors"})
+	if !strings.Contains(text, "  example.com/x: example.com/x@v1.0.0, example.com/x/v2@v2.0.0\n") {
+		t.Errorf("unexpected majors answer:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "conflicts"})
+	if !strings.Contains(text, "example.com/x/v2@v2.0.0 via example.com/app -> example.com/x/v2@v2.0.0\n") ||
+		strings.Contains(text, "surprising effects") {
+		t.Errorf("unexpected conflicts answer:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "chain", "module": "example.com/x"})
+	if !strings.Contains(text, "example.com/app -> example.com/b@v1.0.0 -> example.com/x@v1.0.0\n") {
+		t.Errorf("unexpected chain answer:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "edges"})
+	if !strings.Contains(text, "\nRequirements (7):\n  example.com/app example.com/a@v1.0.0\n") ||
+		!strings.Contains(text, "  example.com/a@v1.0.0 example.com/c@v1.1.0\n") {
+		t.Errorf("unexpected edges answer:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "edges", "resolved": true})
+	if !strings.Contains(text, "  example.com/a@v1.0.0 example.com/c@v1.2.0\n") {
+		t.Errorf("unexpected resolved edges answer:\n%s", text)
+	}
+
+	root := func(args map[string]any) string {
+		args["root"] = "example.com/lib@v1.0.0"
+
+		return resultText(t, callTool(t, env, "gomod_project_graph", args))
+	}
+
+	text = root(map[string]any{"query": "build_list"})
+	if !strings.Contains(text, "Module graph of example.com/lib@v1.0.0: 4 module versions") ||
+		!strings.Contains(text, "replace and exclude directives are left out") ||
+		!strings.Contains(text, "\nBuild list (5):\n  example.com/lib@v1.0.0\n  example.com/b v1.0.0\n"+
+			"  example.com/c v1.2.0\n  example.com/d v0.1.0\n  example.com/x v1.0.0\n") {
+		t.Errorf("unexpected build list:\n%s", text)
+	}
+
+	text = root(map[string]any{"query": "chain", "module": "example.com/x"})
+	if !strings.Contains(text, "example.com/lib@v1.0.0 -> example.com/b@v1.0.0 -> example.com/x@v1.0.0\n") {
+		t.Errorf("unexpected chain from root:\n%s", text)
+	}
+
+	text = root(map[string]any{"query": "chain", "module": "example.com/a"})
+	if !strings.Contains(text, "example.com/a is not in the module graph: example.com/lib@v1.0.0 does not need it.\n") {
+		t.Errorf("unexpected chain to a module not needed:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "chain", "module": "example.com/c@v1.1.0"})
+	if !strings.Contains(text, "Shortest require chain to example.com/c@v1.1.0:\n"+
+		"  example.com/app -> example.com/a@v1.0.0 -> example.com/c@v1.1.0\nIt is not built: v1.2.0 is selected.\n") {
+		t.Errorf("unexpected chain to a superseded version:\n%s", text)
+	}
+
+	text = query(map[string]any{"query": "chain", "module": "example.com/c@v1.0.0"})
+	if !strings.Contains(text, "Nothing in the module graph requires example.com/c@v1.0.0 (selected v1.2.0).\n") {
+		t.Errorf("unexpected chain to a version not in the graph:\n%s", text)
+	}
+
+	for _, args := range []map[string]any{
+		{"query": "chain"}, {"query": "bogus"}, {"root": "example.com/lib@v1.0.0"},
+	} {
+		args["dir"] = dir
+		if result := callTool(t, env, "gomod_project_graph", args); !result.IsError {
+			t.Errorf("expected an error for %v: %s", args, resultText(t, result))
+		}
+	}
+}
+
+func TestToolsProjectSearch(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":    "module example.com/testmod\n",
+		"client.go": "package testmod\n\n// NewClient returns a client.\nfunc NewClient() {}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	dir := filepath.Join(env.localDir, "app")
+	writeTree(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n\nrequire example.com/testmod v1.0.0\n",
+		"go.sum": "example.com/testmod v1.0.0 h1:abc=\nexample.com/testmod v1.0.0/go.mod h1:def=\n" +
+			"example.com/missing v0.1.0 h1:ghi=\n",
+	})
+
+	text := resultText(t, callTool(t, env, "gomod_project_search", map[string]any{"dir": dir, "symbol": "NewClient"}))
+	for _, want := range []string{
+		"Searched 2 module versions of example.com/app for symbol NewClient: 1 matches in 1 modules.\n",
+		"\nexample.com/testmod@v1.0.0 (1):\n  client.go:4: func NewClient\n",
+		"Not searched (1):\n  example.com/missing@v0.1.0: ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_project_search", map[string]any{
+		"dir": dir, "pattern": "returns a", "fixed": true,
+	}))
+	if !strings.Contains(text, "  client.go:3:// NewClient returns a client.\n") {
+		t.Errorf("unexpected pattern search:\n%s", text)
+	}
+
+	if result := callTool(t, env, "gomod_project_search", map[string]any{"dir": dir}); !result.IsError {
+		t.Errorf("expected an error without symbol or pattern: %s", resultText(t, result))
+	}
+}
+
+func TestToolsLicenses(t *testing.T) {
+	mit, err := licenseTexts.ReadFile("licenses/MIT.txt")
+	mustf(t, err, "read MIT reference")
+
+	apache, err := licenseTexts.ReadFile("licenses/Apache-2.0.txt")
+	mustf(t, err, "read Apache reference")
+
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"LICENSE-MIT":              string(mit),
+		"LICENSE-APACHE":           string(apache),
+		"third_party/x/LICENSE.md": "Proprietary. All rights reserved.",
+		"main.go":                  "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_licenses", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+	}))
+
+	if !strings.Contains(text, "License of example.com/testmod@v1.0.0: Apache-2.0 OR MIT") {
+		t.Errorf("expected dual license expression:\n%s", text)
+	}
+
+	if !strings.Contains(text, "third_party/x/LICENSE.md  unknown") || !strings.Contains(text, "coverage below") {
+		t.Errorf("expected unknown third-party license flagged:\n%s", text)
+	}
+
+	text = resultText(t, callTool(t, env, "gomod_licenses", map[string]any{
+		"module":  "example.com/testmod",
+		"version": "v1.0.0",
+		"text":    true,
+	}))
+
+	if !strings.Contains(text, "\nLICENSE-MIT (MIT):\n\n"+strings.TrimRight(string(mit), "\n")+"\n") ||
+		!strings.Contains(text, "\nLICENSE-APACHE (Apache-2.0):\n\n") || strings.Contains(text, "Proprietary") {
+		t.Errorf("expected the root license texts:\n%s", text[:min(len(text), 2000)])
+	}
+}
+
+func TestToolsSetContext_DefaultsArguments(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	result := callTool(t, env, "gomod_set_context", map[string]any{"module": "example.com/testmod", "version": "latest"})
+	if text := resultText(t, result); !strings.Contains(text, "version: v1.0.0") {
+		t.Errorf("set context should resolve latest, got:\n%s", text)
+	}
+
+	result = callTool(t, env, "gomod_read_file", map[string]any{"path": "main.go"})
+	if result.IsError || resultText(t, result) != "package main\n" {
+		t.Errorf("read_file with context = %q", resultText(t, result))
+	}
+
+	result = callTool(t, env, "gomod_get_context", map[string]any{})
+	if text := resultText(t, result); !strings.Contains(text, "example.com/testmod") ||
+		!strings.Contains(text, "package: (not set)") {
+		t.Errorf("get context:\n%s", text)
+	}
+
+	callTool(t, env, "gomod_set_context", map[string]any{})
+
+	_, err := env.session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "gomod_read_file",
+		Arguments: map[string]any{"path": "main.go"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "module") {
+		t.Errorf("read_file without context should fail validation, got %v", err)
+	}
+}
+
+func TestToolsSetContext_ListsArgumentsAsOptional(t *testing.T) {
+	env := setupTestEnv(t, http.NotFoundHandler())
+	defer env.close()
+
+	tools, err := env.session.ListTools(context.Background(), nil)
+	mustf(t, err, "list tools")
+
+	// Handles also stand in for path and from.
+	want := map[string]string{"gomod_tags": "", "gomod_read_file": "", "gomod_compare_file": "to"}
+
+	for _, tool := range tools.Tools {
+		w, ok := want[tool.Name]
+		if !ok {
+			continue
+		}
+
+		schema, _ := tool.InputSchema.(map[string]any)
+		required, _ := schema["required"].([]any)
+
+		var got []string
+		for _, r := range required {
+			got = append(got, r.(string))
+		}
+
+		if strings.Join(got, ",") != w {
+			t.Errorf("%s required = %v, want [%s]", tool.Name, got, w)
+		}
+	}
+}
+
+func TestToolsAliases_ExpandModuleArgument(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData), func(svc *services) {
+		svc.aliases = ModuleAliases{"tm": "example.com/testmod"}
+	})
+	defer env.close()
+
+	result := callTool(t, env, "gomod_read_file", map[string]any{"module": "tm", "version": "v1.0.0", "path": "main.go"})
+	if result.IsError || resultText(t, result) != "package main\n" {
+		t.Errorf("read_file via alias = %q", resultText(t, result))
+	}
+
+	result = callTool(t, env, "gomod_aliases", map[string]any{})
+	if text := resultText(t, result); !strings.Contains(text, "tm     example.com/testmod") {
+		t.Errorf("aliases:\n%s", text)
+	}
+}
+
+func TestToolsListVersions_GitTags(t *testing.T) {
+	repo := createTaggedRepo(t, "v0.1.0", "v1.0.0", "v1.1.0")
+	origin := `{"Version":"v1.0.0","Origin":{"VCS":"git","URL":"` + repo + `"}}`
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mono/@v/list":
+			_, _ = w.Write([]byte("v0.1.0\nv1.0.0\n"))
+		case "/example.com/mono/@latest":
+			_, _ = w.Write([]byte(origin))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_list_versions", map[string]any{
+		"module": "example.com/mono", "git_tags": true,
+	}))
+
+	for _, want := range []string{
+		"v1.0.0\nv1.1.0  (git tag only, not on the proxy yet)\n",
+		"Git tags: 3 versions in " + repo + ", 1 not on the proxy",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestToolsCompareFile(t *testing.T) {
+	zips := map[string][]byte{
+		"v1.0.0": createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n", "main.go": "package main\n\nfunc a() {}\n",
+		}),
+		"v1.1.0": createTestZip(t, "example.com/testmod@v1.1.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n", "main.go": "package main\n\nfunc b() {}\n", "new.go": "package main\n",
+		}),
+	}
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := strings.TrimSuffix(path.Base(r.URL.Path), ".zip")
+		if data, ok := zips[version]; ok {
+			_, _ = w.Write(data)
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer env.close()
+
+	compare := func(file string) *mcp.CallToolResult {
+		return callTool(t, env, "gomod_compare_file", map[string]any{
+			"module": "example.com/testmod", "path": file, "from": "v1.0.0", "to": "v1.1.0",
+		})
+	}
+
+	want := "--- example.com/testmod@v1.0.0/main.go\n+++ example.com/testmod@v1.1.0/main.go\n" +
+		"@@ -1,3 +1,3 @@\n package main\n \n-func a() {}\n+func b() {}\n"
+	if text := resultText(t, compare("main.go")); text != want {
+		t.Errorf("main.go diff:\n%s", text)
+	}
+
+	if text := resultText(t, compare("new.go")); !strings.HasPrefix(text, "--- /dev/null\n") {
+		t.Errorf("added file diff:\n%s", text)
+	}
+
+	if text := resultText(t, compare("go.mod")); !strings.Contains(text, "identical") {
+		t.Errorf("go.mod: %s", text)
+	}
+
+	diff := callTool(t, env, "gomod_diff", map[string]any{
+		"module": "example.com/testmod", "path": "main.go", "versionA": "v1.0.0", "versionB": "v1.1.0",
+	})
+	if text := resultText(t, diff); text != want {
+		t.Errorf("gomod_diff of main.go:\n%s", text)
+	}
+
+	if result := compare("missing.go"); !result.IsError {
+		t.Errorf("missing file should be an error, got %s", resultText(t, result))
+	}
+}
+
+func TestToolsCompareVersions(t *testing.T) {
+	zips := map[string][]byte{
+		"v1.0.0": createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n", "main.go": "package main\n", "old.go": "package main\n",
+		}),
+		"v1.1.0": createTestZip(t, "example.com/testmod@v1.1.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n", "main.go": "package main\n\nfunc b() {}\n", "new.go": "package main\n",
+		}),
+	}
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := strings.TrimSuffix(path.Base(r.URL.Path), ".zip")
+		if data, ok := zips[version]; ok {
+			_, _ = w.Write(data)
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_compare_versions", map[string]any{
+		"module": "example.com/testmod", "from": "v1.0.0", "to": "v1.1.0",
+	}))
+
+	for _, want := range []string{
+		"Comparing example.com/testmod@v1.1.0 against v1.0.0:\n",
+		"\nModified (1):\n  M main.go\n",
+		"\nAdded in v1.1.0 (1):\n  A new.go\n",
+		"\nRemoved in v1.1.0 (1):\n  D old.go\n",
+		"\nUnchanged: 1 files\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestToolsUpgradeReport(t *testing.T) {
+	mit, err := licenseTexts.ReadFile("licenses/MIT.txt")
+	mustf(t, err, "read MIT reference")
+
+	mods := map[string]string{
+		"v1.0.0": "module example.com/testmod\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n",
+		"v1.1.0": "module example.com/testmod\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n",
+		"v1.2.0": "module example.com/testmod\n\ngo 1.22\n\nrequire (\n\texample.com/dep v1.1.0\n" +
+			"\texample.com/extra v0.1.0\n)\n\nretract v1.1.0 // Broken build.\n",
+	}
+
+	changelog := "# Changelog\n\n## v1.2.0\n\n- Client.Close\n\n## v1.1.0\n\n- Broken\n\n## v1.0.0\n\n- First\n"
+
+	zips := map[string][]byte{
+		"v1.0.0": createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+			"go.mod":    mods["v1.0.0"],
+			"LICENSE":   string(mit),
+			"client.go": "package testmod\n\ntype Client struct{}\n\nfunc (c *Client) Do(n int) {}\n\nfunc Old() {}\n",
+		}),
+		"v1.1.0": createTestZip(t, "example.com/testmod@v1.1.0/", map[string]string{"go.mod": mods["v1.1.0"]}),
+		"v1.2.0": createTestZip(t, "example.com/testmod@v1.2.0/", map[string]string{
+			"go.mod":       mods["v1.2.0"],
+			"CHANGELOG.md": changelog,
+			"client.go": "package testmod\n\ntype Client struct{}\n\nfunc (c *Client) Do(n int, s string) {}\n\n" +
+				"func (c *Client) Close() error { return nil }\n",
+		}),
+	}
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch file := path.Base(r.URL.Path); {
+		case r.URL.Path == "/example.com/testmod/@v/list":
+			_, _ = w.Write([]byte("v1.0.0\nv1.1.0\nv1.2.0\n"))
+		case r.URL.Path == "/example.com/testmod/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		case r.URL.Path == "/v1/querybatch":
+			_, _ = w.Write([]byte(`{"results":[{"vulns":[{"id":"GO-2025-0001"}]},{}]}`))
+		case r.URL.Path == "/v1/vulns/GO-2025-0001":
+			_, _ = w.Write([]byte(`{"id":"GO-2025-0001","summary":"Panic in Do",` +
+				`"database_specific":{"severity":"HIGH"}}`))
+		case strings.HasSuffix(file, ".mod") && mods[strings.TrimSuffix(file, ".mod")] != "":
+			_, _ = w.Write([]byte(mods[strings.TrimSuffix(file, ".mod")]))
+		case strings.HasSuffix(file, ".zip") && zips[strings.TrimSuffix(file, ".zip")] != nil:
+			_, _ = w.Write(zips[strings.TrimSuffix(file, ".zip")])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_upgrade_report", map[string]any{
+		"module": "example.com/testmod", "from": "v1.0.0", "to": "latest",
+	}))
+
+	for _, want := range []string{
+		"Upgrade of example.com/testmod from v1.0.0 to v1.2.0:\n",
+		"  go directive: 1.21 -> 1.22\n",
+		"  dependencies: 1 added, 0 removed, 1 changed\n",
+		"  API: 1 added, 1 removed, 1 changed; 2 breaking (see gomod_apidiff)\n",
+		"  license: MIT -> unknown\n",
+		"  retractions: 1 of the versions taken in\n",
+		"  vulnerabilities: 1 fixed, 0 remaining, 0 introduced\n",
+		"  changelog: 2 entries in CHANGELOG.md\n",
+		"  ~ example.com/dep v1.0.0 -> v1.1.0\n  + example.com/extra v0.1.0\n",
+		"  example.com/testmod:\n    + func (c *Client) Close() error\n" +
+			"    ~ func (c *Client) Do(n int)\n      -> func (c *Client) Do(n int, s string)\n    - func Old()\n",
+		"Retracted versions (1):\n  v1.1.0: Broken build.\n",
+		"Fixed vulnerabilities (1):\n  GO-2025-0001 (HIGH): Panic in Do\n",
+		"Changelog (CHANGELOG.md):\n\n## v1.2.0\n\n- Client.Close\n\n## v1.1.0\n\n- Broken\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in report:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "First") {
+		t.Errorf("changelog includes the from version:\n%s", text)
+	}
+
+	result := callTool(t, env, "gomod_upgrade_report", map[string]any{
+		"module": "example.com/testmod", "from": "v1.2.0", "to": "v1.0.0",
+	})
+	if !result.IsError {
+		t.Errorf("expected an error for a downgrade: %s", resultText(t, result))
+	}
+}
+
+func TestToolsFileHandles(t *testing.T) {
+	zips := map[string][]byte{
+		"v1.0.0": createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n", "main.go": "package main\n",
+		}),
+		"v1.1.0": createTestZip(t, "example.com/testmod@v1.1.0/", map[string]string{
+			"go.mod": "module example.com/testmod\n", "main.go": "package main // v1.1.0\n",
+		}),
+	}
+
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if data, ok := zips[strings.TrimSuffix(path.Base(r.URL.Path), ".zip")]; ok {
+			_, _ = w.Write(data)
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer env.close()
+
+	list := resultText(t, callTool(t, env, "gomod_list_files", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "handles": true,
+	}))
+	if !strings.Contains(list, "[1] go.mod (27 bytes)\n[2] main.go (13 bytes)\n") {
+		t.Fatalf("list with handles:\n%s", list)
+	}
+
+	if text := resultText(t, callTool(t, env, "gomod_read_file", map[string]any{"handle": 2})); text != "package main\n" {
+		t.Errorf("read by handle = %q", text)
+	}
+
+	diff := resultText(t, callTool(t, env, "gomod_compare_file", map[string]any{"handle": 2, "to": "v1.1.0"}))
+	if !strings.Contains(diff, "+package main // v1.1.0\n") {
+		t.Errorf("compare by handle:\n%s", diff)
+	}
+
+	// The same file keeps its handle.
+	list = resultText(t, callTool(t, env, "gomod_list_files", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "path": "main", "handles": true,
+	}))
+	if !strings.Contains(list, "[2] main.go (13 bytes)\n") {
+		t.Errorf("relisted handles:\n%s", list)
+	}
+
+	if result := callTool(t, env, "gomod_read_file", map[string]any{"handle": 9}); !result.IsError {
+		t.Errorf("unknown handle should fail, got %q", resultText(t, result))
+	}
+}
+
+func TestToolsOutputFormat(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData), func(svc *services) {
+		svc.output = newOutputFormatter(outputMarkdown)
+	})
+	defer env.close()
+
+	read := map[string]any{"module": "example.com/testmod", "version": "v1.0.0", "path": "main.go"}
+
+	if text := resultText(t, callTool(t, env, "gomod_read_file", read)); text != "```go\npackage main\n```\n" {
+		t.Errorf("markdown read_file = %q", text)
+	}
+
+	read["format"] = outputPlain
+	if text := resultText(t, callTool(t, env, "gomod_read_file", read)); text != "package main\n" {
+		t.Errorf("plain read_file = %q", text)
+	}
+
+	text := resultText(t, callTool(t, env, "gomod_read_mod", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "format": outputJSON,
+	}))
+
+	var out jsonOutput
+	err := json.Unmarshal([]byte(text), &out)
+
+	var info goModInfo
+	if err == nil {
+		err = json.Unmarshal(out.Result, &info)
+	}
+
+	if err != nil || out.Tool != "gomod_read_mod" || info.Module != "example.com/testmod" {
+		t.Errorf("json read_mod = %s (%v)", text, err)
+	}
+
+	// Tools without structured content have no json format.
+	result := callTool(t, env, "gomod_dir_stats", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "format": outputJSON,
+	})
+	if text := resultText(t, result); !result.IsError || !strings.Contains(text, "no structured content") {
+		t.Errorf("json dir_stats = %q", text)
+	}
+
+	read["format"] = "html"
+	if result := callTool(t, env, "gomod_read_file", read); !result.IsError {
+		t.Errorf("unknown format should fail, got %q", resultText(t, result))
+	}
+
+	// gomod_tags keeps its own format argument.
+	result = callTool(t, env, "gomod_tags", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "format": "etags",
+	})
+	if text := resultText(t, result); result.IsError || !strings.HasPrefix(text, "```text\n\f\n") {
+		t.Errorf("etags = %q", text)
+	}
+
+	tools, err := env.session.ListTools(context.Background(), nil)
+	mustf(t, err, "list tools")
+
+	for _, tool := range tools.Tools {
+		schema, _ := tool.InputSchema.(map[string]any)
+		props, _ := schema["properties"].(map[string]any)
+
+		format, _ := props["format"].(map[string]any)
+		if format == nil {
+			t.Errorf("%s lists no format argument", tool.Name)
+
+			continue
+		}
+
+		_, structured := structuredTools[tool.Name]
+		if enum, _ := format["enum"].([]any); tool.Name != "gomod_tags" && slices.Contains(enum, outputJSON) != structured {
+			t.Errorf("%s formats = %v", tool.Name, enum)
+		}
+	}
+}
+
+func TestToolsProxyStatus(t *testing.T) {
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang.org/x/mod/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v0.33.0"}`))
+		case "/sumdb/latest":
+			_, _ = w.Write([]byte("go.sum database tree\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}), func(svc *services) {
+		svc.proxy.health = NewHealthTracker()
+		svc.sumDBURL = svc.proxy.baseURL + "/sumdb"
+	})
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_proxy_status", map[string]any{}))
+
+	for _, want := range []string{"proxy  " + env.proxyHTTP.URL + "  ", "sumdb  " + env.proxyHTTP.URL + "/sumdb"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "FAIL") {
+		t.Errorf("unexpected failure:\n%s", text)
+	}
+}
+
+func TestToolsProxyStatusGOPROXYList(t *testing.T) {
+	env := setupTestEnv(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/golang.org/x/mod/@latest" {
+			_, _ = w.Write([]byte(`{"Version":"v0.33.0"}`))
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}), func(svc *services) {
+		svc.proxy.proxies = []proxyHop{{url: svc.proxy.baseURL + "/mirror"}, {url: svc.proxy.baseURL}, {url: "direct"}}
+		svc.sumDBURL = svc.proxy.baseURL + "/sumdb"
+	})
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_proxy_status", map[string]any{}))
+
+	for _, want := range []string{
+		"proxy  " + env.proxyHTTP.URL + "/mirror  FAIL",
+		"proxy  " + env.proxyHTTP.URL + "         ok",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "direct") {
+		t.Errorf("direct is not an endpoint:\n%s", text)
+	}
+}
+
+func TestToolsGrep(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{
+		"go.mod":  "module example.com/testmod\n",
+		"main.go": "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+	})
+
+	env := setupTestEnv(t, fakeProxy(zipData))
+	defer env.close()
+
+	text := resultText(t, callTool(t, env, "gomod_grep", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "pattern": `println\(`, "context": 1,
+	}))
+	if !strings.Contains(text, "main.go-3-func main() {\nmain.go:4:\tprintln(\"hi\")\nmain.go-5-}\n") {
+		t.Errorf("unexpected grep output:\n%s", text)
+	}
+
+	result := callTool(t, env, "gomod_grep", map[string]any{
+		"module": "example.com/testmod", "version": "v1.0.0", "pattern": "(",
+	})
+	if !result.IsError {
+		t.Errorf("invalid pattern should fail, got %q", resultText(t, result))
+	}
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// maxUpgradeAPIChanges bounds the API changes listed in an upgrade
+	// report; the counts cover all of them.
+	maxUpgradeAPIChanges = 100
+	// maxUpgradeChangelog bounds the changelog text quoted, in bytes.
+	maxUpgradeChangelog = 8000
+)
+
+// upgradeVersion is one side of an upgrade.
+type upgradeVersion struct {
+	version string
+	files   moduleFiles
+	goMod   *modfile.File
+	api     map[string]packageAPI
+	license string
+}
+
+// depChange is a requirement that differs between two go.mod files. A
+// version is empty on the side that does not require the module.
+type depChange struct {
+	path     string
+	from, to string
+}
+
+// upgradeReport is what changes when a module is upgraded. The
+// retractions and vulnerabilities come from services that may be
+// unreachable; their sections then hold the error instead.
+type upgradeReport struct {
+	module   string
+	from, to *upgradeVersion
+
+	deps []depChange
+	api  []apiChange
+
+	changelogFile string
+	changelog     []changelogEntry
+
+	retracted  []string // versions after from up to to
+	retraction *modfile.File
+	retractErr error
+
+	fixed, remaining, introduced []*vulnFinding
+	vulnErr                      error
+}
+
+func loadUpgradeVersion(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, mod, version string,
+) (*upgradeVersion, error) {
+	data, err := readGoMod(ctx, proxy, modCache, mod, version)
+	if err != nil {
+		return nil, fmt.Errorf("read go.mod of %s: %w", version, err)
+	}
+
+	goMod, err := modfile.ParseLax("go.mod", []byte(data), nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse go.mod of %s: %w", version, err)
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, mod, version)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := moduleAPI(mf, "")
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", version, err)
+	}
+
+	_, root, err := findLicenses(mf)
+	if err != nil {
+		return nil, fmt.Errorf("find licenses of %s: %w", version, err)
+	}
+
+	return &upgradeVersion{version: version, files: mf, goMod: goMod, api: api, license: moduleLicense(root)}, nil
+}
+
+// diffRequirements compares the require directives of two go.mod files,
+// sorted by module path.
+func diffRequirements(from, to *modfile.File) []depChange {
+	versions := func(f *modfile.File) map[string]string {
+		m := make(map[string]string, len(f.Require))
+		for _, r := range f.Require {
+			m[r.Mod.Path] = r.Mod.Version
+		}
+
+		return m
+	}
+
+	old, cur := versions(from), versions(to)
+	paths := sortedKeys(old)
+
+	for p := range cur {
+		if _, ok := old[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+
+	slices.Sort(paths)
+
+	var changes []depChange
+
+	for _, p := range paths {
+		if old[p] != cur[p] {
+			changes = append(changes, depChange{path: p, from: old[p], to: cur[p]})
+		}
+	}
+
+	return changes
+}
+
+// addRetractions lists the versions after from up to to that the latest
+// go.mod retracts.
+func (r *upgradeReport) addRetractions(ctx context.Context, proxy *ProxyClient) {
+	versions, err := proxy.ListVersions(ctx, r.module)
+	if err != nil {
+		r.retractErr = fmt.Errorf("list versions: %w", err)
+
+		return
+	}
+
+	_, latest, err := latestModFile(ctx, proxy, r.module)
+	if err != nil {
+		r.retractErr = err
+
+		return
+	}
+
+	r.retraction = latest
+
+	if !slices.Contains(versions, r.to.version) {
+		versions = append(versions, r.to.version)
+	}
+
+	semver.Sort(versions)
+
+	for _, v := range versions {
+		if semver.Compare(v, r.from.version) > 0 && semver.Compare(v, r.to.version) <= 0 && retraction(latest, v) != nil {
+			r.retracted = append(r.retracted, v)
+		}
+	}
+}
+
+// addVulns sorts the vulnerabilities of the two versions into those the
+// upgrade fixes, those it keeps and those it introduces.
+func (r *upgradeReport) addVulns(ctx context.Context, osv *OSVClient) {
+	findings, err := scanVulns(ctx, osv, []module.Version{
+		{Path: r.module, Version: r.from.version},
+		{Path: r.module, Version: r.to.version},
+	})
+	if err != nil {
+		r.vulnErr = err
+
+		return
+	}
+
+	for _, f := range findings {
+		var inFrom, inTo bool
+
+		for _, v := range f.affected {
+			inFrom = inFrom || v.Version == r.from.version
+			inTo = inTo || v.Version == r.to.version
+		}
+
+		switch {
+		case !inTo:
+			r.fixed = append(r.fixed, f)
+		case inFrom:
+			r.remaining = append(r.remaining, f)
+		default:
+			r.introduced = append(r.introduced, f)
+		}
+	}
+}
+
+// addChangelog extracts the entries for the versions the upgrade takes in
+// from the new version's changelog.
+func (r *upgradeReport) addChangelog() error {
+	p, err := findChangelog(r.to.files)
+	if err != nil || p == "" {
+		return err
+	}
+
+	text, err := r.to.files.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", p, err)
+	}
+
+	r.changelogFile = p
+	r.changelog = changelogBetween(changelogEntries(text), r.from.version, r.to.version)
+
+	return nil
+}
+
+type upgradeReportInput struct {
+	Module string `json:"module" jsonschema:"Go module path"`
+	From   string `json:"from" jsonschema:"Current module version"`
+	To     string `json:"to" jsonschema:"Module version to upgrade to, or 'latest'"`
+}
+
+func handleUpgradeReport(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, osv *OSVClient, input upgradeReportInput,
+) (*mcp.CallToolResult, any, error) {
+	r := &upgradeReport{module: input.Module}
+
+	for i, v := range []string{input.From, input.To} {
+		version, err := resolveVersion(ctx, proxy, input.Module, v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		uv, err := loadUpgradeVersion(ctx, proxy, cache, modCache, input.Module, version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if i == 0 {
+			r.from = uv
+		} else {
+			r.to = uv
+		}
+	}
+
+	if semver.Compare(r.from.version, r.to.version) >= 0 {
+		return errorResult(fmt.Sprintf("%s is not newer than %s; from must be the older version.",
+			r.to.version, r.from.version)), nil, nil
+	}
+
+	r.deps = diffRequirements(r.from.goMod, r.to.goMod)
+	r.api = diffAPI(r.from.api, r.to.api)
+
+	if err := r.addChangelog(); err != nil {
+		return nil, nil, err
+	}
+
+	r.addRetractions(ctx, proxy)
+	r.addVulns(ctx, osv)
+
+	if len(r.api) > maxUpgradeAPIChanges || r.changelogShown() < len(r.changelog) {
+		noteTruncated(ctx)
+	}
+
+	return textResult(formatUpgradeReport(r)), nil, nil
+}
+
+func formatUpgradeReport(r *upgradeReport) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Upgrade of %s from %s to %s:\n", r.module, r.from.version, r.to.version)
+
+	formatUpgradeSummary(&sb, r)
+
+	if r.retraction != nil {
+		if rat := retraction(r.retraction, r.to.version); rat != nil {
+			fmt.Fprintf(&sb, "\nWarning: %s is itself retracted", r.to.version)
+
+			if rat.Rationale != "" {
+				fmt.Fprintf(&sb, ": %s", rat.Rationale)
+			}
+
+			sb.WriteString("\n")
+		}
+	}
+
+	formatDepChanges(&sb, r.deps)
+	writeAPIChanges(&sb, "API changes", r.module, r.api, nil, maxUpgradeAPIChanges,
+		"compare packages with gomod_compare_file")
+	formatUpgradeRetractions(&sb, r)
+
+	for _, s := range []struct {
+		title    string
+		findings []*vulnFinding
+	}{
+		{"Fixed vulnerabilities", r.fixed},
+		{"Remaining vulnerabilities", r.remaining},
+		{"Introduced vulnerabilities", r.introduced},
+	} {
+		if len(s.findings) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n%s (%d):\n", s.title, len(s.findings))
+
+		for _, f := range s.findings {
+			fmt.Fprintf(&sb, "  %s (%s): %s\n", f.vuln.ID, f.severity, f.vuln.title())
+		}
+	}
+
+	formatUpgradeChangelog(&sb, r)
+
+	return sb.String()
+}
+
+func formatUpgradeSummary(sb *strings.Builder, r *upgradeReport) {
+	goVersion := func(f *modfile.File) string {
+		if f.Go == nil {
+			return "none"
+		}
+
+		return f.Go.Version
+	}
+
+	if from, to := goVersion(r.from.goMod), goVersion(r.to.goMod); from != to {
+		fmt.Fprintf(sb, "  go directive: %s -> %s", from, to)
+	} else {
+		fmt.Fprintf(sb, "  go directive: %s (unchanged)", to)
+	}
+
+	if tc := r.to.goMod.Toolchain; tc != nil && (r.from.goMod.Toolchain == nil || r.from.goMod.Toolchain.Name != tc.Name) {
+		fmt.Fprintf(sb, ", toolchain %s", tc.Name)
+	}
+
+	sb.WriteString("\n")
+
+	var added, removed, changed int
+
+	for _, d := range r.deps {
+		switch {
+		case d.from == "":
+			added++
+		case d.to == "":
+			removed++
+		default:
+			changed++
+		}
+	}
+
+	fmt.Fprintf(sb, "  dependencies: %s\n", changeCounts(added, removed, changed))
+
+	added, removed, changed = 0, 0, 0
+
+	for _, c := range r.api {
+		switch c.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		default:
+			changed++
+		}
+	}
+
+	fmt.Fprintf(sb, "  API: %s", changeCounts(added, removed, changed))
+
+	if breaking, _, _ := classifyAPIChanges(r.api, r.from.api); len(breaking) > 0 {
+		fmt.Fprintf(sb, "; %d breaking (see gomod_apidiff)", len(breaking))
+	}
+
+	sb.WriteString("\n")
+
+	fromLicense, toLicense := cmp.Or(r.from.license, "unknown"), cmp.Or(r.to.license, "unknown")
+	if fromLicense != toLicense {
+		fmt.Fprintf(sb, "  license: %s -> %s\n", fromLicense, toLicense)
+	} else {
+		fmt.Fprintf(sb, "  license: %s (unchanged)\n", toLicense)
+	}
+
+	switch {
+	case r.retractErr != nil:
+		fmt.Fprintf(sb, "  retractions: unavailable (%v)\n", r.retractErr)
+	case len(r.retracted) == 0:
+		sb.WriteString("  retractions: none\n")
+	default:
+		fmt.Fprintf(sb, "  retractions: %d of the versions taken in\n", len(r.retracted))
+	}
+
+	if r.vulnErr != nil {
+		fmt.Fprintf(sb, "  vulnerabilities: unavailable (%v)\n", r.vulnErr)
+	} else {
+		fmt.Fprintf(sb, "  vulnerabilities: %d fixed, %d remaining, %d introduced\n",
+			len(r.fixed), len(r.remaining), len(r.introduced))
+	}
+
+	switch {
+	case r.changelogFile == "":
+		sb.WriteString("  changelog: not found\n")
+	case len(r.changelog) == 0:
+		fmt.Fprintf(sb, "  changelog: no entries after %s in %s\n", r.from.version, r.changelogFile)
+	default:
+		fmt.Fprintf(sb, "  changelog: %d entries in %s\n", len(r.changelog), r.changelogFile)
+	}
+}
+
+func changeCounts(added, removed, changed int) string {
+	if added+removed+changed == 0 {
+		return "unchanged"
+	}
+
+	return fmt.Sprintf("%d added, %d removed, %d changed", added, removed, changed)
+}
+
+func formatDepChanges(sb *strings.Builder, deps []depChange) {
+	if len(deps) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "\nDependency changes (%d):\n", len(deps))
+
+	for _, d := range deps {
+		switch {
+		case d.from == "":
+			fmt.Fprintf(sb, "  + %s %s\n", d.path, d.to)
+		case d.to == "":
+			fmt.Fprintf(sb, "  - %s %s\n", d.path, d.from)
+		default:
+			fmt.Fprintf(sb, "  ~ %s %s -> %s\n", d.path, d.from, d.to)
+		}
+	}
+}
+
+func formatUpgradeRetractions(sb *strings.Builder, r *upgradeReport) {
+	if len(r.retracted) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "\nRetracted versions (%d):\n", len(r.retracted))
+
+	for _, v := range r.retracted {
+		fmt.Fprintf(sb, "  %s", v)
+
+		if rat := retraction(r.retraction, v); rat.Rationale != "" {
+			fmt.Fprintf(sb, ": %s", rat.Rationale)
+		}
+
+		sb.WriteString("\n")
+	}
+}
+
+func formatUpgradeChangelog(sb *strings.Builder, r *upgradeReport) {
+	if len(r.changelog) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "\nChangelog (%s):\n\n", r.changelogFile)
+	writeChangelog(sb, r.changelog, r.changelogShown(), r.changelogFile)
+}
+
+// changelogShown returns how many changelog entries fit in
+// maxUpgradeChangelog.
+func (r *upgradeReport) changelogShown() int {
+	return changelogFit(r.changelog, maxUpgradeChangelog)
+}
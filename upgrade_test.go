@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestDiffRequirements(t *testing.T) {
+	parse := func(src string) *modfile.File {
+		f, err := modfile.ParseLax("go.mod", []byte(src), nil)
+		mustf(t, err, "parse go.mod")
+
+		return f
+	}
+
+	from := parse("module m\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n" +
+		"\texample.com/c v0.1.0 // indirect\n)\n")
+	to := parse("module m\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.2.0\n\texample.com/d v0.3.0\n)\n")
+
+	want := []depChange{
+		{path: "example.com/b", from: "v1.0.0", to: "v1.2.0"},
+		{path: "example.com/c", from: "v0.1.0"},
+		{path: "example.com/d", to: "v0.3.0"},
+	}
+
+	got := diffRequirements(from, to)
+	if len(got) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultUsageLimit   = 5
+	defaultUsageContext = 3
+)
+
+// Usage categories, in order of preference when picking representative
+// snippets.
+const (
+	usageExample  = "example"
+	usageTest     = "test"
+	usageInternal = "internal"
+	usageCode     = "code"
+)
+
+var usageRank = map[string]int{usageExample: 0, usageTest: 1, usageInternal: 2, usageCode: 3}
+
+// usage is a reference to a symbol found in the module's source.
+type usage struct {
+	file      *goFile
+	line      int
+	category  string
+	enclosing string // enclosing function, e.g. "ExampleClient" or "Client.Do"
+}
+
+// findUsages returns references to symbol ("Name" or "Type.Method")
+// declared in the package with import path pkgPath. References in the
+// declaring package match the bare identifier; elsewhere they must be
+// qualified with the package's import name. Method references are matched
+// by selector name only, since no type information is available.
+func findUsages(fset *token.FileSet, files []*goFile, module, pkgPath, symbol string) []usage {
+	pkgDir := strings.TrimPrefix(strings.TrimPrefix(pkgPath, module), "/")
+	if pkgDir == "" {
+		pkgDir = "."
+	}
+
+	typeName, method, isMethod := strings.Cut(symbol, ".")
+
+	var result []usage
+
+	for _, f := range files {
+		inPkg := path.Dir(f.path) == pkgDir
+		importName := importNameFor(f.ast, pkgPath)
+
+		if !inPkg && importName == "" {
+			continue
+		}
+
+		match := func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if isMethod {
+					return n.Sel.Name == method
+				}
+
+				x, ok := n.X.(*ast.Ident)
+
+				return ok && x.Name == importName && n.Sel.Name == symbol
+			case *ast.Ident:
+				return inPkg && !isMethod && n.Name == symbol
+			}
+
+			return false
+		}
+
+		if isMethod && !inPkg && !mentionsType(f.ast, importName, typeName) {
+			continue
+		}
+
+		result = append(result, fileUsages(fset, f, match)...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return usageRank[result[i].category] < usageRank[result[j].category]
+	})
+
+	return result
+}
+
+// fileUsages walks the function bodies of f and returns one usage per
+// enclosing function for nodes accepted by match.
+func fileUsages(fset *token.FileSet, f *goFile, match func(ast.Node) bool) []usage {
+	var result []usage
+
+	isTest := strings.HasSuffix(f.path, "_test.go")
+
+	for _, decl := range f.ast.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		var found ast.Node
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+
+			if n != nil && match(n) {
+				found = n
+
+				return false
+			}
+
+			return true
+		})
+
+		if found == nil {
+			continue
+		}
+
+		category := usageCode
+
+		switch {
+		case isTest && strings.HasPrefix(fn.Name.Name, "Example"):
+			category = usageExample
+		case isTest:
+			category = usageTest
+		case strings.HasPrefix(f.path, "internal/") || strings.Contains(f.path, "/internal/"):
+			category = usageInternal
+		}
+
+		result = append(result, usage{
+			file:      f,
+			line:      fset.Position(found.Pos()).Line,
+			category:  category,
+			enclosing: funcDisplayName(fn),
+		})
+	}
+
+	return result
+}
+
+// importNameFor returns the name under which file imports pkgPath, or ""
+// if it does not import it. Unnamed imports use the last path element,
+// ignoring a major version suffix.
+func importNameFor(file *ast.File, pkgPath string) string {
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p != pkgPath {
+			continue
+		}
+
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+
+		return defaultImportName(p)
+	}
+
+	return ""
+}
+
+// defaultImportName guesses the package name of an import path from its
+// last element, skipping a /vN major version suffix.
+func defaultImportName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' {
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = elems[len(elems)-2]
+		}
+	}
+
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// mentionsType reports whether the file refers to importName.typeName,
+// used to limit method matches to files that deal with the type.
+func mentionsType(file *ast.File, importName, typeName string) bool {
+	found := false
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == typeName {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == importName {
+				found = true
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// snippet returns the lines around line (1-based) with line numbers.
+func snippet(src string, line, contextLines int) string {
+	lines := strings.Split(src, "\n")
+
+	start := max(line-1-contextLines, 0)
+	end := min(line+contextLines, len(lines))
+
+	var sb strings.Builder
+
+	for i := start; i < end; i++ {
+		fmt.Fprintf(&sb, "%5d | %s\n", i+1, lines[i])
+	}
+
+	return sb.String()
+}
+
+type usageExamplesInput struct {
+	Module  string `json:"module" jsonschema:"Go module path"`
+	Version string `json:"version" jsonschema:"Module version or 'latest'"`
+	Symbol  string `json:"symbol" jsonschema:"Exported symbol: Name or Type.Method"`
+	Package string `json:"package,omitempty" jsonschema:"Declaring package (import path or directory), default root"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of snippets (default 5)"`
+	Context int    `json:"context,omitempty" jsonschema:"Lines of context around each usage (default 3)"`
+}
+
+func handleUsageExamples(
+	ctx context.Context, proxy *ProxyClient, cache *ZipCache,
+	modCache *ModCache, input usageExamplesInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.Symbol == "" {
+		return errorResult("A symbol is required, e.g. \"NewClient\" or \"Client.Do\"."), nil, nil
+	}
+
+	version, err := resolveVersion(ctx, proxy, input.Module, input.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mf, err := openModule(ctx, proxy, cache, modCache, input.Module, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset, files, err := parseGoFiles(mf, "", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkgPath := packageImportPath(input.Module, input.Package)
+	if pkgPath == "" {
+		pkgPath = input.Module
+	}
+
+	usages := findUsages(fset, files, input.Module, pkgPath, input.Symbol)
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultUsageLimit
+	}
+
+	contextLines := input.Context
+	if contextLines <= 0 {
+		contextLines = defaultUsageContext
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Usages of %s in %s@%s (showing %d of %d):\n",
+		input.Symbol, input.Module, version, min(limit, len(usages)), len(usages))
+
+	for i, u := range usages {
+		if i == limit {
+			break
+		}
+
+		fmt.Fprintf(&sb, "\n%s:%d (%s, in %s)\n", u.file.path, u.line, u.category, u.enclosing)
+		sb.WriteString(snippet(u.file.src, u.line, contextLines))
+	}
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindUsages(t *testing.T) {
+	files := map[string]string{
+		"client.go": "package client\n\nfunc New() *Client { return &Client{} }\n\n" +
+			"type Client struct{}\n\nfunc (c *Client) Do() {}\n\nfunc Default() *Client { return New() }\n",
+		"example_test.go": "package client_test\n\nimport \"example.com/client\"\n\n" +
+			"func ExampleNew() {\n\tc := client.New()\n\tc.Do()\n}\n",
+		"client_test.go": "package client\n\nimport \"testing\"\n\nfunc TestNew(t *testing.T) {\n\t_ = New()\n}\n",
+		"internal/wrap/wrap.go": "package wrap\n\nimport c \"example.com/client\"\n\n" +
+			"func Wrap() { x := &c.Client{}; x.Do() }\n",
+		"other/other.go": "package other\n\nfunc New() {}\n\nfunc F() { New() }\n",
+	}
+
+	entry, err := NewZipCache().Put("example.com/client", "v1.0.0",
+		createTestZip(t, "example.com/client@v1.0.0/", files))
+
+	mustf(t, err, "put zip in cache")
+
+	fset, parsed, err := parseGoFiles(zipFiles{entry}, "", 0)
+
+	mustf(t, err, "parse go files")
+
+	usages := findUsages(fset, parsed, "example.com/client", "example.com/client", "New")
+
+	var got []string
+
+	for _, u := range usages {
+		got = append(got, u.category+":"+u.file.path+":"+u.enclosing)
+	}
+
+	want := []string{
+		"example:example_test.go:ExampleNew",
+		"test:client_test.go:TestNew",
+		"code:client.go:Default",
+	}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("usages of New:\n got %v\nwant %v", got, want)
+	}
+
+	usages = findUsages(fset, parsed, "example.com/client", "example.com/client", "Client.Do")
+
+	got = nil
+
+	for _, u := range usages {
+		got = append(got, u.category+":"+u.file.path)
+	}
+
+	want = []string{"example:example_test.go", "internal:internal/wrap/wrap.go"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("usages of Client.Do:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestDefaultImportName(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"example.com/client", "client"},
+		{"github.com/foo/bar/v2", "bar"},
+		{"github.com/foo/go-yaml", "go_yaml"},
+		{"v2", "v2"},
+	}
+
+	for _, tt := range tests {
+		if got := defaultImportName(tt.input); got != tt.want {
+			t.Errorf("defaultImportName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	got := snippet("a\nb\nc\nd\ne\n", 3, 1)
+	want := "    2 | b\n    3 | c\n    4 | d\n"
+
+	if got != want {
+		t.Errorf("snippet = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+)
+
+const maxGoGetPageSize = 1 << 20 // 1 MB
+
+// errNoGoImport is returned when a page has no go-import tag for a path.
+var errNoGoImport = errors.New("no go-import meta tag")
+
+// goImport is a go-import meta tag: the import path prefix a repository
+// serves, the repository's VCS and its root URL.
+type goImport struct {
+	prefix string
+	vcs    string
+	repo   string
+}
+
+// VanityResolver looks up the repository behind a custom import path,
+// such as k8s.io/api or gopkg.in/yaml.v3, from the go-import meta tags
+// its ?go-get=1 page serves, as the go command does. Results are kept for
+// the life of the process.
+type VanityResolver struct {
+	baseURL string // "" for https://<import path>; tests serve the pages
+	client  *http.Client
+	offline bool
+
+	mu    sync.Mutex
+	cache map[string]goImport
+}
+
+// NewVanityResolver creates a resolver that fetches pages over HTTPS.
+func NewVanityResolver() *VanityResolver {
+	return &VanityResolver{
+		client: http.DefaultClient,
+		cache:  make(map[string]goImport),
+	}
+}
+
+// Resolve returns the go-import tag that covers importPath. When the tag
+// names a shorter prefix, the prefix's own page must confirm it, so that
+// a page cannot claim import paths it does not serve.
+func (r *VanityResolver) Resolve(ctx context.Context, importPath string) (goImport, error) {
+	r.mu.Lock()
+	imp, ok := r.cache[importPath]
+	r.mu.Unlock()
+
+	if ok {
+		return imp, nil
+	}
+
+	imp, err := r.lookup(ctx, importPath)
+	if err != nil {
+		return goImport{}, err
+	}
+
+	if imp.prefix != importPath {
+		root, err := r.lookup(ctx, imp.prefix)
+		if err != nil {
+			return goImport{}, fmt.Errorf("verify %s: %w", imp.prefix, err)
+		}
+
+		if root != imp {
+			return goImport{}, fmt.Errorf("go-import tags of %s and %s disagree", importPath, imp.prefix)
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[importPath] = imp
+	r.mu.Unlock()
+
+	return imp, nil
+}
+
+func (r *VanityResolver) lookup(ctx context.Context, importPath string) (goImport, error) {
+	if r.offline {
+		return goImport{}, ErrOffline
+	}
+
+	url := "https://" + importPath + "?go-get=1"
+	if r.baseURL != "" {
+		url = r.baseURL + "/" + importPath + "?go-get=1"
+	}
+
+	noteBackend(ctx, strings.SplitN(importPath, "/", 2)[0])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return goImport{}, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return goImport{}, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	imports, err := parseGoImports(io.LimitReader(resp.Body, maxGoGetPageSize))
+	if err != nil {
+		return goImport{}, fmt.Errorf("parse %s: %w", url, err)
+	}
+
+	// Like the go command, a page with a matching tag counts regardless
+	// of its status code, and one without fails with it.
+	imp, ok := matchGoImport(imports, importPath)
+	if !ok {
+		if resp.StatusCode != http.StatusOK {
+			return goImport{}, fmt.Errorf("%s: unexpected status %d", url, resp.StatusCode)
+		}
+
+		return goImport{}, fmt.Errorf("%s: %w for %s", url, errNoGoImport, importPath)
+	}
+
+	return imp, nil
+}
+
+// parseGoImports returns the go-import meta tags in the head of an HTML
+// page. Like the go command, it reads HTML leniently as XML and stops at
+// the body.
+func parseGoImports(r io.Reader) ([]goImport, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "ascii") {
+			return input, nil
+		}
+
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	var imports []goImport
+
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) || len(imports) > 0 {
+				return imports, nil
+			}
+
+			return nil, fmt.Errorf("read HTML: %w", err)
+		}
+
+		if e, ok := tok.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			return imports, nil
+		}
+
+		if e, ok := tok.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			return imports, nil
+		}
+
+		e, ok := tok.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") || metaAttr(e, "name") != "go-import" {
+			continue
+		}
+
+		if f := strings.Fields(metaAttr(e, "content")); len(f) == 3 {
+			imports = append(imports, goImport{prefix: f[0], vcs: f[1], repo: f[2]})
+		}
+	}
+}
+
+func metaAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// matchGoImport picks the tag whose prefix is importPath or one of its
+// parents. Tags for the "mod" protocol name a proxy rather than the
+// repository and are used only when there is no other.
+func matchGoImport(imports []goImport, importPath string) (goImport, bool) {
+	var fallback *goImport
+
+	for i, imp := range imports {
+		if importPath != imp.prefix && !strings.HasPrefix(importPath, imp.prefix+"/") {
+			continue
+		}
+
+		if imp.vcs != "mod" {
+			return imp, true
+		}
+
+		if fallback == nil {
+			fallback = &imports[i]
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+
+	return goImport{}, false
+}
+
+type resolveImportInput struct {
+	ImportPath string `json:"import_path" jsonschema:"Package import path, such as k8s.io/client-go/rest"`
+}
+
+func handleResolveImport(
+	ctx context.Context, proxy *ProxyClient, input resolveImportInput,
+) (*mcp.CallToolResult, any, error) {
+	importPath := strings.Trim(strings.TrimSpace(input.ImportPath), "/")
+	if err := module.CheckImportPath(importPath); err != nil {
+		return errorResult(fmt.Sprintf("Invalid import path: %v.", err)), nil, nil
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Import path: %s\n", importPath)
+
+	mod, info, err := importModule(ctx, proxy, importPath)
+
+	switch {
+	case err == nil:
+		fmt.Fprintf(&sb, "Module: %s (latest %s)\n", mod, info.Version)
+
+		if mod != importPath {
+			fmt.Fprintf(&sb, "Package directory: %s\n", strings.TrimPrefix(importPath, mod+"/"))
+		}
+	case errors.Is(err, ErrModuleNotFound):
+		sb.WriteString("Module: none of the path and its parents is a module on the proxy\n")
+	default:
+		fmt.Fprintf(&sb, "Module: unknown (%v)\n", err)
+	}
+
+	if info != nil && info.Origin != nil && info.Origin.URL != "" {
+		fmt.Fprintf(&sb, "Repository: %s (%s, from the proxy's origin information)\n",
+			info.Origin.URL, cmp.Or(info.Origin.VCS, "unknown VCS"))
+	}
+
+	if proxy.vanity == nil {
+		return textResult(sb.String()), nil, nil
+	}
+
+	imp, err := proxy.vanity.Resolve(ctx, importPath)
+	if err != nil {
+		fmt.Fprintf(&sb, "go-import: %v\n", err)
+
+		return textResult(sb.String()), nil, nil
+	}
+
+	fmt.Fprintf(&sb, "go-import: %s %s %s\n", imp.prefix, imp.vcs, imp.repo)
+
+	if imp.vcs == "mod" {
+		fmt.Fprintf(&sb, "The tag names a module proxy for %s rather than its repository.\n", imp.prefix)
+	} else if mod != imp.prefix && inModule(mod, imp.prefix) {
+		fmt.Fprintf(&sb, "The module is in the %s directory of the repository rooted at %s.\n",
+			strings.TrimPrefix(mod, imp.prefix+"/"), imp.prefix)
+	}
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const goGetPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+<meta name="go-import" content="example.com/kit mod https://proxy.example.com">
+<meta name="go-import" content="example.com/kit git https://github.com/acme/kit">
+<meta name="go-source" content="example.com/kit https://github.com/acme/kit _ _">
+</head>
+<body>
+<meta name="go-import" content="example.com/other git https://github.com/acme/other">
+</body>
+</html>`
+
+func TestParseGoImports(t *testing.T) {
+	imports, err := parseGoImports(strings.NewReader(goGetPage))
+	mustf(t, err, "parse")
+
+	if len(imports) != 2 {
+		t.Fatalf("got %+v, want the two tags in the head", imports)
+	}
+
+	imp, ok := matchGoImport(imports, "example.com/kit/log")
+	if !ok || imp != (goImport{"example.com/kit", "git", "https://github.com/acme/kit"}) {
+		t.Errorf("match = %+v, %v; want the git tag", imp, ok)
+	}
+
+	if _, ok := matchGoImport(imports, "example.com/kitchen"); ok {
+		t.Error("example.com/kitchen should not match the example.com/kit prefix")
+	}
+
+	if imp, ok := matchGoImport(imports[:1], "example.com/kit"); !ok || imp.vcs != "mod" {
+		t.Errorf("mod-only match = %+v, %v", imp, ok)
+	}
+}
+
+func TestVanityResolver(t *testing.T) {
+	var requests atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.URL.Path {
+		case "/example.com/kit", "/example.com/kit/log":
+			_, _ = w.Write([]byte(goGetPage))
+		case "/example.com/liar/x":
+			_, _ = w.Write([]byte(`<meta name="go-import" content="example.com/liar git https://evil.example">`))
+		case "/example.com/liar":
+			_, _ = w.Write([]byte(`<meta name="go-import" content="example.com/liar git https://good.example">`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	r := NewVanityResolver()
+	r.baseURL = ts.URL
+	r.client = ts.Client()
+
+	for range 2 {
+		imp, err := r.Resolve(context.Background(), "example.com/kit/log")
+		if err != nil || imp.repo != "https://github.com/acme/kit" {
+			t.Errorf("kit/log = %+v, %v", imp, err)
+		}
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d requests, want 2: the page and its prefix, then the cache", n)
+	}
+
+	if _, err := r.Resolve(context.Background(), "example.com/liar/x"); err == nil {
+		t.Error("expected an error when the prefix page disagrees")
+	}
+
+	if _, err := r.Resolve(context.Background(), "example.com/none"); err == nil {
+		t.Error("expected an error for a page without tags")
+	}
+
+	r.offline = true
+	if _, err := r.Resolve(context.Background(), "example.com/kit"); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline: got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+var (
+	versionPrefixRe = regexp.MustCompile(`^v\d+(\.\d+)?$`)
+	versionTildeRe  = regexp.MustCompile(`^~v?(\d+)\.(\d+)(\.\d+)?$`)
+)
+
+// versionQuery selects a version from a module's version list.
+type versionQuery struct {
+	match     func(v string) bool
+	lowest    bool                   // pick the lowest match rather than the highest
+	published func(t time.Time) bool // if set, the publish times a match must have
+}
+
+// parseVersionQuery parses the version queries of the go command that
+// select from the version list, plus a tilde range:
+//
+//	v1, v1.2            the highest v1.x.x, v1.2.x
+//	<v2.0.0, <=v1.4.0   the highest version below, or at or below
+//	>v1.2.3, >=v1.2.3   the lowest version above, or at or above
+//	~1.4, ~v1.4.2       the highest v1.4.x, at or above v1.4.2
+//	<2024-06-01         the highest version published before a date or
+//	<=2024-06-01        RFC 3339 time, or at or before it (through the day)
+//
+// It returns false for anything else, including exact versions.
+func parseVersionQuery(q string) (versionQuery, bool) {
+	if published, ok := parsePublishedBound(q); ok {
+		return versionQuery{match: func(string) bool { return true }, published: published}, true
+	}
+
+	if versionPrefixRe.MatchString(q) {
+		return versionQuery{match: func(v string) bool { return strings.HasPrefix(v, q+".") }}, true
+	}
+
+	if m := versionTildeRe.FindStringSubmatch(q); m != nil {
+		minor := "v" + m[1] + "." + m[2]
+		low := minor + cmp.Or(m[3], ".0")
+
+		return versionQuery{match: func(v string) bool {
+			return semver.MajorMinor(v) == minor && semver.Compare(v, low) >= 0
+		}}, true
+	}
+
+	for _, op := range []string{"<=", ">=", "<", ">"} {
+		bound, ok := strings.CutPrefix(q, op)
+		if !ok || !semver.IsValid(bound) {
+			continue
+		}
+
+		accept := map[string]func(int) bool{
+			"<=": func(c int) bool { return c <= 0 },
+			">=": func(c int) bool { return c >= 0 },
+			"<":  func(c int) bool { return c < 0 },
+			">":  func(c int) bool { return c > 0 },
+		}[op]
+
+		return versionQuery{
+			match:  func(v string) bool { return accept(semver.Compare(v, bound)) },
+			lowest: op[0] == '>',
+		}, true
+	}
+
+	return versionQuery{}, false
+}
+
+// parsePublishedBound parses a <date or <=date query into a test of a
+// version's publish time. A date without a time is a UTC day.
+func parsePublishedBound(q string) (func(t time.Time) bool, bool) {
+	bound, inclusive := strings.CutPrefix(q, "<=")
+	if !inclusive {
+		var ok bool
+		if bound, ok = strings.CutPrefix(q, "<"); !ok {
+			return nil, false
+		}
+	}
+
+	limit, err := time.Parse(time.RFC3339, bound)
+	if err != nil {
+		if limit, err = time.Parse(time.DateOnly, bound); err != nil {
+			return nil, false
+		}
+
+		if inclusive {
+			limit, inclusive = limit.AddDate(0, 0, 1), false
+		}
+	}
+
+	return func(t time.Time) bool { return t.Before(limit) || inclusive && t.Equal(limit) }, true
+}
+
+// selectVersion picks the version the query selects from a sorted version
+// list. Like the go command, it prefers releases and falls back to
+// prereleases only when no release matches.
+func (q versionQuery) selectVersion(versions []string) (string, bool) {
+	var releases, prereleases []string
+
+	for _, v := range versions {
+		switch {
+		case !q.match(v):
+		case semver.Prerelease(v) == "":
+			releases = append(releases, v)
+		default:
+			prereleases = append(prereleases, v)
+		}
+	}
+
+	for _, list := range [][]string{releases, prereleases} {
+		if len(list) == 0 {
+			continue
+		}
+
+		if q.lowest {
+			return list[0], true
+		}
+
+		return list[len(list)-1], true
+	}
+
+	return "", false
+}
+
+// resolveVersionQuery resolves a version query against the module's
+// version list on the proxy.
+func resolveVersionQuery(ctx context.Context, proxy *ProxyClient, mod, query string, q versionQuery) (string, error) {
+	versions, err := proxy.ListVersions(ctx, mod)
+	if err != nil {
+		return "", fmt.Errorf("list versions for %q: %w", query, err)
+	}
+
+	var (
+		version string
+		ok      bool
+	)
+
+	if q.published != nil {
+		if version, ok, err = publishedVersion(ctx, proxy, mod, versions, q.published); err != nil {
+			return "", fmt.Errorf("version times for %q: %w", query, err)
+		}
+	} else {
+		version, ok = q.selectVersion(versions)
+	}
+
+	if !ok {
+		return "", fmt.Errorf("no version of %s matches %q among the %d listed", mod, query, len(versions))
+	}
+
+	return version, nil
+}
+
+// publishedVersion returns the highest of the sorted versions whose
+// .info publish time passes the published test, preferring releases to
+// prereleases like selectVersion. It walks down from the highest version,
+// fetching the times of scanConcurrency versions at a time, and stops at
+// the first that passes, so versions below it are never asked about.
+func publishedVersion(
+	ctx context.Context, proxy *ProxyClient, mod string, versions []string, published func(time.Time) bool,
+) (string, bool, error) {
+	var releases, prereleases []string
+
+	for _, v := range slices.Backward(versions) {
+		if semver.Prerelease(v) == "" {
+			releases = append(releases, v)
+		} else {
+			prereleases = append(prereleases, v)
+		}
+	}
+
+	for _, list := range [][]string{releases, prereleases} {
+		for len(list) > 0 {
+			batch := list[:min(scanConcurrency, len(list))]
+			list = list[len(batch):]
+
+			ok := make([]bool, len(batch))
+			errs := make([]error, len(batch))
+
+			parallelEach(batch, func(i int, v string) {
+				info, err := proxy.Info(ctx, mod, v)
+				if err != nil {
+					errs[i] = err
+
+					return
+				}
+
+				ok[i] = !info.Time.IsZero() && published(info.Time)
+			})
+
+			for i, v := range batch {
+				// A listed version the proxy has no .info for is skipped.
+				if errs[i] != nil && !errors.Is(errs[i], ErrModuleNotFound) {
+					return "", false, errs[i]
+				}
+
+				if ok[i] {
+					return v, true, nil
+				}
+			}
+		}
+	}
+
+	return "", false, nil
+}
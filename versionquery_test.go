@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVersionQuery(t *testing.T) {
+	versions := []string{"v0.9.0", "v1.0.0", "v1.2.0-rc.1", "v1.2.0", "v1.2.5", "v1.3.0", "v1.4.0", "v1.4.2",
+		"v1.5.0-rc.1", "v2.0.0-beta.1"}
+
+	for query, want := range map[string]string{
+		"v1":       "v1.4.2",
+		"v1.2":     "v1.2.5",
+		"v2":       "v2.0.0-beta.1", // only a prerelease matches
+		"v3":       "",
+		"<v1.2.5":  "v1.2.0",
+		"<=v1.2.5": "v1.2.5",
+		">v1.2.0":  "v1.2.5",
+		">=v1.2.0": "v1.2.0",
+		"<v2.0.0":  "v1.4.2",
+		"~1.4":     "v1.4.2",
+		"~v1.2.1":  "v1.2.5",
+		"~1.5":     "", // v1.5.0-rc.1 is below v1.5.0
+		"~1.4.3":   "",
+	} {
+		q, ok := parseVersionQuery(query)
+		if !ok {
+			t.Errorf("parseVersionQuery(%q) failed", query)
+
+			continue
+		}
+
+		if got, _ := q.selectVersion(versions); got != want {
+			t.Errorf("%s selects %q, want %q", query, got, want)
+		}
+	}
+
+	for _, query := range []string{"v1.2.3", "latest", "1.2", "<1.2.0", "~1", "v1.2.x", "master"} {
+		if _, ok := parseVersionQuery(query); ok {
+			t.Errorf("parseVersionQuery(%q) should not be a query", query)
+		}
+	}
+}
+
+func TestParsePublishedBound(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	noon := day.Add(12 * time.Hour)
+
+	for _, tt := range []struct {
+		query string
+		t     time.Time
+		want  bool
+	}{
+		{"<2024-06-01", day.Add(-time.Second), true},
+		{"<2024-06-01", day, false},
+		{"<=2024-06-01", noon, true},
+		{"<=2024-06-01", day.AddDate(0, 0, 1), false},
+		{"<2024-06-01T12:00:00Z", noon, false},
+		{"<=2024-06-01T12:00:00Z", noon, true},
+		{"<=2024-06-01T14:00:00+02:00", noon, true},
+	} {
+		published, ok := parsePublishedBound(tt.query)
+		if !ok || published(tt.t) != tt.want {
+			t.Errorf("%s at %v: ok %v, want %v", tt.query, tt.t, ok, tt.want)
+		}
+	}
+
+	for _, query := range []string{">2024-06-01", "<2024-13-01", "<v1.2.0", "2024-06-01"} {
+		if _, ok := parsePublishedBound(query); ok {
+			t.Errorf("parsePublishedBound(%q) should fail", query)
+		}
+	}
+}
+
+func TestResolveVersionQuery_Published(t *testing.T) {
+	times := map[string]string{
+		"v1.0.0": "2023-01-10T00:00:00Z", "v1.1.0": "2023-06-01T00:00:00Z",
+		"v1.2.0-rc.1": "2023-06-20T00:00:00Z", "v1.2.0": "2024-02-01T00:00:00Z",
+	}
+
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, _ := strings.CutPrefix(r.URL.Path, "/example.com/mod/@v/")
+		if v == "list" {
+			_, _ = w.Write([]byte("v1.0.0\nv1.1.0\nv1.2.0-rc.1\nv1.2.0\nv1.3.0\n"))
+
+			return
+		}
+
+		// v1.3.0 has no .info and is skipped.
+		if published, ok := times[strings.TrimSuffix(v, ".info")]; ok {
+			_, _ = w.Write([]byte(`{"Version":"` + strings.TrimSuffix(v, ".info") + `","Time":"` + published + `"}`))
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	for query, want := range map[string]string{
+		"<2023-06-01":  "v1.0.0",
+		"<=2023-06-01": "v1.1.0",
+		"<2024-01-01":  "v1.1.0", // releases before prereleases
+		"<2030-01-01":  "v1.2.0",
+	} {
+		q, _ := parseVersionQuery(query)
+		if got, err := resolveVersionQuery(context.Background(), proxy, "example.com/mod", query, q); got != want {
+			t.Errorf("%s = %q (%v), want %q", query, got, err, want)
+		}
+	}
+
+	q, _ := parseVersionQuery("<2020-01-01")
+
+	_, err := resolveVersionQuery(context.Background(), proxy, "example.com/mod", "<2020-01-01", q)
+	if err == nil || !strings.Contains(err.Error(), "among the 5 listed") {
+		t.Errorf("expected no match before 2020: %v", err)
+	}
+}
+
+func TestResolveVersionQuery_PublishedStopsEarly(t *testing.T) {
+	var infos atomic.Int32
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var list strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&list, "v1.%d.0\n", i)
+	}
+
+	proxy, ts := newTestProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, _ := strings.CutPrefix(r.URL.Path, "/example.com/mod/@v/")
+		if v == "list" {
+			_, _ = w.Write([]byte(list.String()))
+
+			return
+		}
+
+		infos.Add(1)
+
+		v = strings.TrimSuffix(v, ".info")
+		minor, _ := strconv.Atoi(strings.Split(v, ".")[1])
+
+		fmt.Fprintf(w, `{"Version":%q,"Time":%q}`, v, start.AddDate(0, 0, minor).Format(time.RFC3339))
+	}))
+	defer ts.Close()
+
+	q, _ := parseVersionQuery("<2020-02-07")
+
+	got, err := resolveVersionQuery(context.Background(), proxy, "example.com/mod", "<2020-02-07", q)
+	if got != "v1.36.0" {
+		t.Errorf("got %q (%v), want v1.36.0", got, err)
+	}
+
+	if n := infos.Load(); n > scanConcurrency {
+		t.Errorf("%d .info requests for a version near the top of 40", n)
+	}
+}
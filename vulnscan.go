@@ -0,0 +1,268 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// vulnFinding is one vulnerability and the scanned module versions it
+// affects.
+type vulnFinding struct {
+	vuln     *osvVuln
+	severity string
+	affected []module.Version
+}
+
+// vulnSeverity returns the rating of v: the advisory database's own
+// label, the CVSS v3 vector's score, or the label of a GHSA alias (Go
+// vulndb records usually carry no severity themselves).
+func vulnSeverity(ctx context.Context, osv *OSVClient, v *osvVuln) string {
+	if s := recordSeverity(v); s != severityUnknown {
+		return s
+	}
+
+	for _, alias := range v.Aliases {
+		if !strings.HasPrefix(alias, "GHSA-") {
+			continue
+		}
+
+		if a, err := osv.Vuln(ctx, alias); err == nil {
+			if s := recordSeverity(a); s != severityUnknown {
+				return s
+			}
+		}
+	}
+
+	return severityUnknown
+}
+
+func recordSeverity(v *osvVuln) string {
+	if s := normalizeSeverity(v.DatabaseSpecific.Severity); s != severityUnknown {
+		return s
+	}
+
+	for _, s := range v.Severity {
+		if score, ok := cvss3BaseScore(s.Score); ok && strings.HasPrefix(s.Type, "CVSS_V3") {
+			return cvss3Rating(score)
+		}
+	}
+
+	return severityUnknown
+}
+
+// scanVulns queries OSV for every version and returns the findings sorted
+// by severity, then ID.
+func scanVulns(ctx context.Context, osv *OSVClient, versions []module.Version) ([]*vulnFinding, error) {
+	ids, err := osv.QueryBatch(ctx, versions)
+	if err != nil {
+		return nil, fmt.Errorf("query OSV: %w", err)
+	}
+
+	byID := make(map[string]*vulnFinding)
+
+	for i, vulnIDs := range ids {
+		for _, id := range vulnIDs {
+			f, ok := byID[id]
+			if !ok {
+				f = &vulnFinding{}
+				byID[id] = f
+			}
+
+			f.affected = append(f.affected, versions[i])
+		}
+	}
+
+	vulnIDs := sortedKeys(byID)
+	findings := make([]*vulnFinding, len(vulnIDs))
+
+	for i, id := range vulnIDs {
+		findings[i] = byID[id]
+	}
+
+	var (
+		mu       sync.Mutex
+		fetchErr error
+	)
+
+	parallelEach(vulnIDs, func(i int, id string) {
+		v, err := osv.Vuln(ctx, id)
+		if err != nil {
+			mu.Lock()
+			fetchErr = fmt.Errorf("fetch %s: %w", id, err)
+			mu.Unlock()
+
+			return
+		}
+
+		findings[i].vuln = v
+		findings[i].severity = vulnSeverity(ctx, osv, v)
+	})
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	rank := make(map[string]int, len(severityOrder))
+	for i, s := range severityOrder {
+		rank[s] = i
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return rank[findings[i].severity] < rank[findings[j].severity]
+	})
+
+	return findings, nil
+}
+
+func formatVulnFindings(sb *strings.Builder, findings []*vulnFinding) {
+	if len(findings) == 0 {
+		sb.WriteString("\nNo known vulnerabilities found.\n")
+
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.severity]++
+	}
+
+	current := ""
+
+	for _, f := range findings {
+		if f.severity != current {
+			current = f.severity
+			fmt.Fprintf(sb, "\n%s (%d):\n", current, counts[current])
+		}
+
+		id := f.vuln.ID
+		if len(f.vuln.Aliases) > 0 {
+			id += " (" + strings.Join(f.vuln.Aliases, ", ") + ")"
+		}
+
+		fmt.Fprintf(sb, "  %s: %s\n", id, f.vuln.title())
+
+		for _, v := range f.affected {
+			fmt.Fprintf(sb, "    %s", v)
+
+			if fixed := f.vuln.fixedVersions(v.Path); len(fixed) > 0 {
+				fmt.Fprintf(sb, " (fixed in %s)", strings.Join(fixed, ", "))
+			}
+
+			sb.WriteString("\n")
+
+			if symbols := f.vuln.affectedSymbols(v.Path); len(symbols) > 0 {
+				fmt.Fprintf(sb, "      affected: %s\n", strings.Join(symbols, ", "))
+			}
+		}
+	}
+}
+
+// lowestFix returns the lowest version of the module above current that
+// fixes all the findings, or "" and the ID of a vulnerability with no fix
+// above current.
+func lowestFix(findings []*vulnFinding, mod, current string) (string, string) {
+	lowest := ""
+
+	for _, f := range findings {
+		fix := ""
+
+		for _, v := range f.vuln.fixedVersions(mod) {
+			if semver.Compare(v, current) > 0 && (fix == "" || semver.Compare(v, fix) < 0) {
+				fix = v
+			}
+		}
+
+		if fix == "" {
+			return "", f.vuln.ID
+		}
+
+		if semver.Compare(fix, lowest) > 0 {
+			lowest = fix
+		}
+	}
+
+	return lowest, ""
+}
+
+type osvScanInput struct {
+	Dir   string `json:"dir,omitempty" jsonschema:"Project directory with go.mod and go.sum (default: bound project)"`
+	GoSum string `json:"go_sum,omitempty" jsonschema:"go.sum content, instead of dir"`
+	GoMod string `json:"go_mod,omitempty" jsonschema:"go.mod content, scanned when no go.sum is available"`
+
+	Module  string `json:"module,omitempty" jsonschema:"Scan this module alone, instead of a project"`
+	Version string `json:"version,omitempty" jsonschema:"Version of module, or 'latest' (default)"`
+}
+
+func handleOSVScan(
+	ctx context.Context, proxy *ProxyClient, osv *OSVClient, binding *projectBinding, input osvScanInput,
+) (*mcp.CallToolResult, any, error) {
+	if input.Module != "" {
+		return scanModuleVulns(ctx, proxy, osv, input.Module, cmp.Or(input.Version, "latest"))
+	}
+
+	proj, err := projectFromInput(binding, input.Dir, input.GoMod, input.GoSum)
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+
+	versions := proj.scanVersions()
+
+	findings, err := scanVulns(ctx, osv, versions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Scanned %d module versions", len(versions))
+
+	if name := proj.modulePath(); name != "" {
+		fmt.Fprintf(&sb, " of %s", name)
+	}
+
+	fmt.Fprintf(&sb, " against OSV: %d vulnerabilities.\n", len(findings))
+
+	formatVulnFindings(&sb, findings)
+
+	return textResult(sb.String()), nil, nil
+}
+
+// scanModuleVulns reports the vulnerabilities of one module version, and
+// the lowest version to upgrade to that fixes them all.
+func scanModuleVulns(
+	ctx context.Context, proxy *ProxyClient, osv *OSVClient, mod, version string,
+) (*mcp.CallToolResult, any, error) {
+	version, err := resolveVersion(ctx, proxy, mod, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	findings, err := scanVulns(ctx, osv, []module.Version{{Path: mod, Version: version}})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Scanned %s@%s against OSV: %d vulnerabilities.\n", mod, version, len(findings))
+
+	if len(findings) > 0 {
+		if fix, unfixed := lowestFix(findings, mod, version); unfixed != "" {
+			fmt.Fprintf(&sb, "No later version fixes %s.\n", unfixed)
+		} else {
+			fmt.Fprintf(&sb, "Upgrade to %s or later to fix them all.\n", fix)
+		}
+	}
+
+	formatVulnFindings(&sb, findings)
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// osvServer fakes OSV: example.com/a@1.0.0 has a Go vulndb entry whose
+// severity comes from its GHSA alias, example.com/b@2.0.0 a record with a
+// CVSS vector.
+func osvServer(t *testing.T, queries *[]osvQuery) *httptest.Server {
+	t.Helper()
+
+	vulns := map[string]string{
+		"GO-2024-0001": `{"id":"GO-2024-0001","summary":"Panic on crafted input","aliases":["GHSA-aaaa-bbbb-cccc"],
+"affected":[{"package":{"name":"example.com/a","ecosystem":"Go"},
+"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"1.0.1"}]}],
+"ecosystem_specific":{"imports":[{"path":"example.com/a/parse","symbols":["Parse","Decoder.Decode"]}]}}]}`,
+		"GHSA-aaaa-bbbb-cccc": `{"id":"GHSA-aaaa-bbbb-cccc","database_specific":{"severity":"MODERATE"}}`,
+		"GO-2024-0002": `{"id":"GO-2024-0002","details":"Remote code execution.\nMore text.",
+"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}]}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/querybatch" {
+			var req struct {
+				Queries []osvQuery `json:"queries"`
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode query: %v", err)
+			}
+
+			*queries = req.Queries
+
+			type result struct {
+				Vulns []map[string]string `json:"vulns"`
+			}
+
+			results := make([]result, len(req.Queries))
+
+			for i, q := range req.Queries {
+				switch q.Package.Name + "@" + q.Version {
+				case "example.com/a@1.0.0":
+					results[i].Vulns = []map[string]string{{"id": "GO-2024-0001"}}
+				case "example.com/b@2.0.0":
+					results[i].Vulns = []map[string]string{{"id": "GO-2024-0002"}}
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": results})
+
+			return
+		}
+
+		body, ok := vulns[strings.TrimPrefix(r.URL.Path, "/v1/vulns/")]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestHandleOSVScan(t *testing.T) {
+	var queries []osvQuery
+
+	ts := osvServer(t, &queries)
+	defer ts.Close()
+
+	osv := &OSVClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+
+	goSum := `example.com/a v1.0.0 h1:aaa=
+example.com/a v1.0.0/go.mod h1:bbb=
+example.com/b v2.0.0 h1:ccc=
+example.com/c v0.1.0/go.mod h1:ddd=
+example.com/d v0.2.0 h1:eee=
+`
+
+	result, _, err := handleOSVScan(context.Background(), nil, osv, nil, osvScanInput{GoSum: goSum})
+	mustf(t, err, "scan")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	if len(queries) != 3 || queries[0].Version != "1.0.0" || queries[0].Package.Ecosystem != "Go" {
+		t.Errorf("unexpected queries (go.mod-only entries must be skipped): %+v", queries)
+	}
+
+	for _, want := range []string{
+		"Scanned 3 module versions against OSV: 2 vulnerabilities.",
+		"CRITICAL (1):\n  GO-2024-0002: Remote code execution.\n    example.com/b@v2.0.0\n",
+		"MEDIUM (1):\n  GO-2024-0001 (GHSA-aaaa-bbbb-cccc): Panic on crafted input\n" +
+			"    example.com/a@v1.0.0 (fixed in v1.0.1)\n" +
+			"      affected: example.com/a/parse.Parse, example.com/a/parse.Decoder.Decode\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+
+	if strings.Index(text, "CRITICAL") > strings.Index(text, "MEDIUM") {
+		t.Errorf("expected CRITICAL before MEDIUM:\n%s", text)
+	}
+}
+
+func TestHandleOSVScan_BoundProject(t *testing.T) {
+	var queries []osvQuery
+
+	ts := osvServer(t, &queries)
+	defer ts.Close()
+
+	osv := &OSVClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+	dir := t.TempDir()
+
+	writeTree(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n\nrequire example.com/d v0.2.0\n",
+	})
+
+	binding := &projectBinding{}
+	binding.Set(dir)
+
+	result, _, err := handleOSVScan(context.Background(), nil, osv, binding, osvScanInput{})
+	mustf(t, err, "scan")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	if !strings.Contains(text, "Scanned 1 module versions of example.com/app") ||
+		!strings.Contains(text, "No known vulnerabilities found.") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+}
+
+func TestHandleOSVScan_Module(t *testing.T) {
+	var queries []osvQuery
+
+	ts := osvServer(t, &queries)
+	defer ts.Close()
+
+	osv := &OSVClient{apiClient{baseURL: ts.URL, client: ts.Client()}}
+
+	scan := func(mod, version string) string {
+		result, _, err := handleOSVScan(context.Background(), nil, osv, nil, osvScanInput{Module: mod, Version: version})
+		mustf(t, err, "scan")
+
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := scan("example.com/a", "v1.0.0")
+	if len(queries) != 1 || !strings.Contains(text, "Scanned example.com/a@v1.0.0 against OSV: 1 vulnerabilities.\n"+
+		"Upgrade to v1.0.1 or later to fix them all.\n") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	if text := scan("example.com/b", "v2.0.0"); !strings.Contains(text, "No later version fixes GO-2024-0002.\n") {
+		t.Errorf("unexpected output for an unfixed vulnerability:\n%s", text)
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	defaultWatchInterval = 15 * time.Minute
+	maxWatchEvents       = 500
+)
+
+// Kinds of watch events.
+const (
+	eventNewVersion  = "new_version"
+	eventRetraction  = "retraction"
+	eventDeprecation = "deprecation"
+)
+
+// watchEvent is a change observed on a watched module.
+type watchEvent struct {
+	seq    int
+	time   time.Time
+	module string
+	kind   string
+	detail string
+}
+
+func (e watchEvent) String() string {
+	return fmt.Sprintf("#%d %s %s %s: %s", e.seq, e.time.Format(time.RFC3339), e.module, e.kind, e.detail)
+}
+
+// moduleSnapshot is what the proxy reported about a module at one poll.
+type moduleSnapshot struct {
+	versions   map[string]bool
+	retracted  map[string]string // version interval -> rationale
+	deprecated string
+}
+
+type watchState struct {
+	snapshot *moduleSnapshot
+	since    time.Time
+	lastPoll time.Time
+	lastErr  error
+}
+
+// Watcher polls the proxy for watched modules and records new versions,
+// retractions and deprecations as events.
+type Watcher struct {
+	proxy    *ProxyClient
+	interval time.Duration
+
+	mu      sync.Mutex
+	watches map[string]*watchState
+	events  []watchEvent
+	nextSeq int
+	notify  func(ctx context.Context, e watchEvent)
+}
+
+// NewWatcher creates a Watcher that polls every interval once scheduled.
+func NewWatcher(proxy *ProxyClient, interval time.Duration) *Watcher {
+	return &Watcher{
+		proxy:    proxy,
+		interval: interval,
+		watches:  make(map[string]*watchState),
+		nextSeq:  1,
+	}
+}
+
+// SetNotifier registers a function called for every new event, e.g. to
+// forward it to connected clients.
+func (w *Watcher) SetNotifier(notify func(ctx context.Context, e watchEvent)) {
+	w.mu.Lock()
+	w.notify = notify
+	w.mu.Unlock()
+}
+
+// Add starts watching a module. The current state is recorded as the
+// baseline, so only later changes produce events.
+func (w *Watcher) Add(ctx context.Context, module string) error {
+	snap, err := w.snapshot(ctx, module)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.watches[module]; !ok {
+		w.watches[module] = &watchState{snapshot: snap, since: now, lastPoll: now}
+	}
+
+	return nil
+}
+
+// Remove stops watching a module and reports whether it was watched.
+func (w *Watcher) Remove(module string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, ok := w.watches[module]
+	delete(w.watches, module)
+
+	return ok
+}
+
+// Schedule registers the watcher's polling as a job on s.
+func (w *Watcher) Schedule(s *Scheduler) {
+	s.Every("watch", w.interval, func(ctx context.Context) error {
+		w.Poll(ctx)
+
+		return nil
+	})
+}
+
+// Poll checks every watched module once and records any changes.
+func (w *Watcher) Poll(ctx context.Context) {
+	for _, module := range w.Modules() {
+		snap, err := w.snapshot(ctx, module)
+
+		w.mu.Lock()
+
+		state, ok := w.watches[module]
+		if !ok {
+			w.mu.Unlock()
+
+			continue
+		}
+
+		state.lastPoll = time.Now()
+		state.lastErr = err
+
+		var events []watchEvent
+
+		if err == nil {
+			events = w.recordChanges(module, state.snapshot, snap)
+			state.snapshot = snap
+		}
+
+		notify := w.notify
+
+		w.mu.Unlock()
+
+		if err != nil {
+			log.Printf("warning: watch poll for %s failed: %v", module, err)
+		}
+
+		if notify != nil {
+			for _, e := range events {
+				notify(ctx, e)
+			}
+		}
+	}
+}
+
+// Modules returns the watched module paths, sorted.
+func (w *Watcher) Modules() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	modules := make([]string, 0, len(w.watches))
+
+	for m := range w.watches {
+		modules = append(modules, m)
+	}
+
+	sort.Strings(modules)
+
+	return modules
+}
+
+// watchStatus summarises one watched module.
+type watchStatus struct {
+	module   string
+	since    time.Time
+	lastPoll time.Time
+	lastErr  error
+	versions int
+}
+
+// Status returns the state of every watched module, sorted by path.
+func (w *Watcher) Status() []watchStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := make([]watchStatus, 0, len(w.watches))
+
+	for m, state := range w.watches {
+		result = append(result, watchStatus{
+			module:   m,
+			since:    state.since,
+			lastPoll: state.lastPoll,
+			lastErr:  state.lastErr,
+			versions: len(state.snapshot.versions),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].module < result[j].module })
+
+	return result
+}
+
+// Events returns the recorded events with a sequence number greater than
+// since, oldest first.
+func (w *Watcher) Events(since int) []watchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var result []watchEvent
+
+	for _, e := range w.events {
+		if e.seq > since {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// recordChanges diffs two snapshots and appends the resulting events.
+// Must be called with w.mu held.
+func (w *Watcher) recordChanges(module string, prev, cur *moduleSnapshot) []watchEvent {
+	var added []watchEvent
+
+	emit := func(kind, detail string) {
+		e := watchEvent{seq: w.nextSeq, time: time.Now(), module: module, kind: kind, detail: detail}
+		w.nextSeq++
+
+		added = append(added, e)
+	}
+
+	versions := sortedKeys(cur.versions)
+	semver.Sort(versions)
+
+	for _, v := range versions {
+		if !prev.versions[v] {
+			emit(eventNewVersion, v)
+		}
+	}
+
+	for _, interval := range sortedKeys(cur.retracted) {
+		if _, ok := prev.retracted[interval]; !ok {
+			detail := interval
+			if rationale := cur.retracted[interval]; rationale != "" {
+				detail += " (" + rationale + ")"
+			}
+
+			emit(eventRetraction, detail)
+		}
+	}
+
+	if cur.deprecated != "" && cur.deprecated != prev.deprecated {
+		emit(eventDeprecation, cur.deprecated)
+	}
+
+	w.events = append(w.events, added...)
+	if len(w.events) > maxWatchEvents {
+		w.events = w.events[len(w.events)-maxWatchEvents:]
+	}
+
+	return added
+}
+
+// snapshot fetches the version list and the latest go.mod's retractions
+// and deprecation notice.
+func (w *Watcher) snapshot(ctx context.Context, module string) (*moduleSnapshot, error) {
+	versions, err := w.proxy.ListVersions(ctx, module)
+	if err != nil {
+		return nil, fmt.Errorf("list versions: %w", err)
+	}
+
+	snap := &moduleSnapshot{
+		versions:  make(map[string]bool, len(versions)),
+		retracted: make(map[string]string),
+	}
+
+	for _, v := range versions {
+		snap.versions[v] = true
+	}
+
+	latest, mf, err := latestModFile(ctx, w.proxy, module)
+	if err != nil {
+		return nil, err
+	}
+
+	snap.versions[latest] = true
+	snap.deprecated = moduleDeprecation(mf)
+
+	for _, r := range mf.Retract {
+		snap.retracted[formatVersionInterval(r.Low, r.High)] = r.Rationale
+	}
+
+	return snap, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// notifySessions returns a watch notifier that sends events as MCP log
+// messages to every connected session.
+func notifySessions(server *mcp.Server) func(ctx context.Context, e watchEvent) {
+	return func(ctx context.Context, e watchEvent) {
+		for ss := range server.Sessions() {
+			_ = ss.Log(ctx, &mcp.LoggingMessageParams{
+				Level:  "notice",
+				Logger: "gomod_watch",
+				Data:   e.String(),
+			})
+		}
+	}
+}
+
+type watchInput struct {
+	Module string `json:"module" jsonschema:"Go module path to watch"`
+	Remove bool   `json:"remove,omitempty" jsonschema:"Stop watching the module instead"`
+}
+
+func handleWatch(ctx context.Context, watcher *Watcher, input watchInput) (*mcp.CallToolResult, any, error) {
+	if input.Remove {
+		if !watcher.Remove(input.Module) {
+			return errorResult(fmt.Sprintf("Module %q is not being watched.", input.Module)), nil, nil
+		}
+
+		return textResult(fmt.Sprintf("Stopped watching %s.", input.Module)), nil, nil
+	}
+
+	if err := watcher.Add(ctx, input.Module); err != nil {
+		return nil, nil, err
+	}
+
+	return textResult(fmt.Sprintf(
+		"Watching %s for new versions, retractions and deprecations (polling every %s). "+
+			"Use gomod_watch_events to see changes.", input.Module, watcher.interval,
+	)), nil, nil
+}
+
+type watchEventsInput struct {
+	Since int `json:"since,omitempty" jsonschema:"Only return events with a sequence number greater than this"`
+}
+
+func handleWatchEvents(watcher *Watcher, input watchEventsInput) (*mcp.CallToolResult, any, error) {
+	var sb strings.Builder
+
+	status := watcher.Status()
+
+	fmt.Fprintf(&sb, "Watched modules (%d):\n", len(status))
+
+	for _, s := range status {
+		fmt.Fprintf(&sb, "  %s (%d versions, since %s, last poll %s)\n",
+			s.module, s.versions, s.since.Format(time.RFC3339), s.lastPoll.Format(time.RFC3339))
+
+		if s.lastErr != nil {
+			fmt.Fprintf(&sb, "    last poll failed: %v\n", s.lastErr)
+		}
+	}
+
+	events := watcher.Events(input.Since)
+
+	fmt.Fprintf(&sb, "\nEvents (%d):\n", len(events))
+
+	for _, e := range events {
+		fmt.Fprintf(&sb, "  %s\n", e)
+	}
+
+	return textResult(sb.String()), nil, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mutableProxy serves a single module whose version list and latest go.mod
+// can be changed between polls.
+type mutableProxy struct {
+	mu       sync.Mutex
+	versions []string
+	goMod    string
+}
+
+func (p *mutableProxy) set(goMod string, versions ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.versions = versions
+	p.goMod = goMod
+}
+
+func (p *mutableProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	latest := p.versions[len(p.versions)-1]
+
+	switch r.URL.Path {
+	case "/example.com/mod/@v/list":
+		_, _ = w.Write([]byte(strings.Join(p.versions, "\n") + "\n"))
+	case "/example.com/mod/@latest":
+		_, _ = w.Write([]byte(`{"Version":"` + latest + `"}`))
+	case "/example.com/mod/@v/" + latest + ".mod":
+		_, _ = w.Write([]byte(p.goMod))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	fake := &mutableProxy{}
+	fake.set("module example.com/mod\n", "v1.0.0", "v1.1.0")
+
+	proxy, ts := newTestProxy(fake)
+	defer ts.Close()
+
+	watcher := NewWatcher(proxy, time.Hour)
+
+	var notified []watchEvent
+
+	watcher.SetNotifier(func(_ context.Context, e watchEvent) {
+		notified = append(notified, e)
+	})
+
+	ctx := context.Background()
+
+	if err := watcher.Add(ctx, "example.com/mod"); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher.Poll(ctx)
+
+	if events := watcher.Events(0); len(events) != 0 {
+		t.Fatalf("expected no events without changes, got %v", events)
+	}
+
+	fake.set(`// Deprecated: use example.com/mod/v2 instead.
+module example.com/mod
+
+retract v1.1.0 // Broken build.
+`, "v1.0.0", "v1.1.0", "v1.2.0")
+
+	watcher.Poll(ctx)
+
+	events := watcher.Events(0)
+
+	want := []struct{ kind, detail string }{
+		{eventNewVersion, "v1.2.0"},
+		{eventRetraction, "v1.1.0 (Broken build.)"},
+		{eventDeprecation, "use example.com/mod/v2 instead."},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+
+	for i, w := range want {
+		if events[i].kind != w.kind || events[i].detail != w.detail {
+			t.Errorf("event %d = %s %q, want %s %q", i, events[i].kind, events[i].detail, w.kind, w.detail)
+		}
+	}
+
+	if len(notified) != len(want) {
+		t.Errorf("notifier got %d events, want %d", len(notified), len(want))
+	}
+
+	if got := watcher.Events(events[1].seq); len(got) != 1 || got[0].kind != eventDeprecation {
+		t.Errorf("Events(since) = %v, want only the deprecation", got)
+	}
+
+	watcher.Poll(ctx)
+
+	if got := watcher.Events(events[2].seq); len(got) != 0 {
+		t.Errorf("expected no new events on unchanged poll, got %v", got)
+	}
+}
+
+func TestWatcherRemove(t *testing.T) {
+	fake := &mutableProxy{}
+	fake.set("module example.com/mod\n", "v1.0.0")
+
+	proxy, ts := newTestProxy(fake)
+	defer ts.Close()
+
+	watcher := NewWatcher(proxy, time.Hour)
+
+	if err := watcher.Add(context.Background(), "example.com/mod"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !watcher.Remove("example.com/mod") {
+		t.Error("expected Remove to report the module as watched")
+	}
+
+	if watcher.Remove("example.com/mod") {
+		t.Error("expected second Remove to report false")
+	}
+
+	if modules := watcher.Modules(); len(modules) != 0 {
+		t.Errorf("expected no watched modules, got %v", modules)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// ErrInvalidZip is returned for a downloaded module zip that the go command
+// would refuse to extract.
+var ErrInvalidZip = errors.New("invalid module zip")
+
+// checkZip checks a downloaded module zip against the constraints of the
+// module zip format, as the go command does before it extracts one: every
+// file under the module@version/ prefix, valid and unique file names
+// that stay inside the module, and the size limits. modzip.CheckZip only
+// reads files, so the zip is written to a temporary one first.
+func checkZip(mod, version string, data []byte) error {
+	tmp, err := os.CreateTemp("", "gomod-*.zip")
+	if err != nil {
+		return fmt.Errorf("create temp zip: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("write temp zip: %w", err)
+	}
+
+	if _, err := modzip.CheckZip(module.Version{Path: mod, Version: version}, tmp.Name()); err != nil {
+		return fmt.Errorf("check %s@%s zip: %w: %w", mod, version, ErrInvalidZip, err)
+	}
+
+	return nil
+}
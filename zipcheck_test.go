@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckZip(t *testing.T) {
+	const prefix = "example.com/m@v1.0.0/"
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string // "" for a valid zip
+	}{
+		{"valid", createTestZip(t, prefix, map[string]string{"go.mod": "module example.com/m\n", "a/a.go": "package a\n"}),
+			""},
+		{"not a zip", []byte("<html>proxy error</html>"), "not a valid zip file"},
+		{"wrong prefix", createTestZip(t, "example.com/other@v1.0.0/", map[string]string{"a.go": "package a\n"}),
+			`does not have prefix "example.com/m@v1.0.0/"`},
+		{"path escape", createTestZip(t, prefix, map[string]string{"../evil.go": "package evil\n"}), "../evil.go"},
+		{"case collision", createTestZip(t, prefix, map[string]string{"a.go": "package a\n", "A.go": "package a\n"}),
+			"case-insensitive file name collision"},
+		{"oversized go.mod", createTestZip(t, prefix, map[string]string{"go.mod": strings.Repeat("a", 16<<20+1)}),
+			"go.mod file too large"},
+	} {
+		err := checkZip("example.com/m", "v1.0.0", tt.data)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+
+			continue
+		}
+
+		if !errors.Is(err, ErrInvalidZip) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestProxyClient_DownloadZipRejectsInvalidZip(t *testing.T) {
+	zipData := createTestZip(t, "example.com/testmod@v1.0.0/", map[string]string{"../../outside.go": "package x\n"})
+
+	proxy, ts := newTestProxy(fakeProxy(zipData))
+	defer ts.Close()
+
+	disk, err := OpenDiskCache(t.TempDir())
+	mustf(t, err, "open disk cache")
+
+	proxy.disk = disk
+
+	if _, err := proxy.DownloadZip(context.Background(), "example.com/testmod", "v1.0.0"); !errors.Is(err,
+		ErrInvalidZip) {
+		t.Fatalf("DownloadZip = %v, want an invalid zip error", err)
+	}
+
+	if _, ok := disk.Get("example.com/testmod", "v1.0.0", "zip"); ok {
+		t.Error("the invalid zip was cached on disk")
+	}
+}